@@ -0,0 +1,315 @@
+// Package check verifies translation files against their source without calling a
+// model: missing and extra keys, untranslated values, broken placeholders and markup,
+// terminology violations and translations whose source changed since they were made.
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/rtl"
+	"github.com/hikanner/jta/internal/terminology"
+	"github.com/hikanner/jta/internal/tm"
+	"github.com/hikanner/jta/internal/utils"
+)
+
+// Rule identifies a kind of issue
+type Rule string
+
+const (
+	RuleMissingKey   Rule = "missing-key"  // key of the source missing or empty in the translation
+	RuleExtraKey     Rule = "extra-key"    // key of the translation that is not in the source
+	RuleUntranslated Rule = "untranslated" // value identical to the source
+	RulePlaceholder  Rule = "placeholder"  // placeholders or markup differ from the source
+	RuleTerminology  Rule = "terminology"  // preserve or consistent term not followed
+	RuleStale        Rule = "stale"        // source changed since the value was translated
+)
+
+// Rules are all rules with their default severity, in report order
+var Rules = []struct {
+	Rule        Rule
+	Severity    Severity
+	Description string
+}{
+	{RuleMissingKey, SeverityError, "Key of the source is missing or empty in the translation"},
+	{RuleExtraKey, SeverityWarning, "Key of the translation is not in the source"},
+	{RuleUntranslated, SeverityWarning, "Value is identical to the source"},
+	{RulePlaceholder, SeverityError, "Placeholders or markup differ from the source"},
+	{RuleTerminology, SeverityWarning, "Terminology is not followed"},
+	{RuleStale, SeverityWarning, "Source changed since the value was translated"},
+}
+
+// Severity is how serious an issue is
+type Severity int
+
+const (
+	SeverityOff Severity = iota // rule disabled
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = map[Severity]string{
+	SeverityOff:     "off",
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalText writes the severity by name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity parses off, info, warning or error
+func ParseSeverity(name string) (Severity, error) {
+	for severity, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return severity, nil
+		}
+	}
+	return SeverityOff, domain.NewValidationError("unknown severity (want off, info, warning or error)", nil).
+		WithContext("severity", name)
+}
+
+// ParseRule parses a rule name
+func ParseRule(name string) (Rule, error) {
+	for _, r := range Rules {
+		if string(r.Rule) == name {
+			return r.Rule, nil
+		}
+	}
+	return "", domain.NewValidationError("unknown rule", nil).
+		WithContext("rule", name)
+}
+
+// Issue is a problem found in a translation file
+type Issue struct {
+	Rule     Rule     `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`           // file the issue is in (the source for missing keys)
+	Line     int      `json:"line,omitempty"` // line of the key in the file, 0 = unknown
+	Target   string   `json:"target"`         // translation file
+	Lang     string   `json:"lang"`
+	Key      string   `json:"key,omitempty"` // empty for issues of the whole file
+	Message  string   `json:"message"`
+}
+
+// Target is a translation file to check against its source
+type Target struct {
+	SourcePath string
+	SourceLang string
+	Path       string
+	Lang       string
+}
+
+// Checker checks translation files
+type Checker struct {
+	protector    *format.Protector
+	rtlProcessor *rtl.Processor
+	terminology  *domain.Terminology
+	translations map[string]*domain.TerminologyTranslation // target language -> term translations
+	memory       *tm.Memory
+	severities   map[Rule]Severity
+	jsonUtil     *utils.JSONUtil
+}
+
+// NewChecker creates a checker with the default severities
+func NewChecker() *Checker {
+	severities := make(map[Rule]Severity)
+	for _, r := range Rules {
+		severities[r.Rule] = r.Severity
+	}
+	return &Checker{
+		protector:    format.NewProtector(),
+		rtlProcessor: rtl.NewProcessor(),
+		translations: make(map[string]*domain.TerminologyTranslation),
+		severities:   severities,
+		jsonUtil:     utils.NewJSONUtil(),
+	}
+}
+
+// SetTerminology enables the terminology rule. Translations are per target language;
+// languages without one are only checked for preserve terms.
+func (c *Checker) SetTerminology(term *domain.Terminology, translations map[string]*domain.TerminologyTranslation) {
+	c.terminology = term
+	maps.Copy(c.translations, translations)
+}
+
+// SetMemory enables the stale rule, which needs to know what each translation was made from
+func (c *Checker) SetMemory(memory *tm.Memory) {
+	c.memory = memory
+}
+
+// SetSeverity changes the severity of a rule; SeverityOff disables it
+func (c *Checker) SetSeverity(rule Rule, severity Severity) {
+	c.severities[rule] = severity
+}
+
+// Check returns the issues of a translation file, ordered by key. A missing
+// translation file is a single missing-key issue.
+func (c *Checker) Check(target Target) ([]Issue, error) {
+	sourceData, err := os.ReadFile(target.SourcePath)
+	if err != nil {
+		return nil, domain.NewIOError("failed to read source file", err).
+			WithContext("path", target.SourcePath)
+	}
+	sources, err := c.flatten(sourceData, target.SourcePath)
+	if err != nil {
+		return nil, err
+	}
+	sourceLines := keyLines(sourceData)
+
+	var issues []Issue
+	add := func(rule Rule, path string, line int, key, message string) {
+		if severity := c.severities[rule]; severity != SeverityOff {
+			issues = append(issues, Issue{
+				Rule:     rule,
+				Severity: severity,
+				Path:     path,
+				Line:     line,
+				Target:   target.Path,
+				Lang:     target.Lang,
+				Key:      key,
+				Message:  message,
+			})
+		}
+	}
+
+	targetData, err := os.ReadFile(target.Path)
+	if os.IsNotExist(err) {
+		add(RuleMissingKey, target.Path, 0, "", fmt.Sprintf("translation file not found (%d keys to translate)", len(sources)))
+		return issues, nil
+	}
+	if err != nil {
+		return nil, domain.NewIOError("failed to read translation file", err).
+			WithContext("path", target.Path)
+	}
+	translations, err := c.flatten(targetData, target.Path)
+	if err != nil {
+		return nil, err
+	}
+	targetLines := keyLines(targetData)
+
+	// Same base language (en-GB from en): identical values are expected
+	sameLang := baseLanguage(target.SourceLang) == baseLanguage(target.Lang)
+
+	for _, key := range slices.Sorted(maps.Keys(sources)) {
+		source := sources[key]
+		translated, ok := translations[key]
+		if !ok || (translated == "" && source != "") {
+			add(RuleMissingKey, target.SourcePath, sourceLines[key], key, fmt.Sprintf("not translated: %q", source))
+			continue
+		}
+
+		line := targetLines[key]
+		if translated == source && !sameLang && c.translatable(source) {
+			add(RuleUntranslated, target.Path, line, key, fmt.Sprintf("identical to the source: %q", source))
+		}
+		if message := c.formatMismatch(source, translated); message != "" {
+			add(RulePlaceholder, target.Path, line, key, message)
+		}
+		if previous, ok := c.previousSource(target, key, source, translated); ok {
+			add(RuleStale, target.Path, line, key, fmt.Sprintf("source changed from %q to %q since it was translated", previous, source))
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(translations)) {
+		if _, ok := sources[key]; !ok {
+			add(RuleExtraKey, target.Path, targetLines[key], key, "not in the source")
+		}
+	}
+
+	if c.terminology != nil {
+		checker := terminology.NewChecker(c.terminology, c.translations[target.Lang], target.Lang)
+		for _, v := range checker.CheckAll(sources, translations) {
+			add(RuleTerminology, target.Path, targetLines[v.Key], v.Key, v.Message)
+		}
+	}
+
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return issues, nil
+}
+
+// flatten parses a JSON file into its string values by key path
+func (c *Checker) flatten(data []byte, path string) (map[string]string, error) {
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, domain.NewFormatError("failed to parse JSON", err).
+			WithContext("path", path)
+	}
+	return c.jsonUtil.FlattenStrings(values), nil
+}
+
+// translatable reports whether a source text has words to translate, besides
+// placeholders, markup, URLs and preserve terms
+func (c *Checker) translatable(text string) bool {
+	for _, elem := range c.protector.Extract(text) {
+		text = strings.ReplaceAll(text, elem.Value, " ")
+	}
+	if c.terminology != nil {
+		for _, term := range c.terminology.PreserveTerms {
+			text = strings.ReplaceAll(text, term, " ")
+		}
+	}
+	return strings.IndexFunc(text, unicode.IsLetter) >= 0
+}
+
+// formatMismatch describes placeholders and markup of the source missing from the
+// translation, or placeholders and tags the translation added
+func (c *Checker) formatMismatch(source, translated string) string {
+	// Right-to-left translations mark URLs and numbers left-to-right; the marks aren't
+	// part of the elements
+	translated = c.rtlProcessor.StripDirectionalMarks(translated)
+	report := c.protector.GetValidationReport(source, translated)
+	problems := report.Errors
+	var extra []string
+	for _, elem := range report.ExtraElements {
+		if (elem.Type == format.ElementTypePlaceholder || elem.Type == format.ElementTypeHTML) && !slices.Contains(extra, elem.Value) {
+			extra = append(extra, elem.Value)
+		}
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "Unexpected "+strings.Join(extra, ", "))
+	}
+	return strings.Join(problems, "; ")
+}
+
+// previousSource returns the source text a translation was made from, if the
+// translation memory knows it and the source has changed since
+func (c *Checker) previousSource(target Target, key, source, translated string) (string, bool) {
+	if c.memory == nil {
+		return "", false
+	}
+	// The current source has this translation: nothing changed
+	if e, ok := c.memory.Exact(target.SourceLang, target.Lang, source); ok && e.Target == translated {
+		return "", false
+	}
+	previous, ok := c.memory.SourceOf(target.SourceLang, target.Lang, key, translated)
+	if !ok || previous == source {
+		return "", false
+	}
+	return previous, true
+}
+
+// baseLanguage returns the language without region (pt for pt-BR)
+func baseLanguage(lang string) string {
+	if code, ok := domain.NormalizeLanguageCode(lang); ok {
+		lang = code
+	}
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
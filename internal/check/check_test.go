@@ -0,0 +1,322 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/tm"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// issueRules returns the rule of each issue by key ("" for the whole file)
+func issueRules(issues []Issue) map[string][]Rule {
+	rules := make(map[string][]Rule)
+	for _, issue := range issues {
+		rules[issue.Key] = append(rules[issue.Key], issue.Rule)
+	}
+	return rules
+}
+
+func TestChecker_Check(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	target := filepath.Join(dir, "de.json")
+	writeFile(t, source, `{
+  "app": {
+    "title": "Jta Cloud",
+    "save": "Save",
+    "greeting": "Hello {name}",
+    "count": "42",
+    "workspace": "Open workspace",
+    "empty": "Nothing here",
+    "new": "New string"
+  }
+}`)
+	writeFile(t, target, `{
+  "app": {
+    "title": "Jta Cloud",
+    "save": "Save",
+    "greeting": "Hallo {nom}",
+    "count": "42",
+    "workspace": "Arbeitsbereich öffnen",
+    "empty": "",
+    "old": "Alt"
+  }
+}`)
+
+	checker := NewChecker()
+	checker.SetTerminology(
+		&domain.Terminology{SourceLanguage: "en", PreserveTerms: []string{"Jta Cloud"}, ConsistentTerms: []string{"workspace"}},
+		map[string]*domain.TerminologyTranslation{"de": {SourceLanguage: "en", TargetLanguage: "de", Translations: map[string]string{"workspace": "Workspace"}}},
+	)
+	issues, err := checker.Check(Target{SourcePath: source, SourceLang: "en", Path: target, Lang: "de"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	got := issueRules(issues)
+	want := map[string][]Rule{
+		"app.save":      {RuleUntranslated},
+		"app.greeting":  {RulePlaceholder},
+		"app.workspace": {RuleTerminology},
+		"app.empty":     {RuleMissingKey},
+		"app.new":       {RuleMissingKey},
+		"app.old":       {RuleExtraKey},
+	}
+	for key, rules := range want {
+		if strings.Join(ruleNames(got[key]), ",") != strings.Join(ruleNames(rules), ",") {
+			t.Errorf("%s: rules = %v, want %v", key, got[key], rules)
+		}
+	}
+	// Brand names and numbers stay the same in every language
+	for _, key := range []string{"app.title", "app.count"} {
+		if len(got[key]) > 0 {
+			t.Errorf("%s: unexpected issues %v", key, got[key])
+		}
+	}
+
+	for _, issue := range issues {
+		switch issue.Key {
+		case "app.new":
+			// Missing keys point at the source, where the string was added
+			if issue.Path != source || issue.Line != 9 || issue.Severity != SeverityError {
+				t.Errorf("missing key issue = %+v", issue)
+			}
+		case "app.greeting":
+			if issue.Path != target || issue.Line != 5 || !strings.Contains(issue.Message, "{name}") || !strings.Contains(issue.Message, "{nom}") {
+				t.Errorf("placeholder issue = %+v", issue)
+			}
+		}
+	}
+}
+
+func TestChecker_Check_RTL(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	target := filepath.Join(dir, "ar.json")
+	writeFile(t, source, `{"help": "See https://example.com/help for {count} tips"}`)
+	// URLs of right-to-left translations are wrapped in left-to-right marks
+	writeFile(t, target, `{"help": "راجع \u200ehttps://example.com/help\u200e للحصول على {count} نصائح"}`)
+
+	issues, err := NewChecker().Check(Target{SourcePath: source, SourceLang: "en", Path: target, Lang: "ar"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) > 0 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
+
+func ruleNames(rules []Rule) []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = string(r)
+	}
+	return names
+}
+
+func TestChecker_Check_MissingFileAndSeverity(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	writeFile(t, source, `{"a": "One", "b": "Two"}`)
+
+	checker := NewChecker()
+	issues, err := checker.Check(Target{SourcePath: source, SourceLang: "en", Path: filepath.Join(dir, "fr.json"), Lang: "fr"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Rule != RuleMissingKey || issues[0].Key != "" {
+		t.Fatalf("issues = %+v, want one missing file", issues)
+	}
+
+	// Same text in the same base language is fine; a disabled rule reports nothing
+	writeFile(t, filepath.Join(dir, "en-GB.json"), `{"a": "One", "b": "Two", "c": "Three"}`)
+	checker.SetSeverity(RuleExtraKey, SeverityOff)
+	issues, err = checker.Check(Target{SourcePath: source, SourceLang: "en", Path: filepath.Join(dir, "en-GB.json"), Lang: "en-GB"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("issues = %+v, want none", issues)
+	}
+}
+
+func TestChecker_Check_Stale(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	target := filepath.Join(dir, "de.json")
+	writeFile(t, source, `{"save": "Save all", "cancel": "Cancel", "edited": "Delete"}`)
+	writeFile(t, target, `{"save": "Speichern", "cancel": "Abbrechen", "edited": "Entfernen"}`)
+
+	memory, err := tm.Load(filepath.Join(dir, "tm.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "de", Source: "Save", Target: "Speichern", Key: "save"})
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "de", Source: "Cancel", Target: "Abbrechen", Key: "cancel"})
+	memory.Add(tm.Entry{SourceLang: "en", TargetLang: "de", Source: "Delete", Target: "Löschen", Key: "edited"})
+
+	checker := NewChecker()
+	checker.SetMemory(memory)
+	issues, err := checker.Check(Target{SourcePath: source, SourceLang: "en", Path: target, Lang: "de"})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	// A translation edited by hand is not stale
+	if len(issues) != 1 || issues[0].Rule != RuleStale || issues[0].Key != "save" {
+		t.Fatalf("issues = %+v, want save stale", issues)
+	}
+	if !strings.Contains(issues[0].Message, `"Save"`) {
+		t.Errorf("message = %q, want the previous source", issues[0].Message)
+	}
+}
+
+func TestKeyLines(t *testing.T) {
+	data := []byte(`{
+  "a": "x",
+  "b": {
+    "c": 1,
+    "d": "y",
+    "e": [
+      "z",
+      {"f": "w"}
+    ]
+  },
+  "g":
+    "v"
+}`)
+	want := map[string]int{"a": 2, "b.d": 5, "b.e[0]": 7, "b.e[1].f": 8, "g": 12}
+	got := keyLines(data)
+	for key, line := range want {
+		if got[key] != line {
+			t.Errorf("line of %s = %d, want %d", key, got[key], line)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("keyLines() = %v", got)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, name := range []string{"off", "info", "warning", "ERROR"} {
+		if _, err := ParseSeverity(name); err != nil {
+			t.Errorf("ParseSeverity(%q) error = %v", name, err)
+		}
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("ParseSeverity(fatal) should fail")
+	}
+}
+
+func testReport() *Report {
+	report := &Report{}
+	report.Add(Target{SourcePath: "en.json", Path: "de.json", Lang: "de"}, []Issue{
+		{Rule: RuleMissingKey, Severity: SeverityError, Path: "en.json", Line: 3, Target: "de.json", Lang: "de", Key: "title", Message: `not translated: "Title"`},
+		{Rule: RuleUntranslated, Severity: SeverityWarning, Path: "de.json", Line: 2, Target: "de.json", Lang: "de", Key: "save", Message: `identical to the source: "Save"`},
+	})
+	report.Add(Target{SourcePath: "en.json", Path: "fr.json", Lang: "fr"}, nil)
+	return report
+}
+
+func TestReport_Failed(t *testing.T) {
+	report := testReport()
+	tests := map[Severity]bool{SeverityOff: false, SeverityInfo: true, SeverityWarning: true, SeverityError: true}
+	for failOn, want := range tests {
+		if got := report.Failed(failOn); got != want {
+			t.Errorf("Failed(%s) = %v, want %v", failOn, got, want)
+		}
+	}
+
+	warnings := &Report{Issues: []Issue{{Severity: SeverityWarning}}}
+	if warnings.Failed(SeverityError) {
+		t.Error("warnings should not fail at error severity")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report := testReport()
+
+	var text bytes.Buffer
+	if err := report.Write(&text, "text", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), `en.json:3: error [missing-key] title (de): not translated: "Title"`) {
+		t.Errorf("text report = %s", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := report.Write(&js, "json", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Files  int `json:"files"`
+		Errors int `json:"errors"`
+		Issues []struct {
+			Severity string `json:"severity"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(js.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if parsed.Files != 2 || parsed.Errors != 1 || len(parsed.Issues) != 2 || parsed.Issues[0].Severity != "error" {
+		t.Errorf("JSON report = %s", js.String())
+	}
+
+	var junit bytes.Buffer
+	if err := report.Write(&junit, "junit", "1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit report: %v", err)
+	}
+	if suites.Tests != 3 || suites.Failures != 2 || len(suites.Suites) != 2 || suites.Suites[1].Cases[0].Failure != nil {
+		t.Errorf("JUnit report = %s", junit.String())
+	}
+
+	var sarif bytes.Buffer
+	if err := report.Write(&sarif, "sarif", "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(sarif.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF report: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("SARIF report = %s", sarif.String())
+	}
+	if r := log.Runs[0].Results[1]; r.RuleID != "untranslated" || r.Level != "warning" || r.Locations[0].PhysicalLocation.Region.StartLine != 2 {
+		t.Errorf("SARIF result = %+v", r)
+	}
+
+	if err := report.Write(&bytes.Buffer{}, "html", ""); err == nil {
+		t.Error("Write() with unknown format should fail")
+	}
+}
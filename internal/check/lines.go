@@ -0,0 +1,91 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// keyLines returns the line of every string value in a JSON document, by the key
+// paths of utils.JSONUtil.FlattenStrings ("a.b", "a[0]"). Invalid JSON yields the
+// lines found before the error.
+func keyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	// One frame per open object or array: its path, the pending key of an object,
+	// the next index of an array
+	type frame struct {
+		path  string
+		array bool
+		key   string
+		index int
+	}
+	var stack []frame
+	expectKey := false
+
+	// Offsets only grow, so the newlines are counted from the last one
+	line, counted := 1, int64(0)
+	lineAt := func(offset int64) int {
+		line += bytes.Count(data[counted:offset], []byte("\n"))
+		counted = offset
+		return line
+	}
+	// valuePath returns the path of the value about to be read in the innermost frame
+	valuePath := func() string {
+		top := &stack[len(stack)-1]
+		if top.array {
+			path := fmt.Sprintf("%s[%d]", top.path, top.index)
+			top.index++
+			return path
+		}
+		if top.path == "" {
+			return top.key
+		}
+		return top.path + "." + top.key
+	}
+
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return lines
+		}
+
+		if len(stack) > 0 && !stack[len(stack)-1].array && expectKey {
+			if key, ok := token.(string); ok {
+				stack[len(stack)-1].key = key
+				expectKey = false
+				continue
+			}
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				path := ""
+				if len(stack) > 0 {
+					path = valuePath()
+				}
+				stack = append(stack, frame{path: path, array: t == '['})
+				expectKey = t == '{'
+				continue
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if len(stack) > 0 {
+				// The token starts after the separator before it
+				offset := start + int64(bytes.IndexByte(data[start:], '"'))
+				lines[valuePath()] = lineAt(offset)
+			}
+		default:
+			if len(stack) > 0 {
+				valuePath()
+			}
+		}
+		// After a value, an object expects its next key
+		expectKey = len(stack) > 0 && !stack[len(stack)-1].array
+	}
+}
@@ -0,0 +1,275 @@
+package check
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// Formats are the report formats
+var Formats = []string{"text", "json", "junit", "sarif"}
+
+// Report is the result of checking translation files
+type Report struct {
+	Targets []Target
+	Issues  []Issue
+}
+
+// Add records the issues of a checked translation file
+func (r *Report) Add(target Target, issues []Issue) {
+	r.Targets = append(r.Targets, target)
+	r.Issues = append(r.Issues, issues...)
+}
+
+// Count returns the number of issues of a severity
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether there are issues at or above a severity; SeverityOff never fails
+func (r *Report) Failed(failOn Severity) bool {
+	if failOn == SeverityOff {
+		return false
+	}
+	for _, issue := range r.Issues {
+		if issue.Severity >= failOn {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the report in one of Formats; version is the jta version for SARIF
+func (r *Report) Write(w io.Writer, format, version string) error {
+	switch format {
+	case "text", "":
+		return r.WriteText(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "junit":
+		return r.WriteJUnit(w)
+	case "sarif":
+		return r.WriteSARIF(w, version)
+	default:
+		return domain.NewValidationError("unknown report format (want text, json, junit or sarif)", nil).
+			WithContext("format", format)
+	}
+}
+
+// WriteText writes one line per issue, in the file:line form editors and CI logs link
+func (r *Report) WriteText(w io.Writer) error {
+	for _, issue := range r.Issues {
+		location := issue.Path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", issue.Path, issue.Line)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s [%s] %s: %s\n", location, issue.Severity, issue.Rule, issue.subject(), issue.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subject names what an issue is about: the key and language, or the translation file
+func (i Issue) subject() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s (%s)", i.Target, i.Lang)
+	}
+	return fmt.Sprintf("%s (%s)", i.Key, i.Lang)
+}
+
+// WriteJSON writes the issues with totals per severity
+func (r *Report) WriteJSON(w io.Writer) error {
+	issues := r.Issues
+	if issues == nil {
+		issues = []Issue{}
+	}
+	report := struct {
+		Files    int     `json:"files"`
+		Errors   int     `json:"errors"`
+		Warnings int     `json:"warnings"`
+		Infos    int     `json:"infos"`
+		Issues   []Issue `json:"issues"`
+	}{
+		Files:    len(r.Targets),
+		Errors:   r.Count(SeverityError),
+		Warnings: r.Count(SeverityWarning),
+		Infos:    r.Count(SeverityInfo),
+		Issues:   issues,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a test suite per translation file with a failed test case per
+// issue; a file without issues has one passing test case
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{Name: "jta check"}
+	for _, target := range r.Targets {
+		suite := junitTestSuite{Name: target.Path}
+		for _, issue := range r.Issues {
+			if issue.Target != target.Path || issue.Lang != target.Lang {
+				continue
+			}
+			name := string(issue.Rule)
+			if issue.Key != "" {
+				name += " " + issue.Key
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      name,
+				ClassName: target.Path,
+				Failure: &junitFailure{
+					Message: issue.Message,
+					Type:    issue.Severity.String(),
+					Text:    fmt.Sprintf("%s:%d: %s", issue.Path, issue.Line, issue.Message),
+				},
+			})
+			suite.Failures++
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: "check", ClassName: target.Path})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteSARIF writes a SARIF 2.1.0 log, as read by GitHub code scanning and other tools
+func (r *Report) WriteSARIF(w io.Writer, version string) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID                   string  `json:"id"`
+		ShortDescription     message `json:"shortDescription"`
+		DefaultConfiguration struct {
+			Level string `json:"level"`
+		} `json:"defaultConfiguration"`
+	}
+	type region struct {
+		StartLine int `json:"startLine"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region *region `json:"region,omitempty"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	var rules []rule
+	for _, def := range Rules {
+		ru := rule{ID: string(def.Rule), ShortDescription: message{Text: def.Description}}
+		ru.DefaultConfiguration.Level = sarifLevel(def.Severity)
+		rules = append(rules, ru)
+	}
+
+	results := []result{}
+	for _, issue := range r.Issues {
+		var loc location
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(issue.Path)
+		if issue.Line > 0 {
+			loc.PhysicalLocation.Region = &region{StartLine: issue.Line}
+		}
+		results = append(results, result{
+			RuleID:    string(issue.Rule),
+			Level:     sarifLevel(issue.Severity),
+			Message:   message{Text: issue.subject() + ": " + issue.Message},
+			Locations: []location{loc},
+		})
+	}
+
+	log := map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool": map[string]any{"driver": map[string]any{
+				"name":           "jta",
+				"version":        strings.TrimPrefix(version, "v"),
+				"informationUri": "https://github.com/hikanner/jta",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(log)
+}
+
+// sarifLevel maps a severity to a SARIF level
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
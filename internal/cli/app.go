@@ -1,19 +1,29 @@
 package cli
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/hikanner/jta/internal/domain"
 	"github.com/hikanner/jta/internal/incremental"
+	"github.com/hikanner/jta/internal/length"
+	"github.com/hikanner/jta/internal/lock"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
+	"github.com/hikanner/jta/internal/review"
+	"github.com/hikanner/jta/internal/style"
 	"github.com/hikanner/jta/internal/terminology"
+	"github.com/hikanner/jta/internal/tm"
 	"github.com/hikanner/jta/internal/translator"
 	"github.com/hikanner/jta/internal/ui"
 	"github.com/hikanner/jta/internal/utils"
@@ -21,10 +31,23 @@ import (
 
 // AppConfig contains application configuration
 type AppConfig struct {
-	Provider string
-	Model    string
-	APIKey   string
-	Verbose  bool
+	Provider          string
+	Model             string
+	APIKey            string
+	Verbose           bool
+	Quiet             bool               // print only warnings and errors
+	Logger            *slog.Logger       // progress of translations and terminology, nil = discarded
+	Progress          *ui.Progress       // view of the batches of the run, nil = none
+	Concurrency       int                // upper bound for in-flight API requests
+	RequestsPerMinute int                // 0 = unlimited
+	TokensPerMinute   int                // 0 = unlimited
+	PricingFile       string             // JSON price overrides (optional)
+	MaxCost           float64            // USD spending cap for the whole run, 0 = unlimited
+	CacheDir          string             // response cache directory, empty = caching disabled
+	CacheTTL          time.Duration      // how long cached responses are reused, 0 = forever
+	Cassette          string             // replay from this file (replay provider) or record into it
+	TerminologyStore  string             // glossary file or service URL shared across projects, empty = terminology dir
+	Budget            *translator.Budget // spending cap shared with other apps of the run, nil = own cap from MaxCost
 }
 
 // TranslateParams contains parameters for translation
@@ -43,6 +66,16 @@ type TranslateParams struct {
 	BatchSize       int
 	Concurrency     int
 	Yes             bool
+	DryRun          bool                           // estimate items, tokens and cost without calling the API
+	NoTM            bool                           // don't consult or update the translation memory
+	TMThreshold     float64                        // minimum similarity for fuzzy references (0 = default)
+	StyleGuide      *domain.StyleGuide             // style guide from the config, used when there is no style guide file
+	Locked          string                         // key patterns whose translations are kept as is
+	TermTexts       []string                       // texts of all source files sharing the terminology, empty = this source's
+	Terminology     *domain.Terminology            // prepared once for all translations, nil = load or detect it
+	TermTranslation *domain.TerminologyTranslation // prepared once per target language, nil = load or translate it
+	Label           string                         // output prefix when translations run at the same time, e.g. "[de] "
+	Name            string                         // name in the progress view, e.g. "de" or "common.json de"
 }
 
 // App is the main application
@@ -54,6 +87,23 @@ type App struct {
 	jsonUtil    *utils.JSONUtil
 	config      AppConfig
 	ui          *ui.Printer
+	pricing     provider.ModelPricing
+	pricingOK   bool                        // whether the model's price is known
+	cache       *provider.CachingProvider   // nil when caching is disabled
+	recorder    *provider.RecordingProvider // nil unless recording a cassette
+	budget      *translator.Budget          // nil without a spending cap
+	label       string                      // output prefix of a translation running alongside others
+	logger      *slog.Logger                // progress of the translation, with its file and language
+	state       *appState                   // shared by the app's concurrent translations
+}
+
+// appState is the state an app's concurrent translations share
+type appState struct {
+	memoryMu sync.Mutex
+	memories map[string]*tm.Memory // translation memory per terminology dir
+
+	promptsMu sync.Mutex
+	prompts   map[string]*prompt.Templates // prompt templates per terminology dir
 }
 
 // NewApp creates a new application instance
@@ -64,12 +114,13 @@ func NewApp(ctx context.Context, config AppConfig) (*App, error) {
 	var prov provider.AIProvider
 	var err error
 
-	if config.APIKey != "" {
-		// Use provided API key
+	if config.APIKey != "" || providerType == provider.ProviderTypeReplay {
+		// Use provided API key (the replay provider needs none)
 		prov, err = provider.NewProvider(ctx, &provider.ProviderConfig{
-			Type:   providerType,
-			APIKey: config.APIKey,
-			Model:  config.Model,
+			Type:     providerType,
+			APIKey:   config.APIKey,
+			Model:    config.Model,
+			Cassette: config.Cassette,
 		})
 	} else {
 		// Use environment variable
@@ -80,34 +131,272 @@ func NewApp(ctx context.Context, config AppConfig) (*App, error) {
 		return nil, fmt.Errorf("failed to create provider: %w", err)
 	}
 
+	// Record real traffic when a cassette is given for a live provider
+	if config.Cassette != "" && providerType != provider.ProviderTypeReplay {
+		prov = provider.NewRecordingProvider(prov)
+	}
+
+	return NewAppWithProvider(config, prov)
+}
+
+// NewAppWithProvider creates an application around an existing provider.
+// Rate limiting and caching are layered on top as configured.
+func NewAppWithProvider(config AppConfig, prov provider.AIProvider) (*App, error) {
+	recorder, _ := prov.(*provider.RecordingProvider)
+
+	// Share one rate budget per provider+model across all callers
+	prov = provider.NewRateLimitedProvider(prov, provider.RateLimits{
+		RequestsPerMinute: config.RequestsPerMinute,
+		TokensPerMinute:   config.TokensPerMinute,
+		MaxConcurrency:    config.Concurrency,
+	})
+
+	// Cache outside the rate limiter so hits don't consume rate budget
+	var cache *provider.CachingProvider
+	if config.CacheDir != "" {
+		cache = provider.NewCachingProvider(prov, provider.NewResponseCache(config.CacheDir, config.CacheTTL))
+		prov = cache
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
 	// Create terminology manager
-	termManager := terminology.NewManager(prov)
+	termManager, err := newTermManager(prov, config.TerminologyStore)
+	if err != nil {
+		return nil, err
+	}
+	termManager.SetLogger(logger)
+
+	// Resolve model pricing for cost estimates and the budget
+	pricingTable, err := provider.LoadPricingTable(config.PricingFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing: %w", err)
+	}
+	pricing, pricingOK := pricingTable.Lookup(prov.GetModelName())
+	if config.MaxCost > 0 && !pricingOK {
+		return nil, domain.NewConfigError("--max-cost requires pricing for the model; add it with --pricing", nil).
+			WithContext("model", prov.GetModelName())
+	}
 
-	// Create translation engine
-	engine := translator.NewEngine(prov, termManager)
+	// One budget caps the whole run, across all target languages and models
+	budget := config.Budget.WithPricing(pricing)
+	if budget == nil && config.MaxCost > 0 {
+		budget = translator.NewBudget(config.MaxCost, pricing)
+	}
 
 	// Create incremental translator
 	incrTranslator := incremental.NewTranslator()
 
-	return &App{
+	printer := ui.NewPrinter(config.Verbose)
+	printer.SetQuiet(config.Quiet)
+	if config.Progress != nil {
+		printer.SetOutput(config.Progress)
+	}
+
+	app := &App{
 		provider:    prov,
 		termManager: termManager,
-		engine:      engine,
 		incr:        incrTranslator,
 		jsonUtil:    utils.NewJSONUtil(),
 		config:      config,
-		ui:          ui.NewPrinter(config.Verbose),
-	}, nil
+		ui:          printer,
+		pricing:     pricing,
+		pricingOK:   pricingOK,
+		cache:       cache,
+		recorder:    recorder,
+		budget:      budget,
+		logger:      logger,
+		state:       &appState{},
+	}
+	app.engine = app.newEngine()
+	return app, nil
+}
+
+// newEngine creates a translation engine with the app's pricing, budget and logger
+func (a *App) newEngine() *translator.Engine {
+	engine := translator.NewEngine(a.provider, a.termManager)
+	engine.SetPricing(a.pricing)
+	engine.SetBudget(a.budget)
+	engine.SetLogger(a.logger)
+	return engine
+}
+
+// forTranslation returns a copy of the app for one translation, with its own engine
+// and a logger naming the file and language, so several translations can run at the
+// same time. A label starts their output lines.
+func (a *App) forTranslation(params TranslateParams) *App {
+	t := *a
+	t.label = params.Label
+	t.ui = a.ui.WithPrefix(params.Label)
+	t.logger = a.logger.With("file", params.SourcePath, "lang", params.TargetLang)
+	if params.Label != "" {
+		t.logger = t.logger.With(ui.LabelKey, params.Label)
+	}
+	t.engine = t.newEngine()
+	return &t
+}
+
+// appPool creates the applications of a run lazily, one per provider and model, so target
+// languages can use different models while sharing the spending cap
+type appPool struct {
+	ctx    context.Context
+	config AppConfig
+	apps   map[string]*App
+	order  []*App
+}
+
+// newAppPool creates a pool of applications with a common configuration
+func newAppPool(ctx context.Context, config AppConfig) *appPool {
+	return &appPool{ctx: ctx, config: config, apps: make(map[string]*App)}
+}
+
+// get returns the application of a provider and model, creating it on first use
+func (p *appPool) get(providerName, model string) (*App, error) {
+	id := providerName + "/" + model
+	if app, ok := p.apps[id]; ok {
+		return app, nil
+	}
+	// A cassette records or replays the traffic of a single provider
+	if p.config.Cassette != "" && len(p.apps) > 0 {
+		return nil, domain.NewConfigError("--cassette can't be used with different providers or models per language", nil).
+			WithContext("provider", providerName).
+			WithContext("model", model)
+	}
+
+	config := p.config
+	config.Provider = providerName
+	config.Model = model
+	if len(p.order) > 0 {
+		config.Budget = p.order[0].budget
+	}
+
+	app, err := NewApp(p.ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	p.apps[id] = app
+	p.order = append(p.order, app)
+	return app, nil
+}
+
+// Close closes every application of the pool
+func (p *appPool) Close() error {
+	var errs []error
+	for _, app := range p.order {
+		errs = append(errs, app.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// newTermManager creates the terminology manager for a terminology store (see terminology.OpenRepository).
+// Glossary services are authenticated with JTA_TERMINOLOGY_TOKEN.
+func newTermManager(prov provider.AIProvider, store string) (*terminology.Manager, error) {
+	repository, err := terminology.OpenRepository(store, os.Getenv("JTA_TERMINOLOGY_TOKEN"))
+	if err != nil {
+		return nil, err
+	}
+	return terminology.NewManagerWithRepository(prov, repository, store), nil
 }
 
-// Translate performs the translation workflow
-func (a *App) Translate(ctx context.Context, params TranslateParams) error {
+// termLocation describes where a terminology file is saved: the shared store, or the file in the terminology directory
+func (a *App) termLocation(terminologyDir, file string) string {
+	if a.config.TerminologyStore != "" {
+		return a.config.TerminologyStore
+	}
+	return filepath.Join(terminologyDir, file)
+}
+
+// printLengthViolations lists translations that are still longer than their limit
+func (a *App) printLengthViolations(violations []domain.LengthViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	const shown = 10
+	a.ui.PrintWarning(fmt.Sprintf("%d translations are longer than their limit:", len(violations)))
+	for i, v := range violations {
+		if i == shown && !a.config.Verbose {
+			a.ui.PrintSubtle(fmt.Sprintf("... and %d more (use --verbose to list all)", len(violations)-shown))
+			break
+		}
+		a.ui.PrintSubtle(fmt.Sprintf("[%s] %d > %s: %q", v.Key, v.Length, v.Limit, v.Text))
+	}
+}
+
+// lengthLimits are the length limits of a source file: rules by key pattern from the
+// terminology directory, and per-key limits from the source's sidecar metadata
+type lengthLimits struct {
+	rules []domain.LengthRule
+	keys  map[string]domain.LengthLimit
+}
+
+// loadLengthLimits loads the length limits of a source file
+func loadLengthLimits(terminologyDir, sourcePath string) (lengthLimits, error) {
+	rules, err := length.LoadRules(terminologyDir)
+	if err != nil {
+		return lengthLimits{}, err
+	}
+	keys, err := length.LoadSidecar(sourcePath)
+	if err != nil {
+		return lengthLimits{}, err
+	}
+	return lengthLimits{rules: rules, keys: keys}, nil
+}
+
+// usePrompts loads the prompt templates of a terminology directory for translation and
+// terminology, printing which defaults are overridden. The templates of a directory are
+// loaded once and shared by the app's translations.
+func (a *App) usePrompts(terminologyDir string) error {
+	a.state.promptsMu.Lock()
+	defer a.state.promptsMu.Unlock()
+
+	prompts, ok := a.state.prompts[terminologyDir]
+	if !ok {
+		var err error
+		prompts, err = prompt.Load(terminologyDir)
+		if err != nil {
+			return err
+		}
+		for _, name := range prompt.Names {
+			if path := prompts.Source(name); path != "" {
+				a.ui.PrintSubtle(fmt.Sprintf("Using %s prompt template %s", name, path))
+			}
+		}
+		if a.state.prompts == nil {
+			a.state.prompts = make(map[string]*prompt.Templates)
+		}
+		a.state.prompts[terminologyDir] = prompts
+		a.termManager.SetPrompts(prompts)
+	}
+	a.engine.SetPrompts(prompts)
+	return nil
+}
+
+// Close flushes state that outlives a single translation, such as a recorded cassette
+func (a *App) Close() error {
+	if a.recorder == nil {
+		return nil
+	}
+	if err := a.recorder.Save(a.config.Cassette); err != nil {
+		return fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return nil
+}
+
+// Translate performs the translation workflow. It returns the result of the translation,
+// or nil if nothing was translated (dry run, no changes).
+func (a *App) Translate(ctx context.Context, params TranslateParams) (*domain.TranslationResult, error) {
+	a = a.forTranslation(params)
+
 	// Step 1: Load source JSON
 	a.ui.PrintStep(ui.IconFile, "Loading source file...")
 	source, err := a.jsonUtil.LoadJSON(params.SourcePath)
 	if err != nil {
 		a.ui.PrintError(fmt.Sprintf("Failed to load source: %v", err))
-		return fmt.Errorf("failed to load source: %w", err)
+		return nil, fmt.Errorf("failed to load source: %w", err)
 	}
 	a.ui.PrintSuccess("Source file loaded")
 
@@ -136,6 +425,82 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 		}
 	}
 
+	// Translations saved by a run that hit the budget are reused
+	checkpointPath := incremental.CheckpointPath(params.TerminologyDir, params.SourcePath, params.TargetLang)
+	checkpoint, err := incremental.LoadCheckpoint(checkpointPath)
+	if err != nil {
+		a.ui.PrintWarning(fmt.Sprintf("Ignoring checkpoint: %v", err))
+		checkpoint = nil
+	}
+
+	// The translations before this run: locked ones are kept, and they tell what changed
+	var previous map[string]string
+	if existing, err := a.jsonUtil.LoadJSON(outputPath); err == nil {
+		previous = a.jsonUtil.FlattenStrings(existing)
+	}
+
+	// Locked keys and translations a reviewer approved are kept as they are
+	reviewPath := review.Path(params.TerminologyDir, params.SourcePath, params.TargetLang)
+	reviewState, err := review.Load(reviewPath)
+	if err != nil {
+		a.ui.PrintWarning(fmt.Sprintf("Ignoring review: %v", err))
+		reviewState = nil
+	}
+	locked, err := a.lockedTranslations(params, source, previous, reviewState)
+	if err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load locked keys: %v", err))
+		return nil, fmt.Errorf("failed to load locked keys: %w", err)
+	}
+	if len(locked) > 0 {
+		a.ui.PrintSubtle(fmt.Sprintf("Keeping %s locked translations", a.ui.FormatNumber(len(locked))))
+	}
+	prefilled := checkpoint.Prefilled()
+
+	// Consult the translation memory: exact matches are reused, fuzzy ones become references
+	var memory *tm.Memory
+	if !params.NoTM {
+		memory, err = a.loadMemory(params.TerminologyDir)
+		if err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Translation memory disabled: %v", err))
+			memory = nil
+		}
+	}
+	prefilled, references := a.lookupMemory(memory, params, source, sourceLang, prefilled)
+
+	// The target language's style guide goes into every prompt
+	styleGuide, err := style.Load(params.TerminologyDir, params.TargetLang)
+	if err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load style guide: %v", err))
+		return nil, fmt.Errorf("failed to load style guide: %w", err)
+	}
+	if styleGuide == nil && !params.StyleGuide.IsZero() {
+		styleGuide = params.StyleGuide
+	}
+	if styleGuide != nil {
+		a.ui.PrintSubtle(fmt.Sprintf("Using style guide %s", styleGuide.Path))
+	}
+
+	// Length limits for fixed-size UI elements go into the prompts and are verified
+	limits, err := loadLengthLimits(params.TerminologyDir, params.SourcePath)
+	if err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load length limits: %v", err))
+		return nil, fmt.Errorf("failed to load length limits: %w", err)
+	}
+	if len(limits.rules) > 0 || len(limits.keys) > 0 {
+		a.ui.PrintSubtle(fmt.Sprintf("Length limits: %d rules, %d keys from metadata", len(limits.rules), len(limits.keys)))
+	}
+
+	// Prompt templates overridden in the terminology directory
+	if err := a.usePrompts(params.TerminologyDir); err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load prompt templates: %v", err))
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	// Dry run: estimate and stop before any API call
+	if params.DryRun {
+		return nil, a.dryRun(params, source, sourceLang, prefilled, locked, references, styleGuide, limits)
+	}
+
 	// Step 4: Handle incremental translation mode
 	var target map[string]any
 	var diff *incremental.DiffResult
@@ -152,7 +517,7 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 				diff, err = a.incr.AnalyzeDiff(source, target)
 				if err != nil {
 					a.ui.PrintError(fmt.Sprintf("Failed to analyze diff: %v", err))
-					return fmt.Errorf("failed to analyze diff: %w", err)
+					return nil, fmt.Errorf("failed to analyze diff: %w", err)
 				}
 
 				a.ui.PrintSubtle(fmt.Sprintf("New: %s keys", a.ui.FormatNumber(diff.Stats.NewCount)))
@@ -161,19 +526,15 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 
 				if !a.incr.ShouldTranslate(diff, false) {
 					a.ui.PrintSuccess("No changes detected, skipping translation")
-					return nil
+					return nil, nil
 				}
 
 				if !params.Yes {
 					a.ui.PrintInfo(fmt.Sprintf("Will translate %d keys, keep %d unchanged",
 						diff.Stats.NewCount+diff.Stats.ModifiedCount, diff.Stats.UnchangedCount))
-					fmt.Print("Continue? [Y/n] ")
-
-					var response string
-					_, _ = fmt.Scanln(&response)
-					if strings.ToLower(response) == "n" {
+					if !confirm(a.label + "Continue?") {
 						a.ui.PrintWarning("Cancelled by user")
-						return fmt.Errorf("cancelled by user")
+						return nil, fmt.Errorf("cancelled by user")
 					}
 				}
 			}
@@ -185,137 +546,19 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 	var termTranslation *domain.TerminologyTranslation
 
 	if !params.NoTerminology {
-		// Load or detect terminology
-		if a.termManager.TerminologyExists(params.TerminologyDir) {
-			a.ui.PrintStep(ui.IconBook, "Loading terminology...")
-			term, err = a.termManager.LoadTerminology(params.TerminologyDir)
+		term = params.Terminology
+		if term == nil {
+			term, err = a.loadTerminology(ctx, params, source, sourceLang)
 			if err != nil {
-				a.ui.PrintError(fmt.Sprintf("Failed to load terminology: %v", err))
-				return fmt.Errorf("failed to load terminology: %w", err)
-			}
-
-			// Check source language match
-			if term.SourceLanguage != sourceLang {
-				if params.RedetectTerms {
-					a.ui.PrintWarning(fmt.Sprintf("Source language changed (%s -> %s), re-detecting terminology...", term.SourceLanguage, sourceLang))
-					term = nil // Force re-detection
-				} else {
-					a.ui.PrintWarning(fmt.Sprintf("Source language mismatch: terminology is %s, but source is %s", term.SourceLanguage, sourceLang))
-					a.ui.PrintWarning("Use --redetect-terms to re-detect terminology for the new source language")
-					return fmt.Errorf("source language mismatch")
-				}
-			} else {
-				a.ui.PrintSuccess("Terminology loaded")
+				return nil, err
 			}
 		}
 
-		// Detect terminology if not loaded
-		if term == nil && !params.SkipTerminology {
-			a.ui.PrintStep(ui.IconMagnify, "Detecting terminology...")
-
-			// Extract texts for detection
-			texts := extractTexts(source)
-			a.ui.PrintSubtle(fmt.Sprintf("Extracted %d text strings from source file", len(texts)))
-
-			terms, err := a.termManager.DetectTerms(ctx, texts, sourceLang)
+		termTranslation = params.TermTranslation
+		if termTranslation == nil && term != nil {
+			termTranslation, err = a.termTranslation(ctx, params, term)
 			if err != nil {
-				a.ui.PrintWarning(fmt.Sprintf("Failed to detect terms: %v", err))
-			} else {
-				// Build terminology
-				term = &domain.Terminology{
-					SourceLanguage:  sourceLang,
-					PreserveTerms:   []string{},
-					ConsistentTerms: []string{},
-				}
-
-				for _, t := range terms {
-					if t.Type == domain.TermTypePreserve {
-						term.PreserveTerms = append(term.PreserveTerms, t.Term)
-					} else {
-						term.ConsistentTerms = append(term.ConsistentTerms, t.Term)
-					}
-				}
-
-				a.ui.PrintSuccess(fmt.Sprintf("Detected %s terms", a.ui.FormatNumber(len(terms))))
-				a.ui.PrintSubtle(fmt.Sprintf("  Preserve: %d, Consistent: %d", len(term.PreserveTerms), len(term.ConsistentTerms)))
-
-				// Save terminology
-				shouldSave := params.Yes
-				if !params.Yes {
-					fmt.Printf("Save terminology to %s/terminology.json? [Y/n] ", params.TerminologyDir)
-					var response string
-					_, _ = fmt.Scanln(&response)
-					shouldSave = strings.ToLower(response) != "n"
-				}
-
-				if shouldSave {
-					err = a.termManager.SaveTerminology(params.TerminologyDir, term)
-					if err != nil {
-						a.ui.PrintWarning(fmt.Sprintf("Failed to save terminology: %v", err))
-					} else {
-						a.ui.PrintSuccess("Terminology saved")
-					}
-				}
-			}
-		}
-
-		// Load or translate terminology
-		if term != nil && len(term.ConsistentTerms) > 0 {
-			if a.termManager.TranslationExists(params.TerminologyDir, params.TargetLang) {
-				a.ui.PrintStep(ui.IconBook, "Loading terminology translation...")
-				termTranslation, err = a.termManager.LoadTerminologyTranslation(params.TerminologyDir, params.TargetLang)
-				if err != nil {
-					a.ui.PrintError(fmt.Sprintf("Failed to load translation: %v", err))
-					return fmt.Errorf("failed to load terminology translation: %w", err)
-				}
-				a.ui.PrintSuccess("Terminology translation loaded")
-			}
-
-			// Check for missing translations
-			missingTerms := term.GetMissingTranslations(termTranslation)
-			if len(missingTerms) > 0 {
-				a.ui.PrintStep(ui.IconRobot, fmt.Sprintf("Translating %d missing terms...", len(missingTerms)))
-
-				// Create timeout context for term translation (1 minute)
-				translateCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
-				defer cancel()
-
-				translations, err := a.termManager.TranslateTerms(translateCtx, missingTerms, sourceLang, params.TargetLang)
-				if err != nil {
-					a.ui.PrintWarning(fmt.Sprintf("Failed to translate terms: %v", err))
-				} else {
-					// Create or update translation
-					if termTranslation == nil {
-						termTranslation = &domain.TerminologyTranslation{
-							SourceLanguage: sourceLang,
-							TargetLanguage: params.TargetLang,
-							Translations:   translations,
-						}
-					} else {
-						// Merge new translations
-						maps.Copy(termTranslation.Translations, translations)
-					}
-
-					a.ui.PrintSuccess("Terms translated")
-
-					// Save translation
-					shouldSave := params.Yes
-					if !params.Yes {
-						fmt.Printf("Save terminology translation to %s/terminology.%s.json? [Y/n] ", params.TerminologyDir, params.TargetLang)
-						var response string
-						_, _ = fmt.Scanln(&response)
-						shouldSave = strings.ToLower(response) != "n"
-					}
-
-					if shouldSave {
-						err = a.termManager.SaveTerminologyTranslation(params.TerminologyDir, termTranslation)
-						if err != nil {
-							a.ui.PrintWarning(fmt.Sprintf("Failed to save translation: %v", err))
-						} else {
-							a.ui.PrintSuccess("Terminology translation saved")
-						}
-					}
-				}
+				return nil, err
 			}
 		}
 	}
@@ -335,8 +578,15 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 	// Step 7: Translate
 	a.ui.PrintStep(ui.IconRobot, "Translating...")
 
+	if checkpoint != nil {
+		a.ui.PrintSubtle(fmt.Sprintf("Resuming from checkpoint: %s translations already paid for",
+			a.ui.FormatNumber(len(checkpoint.Entries))))
+	}
+
 	// Setup progress callbacks for detailed output
-	a.setupProgressCallbacks()
+	a.setupProgressCallbacks(cmp.Or(params.Name, params.TargetLang))
+
+	cacheHitsBefore := a.cacheHits()
 
 	result, err := a.engine.Translate(ctx, domain.TranslationInput{
 		Source:                 source,
@@ -344,6 +594,12 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 		TargetLang:             params.TargetLang,
 		Terminology:            term,
 		TerminologyTranslation: termTranslation,
+		Prefilled:              prefilled,
+		Locked:                 locked,
+		References:             references,
+		StyleGuide:             styleGuide,
+		LengthLimits:           limits.keys,
+		LengthRules:            limits.rules,
 		Options: domain.TranslationOptions{
 			BatchSize:     params.BatchSize,
 			Concurrency:   params.Concurrency,
@@ -355,26 +611,67 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 		},
 	})
 
+	if result != nil {
+		result.Stats.CacheHits = a.cacheHits() - cacheHitsBefore
+	}
+
+	if err != nil && domain.IsErrorType(err, domain.ErrorTypeBudget) && result != nil {
+		// Keep what was paid for; the next run resumes from here
+		a.ui.PrintNewline()
+		// The budget is shared by every file and language of the run: report its total
+		a.ui.PrintError(fmt.Sprintf("Budget of $%.2f reached after $%.4f, stopping", a.budget.Max(), a.budget.Spent()))
+		if saveErr := a.saveCheckpoint(checkpointPath, params, source, sourceLang, result); saveErr != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to save checkpoint: %v", saveErr))
+		} else {
+			a.ui.PrintInfo(fmt.Sprintf("Saved %d translations to %s; rerun to resume",
+				len(result.Translations), checkpointPath))
+		}
+		return nil, fmt.Errorf("translation stopped: %w", err)
+	}
+
 	if err != nil {
 		a.ui.PrintError(fmt.Sprintf("Translation failed: %v", err))
-		return fmt.Errorf("translation failed: %w", err)
+		return nil, fmt.Errorf("translation failed: %w", err)
 	}
 
 	// Print completion summary
-	fmt.Println()
+	a.ui.PrintNewline()
 	a.ui.PrintSuccess("Translation completed")
 
 	// Step 8: Save result
 	a.ui.PrintStep(ui.IconSave, "Saving translation...")
-	err = a.jsonUtil.SaveJSON(outputPath, result.Target)
+	// Output templates like locales/{lang}/{name}.json point into new directories
+	err = os.MkdirAll(filepath.Dir(outputPath), 0o755)
+	if err == nil {
+		err = a.jsonUtil.SaveJSON(outputPath, result.Target)
+	}
 	if err != nil {
 		a.ui.PrintError(fmt.Sprintf("Failed to save: %v", err))
-		return fmt.Errorf("failed to save result: %w", err)
+		return nil, fmt.Errorf("failed to save result: %w", err)
 	}
 	a.ui.PrintSuccess(fmt.Sprintf("Saved to %s", outputPath))
 
+	if checkpoint != nil {
+		if err := incremental.RemoveCheckpoint(checkpointPath); err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to remove checkpoint: %v", err))
+		}
+	}
+
+	if reviewState != nil {
+		if err := a.recordChanges(reviewPath, reviewState, params, sourceLang, outputPath, source, previous, result); err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to record changes for review: %v", err))
+		}
+	}
+
+	// Remember this run's translations for future reuse
+	if memory != nil {
+		if err := a.rememberTranslations(memory, source, sourceLang, params.TargetLang, result); err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to update translation memory: %v", err))
+		}
+	}
+
 	// Step 9: Print stats
-	fmt.Println() // Empty line for spacing
+	a.ui.PrintNewline()
 	a.ui.PrintHeader("Translation Statistics")
 
 	// Build stats map
@@ -393,19 +690,595 @@ func (a *App) Translate(ctx context.Context, params TranslateParams) error {
 	stats["Failed"] = result.Stats.FailedItems
 	stats["Duration"] = result.Stats.Duration.String()
 	stats["API calls"] = result.Stats.APICallsCount
+	stats["Tokens"] = fmt.Sprintf("%s (prompt %s, completion %s)",
+		a.ui.FormatNumber(result.Stats.TotalTokens),
+		a.ui.FormatNumber(result.Stats.PromptTokens),
+		a.ui.FormatNumber(result.Stats.CompletionTokens))
+	if result.Stats.ReusedItems > 0 {
+		stats["Reused"] = result.Stats.ReusedItems
+	}
+	if result.Stats.LockedItems > 0 {
+		stats["Locked"] = result.Stats.LockedItems
+	}
+	if a.cache != nil {
+		stats["Cache hits"] = result.Stats.CacheHits
+	}
+	if a.pricingOK {
+		stats["Estimated cost"] = fmt.Sprintf("$%.4f", result.Stats.EstimatedCost)
+	}
+	if len(result.TermViolations) > 0 {
+		stats["Term violations"] = len(result.TermViolations)
+	}
+	if len(result.LengthViolations) > 0 {
+		stats["Too long"] = len(result.LengthViolations)
+	}
 
 	a.ui.PrintStats(stats)
 
+	a.printTermViolations(result.TermViolations)
+	a.printLengthViolations(result.LengthViolations)
+
+	return result, nil
+}
+
+// PrepareTerminology loads or detects the terminology of a run once, before its
+// translations, so translations running at the same time don't each detect it. It
+// returns nil when there is no terminology to use.
+func (a *App) PrepareTerminology(ctx context.Context, params TranslateParams) (*domain.Terminology, error) {
+	if params.NoTerminology {
+		return nil, nil
+	}
+
+	source, err := a.jsonUtil.LoadJSON(params.SourcePath)
+	if err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load source: %v", err))
+		return nil, fmt.Errorf("failed to load source: %w", err)
+	}
+	sourceLang := params.SourceLang
+	if sourceLang == "" {
+		baseName := filepath.Base(params.SourcePath)
+		sourceLang = strings.TrimSuffix(baseName, filepath.Ext(baseName))
+	}
+
+	// Detection uses the terminology prompt templates
+	if err := a.usePrompts(params.TerminologyDir); err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load prompt templates: %v", err))
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+	return a.loadTerminology(ctx, params, source, sourceLang)
+}
+
+// PrepareTermTranslation loads the terminology translation of a target language and
+// translates the terms it is missing once, before the translations into that language
+// run at the same time and would each translate and save the same terms
+func (a *App) PrepareTermTranslation(ctx context.Context, params TranslateParams, term *domain.Terminology) (*domain.TerminologyTranslation, error) {
+	if err := a.usePrompts(params.TerminologyDir); err != nil {
+		a.ui.PrintError(fmt.Sprintf("Failed to load prompt templates: %v", err))
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+	return a.termTranslation(ctx, params, term)
+}
+
+// termTranslation loads the terminology translation of the target language and
+// translates the terms it is missing. It returns nil without consistent terms.
+func (a *App) termTranslation(ctx context.Context, params TranslateParams, term *domain.Terminology) (*domain.TerminologyTranslation, error) {
+	if len(term.ConsistentTerms) == 0 {
+		return nil, nil
+	}
+
+	var termTranslation *domain.TerminologyTranslation
+	var err error
+	if a.termManager.TranslationExists(params.TerminologyDir, params.TargetLang) {
+		a.ui.PrintStep(ui.IconBook, "Loading terminology translation...")
+		termTranslation, err = a.termManager.LoadTerminologyTranslation(params.TerminologyDir, params.TargetLang)
+		if err != nil {
+			a.ui.PrintError(fmt.Sprintf("Failed to load translation: %v", err))
+			return nil, fmt.Errorf("failed to load terminology translation: %w", err)
+		}
+		a.ui.PrintSuccess("Terminology translation loaded")
+	}
+
+	// Check for missing translations
+	missingTerms := term.GetMissingTranslations(termTranslation)
+	if len(missingTerms) == 0 {
+		return termTranslation, nil
+	}
+	a.ui.PrintStep(ui.IconRobot, fmt.Sprintf("Translating %d missing terms...", len(missingTerms)))
+
+	// Create timeout context for term translation (1 minute)
+	translateCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+
+	translations, err := a.termManager.TranslateTerms(translateCtx, missingTerms, term.SourceLanguage, params.TargetLang)
+	if err != nil {
+		a.ui.PrintWarning(fmt.Sprintf("Failed to translate terms: %v", err))
+		return termTranslation, nil
+	}
+
+	// Create or update translation
+	if termTranslation == nil {
+		termTranslation = &domain.TerminologyTranslation{
+			SourceLanguage: term.SourceLanguage,
+			TargetLanguage: params.TargetLang,
+			Translations:   translations,
+		}
+	} else {
+		// Merge new translations
+		maps.Copy(termTranslation.Translations, translations)
+	}
+
+	a.ui.PrintSuccess("Terms translated")
+
+	// Save translation
+	shouldSave := params.Yes
+	if !params.Yes {
+		shouldSave = confirm(fmt.Sprintf("%sSave terminology translation to %s?", a.label, a.termLocation(params.TerminologyDir, "terminology."+params.TargetLang+".json")))
+	}
+
+	if shouldSave {
+		err = a.termManager.SaveTerminologyTranslation(params.TerminologyDir, termTranslation)
+		if err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to save translation: %v", err))
+		} else {
+			a.ui.PrintSuccess("Terminology translation saved")
+		}
+	}
+	return termTranslation, nil
+}
+
+// loadTerminology loads the terminology of the terminology directory, or detects and
+// saves it when there is none. It returns nil when there is no terminology to use.
+func (a *App) loadTerminology(ctx context.Context, params TranslateParams, source map[string]any, sourceLang string) (*domain.Terminology, error) {
+	var term *domain.Terminology
+	var err error
+
+	// Load or detect terminology
+	if a.termManager.TerminologyExists(params.TerminologyDir) {
+		a.ui.PrintStep(ui.IconBook, "Loading terminology...")
+		term, err = a.termManager.LoadTerminology(params.TerminologyDir)
+		if err != nil {
+			a.ui.PrintError(fmt.Sprintf("Failed to load terminology: %v", err))
+			return nil, fmt.Errorf("failed to load terminology: %w", err)
+		}
+
+		// Check source language match
+		if term.SourceLanguage != sourceLang {
+			if params.RedetectTerms {
+				a.ui.PrintWarning(fmt.Sprintf("Source language changed (%s -> %s), re-detecting terminology...", term.SourceLanguage, sourceLang))
+				term = nil // Force re-detection
+			} else {
+				a.ui.PrintWarning(fmt.Sprintf("Source language mismatch: terminology is %s, but source is %s", term.SourceLanguage, sourceLang))
+				a.ui.PrintWarning("Use --redetect-terms to re-detect terminology for the new source language")
+				return nil, fmt.Errorf("source language mismatch")
+			}
+		} else {
+			a.ui.PrintSuccess("Terminology loaded")
+		}
+	}
+
+	// Detect terminology if not loaded
+	if term == nil && !params.SkipTerminology {
+		a.ui.PrintStep(ui.IconMagnify, "Detecting terminology...")
+
+		// Extract texts for detection, from all source files when they share the terminology
+		texts := params.TermTexts
+		if len(texts) == 0 {
+			texts = extractTexts(source)
+			a.ui.PrintSubtle(fmt.Sprintf("Extracted %d text strings from source file", len(texts)))
+		} else {
+			a.ui.PrintSubtle(fmt.Sprintf("Extracted %d text strings from all source files", len(texts)))
+		}
+
+		terms, err := a.termManager.DetectTerms(ctx, texts, sourceLang)
+		if err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to detect terms: %v", err))
+		} else {
+			// Build terminology
+			term = &domain.Terminology{
+				SourceLanguage:  sourceLang,
+				PreserveTerms:   []string{},
+				ConsistentTerms: []string{},
+			}
+
+			// Keep the detector's notes so prompts can explain each term
+			for _, t := range terms {
+				term.AddTerm(t)
+			}
+
+			a.ui.PrintSuccess(fmt.Sprintf("Detected %s terms", a.ui.FormatNumber(len(terms))))
+			a.ui.PrintSubtle(fmt.Sprintf("  Preserve: %d, Consistent: %d", len(term.PreserveTerms), len(term.ConsistentTerms)))
+
+			// Save terminology
+			shouldSave := params.Yes
+			if !params.Yes {
+				shouldSave = confirm(fmt.Sprintf("%sSave terminology to %s?", a.label, a.termLocation(params.TerminologyDir, "terminology.json")))
+			}
+
+			if shouldSave {
+				err = a.termManager.SaveTerminology(params.TerminologyDir, term)
+				if err != nil {
+					a.ui.PrintWarning(fmt.Sprintf("Failed to save terminology: %v", err))
+				} else {
+					a.ui.PrintSuccess("Terminology saved")
+
+					// Let 'jta terms detect' skip these strings later
+					if err := a.termManager.RecordDetection(params.TerminologyDir, texts, sourceLang); err != nil {
+						a.ui.PrintWarning(fmt.Sprintf("Failed to record analysed strings: %v", err))
+					}
+				}
+			}
+		}
+	}
+	return term, nil
+}
+
+// printTermViolations lists translations that still don't follow the terminology
+func (a *App) printTermViolations(violations []domain.TermViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	const shown = 10
+	a.ui.PrintWarning(fmt.Sprintf("%d translations don't follow the terminology:", len(violations)))
+	for i, v := range violations {
+		if i == shown && !a.config.Verbose {
+			a.ui.PrintSubtle(fmt.Sprintf("... and %d more (use --verbose to list all)", len(violations)-shown))
+			break
+		}
+		a.ui.PrintSubtle(fmt.Sprintf("[%s] %s", v.Key, v.Message))
+	}
+}
+
+// cacheHits returns the number of responses served from the cache so far
+func (a *App) cacheHits() int {
+	if a.cache == nil {
+		return 0
+	}
+	return a.cache.Stats().Hits
+}
+
+// dryRun prints the expected size and cost of a translation without calling the API.
+// Existing terminology is used for the prompts; detection and term translation are skipped.
+func (a *App) dryRun(
+	params TranslateParams,
+	source map[string]any,
+	sourceLang string,
+	prefilled map[string]domain.PrefilledTranslation,
+	locked map[string]string,
+	references map[string][]domain.TranslationReference,
+	styleGuide *domain.StyleGuide,
+	limits lengthLimits,
+) error {
+	var term *domain.Terminology
+	var termTranslation *domain.TerminologyTranslation
+
+	if !params.NoTerminology && a.termManager.TerminologyExists(params.TerminologyDir) {
+		var err error
+		term, err = a.termManager.LoadTerminology(params.TerminologyDir)
+		if err != nil {
+			a.ui.PrintWarning(fmt.Sprintf("Failed to load terminology: %v", err))
+		}
+		if a.termManager.TranslationExists(params.TerminologyDir, params.TargetLang) {
+			termTranslation, err = a.termManager.LoadTerminologyTranslation(params.TerminologyDir, params.TargetLang)
+			if err != nil {
+				a.ui.PrintWarning(fmt.Sprintf("Failed to load terminology translation: %v", err))
+			}
+		}
+	}
+
+	var keyPatterns, excludeKeyPatterns []string
+	if params.Keys != "" {
+		keyPatterns = []string{params.Keys}
+	}
+	if params.ExcludeKeys != "" {
+		excludeKeyPatterns = []string{params.ExcludeKeys}
+	}
+
+	a.ui.PrintStep(ui.IconMagnify, "Estimating cost (dry run, no API calls)...")
+	estimate, err := a.engine.Estimate(domain.TranslationInput{
+		Source:                 source,
+		SourceLang:             sourceLang,
+		TargetLang:             params.TargetLang,
+		Terminology:            term,
+		TerminologyTranslation: termTranslation,
+		Prefilled:              prefilled,
+		Locked:                 locked,
+		References:             references,
+		StyleGuide:             styleGuide,
+		LengthLimits:           limits.keys,
+		LengthRules:            limits.rules,
+		Options: domain.TranslationOptions{
+			BatchSize:     params.BatchSize,
+			NoTerminology: params.NoTerminology,
+			Keys:          keyPatterns,
+			ExcludeKeys:   excludeKeyPatterns,
+		},
+	})
+	if err != nil {
+		a.ui.PrintError(fmt.Sprintf("Estimate failed: %v", err))
+		return fmt.Errorf("estimate failed: %w", err)
+	}
+
+	a.ui.PrintNewline()
+	a.ui.PrintHeader("Dry Run Estimate")
+
+	stats := map[string]any{
+		"Items":             estimate.Items,
+		"Batches":           estimate.Batches,
+		"API calls":         fmt.Sprintf("%d (translate + reflect + improve)", estimate.APICalls),
+		"Prompt tokens":     "~" + a.ui.FormatNumber(estimate.PromptTokens),
+		"Completion tokens": "~" + a.ui.FormatNumber(estimate.CompletionTokens),
+	}
+	if len(prefilled) > 0 {
+		stats["Reused (checkpoint, memory)"] = len(prefilled)
+	}
+	if len(locked) > 0 {
+		stats["Locked"] = len(locked)
+	}
+	if a.pricingOK {
+		stats["Estimated cost"] = fmt.Sprintf("~$%.4f", estimate.EstimatedCost)
+	} else {
+		stats["Estimated cost"] = fmt.Sprintf("unknown (no pricing for %s, use --pricing)", a.provider.GetModelName())
+	}
+	a.ui.PrintStats(stats)
+
 	return nil
 }
 
+// loadMemory returns the translation memory of a terminology directory, loading it once
+func (a *App) loadMemory(terminologyDir string) (*tm.Memory, error) {
+	a.state.memoryMu.Lock()
+	defer a.state.memoryMu.Unlock()
+
+	path := tm.Path(terminologyDir)
+	if memory, ok := a.state.memories[path]; ok {
+		return memory, nil
+	}
+
+	memory, err := tm.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if a.state.memories == nil {
+		a.state.memories = make(map[string]*tm.Memory)
+	}
+	a.state.memories[path] = memory
+	return memory, nil
+}
+
+// lookupMemory adds exact translation memory matches to prefilled and returns
+// fuzzy matches as prompt references
+func (a *App) lookupMemory(
+	memory *tm.Memory,
+	params TranslateParams,
+	source map[string]any,
+	sourceLang string,
+	prefilled map[string]domain.PrefilledTranslation,
+) (map[string]domain.PrefilledTranslation, map[string][]domain.TranslationReference) {
+	if memory == nil || memory.Len() == 0 {
+		return prefilled, nil
+	}
+
+	threshold := params.TMThreshold
+	if threshold <= 0 {
+		threshold = tm.DefaultFuzzyThreshold
+	}
+	if prefilled == nil {
+		prefilled = make(map[string]domain.PrefilledTranslation)
+	}
+
+	references := make(map[string][]domain.TranslationReference)
+	exact := 0
+	for key, text := range a.jsonUtil.FlattenStrings(source) {
+		if text == "" {
+			continue
+		}
+		if _, ok := prefilled[key]; ok {
+			continue
+		}
+
+		if entry, ok := memory.Exact(sourceLang, params.TargetLang, text); ok {
+			prefilled[key] = domain.PrefilledTranslation{
+				SourceText: text,
+				Text:       entry.Target,
+				Origin:     "tm",
+			}
+			exact++
+			continue
+		}
+
+		for _, match := range memory.Fuzzy(sourceLang, params.TargetLang, text, threshold, 3) {
+			references[key] = append(references[key], domain.TranslationReference{
+				Source:     match.Source,
+				Target:     match.Target,
+				Similarity: match.Similarity,
+			})
+		}
+	}
+
+	if exact > 0 || len(references) > 0 {
+		a.ui.PrintSubtle(fmt.Sprintf("Translation memory: %s exact matches reused, %s keys with fuzzy references",
+			a.ui.FormatNumber(exact), a.ui.FormatNumber(len(references))))
+	}
+
+	return prefilled, references
+}
+
+// lockedTranslations returns the translations kept as they are: those of locked keys
+// (see lock.Load) and those a reviewer approved, while their source text is unchanged.
+// A locked key keeps the translation in the target file; without one it is translated.
+func (a *App) lockedTranslations(params TranslateParams, source map[string]any, previous map[string]string, state *review.State) (map[string]string, error) {
+	locks, err := lock.Load(params.TerminologyDir, params.SourcePath, params.Locked)
+	if err != nil {
+		return nil, err
+	}
+
+	var approved map[string]review.Approval
+	if state != nil {
+		approved = state.Approved
+	}
+
+	locked := make(map[string]string)
+	for key, text := range a.jsonUtil.FlattenStrings(source) {
+		if approval, ok := approved[key]; ok && approval.Source == text {
+			locked[key] = cmp.Or(previous[key], approval.Target)
+		} else if previous[key] != "" && locks.Locked(key) {
+			locked[key] = previous[key]
+		}
+	}
+	return locked, nil
+}
+
+// recordChanges records the translations a run changed, for review with "jta review"
+func (a *App) recordChanges(
+	path string,
+	state *review.State,
+	params TranslateParams,
+	sourceLang, outputPath string,
+	source map[string]any,
+	previous map[string]string,
+	result *domain.TranslationResult,
+) error {
+	sourceTexts := a.jsonUtil.FlattenStrings(source)
+	changes := make(map[string]review.Change)
+	now := time.Now()
+	for key, text := range result.Translations {
+		detail := result.Keys[key]
+		if detail.Status == domain.KeyStatusLocked || text == previous[key] {
+			continue
+		}
+		changes[key] = review.Change{
+			Source:     sourceTexts[key],
+			Old:        previous[key],
+			New:        text,
+			Suggestion: detail.Suggestion,
+			ChangedAt:  now,
+		}
+	}
+	if !state.Record(changes) {
+		return nil
+	}
+
+	state.SourcePath = params.SourcePath
+	state.SourceLanguage = sourceLang
+	state.TargetLanguage = params.TargetLang
+	state.TargetPath = outputPath
+	if err := review.Save(path, state); err != nil {
+		return err
+	}
+	if len(state.Pending) > 0 {
+		a.ui.PrintSubtle(fmt.Sprintf("%s changes to review: jta review %s", a.ui.FormatNumber(len(state.Pending)), outputPath))
+	}
+	return nil
+}
+
+// Retranslate translates the source text of a key again following a reviewer's note,
+// with the terminology, style guide and length limits of the translation. It returns
+// the translation and reflection's suggestion.
+func (a *App) Retranslate(ctx context.Context, params TranslateParams, key, sourceText, note string) (string, string, error) {
+	a = a.forTranslation(params)
+
+	styleGuide, err := style.Load(params.TerminologyDir, params.TargetLang)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load style guide: %w", err)
+	}
+	if styleGuide == nil && !params.StyleGuide.IsZero() {
+		styleGuide = params.StyleGuide
+	}
+	limits, err := loadLengthLimits(params.TerminologyDir, params.SourcePath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load length limits: %w", err)
+	}
+	if err := a.usePrompts(params.TerminologyDir); err != nil {
+		return "", "", fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	// The terminology is followed when there is one; it is not detected for a single string
+	var term *domain.Terminology
+	var termTranslation *domain.TerminologyTranslation
+	if a.termManager.TerminologyExists(params.TerminologyDir) {
+		if term, err = a.termManager.LoadTerminology(params.TerminologyDir); err != nil {
+			return "", "", fmt.Errorf("failed to load terminology: %w", err)
+		}
+		if a.termManager.TranslationExists(params.TerminologyDir, params.TargetLang) {
+			termTranslation, err = a.termManager.LoadTerminologyTranslation(params.TerminologyDir, params.TargetLang)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to load terminology translation: %w", err)
+			}
+		}
+	}
+
+	// A flat source keeps the key path of the string
+	result, err := a.engine.Translate(ctx, domain.TranslationInput{
+		Source:                 map[string]any{key: sourceText},
+		SourceLang:             params.SourceLang,
+		TargetLang:             params.TargetLang,
+		Terminology:            term,
+		TerminologyTranslation: termTranslation,
+		StyleGuide:             styleGuide,
+		LengthLimits:           limits.keys,
+		LengthRules:            limits.rules,
+		Notes:                  map[string]string{key: note},
+		Options: domain.TranslationOptions{
+			BatchSize:     1,
+			Concurrency:   1,
+			NoTerminology: term == nil,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	text, ok := result.Translations[key]
+	if !ok {
+		return "", "", domain.NewTranslationError("no translation returned", nil).WithContext("key", key)
+	}
+	return text, result.Keys[key].Suggestion, nil
+}
+
+// rememberTranslations adds a run's translations to the translation memory and saves it
+func (a *App) rememberTranslations(memory *tm.Memory, source map[string]any, sourceLang, targetLang string, result *domain.TranslationResult) error {
+	sourceTexts := a.jsonUtil.FlattenStrings(source)
+	for key, text := range result.Translations {
+		memory.Add(tm.Entry{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Source:     sourceTexts[key],
+			Target:     text,
+			Key:        key,
+			Origin:     "jta",
+		})
+	}
+	return memory.Save()
+}
+
+// saveCheckpoint stores the translations of an interrupted run
+func (a *App) saveCheckpoint(path string, params TranslateParams, source map[string]any, sourceLang string, result *domain.TranslationResult) error {
+	sourceTexts := a.jsonUtil.FlattenStrings(source)
+
+	cp := &incremental.Checkpoint{
+		SourcePath:     params.SourcePath,
+		SourceLanguage: sourceLang,
+		TargetLanguage: params.TargetLang,
+		CreatedAt:      time.Now(),
+		Entries:        make(map[string]incremental.CheckpointEntry, len(result.Translations)),
+	}
+	for key, text := range result.Translations {
+		cp.Entries[key] = incremental.CheckpointEntry{
+			Source: sourceTexts[key],
+			Target: text,
+		}
+	}
+
+	return incremental.SaveCheckpoint(path, cp)
+}
+
 func extractTexts(data any) []string {
 	var texts []string
 
 	switch v := data.(type) {
 	case map[string]any:
-		for _, value := range v {
-			texts = append(texts, extractTexts(value)...)
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			texts = append(texts, extractTexts(v[key])...)
 		}
 	case []any:
 		for _, value := range v {
@@ -420,47 +1293,60 @@ func extractTexts(data any) []string {
 	return texts
 }
 
-// setupProgressCallbacks configures progress callbacks for detailed output
-func (a *App) setupProgressCallbacks() {
-	var batchCount int
-	var successCount int
-	var retryCount int
-	var mu sync.Mutex
-
-	// Setup batch processor callback
+// setupProgressCallbacks logs the progress of batches and shows it in the progress view
+// under name
+func (a *App) setupProgressCallbacks(name string) {
+	var started sync.Once
+	var task *ui.ProgressTask
+	cost := func(usage provider.Usage) float64 {
+		if !a.pricingOK {
+			return 0
+		}
+		return a.pricing.Cost(usage)
+	}
 	a.engine.GetBatchProcessor().SetProgressCallback(func(event translator.BatchProgressEvent) {
-		mu.Lock()
-		defer mu.Unlock()
-
+		// The first event of any batch tells how many there are
+		started.Do(func() {
+			a.logger.Info("Split into batches", "batches", event.TotalBatches,
+				"batch_size", event.BatchSize, "concurrency", event.Concurrency)
+			task = a.config.Progress.Task(name)
+			task.SetTotal(event.TotalBatches)
+		})
 		switch event.Type {
 		case "start":
-			if batchCount == 0 {
-				// First batch - print preparation info
-				fmt.Printf("   📦 Split into %d batches (%d items/batch, concurrency: %d)\n",
-					event.TotalBatches, event.BatchSize, event.Concurrency)
-				fmt.Println()
-			}
-			batchCount++
-			fmt.Printf("[Batch %d] 📝 Translating...\n", event.BatchIndex)
-
+			a.logger.Debug("Translating", "batch", event.BatchIndex)
+			task.Start()
 		case "complete":
-			successCount++
-			fmt.Printf("[Batch %d] ✓ Translated      (%.1fs, %d tokens)\n",
-				event.BatchIndex, event.Duration.Seconds(), event.Tokens)
-
+			a.logger.Info("Translated", "batch", event.BatchIndex, "duration", event.Duration, "tokens", event.Tokens)
 		case "retry":
-			retryCount++
-			fmt.Printf("[Batch %d] ⚠️  Translation failed (attempt %d/%d): %v\n",
-				event.BatchIndex, event.Attempt, event.MaxAttempts, event.Error)
-			fmt.Printf("[Batch %d] ⏰  Retry in %ds...\n", event.BatchIndex, 1<<uint(event.Attempt-1))
-
+			a.logger.Warn("Translation failed, retrying", "batch", event.BatchIndex,
+				"attempt", event.Attempt, "max_attempts", event.MaxAttempts, "backoff", event.Backoff, "error", event.Error)
+			task.Retry()
 		case "error":
-			fmt.Printf("[Batch %d] ❌ Translation failed after %d attempts: %v\n",
-				event.BatchIndex, event.MaxAttempts, event.Error)
+			a.logger.Error("Translation failed", "batch", event.BatchIndex, "attempts", event.Attempt, "error", event.Error)
+			task.Fail(event.Usage.TotalTokens, cost(event.Usage))
+		case "skipped":
+			a.logger.Warn("Skipped: budget exhausted", "batch", event.BatchIndex)
+			task.Skip()
+		case "done":
+			task.Done(event.Usage.TotalTokens, cost(event.Usage))
 		}
 	})
+}
 
-	// Setup reflection engine callback
-	// Reflection progress is now logged per-batch in BatchProcessor
-	// No need for global reflection callback
+// confirmMu keeps the questions of concurrent translations from interleaving
+var confirmMu sync.Mutex
+
+// confirm asks a yes/no question on the terminal; anything but "n" is yes
+func confirm(question string) bool {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	// The live progress view would draw over the question and the answer
+	runProgress.Pause()
+	defer runProgress.Resume()
+
+	fmt.Printf("%s [Y/n] ", question)
+	var response string
+	_, _ = fmt.Scanln(&response)
+	return strings.ToLower(response) != "n"
 }
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/hikanner/jta/internal/provider"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// newCacheCmd creates the "cache" command for managing the response cache
+func newCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk response cache",
+		Long: `Manage the on-disk response cache.
+
+Jta caches AI responses under <terminology-dir>/cache, keyed by provider, model,
+system message and prompt, so re-running a translation doesn't pay for identical
+requests again. Use --no-cache on a translation run to bypass it.`,
+	}
+
+	var dir string
+	var ttl time.Duration
+	var all bool
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired (or all) cached responses",
+		Example: `  # Remove entries older than the default TTL
+  jta cache prune
+
+  # Remove entries older than one day
+  jta cache prune --ttl 24h
+
+  # Clear the whole cache
+  jta cache prune --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			cacheDir := filepath.Join(dir, "cache")
+
+			removed, err := provider.NewResponseCache(cacheDir, ttl).Prune(all)
+			if err != nil {
+				printer.PrintError(fmt.Sprintf("Failed to prune cache: %v", err))
+				return fmt.Errorf("failed to prune cache: %w", err)
+			}
+
+			printer.PrintSuccess(fmt.Sprintf("Removed %s cached responses from %s",
+				printer.FormatNumber(removed), cacheDir))
+			return nil
+		},
+	}
+
+	pruneCmd.Flags().StringVar(&dir, "terminology-dir", ".jta", "Terminology directory containing the cache")
+	pruneCmd.Flags().DurationVar(&ttl, "ttl", provider.DefaultCacheTTL, "Remove entries older than this")
+	pruneCmd.Flags().BoolVar(&all, "all", false, "Remove all entries")
+
+	cacheCmd.AddCommand(pruneCmd)
+
+	return cacheCmd
+}
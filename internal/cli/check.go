@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hikanner/jta/internal/check"
+	"github.com/hikanner/jta/internal/config"
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/tm"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// checkOptions holds the flags of the "check" command
+type checkOptions struct {
+	to         string
+	sourceLang string
+	output     string
+	dir        string
+	store      string
+	format     string
+	report     string
+	failOn     string
+	severities []string
+}
+
+// newCheckCmd creates the "check" command, which verifies translations without calling a model
+func newCheckCmd() *cobra.Command {
+	opts := &checkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check [source file, directory or glob]",
+		Short: "Check translations for missing, stale and broken strings (no API calls)",
+		Long: `Check translation files against their source without calling a model, for CI.
+
+Rules and their default severity:
+
+  missing-key    error    key of the source missing or empty in the translation
+  extra-key      warning  key of the translation that is not in the source
+  untranslated   warning  value identical to the source
+  placeholder    error    placeholders or HTML tags differ from the source
+  terminology    warning  preserve or consistent term not followed
+  stale          warning  source changed since the value was translated (needs the translation memory)
+
+Translation files are found like 'jta' writes them: from --to and --output, or the
+targets and output of the config file. The command exits with an error when an issue
+is at or above --fail-on, so a pull request that adds a source string without
+translating it fails.`,
+		Example: `  # Check every target of the config file
+  jta check
+
+  # Check two languages, failing on warnings too
+  jta check locales/en.json --to de,fr --fail-on warning
+
+  # SARIF for GitHub code scanning, JUnit for test report viewers
+  jta check --format sarif --report jta.sarif
+  jta check --format junit --report jta-junit.xml
+
+  # Allow English text in translations, make stale strings fail the build
+  jta check --severity untranslated=off --severity stale=error`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCheck(cmd, opts, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.to, "to", "", "Target languages, comma-separated (default: targets of the config file)")
+	cmd.Flags().StringVar(&opts.sourceLang, "source-lang", "", "Source language (default: from the source path)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Translation file path or template, as for translating (e.g. locales/{lang}/{name}.json)")
+	cmd.Flags().StringVar(&opts.dir, "terminology-dir", "", "Terminology directory with the terminology and translation memory (default: .jta)")
+	cmd.Flags().StringVar(&opts.store, "terminology-store", os.Getenv("JTA_TERMINOLOGY_STORE"), "Shared glossary: a .json/.yaml file or an http(s) glossary service URL (env: JTA_TERMINOLOGY_STORE)")
+	cmd.Flags().StringVar(&opts.format, "format", "text", "Report format: "+strings.Join(check.Formats, ", "))
+	cmd.Flags().StringVar(&opts.report, "report", "", "Write the report to a file (default: stdout)")
+	cmd.Flags().StringVar(&opts.failOn, "fail-on", "error", "Exit with an error on issues of this severity or higher: error, warning, info or off")
+	cmd.Flags().StringArrayVar(&opts.severities, "severity", nil, "Change the severity of a rule, e.g. untranslated=error or extra-key=off (repeatable)")
+	cmd.Flags().StringVar(&configFlag, "config", "", "Config file (default: jta.yaml or .jtarc in the current or a parent directory)")
+
+	return cmd
+}
+
+func runCheck(cmd *cobra.Command, opts *checkOptions, args []string) error {
+	printer := ui.NewPrinter(false)
+
+	if !slices.Contains(check.Formats, opts.format) {
+		return domain.NewValidationError("unknown report format (want "+strings.Join(check.Formats, ", ")+")", nil).
+			WithContext("format", opts.format)
+	}
+	failOn, err := check.ParseSeverity(opts.failOn)
+	if err != nil {
+		return err
+	}
+	checker := check.NewChecker()
+	for _, setting := range opts.severities {
+		name, level, ok := strings.Cut(setting, "=")
+		if !ok {
+			return domain.NewValidationError("invalid --severity, want rule=severity", nil).
+				WithContext("severity", setting)
+		}
+		rule, err := check.ParseRule(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		severity, err := check.ParseSeverity(strings.TrimSpace(level))
+		if err != nil {
+			return err
+		}
+		checker.SetSeverity(rule, severity)
+	}
+
+	cfg, err := loadConfig(configFlag)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if len(args) == 0 && (cfg == nil || len(cfg.Sources) == 0) {
+		return fmt.Errorf("source file is required (or declare sources in %s)", config.FileNames[0])
+	}
+	sources, err := sourceFiles(args, cfg, opts.sourceLang, splitList(opts.to))
+	if err != nil {
+		return err
+	}
+	targets, err := checkTargets(cmd, opts, sources)
+	if err != nil {
+		return err
+	}
+
+	dir := opts.dir
+	if dir == "" && cfg != nil {
+		dir = cfg.TerminologyDir
+	}
+	if dir == "" {
+		dir = ".jta"
+	}
+	store := opts.store
+	if !cmd.Flags().Changed("terminology-store") && cfg != nil && cfg.TerminologyStore != "" {
+		store = cfg.TerminologyStore
+	}
+	if err := useCheckTerminology(checker, dir, store, targets); err != nil {
+		return err
+	}
+
+	// The translation memory knows what each translation was made from
+	memory, err := tm.Load(tm.Path(dir))
+	if err != nil {
+		return fmt.Errorf("failed to load translation memory: %w", err)
+	}
+	if memory.Len() > 0 {
+		checker.SetMemory(memory)
+	}
+
+	report := &check.Report{}
+	for _, target := range targets {
+		issues, err := checker.Check(target)
+		if err != nil {
+			return err
+		}
+		report.Add(target, issues)
+	}
+
+	var w io.Writer = os.Stdout
+	if opts.report != "" {
+		f, err := os.Create(opts.report)
+		if err != nil {
+			return domain.NewIOError("failed to create report file", err).WithContext("path", opts.report)
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+	if err := report.Write(w, opts.format, Version); err != nil {
+		return err
+	}
+
+	// A machine-readable report on stdout is not mixed with the summary
+	if opts.report != "" || opts.format == "text" {
+		summary := fmt.Sprintf("%d files checked: %d errors, %d warnings, %d infos",
+			len(report.Targets), report.Count(check.SeverityError), report.Count(check.SeverityWarning), report.Count(check.SeverityInfo))
+		switch {
+		case report.Failed(failOn):
+			printer.PrintError(summary)
+		case len(report.Issues) > 0:
+			printer.PrintWarning(summary)
+		default:
+			printer.PrintSuccess(summary)
+		}
+		if opts.report != "" {
+			printer.PrintSubtle(fmt.Sprintf("Report written to %s", opts.report))
+		}
+	}
+
+	if report.Failed(failOn) {
+		// The issues were reported; usage would only bury them
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return fmt.Errorf("check failed: issues of severity %s or higher", failOn)
+	}
+	return nil
+}
+
+// checkTargets lists the translation files of the sources: the --to languages or the
+// source's targets, at the --output template or the source's output
+func checkTargets(cmd *cobra.Command, opts *checkOptions, sources []config.Source) ([]check.Target, error) {
+	var targets []check.Target
+	for _, src := range sources {
+		override(cmd.Flags(), "source-lang", &src.SourceLang, opts.sourceLang)
+		override(cmd.Flags(), "output", &src.Output, opts.output)
+		if cmd.Flags().Changed("to") || len(src.Targets) == 0 {
+			src.Targets = splitList(opts.to)
+		}
+		if len(src.Targets) == 0 {
+			return nil, fmt.Errorf("--to flag is required (or set targets in %s)", config.FileNames[0])
+		}
+
+		pathLang, defaultOutput := config.SourceLanguage(src.Path)
+		if src.SourceLang == "" {
+			src.SourceLang = pathLang
+		}
+		if src.Output == "" {
+			src.Output = defaultOutput
+		}
+
+		for _, lang := range src.Targets {
+			targets = append(targets, check.Target{
+				SourcePath: src.Path,
+				SourceLang: src.SourceLang,
+				Path:       config.OutputPath(src.Output, src.Path, lang),
+				Lang:       lang,
+			})
+		}
+	}
+	return targets, nil
+}
+
+// useCheckTerminology enables the terminology rule when there is a terminology
+func useCheckTerminology(checker *check.Checker, dir, store string, targets []check.Target) error {
+	manager, err := newTermManager(nil, store)
+	if err != nil {
+		return err
+	}
+	if !manager.TerminologyExists(dir) {
+		return nil
+	}
+	term, err := loadTerms(manager, dir)
+	if err != nil {
+		return err
+	}
+
+	translations := make(map[string]*domain.TerminologyTranslation)
+	for _, target := range targets {
+		if _, ok := translations[target.Lang]; ok || !manager.TranslationExists(dir, target.Lang) {
+			continue
+		}
+		translation, err := manager.LoadTerminologyTranslation(dir, target.Lang)
+		if err != nil {
+			return fmt.Errorf("failed to load terminology translation: %w", err)
+		}
+		translations[target.Lang] = translation
+	}
+	checker.SetTerminology(term, translations)
+	return nil
+}
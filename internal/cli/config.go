@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hikanner/jta/internal/config"
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfig loads the config file given with --config, or the one found in the current
+// directory or a parent. It returns nil if there is none.
+func loadConfig(path string) (*config.Config, error) {
+	if path != "" {
+		return config.Load(path)
+	}
+	return config.Discover(".")
+}
+
+// applyConfig sets the run-wide translate flags that were not given on the command line
+// from the config. Settings that can differ per source file or target language (source
+// language, targets, output, keys, provider and model) are resolved per translation.
+func applyConfig(flags *pflag.FlagSet, cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	setFlag(flags, cfg, "provider", &providerFlag, cfg.Provider)
+	setFlag(flags, cfg, "model", &modelFlag, cfg.Model)
+	setFlag(flags, cfg, "terminology-dir", &terminologyDirFlag, cfg.TerminologyDir)
+	setFlag(flags, cfg, "terminology-store", &termStoreFlag, cfg.TerminologyStore)
+	setFlag(flags, cfg, "skip-terminology", &skipTerminology, cfg.SkipTerminology)
+	setFlag(flags, cfg, "incremental", &incrementalFlag, cfg.Incremental)
+	setFlag(flags, cfg, "batch-size", &batchSizeFlag, cfg.BatchSize)
+	setFlag(flags, cfg, "concurrency", &concurrencyFlag, cfg.Concurrency)
+	setFlag(flags, cfg, "parallel", &parallelFlag, cfg.Parallel)
+	setFlag(flags, cfg, "requests-per-minute", &rpmFlag, cfg.RequestsPerMinute)
+	setFlag(flags, cfg, "tokens-per-minute", &tpmFlag, cfg.TokensPerMinute)
+	setFlag(flags, cfg, "max-cost", &maxCostFlag, cfg.MaxCost)
+	setFlag(flags, cfg, "pricing", &pricingFlag, cfg.Pricing)
+	setFlag(flags, cfg, "no-cache", &noCacheFlag, cfg.NoCache)
+	setFlag(flags, cfg, "cache-ttl", &cacheTTLFlag, cfg.CacheTTL)
+	setFlag(flags, cfg, "no-tm", &noTMFlag, cfg.NoTM)
+	setFlag(flags, cfg, "tm-threshold", &tmThresholdFlag, cfg.TMThreshold)
+	setFlag(flags, cfg, "pseudo-expansion", &pseudoExpansion, cfg.PseudoExpansion)
+}
+
+// setFlag sets a flag variable to a config value, unless the config doesn't set it or
+// the flag was given on the command line. Defaults the config fills in (like its
+// terminology directory) are applied too.
+func setFlag[T comparable](flags *pflag.FlagSet, cfg *config.Config, name string, flag *T, value T) {
+	var zero T
+	if (cfg.IsSet(name) || value != zero) && !flags.Changed(name) {
+		*flag = value
+	}
+}
+
+// translation is one source file translated to one target language, with the settings
+// resolved from flags, the config and defaults
+type translation struct {
+	params   TranslateParams
+	provider string
+	model    string
+}
+
+// resolveTranslations lists the translations of a run. Flags given on the command line
+// win over the source's settings in the config, which win over the top-level ones.
+// Without a source language or output, both come from the source's path.
+func resolveTranslations(flags *pflag.FlagSet, cfg *config.Config, sources []config.Source) ([]translation, error) {
+	var translations []translation
+	outputs := make(map[string]string) // output path -> source path
+	var locked string
+	if cfg != nil {
+		locked = cfg.Locked
+	}
+	for _, src := range sources {
+		override(flags, "source-lang", &src.SourceLang, sourceLangFlag)
+		override(flags, "output", &src.Output, outputFlag)
+		override(flags, "keys", &src.Keys, keysFlag)
+		override(flags, "exclude-keys", &src.ExcludeKeys, excludeKeysFlag)
+		switch {
+		case pseudoFlag != "":
+			// Pseudo-locales take the place of the target languages
+			locales, err := pseudoLocales(pseudoFlag)
+			if err != nil {
+				return nil, err
+			}
+			src.Targets = locales
+		case flags.Changed("to") || len(src.Targets) == 0:
+			src.Targets = splitList(targetLangs)
+		}
+		if len(src.Targets) == 0 {
+			return nil, fmt.Errorf("--to flag is required (or set targets in %s)", config.FileNames[0])
+		}
+
+		pathLang, defaultOutput := config.SourceLanguage(src.Path)
+		if src.SourceLang == "" {
+			src.SourceLang = pathLang
+		}
+		if src.Output == "" {
+			src.Output = defaultOutput
+		}
+		// Without {lang}, every target language would be written to the same file
+		if len(src.Targets) > 1 && !strings.Contains(src.Output, "{lang}") {
+			if info, err := os.Stat(src.Output); err != nil || !info.IsDir() {
+				return nil, domain.NewValidationError("several target languages would be translated into the same output; use {lang} in the output template", nil).
+					WithContext("output", src.Output).
+					WithContext("targets", strings.Join(src.Targets, ","))
+			}
+		}
+
+		for _, lang := range src.Targets {
+			settings := cfg.Language(lang)
+			override(flags, "provider", &settings.Provider, providerFlag)
+			override(flags, "model", &settings.Model, modelFlag)
+			if settings.Provider == "" {
+				settings.Provider = providerFlag
+			}
+			// A different provider doesn't inherit a model meant for another one
+			if flags.Changed("provider") && !flags.Changed("model") {
+				settings.Model = ""
+			}
+
+			// An output template without {name} would write several sources to one file
+			outputPath := config.OutputPath(src.Output, src.Path, lang)
+			if other, ok := outputs[outputPath]; ok && other != src.Path {
+				return nil, domain.NewValidationError("two source files would be translated into the same output; use {name} in the output template", nil).
+					WithContext("output", outputPath).
+					WithContext("sources", other+", "+src.Path)
+			}
+			outputs[outputPath] = src.Path
+
+			translations = append(translations, translation{
+				params: TranslateParams{
+					SourcePath:      src.Path,
+					SourceLang:      src.SourceLang,
+					TargetLang:      lang,
+					OutputPath:      outputPath,
+					TerminologyDir:  terminologyDirFlag,
+					SkipTerminology: skipTerminology,
+					NoTerminology:   noTerminology,
+					RedetectTerms:   redetectTerms,
+					Incremental:     incrementalFlag,
+					Keys:            src.Keys,
+					ExcludeKeys:     src.ExcludeKeys,
+					BatchSize:       batchSizeFlag,
+					Concurrency:     concurrencyFlag,
+					Yes:             yesFlag,
+					DryRun:          dryRunFlag,
+					NoTM:            noTMFlag,
+					TMThreshold:     tmThresholdFlag,
+					StyleGuide:      settings.Style,
+					Locked:          locked,
+				},
+				provider: settings.Provider,
+				model:    settings.Model,
+			})
+		}
+	}
+	return translations, nil
+}
+
+// override replaces a setting with a flag given on the command line
+func override(flags *pflag.FlagSet, name string, setting *string, flag string) {
+	if flags.Changed(name) {
+		*setting = flag
+	}
+}
+
+// sourceFiles returns the source files of a run with their settings: the files of the
+// argument (a file, directory or glob), else those of the config's sources. The source
+// language and targets given as flags (else the sources' own) tell the translations a
+// directory or glob matches apart from the sources.
+func sourceFiles(args []string, cfg *config.Config, sourceLang string, targets []string) ([]config.Source, error) {
+	var sources []config.Source
+	if len(args) > 0 {
+		sources = append(sources, cfg.Source(args[0]))
+	} else if cfg != nil {
+		for _, src := range cfg.Sources {
+			sources = append(sources, cfg.Source(src.Path))
+		}
+	}
+
+	var files []config.Source
+	for _, src := range sources {
+		srcTargets := src.Targets
+		if len(targets) > 0 {
+			srcTargets = targets
+		}
+		paths, err := config.ExpandSource(src.Path, cmp.Or(sourceLang, src.SourceLang), srcTargets)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			file := src
+			file.Path = path
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// sharedTermTexts returns the texts of all source files, so terminology detected for
+// the first file covers the others too. It returns nil for a single file.
+func sharedTermTexts(jsonUtil *utils.JSONUtil, sources []config.Source) ([]string, error) {
+	if len(sources) < 2 {
+		return nil, nil
+	}
+	var texts []string
+	for _, src := range sources {
+		source, err := jsonUtil.LoadJSON(src.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load source %s: %w", src.Path, err)
+		}
+		texts = append(texts, extractTexts(source)...)
+	}
+	return texts, nil
+}
+
+// newConfigCmd creates the "config" command for inspecting the project configuration
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the project configuration",
+		Long: `Inspect the project configuration.
+
+Jta reads its settings from jta.yaml, jta.yml or .jtarc (YAML) in the current directory
+or the closest parent directory, so a project's sources, targets, output paths, provider
+and model per language, key filters and style guides don't have to be repeated as flags.
+Flags given on the command line override the config.`,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the resolved configuration",
+		Long: `Print the resolved configuration as YAML: the config file's settings with relative
+paths resolved and defaults filled in, each source with its own settings, and the
+provider, model and style guide of each target language.`,
+		Example: `  # Show the configuration found from the current directory
+  jta config show
+
+  # Show a specific config file
+  jta config show --config ./config/jta.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(configFlag)
+			if err != nil {
+				return err
+			}
+
+			// The translate flags were never parsed, so they hold their defaults
+			applyConfig(cmd.Root().Flags(), cfg)
+			resolved := resolvedConfig(cfg)
+
+			if cfg == nil {
+				fmt.Fprintf(os.Stdout, "# No config file found (%s), showing defaults\n", strings.Join(config.FileNames, ", "))
+			} else {
+				fmt.Fprintf(os.Stdout, "# %s\n", cfg.Path)
+			}
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(resolved); err != nil {
+				return fmt.Errorf("failed to encode config: %w", err)
+			}
+			return encoder.Close()
+		},
+	}
+
+	configCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file (default: jta.yaml or .jtarc in the current or a parent directory)")
+	configCmd.AddCommand(showCmd)
+
+	return configCmd
+}
+
+// resolvedConfig returns the settings a translation run would use with a config and no flags
+func resolvedConfig(cfg *config.Config) *config.Config {
+	resolved := &config.Config{
+		SourceLang:        sourceLangFlag,
+		Output:            outputFlag,
+		Provider:          providerFlag,
+		Model:             modelFlag,
+		TerminologyDir:    terminologyDirFlag,
+		TerminologyStore:  termStoreFlag,
+		SkipTerminology:   skipTerminology,
+		Keys:              keysFlag,
+		ExcludeKeys:       excludeKeysFlag,
+		Incremental:       incrementalFlag,
+		BatchSize:         batchSizeFlag,
+		Concurrency:       concurrencyFlag,
+		Parallel:          parallelFlag,
+		RequestsPerMinute: rpmFlag,
+		TokensPerMinute:   tpmFlag,
+		MaxCost:           maxCostFlag,
+		Pricing:           pricingFlag,
+		NoCache:           noCacheFlag,
+		CacheTTL:          cacheTTLFlag,
+		NoTM:              noTMFlag,
+		TMThreshold:       tmThresholdFlag,
+		PseudoExpansion:   pseudoExpansion,
+	}
+	if cfg == nil {
+		return resolved
+	}
+
+	resolved.SourceLang = cfg.SourceLang
+	resolved.Targets = cfg.Targets
+	resolved.Output = cfg.Output
+	resolved.Keys = cfg.Keys
+	resolved.ExcludeKeys = cfg.ExcludeKeys
+	resolved.Locked = cfg.Locked
+
+	// Every language a source is translated to, plus those with settings of their own
+	var langs []string
+	for _, src := range cfg.Sources {
+		src = cfg.Source(src.Path)
+		resolved.Sources = append(resolved.Sources, src)
+		langs = append(langs, src.Targets...)
+	}
+	langs = append(langs, cfg.Targets...)
+	for lang := range cfg.Languages {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+
+	resolved.Languages = make(map[string]config.Language)
+	for _, lang := range slices.Compact(langs) {
+		settings := cfg.Language(lang)
+		if settings.Provider == "" {
+			settings.Provider = providerFlag
+		}
+		resolved.Languages[lang] = settings
+	}
+	return resolved
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/ui"
+)
+
+// logFormats are the formats of --log-format
+var logFormats = []string{"pretty", "text", "json"}
+
+// newLogger creates the logger of a run's progress. Pretty lines go to stdout with the
+// rest of the output; text and JSON records go to stderr, so they can be collected
+// apart from it. A log file gets every record, pretty ones as text. --verbose adds
+// debug records, --quiet keeps only warnings and errors. With a progress view, pretty
+// lines are written above it and the view stands in for the lines of every batch.
+// The returned function closes the log file.
+func newLogger(format, file string, verbose, quiet bool, progress *ui.Progress) (*slog.Logger, func() error, error) {
+	if !slices.Contains(logFormats, format) {
+		return nil, nil, domain.NewValidationError("unknown log format (want "+strings.Join(logFormats, ", ")+")", nil).
+			WithContext("format", format)
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case quiet:
+		level = slog.LevelWarn
+	case verbose:
+		level = slog.LevelDebug
+	}
+
+	var w io.Writer = os.Stderr
+	closeLog := func() error { return nil }
+	switch {
+	case file != "":
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, domain.NewIOError("failed to open log file", err).WithContext("path", file)
+		}
+		w, closeLog = f, f.Close
+		if format == "pretty" {
+			format = "text"
+		}
+	case format == "pretty" && progress != nil:
+		var handler slog.Handler = ui.NewLogHandler(progress, level)
+		if !verbose {
+			handler = batchFilter{handler}
+		}
+		return slog.New(handler), closeLog, nil
+	case format == "pretty":
+		return slog.New(ui.NewLogHandler(os.Stdout, level)), closeLog, nil
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: level,
+		// The label only tells apart the lines of a terminal; records have the file and language
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == ui.LabelKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts)), closeLog, nil
+	}
+	return slog.New(slog.NewTextHandler(w, opts)), closeLog, nil
+}
+
+// batchFilter drops the routine records of batches, leaving warnings and errors;
+// the progress view shows how the batches are going
+type batchFilter struct {
+	slog.Handler
+}
+
+// Handle passes on records that aren't about a batch or need attention
+func (f batchFilter) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn {
+		batch := false
+		r.Attrs(func(a slog.Attr) bool {
+			batch = a.Key == "batch"
+			return !batch
+		})
+		if batch {
+			return nil
+		}
+	}
+	return f.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps filtering the handler with attributes
+func (f batchFilter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return batchFilter{f.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps filtering the handler with a group
+func (f batchFilter) WithGroup(name string) slog.Handler {
+	return batchFilter{f.Handler.WithGroup(name)}
+}
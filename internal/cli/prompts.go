@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/prompt"
+	"github.com/hikanner/jta/internal/style"
+	"github.com/hikanner/jta/internal/terminology"
+	"github.com/hikanner/jta/internal/translator"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/hikanner/jta/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// promptsOptions holds the flags shared by all "prompts" subcommands
+type promptsOptions struct {
+	dir   string
+	store string
+}
+
+// newPromptsCmd creates the "prompts" command for inspecting and customizing prompts
+func newPromptsCmd() *cobra.Command {
+	opts := &promptsOptions{}
+
+	promptsCmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Inspect and customize the prompts sent to the model",
+		Long: `Inspect and customize the prompts sent to the model.
+
+Every prompt is a Go text/template. To change one, save its template as
+<terminology-dir>/prompts/<name>.tmpl and edit it; jta uses it instead of the
+built-in one. The templates and their variables are described in the README.
+
+  translate        batch translation
+  reflect          review of a translated batch
+  improve          rewrite of a batch using the review
+  shorten          shortening of translations over their length limit
+  detect-terms     term detection on a whole source file
+  validate-terms   validation of statistical term candidates (large files)
+  translate-terms  translation of consistent terms`,
+	}
+
+	promptsCmd.PersistentFlags().StringVar(&opts.dir, "terminology-dir", ".jta", "Terminology directory")
+	promptsCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file (default: jta.yaml or .jtarc in the current or a parent directory)")
+	promptsCmd.PersistentFlags().StringVar(&opts.store, "terminology-store", os.Getenv("JTA_TERMINOLOGY_STORE"), "Shared glossary: a .json/.yaml file or an http(s) glossary service URL (env: JTA_TERMINOLOGY_STORE)")
+
+	promptsCmd.AddCommand(newPromptsListCmd(opts), newPromptsDumpCmd(opts))
+
+	return promptsCmd
+}
+
+func newPromptsListCmd(opts *promptsOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the prompt templates and where they are loaded from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+
+			prompts, err := prompt.Load(opts.dir)
+			if err != nil {
+				return fmt.Errorf("failed to load prompt templates: %w", err)
+			}
+			for _, name := range prompt.Names {
+				if path := prompts.Source(name); path != "" {
+					printer.PrintInfo(fmt.Sprintf("%-16s %s", name, path))
+				} else {
+					printer.PrintSubtle(fmt.Sprintf("%-16s built-in (override with %s)", name, prompt.Path(opts.dir, name)))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// promptDumpParams are the inputs of "prompts dump"
+type promptDumpParams struct {
+	sourceLang  string
+	targetLang  string
+	keys        string
+	excludeKeys string
+	batchSize   int
+	batch       int
+}
+
+func newPromptsDumpCmd(opts *promptsOptions) *cobra.Command {
+	var params promptDumpParams
+	var templateOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "dump <name> [source.json]",
+		Short: "Print the effective prompt for a source file, or the built-in template",
+		Long: `Print the effective prompt for a source file, or the built-in template.
+
+With a source file the prompt is rendered as jta would send it, using the terminology,
+the style guide (or the language's style in the config) and any template overrides of
+the terminology directory.
+translate, reflect, improve and shorten render one batch (--batch) of the selected keys;
+the source texts stand in for the translations and the review. Translation memory
+references and incremental reuse are not applied.
+
+Without a source file the prompt is rendered with example data.`,
+		Example: `  # Start customizing the translate prompt
+  mkdir -p .jta/prompts
+  jta prompts dump translate --template > .jta/prompts/translate.tmpl
+
+  # The second batch of the translate prompt for German
+  jta prompts dump translate locales/en.json --to de --batch 2
+
+  # The reflection prompt for the settings keys
+  jta prompts dump reflect locales/en.json --to de --keys "settings.**"`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := prompt.ParseName(args[0])
+			if err != nil {
+				return err
+			}
+
+			// The built-in template, so it can be redirected into the override file
+			if templateOnly {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), prompt.Default().Text(name))
+				return err
+			}
+
+			prompts, err := prompt.Load(opts.dir)
+			if err != nil {
+				return fmt.Errorf("failed to load prompt templates: %w", err)
+			}
+
+			var text string
+			if len(args) == 1 {
+				text, err = prompts.Render(name, prompt.Example(name))
+			} else {
+				text, err = renderPrompt(opts, prompts, name, args[1], params)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), text)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&templateOnly, "template", false, "Print the built-in template instead of rendering it")
+	cmd.Flags().StringVar(&params.targetLang, "to", "", "Target language (required for translate, reflect, improve, shorten and translate-terms)")
+	cmd.Flags().StringVar(&params.sourceLang, "source-lang", "", "Source language (auto-detected from filename if not specified)")
+	cmd.Flags().StringVar(&params.keys, "keys", "", "Only include keys matching these patterns (comma-separated)")
+	cmd.Flags().StringVar(&params.excludeKeys, "exclude-keys", "", "Exclude keys matching these patterns (comma-separated)")
+	cmd.Flags().IntVar(&params.batchSize, "batch-size", 20, "Items per API call")
+	cmd.Flags().IntVar(&params.batch, "batch", 1, "Batch to render (1-based)")
+
+	return cmd
+}
+
+// renderPrompt renders a prompt for a source file as a translation or detection run would
+func renderPrompt(opts *promptsOptions, prompts *prompt.Templates, name prompt.Name, sourcePath string, params promptDumpParams) (string, error) {
+	source, err := utils.NewJSONUtil().LoadJSON(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load source file: %w", err)
+	}
+	sourceLang := languageFromPath(sourcePath, params.sourceLang)
+
+	manager, err := newTermManager(nil, opts.store)
+	if err != nil {
+		return "", err
+	}
+	manager.SetPrompts(prompts)
+
+	switch name {
+	case prompt.DetectTerms:
+		return manager.DetectionPrompt(extractTexts(source), sourceLang)
+
+	case prompt.ValidateTerms:
+		text, err := manager.ValidationPrompt(extractTexts(source), sourceLang)
+		if err == nil && text == "" {
+			err = domain.NewValidationError("no term candidates in the source file", nil)
+		}
+		return text, err
+	}
+
+	if params.targetLang == "" {
+		return "", domain.NewValidationError(fmt.Sprintf("--to is required for the %s prompt", name), nil)
+	}
+	targetLang, _ := domain.NormalizeLanguageCode(params.targetLang)
+
+	term, termTranslation, err := loadPromptTerminology(manager, opts.dir, targetLang)
+	if err != nil {
+		return "", err
+	}
+
+	if name == prompt.TranslateTerms {
+		if term == nil {
+			return "", domain.NewValidationError(fmt.Sprintf("no terminology in %s", manager.Location(opts.dir)), nil)
+		}
+		missing := term.GetMissingTranslations(termTranslation)
+		if len(missing) == 0 {
+			return "", domain.NewValidationError(fmt.Sprintf("all consistent terms are translated to %s", targetLang), nil)
+		}
+		return manager.TermTranslationPrompt(missing, sourceLang, targetLang)
+	}
+
+	styleGuide, err := style.Load(opts.dir, targetLang)
+	if err != nil {
+		return "", fmt.Errorf("failed to load style guide: %w", err)
+	}
+	if styleGuide == nil {
+		cfg, err := loadConfig(configFlag)
+		if err != nil {
+			return "", fmt.Errorf("failed to load config: %w", err)
+		}
+		if settings := cfg.Language(targetLang); !settings.Style.IsZero() {
+			styleGuide = settings.Style
+		}
+	}
+
+	limits, err := loadLengthLimits(opts.dir, sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load length limits: %w", err)
+	}
+
+	engine := translator.NewEngine(nil, manager)
+	engine.SetPrompts(prompts)
+	batches, err := engine.Prompts(domain.TranslationInput{
+		Source:                 source,
+		SourceLang:             sourceLang,
+		TargetLang:             targetLang,
+		Terminology:            term,
+		TerminologyTranslation: termTranslation,
+		StyleGuide:             styleGuide,
+		LengthLimits:           limits.keys,
+		LengthRules:            limits.rules,
+		Options: domain.TranslationOptions{
+			BatchSize:   params.batchSize,
+			Keys:        splitList(params.keys),
+			ExcludeKeys: splitList(params.excludeKeys),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if params.batch < 1 || params.batch > len(batches) {
+		return "", domain.NewValidationError(fmt.Sprintf("batch %d does not exist (%d batches)", params.batch, len(batches)), nil)
+	}
+
+	batch := batches[params.batch-1]
+	switch name {
+	case prompt.Reflect:
+		return batch.Reflect, nil
+	case prompt.Improve:
+		return batch.Improve, nil
+	case prompt.Shorten:
+		if batch.Shorten == "" {
+			return "", domain.NewValidationError(fmt.Sprintf("no keys with a length limit in batch %d", params.batch), nil)
+		}
+		return batch.Shorten, nil
+	default:
+		return batch.Translate, nil
+	}
+}
+
+// loadPromptTerminology loads the terminology and its translation into a language, if any
+func loadPromptTerminology(manager *terminology.Manager, dir, targetLang string) (*domain.Terminology, *domain.TerminologyTranslation, error) {
+	if !manager.TerminologyExists(dir) {
+		return nil, nil, nil
+	}
+	term, err := loadTerms(manager, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !manager.TranslationExists(dir, targetLang) {
+		return term, nil, nil
+	}
+	termTranslation, err := manager.LoadTerminologyTranslation(dir, targetLang)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load terminology translation: %w", err)
+	}
+	return term, termTranslation, nil
+}
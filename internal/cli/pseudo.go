@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/pseudo"
+	"github.com/hikanner/jta/internal/utils"
+)
+
+// pseudoLocalize writes the pseudo-locales of the sources, transformed locally without
+// a model: en-XA finds hardcoded strings and layouts without room for longer
+// translations, ar-XB layouts that break right-to-left
+func pseudoLocalize(translations []translation) error {
+	jsonUtil := utils.NewJSONUtil()
+	localizer := pseudo.NewLocalizer()
+	localizer.SetExpansion(pseudoExpansion)
+	for _, t := range translations {
+		source, err := jsonUtil.LoadJSON(t.params.SourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to load source: %w", err)
+		}
+
+		outputPath := t.params.OutputPath
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return domain.NewIOError("failed to create output directory", err).
+				WithContext("path", outputPath)
+		}
+		if err := jsonUtil.SaveJSON(outputPath, localizer.Localize(source, t.params.TargetLang)); err != nil {
+			return fmt.Errorf("failed to save result: %w", err)
+		}
+		printf("Pseudo-localized %s to %s\n", t.params.SourcePath, outputPath)
+	}
+	return nil
+}
+
+// pseudoLocales parses a comma-separated list of pseudo-locales
+func pseudoLocales(list string) ([]string, error) {
+	var locales []string
+	for _, code := range splitList(list) {
+		locale, ok := pseudo.Normalize(code)
+		if !ok {
+			return nil, domain.NewValidationError(fmt.Sprintf("unknown pseudo-locale %q", code), nil).
+				WithContext("supported", strings.Join(pseudo.Locales, ", "))
+		}
+		locales = append(locales, locale)
+	}
+	return locales, nil
+}
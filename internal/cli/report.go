@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"sync"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/report"
+	"github.com/hikanner/jta/internal/utils"
+)
+
+// runReport collects the translations of a run for --report; a nil runReport records nothing
+type runReport struct {
+	path   string
+	report *report.Report
+
+	mu      sync.Mutex
+	sources map[string]map[string]string // source path -> flattened source texts
+}
+
+// newRunReport starts the report written to path, nil without a path
+func newRunReport(path string) *runReport {
+	if path == "" {
+		return nil
+	}
+	return &runReport{path: path, report: report.New(Version), sources: make(map[string]map[string]string)}
+}
+
+// add records a translation: its result, nil if nothing needed translating, or its error
+func (r *runReport) add(app *App, params TranslateParams, result *domain.TranslationResult, err error) {
+	if r == nil {
+		return
+	}
+
+	t := report.Translation{
+		Source:     params.SourcePath,
+		SourceLang: params.SourceLang,
+		TargetLang: params.TargetLang,
+		Output:     params.OutputPath,
+		Provider:   app.provider.Name(),
+		Model:      app.provider.GetModelName(),
+	}
+	switch {
+	case err != nil:
+		t.Status = report.StatusFailed
+		t.Error = err.Error()
+	case result == nil && params.DryRun:
+		t.Status = report.StatusDryRun
+	case result == nil:
+		t.Status = report.StatusUnchanged
+	}
+
+	var sources map[string]string
+	if result != nil {
+		sources = r.sourceTexts(params.SourcePath)
+	}
+	r.report.Add(report.FromResult(t, sources, result))
+}
+
+// sourceTexts returns the texts of a source file. The result only has the translations,
+// so the source is read again, once for all its target languages.
+func (r *runReport) sourceTexts(path string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sources, ok := r.sources[path]
+	if !ok {
+		if data, err := utils.NewJSONUtil().LoadJSON(path); err == nil {
+			sources = utils.NewJSONUtil().FlattenStrings(data)
+		}
+		r.sources[path] = sources
+	}
+	return sources
+}
+
+// save writes the report
+func (r *runReport) save() error {
+	if r == nil {
+		return nil
+	}
+	return r.report.Save(r.path)
+}
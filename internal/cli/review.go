@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/review"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/hikanner/jta/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// reviewOptions holds the flags of the "review" command
+type reviewOptions struct {
+	dir      string
+	provider string
+	model    string
+	apiKey   string
+}
+
+// newReviewCmd creates the "review" command, which walks the strings runs changed
+func newReviewCmd() *cobra.Command {
+	opts := &reviewOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "review [translation file]...",
+		Short: "Review changed translations: accept, edit, reject or translate again",
+		Long: `Review the strings translation runs changed, one key at a time, with the source,
+the translation before the run, the new translation and reflection's suggestion side by side.
+
+  accept      keep the new translation and mark it human-approved
+  edit        type a better translation, marked human-approved
+  reject      put back the translation from before the run
+  translate   translate again with a note for the model, then decide again
+  skip        decide later
+
+Every run records its changes in <terminology-dir>/reviews. Approved translations are
+kept by later runs instead of being translated again, until their source text changes.
+Without arguments, every translation with changes waiting for review is reviewed.`,
+		Example: `  # Review everything the last runs changed
+  jta review
+
+  # Review one translation file
+  jta review locales/de.json
+
+  # Translate right away, then review the changes
+  jta locales/en.json --to de --review`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReview(cmd, opts, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.dir, "terminology-dir", "", "Terminology directory with the reviews (default: .jta)")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "AI provider for translating again (default: from the config, or openai)")
+	cmd.Flags().StringVar(&opts.model, "model", "", "Model name (uses default if not specified)")
+	cmd.Flags().StringVar(&opts.apiKey, "api-key", "", "API key (or use environment variable)")
+	cmd.Flags().StringVar(&configFlag, "config", "", "Config file (default: jta.yaml or .jtarc in the current or a parent directory)")
+
+	return cmd
+}
+
+func runReview(cmd *cobra.Command, opts *reviewOptions, args []string) error {
+	printer := ui.NewPrinter(false)
+
+	cfg, err := loadConfig(configFlag)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	dir := opts.dir
+	if dir == "" && cfg != nil {
+		dir = cfg.TerminologyDir
+	}
+	if dir == "" {
+		dir = ".jta"
+	}
+
+	paths, err := review.List(dir)
+	if err != nil {
+		return err
+	}
+	states := make(map[string]*review.State, len(paths))
+	for _, path := range paths {
+		if states[path], err = review.Load(path); err != nil {
+			return err
+		}
+	}
+
+	// Translation files given as arguments, or every one with changes
+	if len(args) > 0 {
+		var selected []string
+		for _, arg := range args {
+			i := slices.IndexFunc(paths, func(path string) bool {
+				return filepath.Clean(states[path].TargetPath) == filepath.Clean(arg)
+			})
+			if i < 0 {
+				return domain.NewValidationError("no changes recorded for translation file", nil).
+					WithContext("path", arg).
+					WithContext("reviews", filepath.Join(dir, "reviews"))
+			}
+			selected = append(selected, paths[i])
+		}
+		paths = selected
+	}
+	paths = slices.DeleteFunc(paths, func(path string) bool { return len(states[path].Pending) == 0 })
+	if len(paths) == 0 {
+		printer.PrintSuccess("Nothing to review")
+		return nil
+	}
+
+	// The provider is only needed to translate a string again
+	ctx := context.Background()
+	apps := newAppPool(ctx, AppConfig{
+		APIKey: opts.apiKey,
+		Logger: slog.New(ui.NewLogHandler(os.Stdout, slog.LevelWarn)),
+	})
+	for _, path := range paths {
+		state := states[path]
+		settings := cfg.Language(state.TargetLanguage)
+		override(cmd.Flags(), "provider", &settings.Provider, opts.provider)
+		override(cmd.Flags(), "model", &settings.Model, opts.model)
+		if settings.Provider == "" {
+			settings.Provider = "openai"
+		}
+		params := TranslateParams{
+			SourcePath:     state.SourcePath,
+			SourceLang:     state.SourceLanguage,
+			TargetLang:     state.TargetLanguage,
+			TerminologyDir: dir,
+			StyleGuide:     settings.Style,
+		}
+		if err := reviewChanges(ctx, apps, settings.Provider, settings.Model, params, path, state); err != nil {
+			return errors.Join(err, apps.Close())
+		}
+	}
+	return apps.Close()
+}
+
+// reviewTranslations reviews the changes of a run's translations, one after another
+func reviewTranslations(ctx context.Context, apps *appPool, translations []translation) error {
+	for _, t := range translations {
+		path := review.Path(t.params.TerminologyDir, t.params.SourcePath, t.params.TargetLang)
+		state, err := review.Load(path)
+		if err != nil {
+			return err
+		}
+		if len(state.Pending) == 0 {
+			continue
+		}
+		if err := reviewChanges(ctx, apps, t.provider, t.model, t.params, path, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reviewChanges walks the changes of a translation with the reviewer, then writes the
+// decisions to the translation file and the review
+func reviewChanges(ctx context.Context, apps *appPool, providerName, model string, params TranslateParams, path string, state *review.State) error {
+	printer := ui.NewPrinter(false)
+	printer.PrintHeader(fmt.Sprintf("Review of %s (%d changes)", state.TargetPath, len(state.Pending)))
+
+	session := review.NewSession(state, os.Stdin, os.Stdout)
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil {
+		session.SetWidth(width)
+	}
+	session.SetRetranslator(func(ctx context.Context, key, source, note string) (string, string, error) {
+		app, err := apps.get(providerName, model)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to initialize application: %w", err)
+		}
+		return app.Retranslate(ctx, params, key, source, note)
+	})
+
+	result, err := session.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	if result.Changed() {
+		jsonUtil := utils.NewJSONUtil()
+		data, err := jsonUtil.LoadJSON(state.TargetPath)
+		if err != nil {
+			return fmt.Errorf("failed to load translation file: %w", err)
+		}
+		if err := jsonUtil.SaveJSON(state.TargetPath, result.Apply(data)); err != nil {
+			return fmt.Errorf("failed to save translation file: %w", err)
+		}
+	}
+	if err := review.Save(path, state); err != nil {
+		return err
+	}
+
+	printer.PrintNewline()
+	printer.PrintSuccess(fmt.Sprintf("%d accepted, %d edited, %d rejected, %d translated again",
+		result.Accepted, result.Edited, result.Rejected, result.Retranslated))
+	if result.Remaining > 0 {
+		printer.PrintSubtle(fmt.Sprintf("%d changes left for later: jta review %s", result.Remaining, state.TargetPath))
+	}
+	return nil
+}
@@ -2,17 +2,30 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/hikanner/jta/internal/config"
 	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/provider"
+	"github.com/hikanner/jta/internal/pseudo"
+	"github.com/hikanner/jta/internal/tm"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/hikanner/jta/internal/utils"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
 	// Flags
+	configFlag         string
 	targetLangs        string
 	providerFlag       string
 	modelFlag          string
@@ -20,24 +33,50 @@ var (
 	sourceLangFlag     string
 	outputFlag         string
 	terminologyDirFlag string
+	termStoreFlag      string
 	skipTerminology    bool
 	noTerminology      bool
 	redetectTerms      bool
+	detectOfflineFlag  bool
 	incrementalFlag    bool
 	keysFlag           string
 	excludeKeysFlag    string
 	batchSizeFlag      int
 	concurrencyFlag    int
+	parallelFlag       int
+	rpmFlag            int
+	tpmFlag            int
+	dryRunFlag         bool
+	maxCostFlag        float64
+	pricingFlag        string
+	reportFlag         string
+	reviewFlag         bool
+	pseudoFlag         string
+	pseudoExpansion    int
+	noCacheFlag        bool
+	cacheTTLFlag       time.Duration
+	cassetteFlag       string
+	noTMFlag           bool
+	tmThresholdFlag    float64
 	yesFlag            bool
 	verboseFlag        bool
+	quietFlag          bool
+	logFormatFlag      string
+	logFileFlag        string
 	listLanguagesFlag  bool
 	versionFlag        bool
 )
 
+// progressInterval is how often the progress is written when stdout is not a terminal
+const progressInterval = 10 * time.Second
+
+// runProgress is the progress view of the running translation run, nil when not shown
+var runProgress *ui.Progress
+
 // NewRootCmd creates the root command
 func NewRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "jta <source> --to <languages>",
+		Use:   "jta <source file, directory or glob> --to <languages>",
 		Short: "Jta - Agentic JSON Translation Agent",
 		Long: `Jta - AI-powered Agentic JSON Translation tool with intelligent quality optimization
 
@@ -73,36 +112,59 @@ Key Features:
   jta en.json --to zh --keys "settings.*,user.*" --exclude-keys "internal.*"
 
   # Fast mode: skip terminology detection
-  jta en.json --to zh --skip-terminology`,
+  jta en.json --to zh --skip-terminology
+
+  # Estimate cost without calling the API, then cap spending
+  jta en.json --to zh,ja --dry-run
+  jta en.json --to zh,ja --max-cost 2.50
+
+  # Every JSON file of a directory: locales/en/*.json -> locales/de/*.json
+  jta locales/en --to de,fr
+
+  # Pseudo-localize for QA, without a model: en-XA.json and ar-XB.json
+  jta en.json --pseudo en-XA,ar-XB
+
+  # Translate the sources and targets declared in jta.yaml
+  jta`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runTranslate,
 	}
 
+	// Project configuration
+	rootCmd.Flags().StringVar(&configFlag, "config", "", "Config file (default: jta.yaml or .jtarc in the current or a parent directory)")
+
 	// Add flags
 	rootCmd.Flags().StringVar(&targetLangs, "to", "", "Target language(s), comma-separated (e.g., zh,ja,ko) [REQUIRED]")
 	rootCmd.Flags().BoolVar(&listLanguagesFlag, "list-languages", false, "List all supported languages and exit")
 	rootCmd.Flags().BoolVar(&versionFlag, "version", false, "Print version information and exit")
 
 	// AI Provider settings
-	rootCmd.Flags().StringVar(&providerFlag, "provider", "openai", "AI provider: openai, anthropic, or gemini")
+	rootCmd.Flags().StringVar(&providerFlag, "provider", "openai", "AI provider: openai, anthropic, gemini, or replay (offline, needs --cassette)")
 	rootCmd.Flags().StringVar(&modelFlag, "model", "", "Model name (default: gpt-5, claude-sonnet-4-5, gemini-2.5-flash)")
 	rootCmd.Flags().StringVar(&apiKeyFlag, "api-key", "", "API key (or use OPENAI_API_KEY/ANTHROPIC_API_KEY/GEMINI_API_KEY env)")
+	rootCmd.Flags().StringVar(&cassetteFlag, "cassette", "", "Cassette file: replayed with --provider replay, otherwise API traffic is recorded into it")
 
 	// Source settings
 	rootCmd.Flags().StringVar(&sourceLangFlag, "source-lang", "", "Source language (auto-detected from filename if not specified)")
 
 	// Output settings
-	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output file path (default: <target-lang>.json in source directory)")
+	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output file or path template with {lang}, {name}, {dir}, {ext} (default: where the source language is in the source path)")
 
 	// Terminology management
 	rootCmd.Flags().StringVar(&terminologyDirFlag, "terminology-dir", ".jta", "Terminology directory (default: .jta/)")
+	rootCmd.Flags().StringVar(&termStoreFlag, "terminology-store", os.Getenv("JTA_TERMINOLOGY_STORE"), "Shared glossary instead of the terminology directory: a .json/.yaml file or an http(s) glossary service URL (env: JTA_TERMINOLOGY_STORE)")
 	rootCmd.Flags().BoolVar(&skipTerminology, "skip-terminology", false, "Skip term detection (use existing terminology)")
 	rootCmd.Flags().BoolVar(&noTerminology, "no-terminology", false, "Disable terminology management completely")
 	rootCmd.Flags().BoolVar(&redetectTerms, "redetect-terms", false, "Re-detect terminology (use when source language changes)")
+	rootCmd.Flags().BoolVar(&detectOfflineFlag, "detect-offline", false, "Only extract term candidates into <terminology-dir>/candidates.json for review, without any API call")
 
 	// Translation behavior
 	rootCmd.Flags().BoolVar(&incrementalFlag, "incremental", false, "Incremental translation (only translate new/modified content)")
 
+	// Translation memory
+	rootCmd.Flags().BoolVar(&noTMFlag, "no-tm", false, "Don't reuse or record translations in the translation memory (<terminology-dir>/tm.jsonl)")
+	rootCmd.Flags().Float64Var(&tmThresholdFlag, "tm-threshold", tm.DefaultFuzzyThreshold, "Minimum similarity (0-1) for translation memory matches passed to the prompt as references")
+
 	// Key filtering
 	rootCmd.Flags().StringVar(&keysFlag, "keys", "", "Include only these keys (glob patterns, e.g., 'settings.*,user.*')")
 	rootCmd.Flags().StringVar(&excludeKeysFlag, "exclude-keys", "", "Exclude these keys (glob patterns, e.g., 'internal.*,debug.*')")
@@ -110,10 +172,43 @@ Key Features:
 	// Performance tuning
 	rootCmd.Flags().IntVar(&batchSizeFlag, "batch-size", 20, "Items per API call (10-50 recommended, larger = fewer calls but slower)")
 	rootCmd.Flags().IntVar(&concurrencyFlag, "concurrency", 3, "Parallel API requests (1-5 recommended, higher = faster but may hit rate limits)")
+	rootCmd.Flags().IntVar(&parallelFlag, "parallel", 4, "Target languages translated at the same time, sharing the concurrency and rate limits")
+	rootCmd.Flags().IntVar(&rpmFlag, "requests-per-minute", 0, "Max API requests per minute per provider+model (0 = unlimited)")
+	rootCmd.Flags().IntVar(&tpmFlag, "tokens-per-minute", 0, "Max tokens per minute per provider+model (0 = unlimited)")
+
+	// Cost control
+	rootCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Estimate items, tokens and cost without calling the API")
+	rootCmd.Flags().Float64Var(&maxCostFlag, "max-cost", 0, "Stop once spending reaches this many USD and save a checkpoint (0 = unlimited)")
+	rootCmd.Flags().StringVar(&pricingFlag, "pricing", "", "JSON file with model prices per million tokens, e.g. {\"gpt-5\": {\"input\": 1.25, \"output\": 10}}")
+
+	// Response cache
+	rootCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Don't read or write the response cache (<terminology-dir>/cache)")
+	rootCmd.Flags().DurationVar(&cacheTTLFlag, "cache-ttl", provider.DefaultCacheTTL, "How long cached responses are reused (0 = forever)")
+
+	// Reporting
+	rootCmd.Flags().StringVar(&reportFlag, "report", "", "Write a JSON report of the run: per language and key the source, draft, suggestion, final text, tokens, retries and issues")
+
+	// Review
+	rootCmd.Flags().BoolVar(&reviewFlag, "review", false, "Review the changed strings after translating: accept, edit, reject or translate again (see 'jta review')")
+
+	// Pseudo-localization
+	rootCmd.Flags().StringVar(&pseudoFlag, "pseudo", "", "Pseudo-localize instead of translating, without a model: en-XA (accented, expanded) and/or ar-XB (mirrored right-to-left), comma-separated")
+	rootCmd.Flags().IntVar(&pseudoExpansion, "pseudo-expansion", pseudo.DefaultExpansion, "How many percent en-XA strings are made longer, to find layouts without room for translations")
 
 	// UI behavior
 	rootCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Non-interactive mode (skip confirmations, useful for CI/CD)")
 	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Verbose output (show Agentic reflection steps and API details)")
+	rootCmd.Flags().BoolVarP(&quietFlag, "quiet", "q", false, "Print only warnings and errors")
+	rootCmd.Flags().StringVar(&logFormatFlag, "log-format", "pretty", "Progress log format: pretty (stdout), text or json (stderr)")
+	rootCmd.Flags().StringVar(&logFileFlag, "log-file", "", "Append the progress log to a file instead (pretty is written as text)")
+
+	rootCmd.AddCommand(newCacheCmd())
+	rootCmd.AddCommand(newTMCmd())
+	rootCmd.AddCommand(newTermsCmd())
+	rootCmd.AddCommand(newPromptsCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newCheckCmd())
+	rootCmd.AddCommand(newReviewCmd())
 
 	return rootCmd
 }
@@ -131,74 +226,277 @@ func runTranslate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Require source file when not listing languages
-	if len(args) == 0 {
-		return fmt.Errorf("source file is required")
+	// Settings from the project config apply unless given as flags
+	cfg, err := loadConfig(configFlag)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg != nil {
+		printf("Using config %s\n", cfg.Path)
 	}
+	applyConfig(cmd.Flags(), cfg)
 
-	// Require --to flag when translating
-	if targetLangs == "" {
-		return fmt.Errorf("--to flag is required")
+	// Batches are followed in a progress view: live on a terminal, summary lines otherwise
+	runProgress = nil
+	if !quietFlag && !dryRunFlag {
+		runProgress = ui.NewProgress(os.Stdout, progressInterval)
 	}
+	logger, closeLog, err := newLogger(logFormatFlag, logFileFlag, verboseFlag, quietFlag, runProgress)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closeLog() }()
 
-	ctx := context.Background()
+	// Require a source file when not listing languages
+	if len(args) == 0 && (cfg == nil || len(cfg.Sources) == 0) {
+		return fmt.Errorf("source file is required (or declare sources in %s)", config.FileNames[0])
+	}
+	sources, err := sourceFiles(args, cfg, sourceLangFlag, splitList(targetLangs))
+	if err != nil {
+		return err
+	}
+
+	// Offline detection only extracts term candidates for review, nothing is translated
+	if detectOfflineFlag {
+		manager, err := newTermManager(nil, termStoreFlag)
+		if err != nil {
+			return err
+		}
+		paths := make([]string, len(sources))
+		for i, src := range sources {
+			paths[i] = src.Path
+		}
+		return detectOffline(manager, terminologyDirFlag, paths, sources[0].SourceLang)
+	}
 
-	sourcePath := args[0]
+	translations, err := resolveTranslations(cmd.Flags(), cfg, sources)
+	if err != nil {
+		return err
+	}
 
-	// Validate source file exists
-	if _, err := os.Stat(sourcePath); err != nil {
-		return fmt.Errorf("source file not found: %s", sourcePath)
+	// Pseudo-localization transforms the strings locally, nothing is sent to a model
+	if pseudoFlag != "" {
+		return pseudoLocalize(translations)
 	}
 
-	// Parse target languages
-	langs := strings.Split(targetLangs, ",")
-	for i, lang := range langs {
-		langs[i] = strings.TrimSpace(lang)
+	// Terminology is shared by all source files, so it is detected from all of them
+	termTexts, err := sharedTermTexts(utils.NewJSONUtil(), sources)
+	if err != nil {
+		return err
 	}
 
-	// Create application
-	app, err := NewApp(ctx, AppConfig{
-		Provider: providerFlag,
-		Model:    modelFlag,
-		APIKey:   apiKeyFlag,
-		Verbose:  verboseFlag,
+	ctx := context.Background()
+
+	// Responses are cached next to the terminology unless disabled.
+	// Cassettes bypass the cache: recording must see every request, replay is offline anyway.
+	var cacheDir string
+	if !noCacheFlag && cassetteFlag == "" {
+		cacheDir = filepath.Join(terminologyDirFlag, "cache")
+	}
+
+	// One application per provider and model, sharing the spending cap
+	apps := newAppPool(ctx, AppConfig{
+		APIKey:            apiKeyFlag,
+		Verbose:           verboseFlag,
+		Quiet:             quietFlag,
+		Logger:            logger,
+		Progress:          runProgress,
+		Concurrency:       concurrencyFlag,
+		RequestsPerMinute: rpmFlag,
+		TokensPerMinute:   tpmFlag,
+		PricingFile:       pricingFlag,
+		MaxCost:           maxCostFlag,
+		CacheDir:          cacheDir,
+		CacheTTL:          cacheTTLFlag,
+		Cassette:          cassetteFlag,
+		TerminologyStore:  termStoreFlag,
 	})
 
-	if err != nil {
-		return fmt.Errorf("failed to initialize application: %w", err)
-	}
-
-	// Run translation for each target language
-	for _, targetLang := range langs {
-		fmt.Printf("\n🚀 Translating to %s...\n", targetLang)
-
-		err := app.Translate(ctx, TranslateParams{
-			SourcePath:      sourcePath,
-			SourceLang:      sourceLangFlag,
-			TargetLang:      targetLang,
-			OutputPath:      outputFlag,
-			TerminologyDir:  terminologyDirFlag,
-			SkipTerminology: skipTerminology,
-			NoTerminology:   noTerminology,
-			RedetectTerms:   redetectTerms,
-			Incremental:     incrementalFlag,
-			Keys:            keysFlag,
-			ExcludeKeys:     excludeKeysFlag,
-			BatchSize:       batchSizeFlag,
-			Concurrency:     concurrencyFlag,
-			Yes:             yesFlag,
-		})
+	// Translations run at the same time share the rate limits of their provider and
+	// model; dry runs and single translations keep the plain sequential output
+	parallel := min(max(parallelFlag, 1), len(translations))
+	if dryRunFlag {
+		parallel = 1
+	}
 
+	// Terminology is prepared once, not by each translation running at the same time
+	if parallel > 1 {
+		app, err := apps.get(translations[0].provider, translations[0].model)
+		if err != nil {
+			return errors.Join(fmt.Errorf("failed to initialize application: %w", err), apps.Close())
+		}
+		first := translations[0].params
+		first.TermTexts = termTexts
+		term, err := app.PrepareTerminology(ctx, first)
 		if err != nil {
-			return fmt.Errorf("translation failed for %s: %w", targetLang, err)
+			return errors.Join(err, apps.Close())
+		}
+		// Missing terms are translated once per language, not by each file into it
+		termTranslations := make(map[string]*domain.TerminologyTranslation)
+		for i := range translations {
+			t := &translations[i]
+			if t.params.SourceLang != first.SourceLang {
+				continue
+			}
+			t.params.Terminology = term
+			// Nothing was found: don't let every translation try again
+			t.params.SkipTerminology = t.params.SkipTerminology || term == nil
+			if term == nil {
+				continue
+			}
+
+			termTranslation, ok := termTranslations[t.params.TargetLang]
+			if !ok {
+				app, err := apps.get(t.provider, t.model)
+				if err != nil {
+					return errors.Join(fmt.Errorf("failed to initialize application: %w", err), apps.Close())
+				}
+				if termTranslation, err = app.PrepareTermTranslation(ctx, t.params, term); err != nil {
+					return errors.Join(err, apps.Close())
+				}
+				// Terms that failed to translate aren't tried again by every translation
+				if termTranslation == nil && len(term.ConsistentTerms) > 0 {
+					termTranslation = domain.NewTerminologyTranslation(term.SourceLanguage, t.params.TargetLang)
+				}
+				termTranslations[t.params.TargetLang] = termTranslation
+			}
+			t.params.TermTranslation = termTranslation
 		}
+	}
+
+	// Run each translation: every target language of every source
+	summary := newRunSummary()
+	report := newRunReport(reportFlag)
+	runProgress.Start()
+	if parallel > 1 {
+		err = translateParallel(ctx, apps, translations, termTexts, parallel, len(sources) > 1, summary, report)
+	} else {
+		err = translateSequential(ctx, apps, translations, termTexts, len(sources) > 1, summary, report)
+	}
+	runProgress.Stop()
+
+	// The report is written even when the run failed: it says which translations did
+	if reportErr := report.save(); reportErr != nil {
+		err = errors.Join(err, reportErr)
+	} else if report != nil {
+		printf("Report written to %s\n", reportFlag)
+	}
+	if err != nil {
+		return errors.Join(err, apps.Close())
+	}
 
-		fmt.Printf("✅ Translation completed for %s\n", targetLang)
+	// Several files or languages get totals across all of them
+	if len(translations) > 1 && !dryRunFlag && !quietFlag {
+		summary.print(ui.NewPrinter(verboseFlag))
+	}
+
+	// Changes are reviewed once every translation is done
+	if reviewFlag && !dryRunFlag {
+		if err := reviewTranslations(ctx, apps, translations); err != nil {
+			return errors.Join(err, apps.Close())
+		}
 	}
 
+	return apps.Close()
+}
+
+// translateSequential runs the translations one after another
+func translateSequential(ctx context.Context, apps *appPool, translations []translation, termTexts []string, multipleSources bool, summary *runSummary, report *runReport) error {
+	for _, t := range translations {
+		t.params.Name = translationName(t.params, multipleSources)
+		if multipleSources {
+			printf("\n🚀 Translating %s to %s...\n", t.params.SourcePath, t.params.TargetLang)
+		} else {
+			printf("\n🚀 Translating to %s...\n", t.params.TargetLang)
+		}
+
+		app, err := apps.get(t.provider, t.model)
+		if err != nil {
+			return fmt.Errorf("failed to initialize application: %w", err)
+		}
+
+		t.params.TermTexts = termTexts
+		result, err := app.Translate(ctx, t.params)
+		report.add(app, t.params, result, err)
+		if err != nil {
+			return fmt.Errorf("translation failed for %s: %w", t.params.TargetLang, err)
+		}
+		summary.add(t.params, result)
+
+		if !dryRunFlag {
+			printf("✅ Translation completed for %s\n", t.params.TargetLang)
+		}
+	}
 	return nil
 }
 
+// translateParallel runs up to parallel translations at the same time, each line of
+// their output labeled with the language (and file). A failure doesn't stop the others:
+// each translation finishes or saves its own checkpoint, and the errors are joined.
+func translateParallel(ctx context.Context, apps *appPool, translations []translation, termTexts []string, parallel int, multipleSources bool, summary *runSummary, report *runReport) error {
+	// Applications are created up front: the pool is not safe for concurrent use
+	runs := make([]*App, len(translations))
+	var langs []string
+	for i, t := range translations {
+		app, err := apps.get(t.provider, t.model)
+		if err != nil {
+			return fmt.Errorf("failed to initialize application: %w", err)
+		}
+		runs[i] = app
+		if !slices.Contains(langs, t.params.TargetLang) {
+			langs = append(langs, t.params.TargetLang)
+		}
+	}
+	printf("\n🚀 Translating to %s (%d at a time)...\n", strings.Join(langs, ", "), parallel)
+
+	var g errgroup.Group
+	g.SetLimit(parallel)
+	var mu sync.Mutex
+	var errs []error
+	for i, t := range translations {
+		g.Go(func() error {
+			t.params.TermTexts = termTexts
+			t.params.Name = translationName(t.params, multipleSources)
+			t.params.Label = "[" + t.params.Name + "] "
+
+			result, err := runs[i].Translate(ctx, t.params)
+			report.add(runs[i], t.params, result, err)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("translation failed for %s: %w", t.params.TargetLang, err))
+				mu.Unlock()
+				return nil
+			}
+			summary.add(t.params, result)
+			printf("%s✅ Translation completed\n", t.params.Label)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return errors.Join(errs...)
+}
+
+// translationName names a translation in labels and the progress view: its language,
+// and its file when there are several
+func translationName(params TranslateParams, multipleSources bool) string {
+	if multipleSources {
+		return filepath.Base(params.SourcePath) + " " + params.TargetLang
+	}
+	return params.TargetLang
+}
+
+// printf prints the progress of a run, unless --quiet
+func printf(format string, a ...any) {
+	if quietFlag {
+		return
+	}
+	if runProgress != nil {
+		_, _ = fmt.Fprintf(runProgress, format, a...)
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
 // Execute runs the root command
 func Execute() error {
 	return NewRootCmd().Execute()
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/ui"
+)
+
+// runSummary adds up the results of the translations of a run
+type runSummary struct {
+	mu               sync.Mutex // translations may finish at the same time
+	start            time.Time
+	files            map[string]bool
+	languages        map[string]bool
+	translations     int
+	skipped          int // translations without changes
+	stats            domain.TranslationStats
+	termViolations   int
+	lengthViolations int
+}
+
+// newRunSummary starts the summary of a run
+func newRunSummary() *runSummary {
+	return &runSummary{
+		start:     time.Now(),
+		files:     make(map[string]bool),
+		languages: make(map[string]bool),
+	}
+}
+
+// add records the result of a translation, nil if nothing needed translating
+func (s *runSummary) add(params TranslateParams, result *domain.TranslationResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[params.SourcePath] = true
+	s.languages[params.TargetLang] = true
+	s.translations++
+	if result == nil {
+		s.skipped++
+		return
+	}
+
+	stats := result.Stats
+	s.stats.TotalItems += stats.TotalItems
+	s.stats.SuccessItems += stats.SuccessItems
+	s.stats.FailedItems += stats.FailedItems
+	s.stats.ReusedItems += stats.ReusedItems
+	s.stats.LockedItems += stats.LockedItems
+	s.stats.CacheHits += stats.CacheHits
+	s.stats.APICallsCount += stats.APICallsCount
+	s.stats.PromptTokens += stats.PromptTokens
+	s.stats.CompletionTokens += stats.CompletionTokens
+	s.stats.TotalTokens += stats.TotalTokens
+	s.stats.EstimatedCost += stats.EstimatedCost
+	s.termViolations += len(result.TermViolations)
+	s.lengthViolations += len(result.LengthViolations)
+}
+
+// print prints the totals of the run
+func (s *runSummary) print(printer *ui.Printer) {
+	fmt.Println()
+	printer.PrintHeader("Run Summary")
+
+	stats := map[string]any{
+		"Files":          len(s.files),
+		"Languages":      len(s.languages),
+		"Translations":   fmt.Sprintf("%d (%d without changes)", s.translations, s.skipped),
+		"Total items":    s.stats.TotalItems,
+		"Success":        s.stats.SuccessItems,
+		"Failed":         s.stats.FailedItems,
+		"Duration":       time.Since(s.start).Round(time.Millisecond).String(),
+		"API calls":      s.stats.APICallsCount,
+		"Tokens":         printer.FormatNumber(s.stats.TotalTokens),
+		"Estimated cost": fmt.Sprintf("$%.4f", s.stats.EstimatedCost),
+	}
+	if s.stats.ReusedItems > 0 {
+		stats["Reused"] = s.stats.ReusedItems
+	}
+	if s.stats.LockedItems > 0 {
+		stats["Locked"] = s.stats.LockedItems
+	}
+	if s.stats.CacheHits > 0 {
+		stats["Cache hits"] = s.stats.CacheHits
+	}
+	if s.termViolations > 0 {
+		stats["Term violations"] = s.termViolations
+	}
+	if s.lengthViolations > 0 {
+		stats["Too long"] = s.lengthViolations
+	}
+	printer.PrintStats(stats)
+}
@@ -0,0 +1,820 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/keyfilter"
+	"github.com/hikanner/jta/internal/terminology"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/hikanner/jta/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	offlineCandidateLimit   = 500 // candidates written for review
+	offlineCandidatePreview = 20  // candidates printed
+)
+
+// termsOptions holds the flags shared by all "terms" subcommands
+type termsOptions struct {
+	dir      string
+	store    string
+	provider string
+	model    string
+	apiKey   string
+}
+
+// newTermsCmd creates the "terms" command for curating terminology
+func newTermsCmd() *cobra.Command {
+	opts := &termsOptions{}
+
+	termsCmd := &cobra.Command{
+		Use:   "terms",
+		Short: "Curate the terminology used for translation",
+		Long: `Curate the terminology used for translation.
+
+Terminology lives in <terminology-dir>/terminology.json (source terms) and
+terminology.<lang>.json (pinned translations per target language):
+
+  preserve terms    are never translated (brand names, product names, acronyms)
+  consistent terms  are always translated the same way
+
+Terms can carry details (part of speech, definition, case sensitivity, key scope)
+that are shown to the model, and translations can list forbidden alternatives.`,
+	}
+
+	termsCmd.PersistentFlags().StringVar(&opts.dir, "terminology-dir", ".jta", "Terminology directory")
+	termsCmd.PersistentFlags().StringVar(&opts.store, "terminology-store", os.Getenv("JTA_TERMINOLOGY_STORE"), "Shared glossary: a .json/.yaml file or an http(s) glossary service URL (env: JTA_TERMINOLOGY_STORE)")
+	termsCmd.PersistentFlags().StringVar(&opts.provider, "provider", "openai", "AI provider for translate and detect (openai, anthropic, gemini)")
+	termsCmd.PersistentFlags().StringVar(&opts.model, "model", "", "Model name (uses default if not specified)")
+	termsCmd.PersistentFlags().StringVar(&opts.apiKey, "api-key", "", "API key (or use environment variable)")
+
+	termsCmd.AddCommand(
+		newTermsListCmd(opts),
+		newTermsAddCmd(opts),
+		newTermsRemoveCmd(opts),
+		newTermsSetCmd(opts),
+		newTermsForbidCmd(opts),
+		newTermsTranslateCmd(opts),
+		newTermsDetectCmd(opts),
+		newTermsExportCmd(opts),
+		newTermsImportCmd(opts),
+	)
+
+	return termsCmd
+}
+
+func newTermsListCmd(opts *termsOptions) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List terms and their translations, highlighting missing ones",
+		Example: `  # All languages with a terminology translation
+  jta terms list
+
+  # Check specific target languages
+  jta terms list --to zh,ja,ko`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			term, err := loadTerms(manager, opts.dir)
+			if err != nil {
+				return err
+			}
+
+			langs, err := termsLanguages(manager, opts.dir, to)
+			if err != nil {
+				return err
+			}
+			translations := make(map[string]*domain.TerminologyTranslation, len(langs))
+			for _, lang := range langs {
+				if manager.TranslationExists(opts.dir, lang) {
+					if translations[lang], err = manager.LoadTerminologyTranslation(opts.dir, lang); err != nil {
+						return fmt.Errorf("failed to load terminology translation for %s: %w", lang, err)
+					}
+				}
+			}
+
+			printer.PrintHeader(fmt.Sprintf("Terminology (%s)", term.SourceLanguage))
+
+			fmt.Printf("\nPreserve terms (%d):\n", len(term.PreserveTerms))
+			for _, t := range term.PreserveTerms {
+				fmt.Printf("  %s%s\n", t, termDetails(term.Info(t)))
+			}
+
+			fmt.Printf("\nConsistent terms (%d):\n", len(term.ConsistentTerms))
+			for _, t := range term.ConsistentTerms {
+				var parts []string
+				for _, lang := range langs {
+					value := "—"
+					if translations[lang] != nil {
+						if v, ok := translations[lang].GetTermTranslation(t); ok {
+							value = v
+						}
+					}
+					parts = append(parts, fmt.Sprintf("%s: %s", lang, value))
+				}
+				if len(parts) > 0 {
+					fmt.Printf("  %s%s  →  %s\n", t, termDetails(term.Info(t)), strings.Join(parts, ", "))
+				} else {
+					fmt.Printf("  %s%s\n", t, termDetails(term.Info(t)))
+				}
+				for _, lang := range langs {
+					if forbidden := translations[lang].GetForbidden(t); len(forbidden) > 0 {
+						printer.PrintSubtle(fmt.Sprintf("      %s: never %s", lang, strings.Join(forbidden, ", ")))
+					}
+				}
+			}
+			fmt.Println()
+
+			complete := true
+			for _, lang := range langs {
+				if missing := term.GetMissingTranslations(translations[lang]); len(missing) > 0 {
+					complete = false
+					printer.PrintWarning(fmt.Sprintf("%s: %d missing translations: %s",
+						lang, len(missing), strings.Join(missing, ", ")))
+				}
+			}
+			if complete && len(langs) > 0 {
+				printer.PrintSuccess("All terms are translated")
+			} else if !complete {
+				printer.PrintSubtle("Run 'jta terms translate --to <langs>' or 'jta terms set' to fill them in")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target languages to check, comma-separated (default: all with a terminology translation)")
+
+	return cmd
+}
+
+func newTermsAddCmd(opts *termsOptions) *cobra.Command {
+	var preserve bool
+	var sourceLang string
+	var info domain.TermInfo
+	var scope string
+
+	cmd := &cobra.Command{
+		Use:   "add <term>...",
+		Short: "Add preserve or consistent terms",
+		Example: `  # Never translate the product name
+  jta terms add --preserve "Jta Cloud"
+
+  # Translate "workspace" the same way everywhere
+  jta terms add workspace
+
+  # Describe an ambiguous term and limit it to billing keys
+  jta terms add Plan --pos noun --definition "subscription tier" --case-sensitive --scope "billing.**"
+
+  # Add details to an existing term
+  jta terms add credits --definition "prepaid units spent on paid features"`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			term := &domain.Terminology{SourceLanguage: sourceLang, PreserveTerms: []string{}, ConsistentTerms: []string{}}
+			if manager.TerminologyExists(opts.dir) {
+				if term, err = loadTerms(manager, opts.dir); err != nil {
+					return err
+				}
+			}
+
+			if scope != "" {
+				if _, err := keyfilter.NewFilter().ParsePatterns(scope); err != nil {
+					return domain.NewValidationError("invalid --scope pattern", err).
+						WithContext("scope", scope)
+				}
+				info.Scope = splitList(scope)
+			}
+
+			added := 0
+			for _, t := range args {
+				t = strings.TrimSpace(t)
+				if t == "" {
+					continue
+				}
+				if typ, ok := term.TermType(t); ok {
+					if info.IsZero() {
+						printer.PrintWarning(fmt.Sprintf("%q is already a %s term", t, typ))
+					} else {
+						term.SetInfo(t, info)
+						printer.PrintSuccess(fmt.Sprintf("Updated details of %q", t))
+					}
+					continue
+				}
+				if preserve {
+					term.AddPreserveTerm(t)
+				} else {
+					term.AddConsistentTerm(t)
+				}
+				term.SetInfo(t, info)
+				added++
+			}
+
+			if err := manager.SaveTerminology(opts.dir, term); err != nil {
+				return fmt.Errorf("failed to save terminology: %w", err)
+			}
+
+			typ := domain.TermTypeConsistent
+			if preserve {
+				typ = domain.TermTypePreserve
+			}
+			printer.PrintSuccess(fmt.Sprintf("Added %d %s terms", added, typ))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&preserve, "preserve", false, "Add as preserve terms (never translated) instead of consistent terms")
+	cmd.Flags().StringVar(&sourceLang, "source-lang", "en", "Source language when creating a new terminology")
+	cmd.Flags().StringVar(&info.PartOfSpeech, "pos", "", "Part of speech (noun, verb, adjective, ...)")
+	cmd.Flags().StringVar(&info.Definition, "definition", "", "Meaning of the term in this project")
+	cmd.Flags().StringVar(&info.Context, "context", "", "Usage notes or examples for translators")
+	cmd.Flags().BoolVar(&info.CaseSensitive, "case-sensitive", false, "Only match the term with this exact casing")
+	cmd.Flags().StringVar(&scope, "scope", "", "Only apply the term to keys matching these patterns, comma-separated (e.g. billing.*,*.plan)")
+
+	return cmd
+}
+
+func newTermsRemoveCmd(opts *termsOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <term>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove terms and their translations",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			if _, err := loadTerms(manager, opts.dir); err != nil {
+				return err
+			}
+
+			for _, t := range args {
+				removed, err := manager.RemoveTerm(opts.dir, t)
+				if err != nil {
+					return fmt.Errorf("failed to remove %q: %w", t, err)
+				}
+				if removed {
+					printer.PrintSuccess(fmt.Sprintf("Removed %q", t))
+				} else {
+					printer.PrintWarning(fmt.Sprintf("%q is not in the terminology", t))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newTermsSetCmd(opts *termsOptions) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "set <term> <translation>",
+		Short: "Pin the translation of a consistent term for a target language",
+		Example: `  # Always translate "Account" as 帐号 in Simplified Chinese
+  jta terms set Account 帐号 --to zh`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			if to == "" {
+				return domain.NewValidationError("--to is required", nil)
+			}
+			if _, err := loadTerms(manager, opts.dir); err != nil {
+				return err
+			}
+
+			for _, lang := range splitList(to) {
+				if err := manager.SetTermTranslation(opts.dir, args[0], lang, args[1]); err != nil {
+					return fmt.Errorf("failed to set translation: %w", err)
+				}
+				printer.PrintSuccess(fmt.Sprintf("%s: %q → %q", lang, args[0], args[1]))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target language(s), comma-separated")
+
+	return cmd
+}
+
+func newTermsForbidCmd(opts *termsOptions) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "forbid <term> <translation>...",
+		Short: "Forbid translations of a term for a target language",
+		Example: `  # Never translate "Account" as 账户 in Simplified Chinese
+  jta terms forbid Account 账户 --to zh`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			if to == "" {
+				return domain.NewValidationError("--to is required", nil)
+			}
+			if _, err := loadTerms(manager, opts.dir); err != nil {
+				return err
+			}
+
+			for _, lang := range splitList(to) {
+				if err := manager.ForbidTranslations(opts.dir, args[0], lang, args[1:]...); err != nil {
+					return fmt.Errorf("failed to forbid translations: %w", err)
+				}
+				printer.PrintSuccess(fmt.Sprintf("%s: %q must never be %s", lang, args[0], strings.Join(args[1:], ", ")))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target language(s), comma-separated")
+
+	return cmd
+}
+
+func newTermsTranslateCmd(opts *termsOptions) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:     "translate",
+		Short:   "Translate consistent terms that are missing a translation",
+		Example: `  jta terms translate --to zh,ja`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			printer := ui.NewPrinter(false)
+
+			if to == "" {
+				return domain.NewValidationError("--to is required", nil)
+			}
+
+			app, err := opts.newApp(ctx)
+			if err != nil {
+				return err
+			}
+			manager := app.termManager
+
+			term, err := loadTerms(manager, opts.dir)
+			if err != nil {
+				return err
+			}
+
+			for _, lang := range splitList(to) {
+				translation := domain.NewTerminologyTranslation(term.SourceLanguage, lang)
+				if manager.TranslationExists(opts.dir, lang) {
+					if translation, err = manager.LoadTerminologyTranslation(opts.dir, lang); err != nil {
+						return errors.Join(fmt.Errorf("failed to load terminology translation for %s: %w", lang, err), app.Close())
+					}
+				}
+
+				missing := term.GetMissingTranslations(translation)
+				if len(missing) == 0 {
+					printer.PrintSuccess(fmt.Sprintf("%s: all terms already translated", lang))
+					continue
+				}
+
+				translations, err := manager.TranslateTerms(ctx, missing, term.SourceLanguage, lang)
+				if err != nil {
+					return errors.Join(fmt.Errorf("failed to translate terms to %s: %w", lang, err), app.Close())
+				}
+				if translation.Translations == nil {
+					translation.Translations = make(map[string]string)
+				}
+				maps.Copy(translation.Translations, translations)
+
+				if err := manager.SaveTerminologyTranslation(opts.dir, translation); err != nil {
+					return errors.Join(fmt.Errorf("failed to save terminology translation for %s: %w", lang, err), app.Close())
+				}
+				printer.PrintSuccess(fmt.Sprintf("%s: translated %d terms", lang, len(translations)))
+			}
+
+			return app.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target language(s), comma-separated")
+
+	return cmd
+}
+
+func newTermsDetectCmd(opts *termsOptions) *cobra.Command {
+	var sourceLang string
+	var offline bool
+
+	cmd := &cobra.Command{
+		Use:   "detect <source.json>",
+		Short: "Detect terms in source strings that were not analysed before",
+		Long: `Detect terms in source strings that were not analysed before.
+
+Jta records which source strings were already analysed (<terminology-dir>/detection.json),
+so running detect after adding new keys only sends the new strings to the model.
+Newly found terms are added to the terminology.
+
+With --offline no model is called: candidates are extracted from all source strings by
+n-gram statistics and capitalization, scored, and written to <terminology-dir>/candidates.json
+for review. Nothing is added to the terminology.`,
+		Example: `  jta terms detect locales/en.json
+
+  # Candidates for review, without any API call
+  jta terms detect locales/en.json --offline`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if offline {
+				manager, err := newTermManager(nil, opts.store)
+				if err != nil {
+					return err
+				}
+				return detectOffline(manager, opts.dir, args[:1], sourceLang)
+			}
+
+			ctx := context.Background()
+			printer := ui.NewPrinter(false)
+
+			app, err := opts.newApp(ctx)
+			if err != nil {
+				return err
+			}
+			manager := app.termManager
+
+			source, err := app.jsonUtil.LoadJSON(args[0])
+			if err != nil {
+				return errors.Join(fmt.Errorf("failed to load source file: %w", err), app.Close())
+			}
+			lang := languageFromPath(args[0], sourceLang)
+
+			term := &domain.Terminology{SourceLanguage: lang, PreserveTerms: []string{}, ConsistentTerms: []string{}}
+			if manager.TerminologyExists(opts.dir) {
+				if term, err = loadTerms(manager, opts.dir); err != nil {
+					return errors.Join(err, app.Close())
+				}
+				if term.SourceLanguage != lang {
+					return errors.Join(domain.NewValidationError(
+						fmt.Sprintf("source language mismatch: terminology is %s, but source is %s", term.SourceLanguage, lang), nil), app.Close())
+				}
+			}
+
+			terms, analysed, err := manager.DetectNewTerms(ctx, opts.dir, extractTexts(source), lang)
+			if err != nil {
+				return errors.Join(fmt.Errorf("failed to detect terms: %w", err), app.Close())
+			}
+			if analysed == 0 {
+				printer.PrintSuccess("No new source strings since the last detection")
+				return app.Close()
+			}
+
+			for _, t := range terms {
+				term.AddTerm(t)
+				printer.PrintSubtle(fmt.Sprintf("+ %s (%s)", t.Term, t.Type))
+			}
+			if err := manager.SaveTerminology(opts.dir, term); err != nil {
+				return errors.Join(fmt.Errorf("failed to save terminology: %w", err), app.Close())
+			}
+
+			printer.PrintSuccess(fmt.Sprintf("Analysed %d new strings, added %d terms", analysed, len(terms)))
+			if len(terms) > 0 {
+				printer.PrintSubtle("Run 'jta terms list' to see which languages need translations")
+			}
+			return app.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&sourceLang, "source-lang", "", "Source language (auto-detected from filename if not specified)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Extract term candidates for review without calling the model")
+
+	return cmd
+}
+
+// detectOffline extracts term candidates from source files without calling the model,
+// saves them for review and prints the best ones
+func detectOffline(manager *terminology.Manager, dir string, sourcePaths []string, sourceLang string) error {
+	printer := ui.NewPrinter(false)
+
+	var texts []string
+	for _, sourcePath := range sourcePaths {
+		source, err := utils.NewJSONUtil().LoadJSON(sourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to load source file: %w", err)
+		}
+		texts = append(texts, extractTexts(source)...)
+	}
+
+	candidates, err := manager.DetectCandidates(dir, texts, languageFromPath(sourcePaths[0], sourceLang), offlineCandidateLimit)
+	if err != nil {
+		return fmt.Errorf("failed to extract term candidates: %w", err)
+	}
+
+	printer.PrintSuccess(fmt.Sprintf("Found %s term candidates in %s strings (no model calls)",
+		printer.FormatNumber(len(candidates)), printer.FormatNumber(len(texts))))
+	for _, c := range candidates[:min(len(candidates), offlineCandidatePreview)] {
+		line := fmt.Sprintf("  %-32s score %6.2f  ×%d", c.Word, c.Score, c.Frequency)
+		if len(c.Signals) > 0 {
+			line += "  " + strings.Join(c.Signals, ", ")
+		}
+		printer.PrintSubtle(line)
+	}
+	if len(candidates) > offlineCandidatePreview {
+		printer.PrintSubtle(fmt.Sprintf("  ... and %d more", len(candidates)-offlineCandidatePreview))
+	}
+
+	printer.PrintInfo(fmt.Sprintf("Review %s and add real terms with 'jta terms add'", terminology.CandidatesPath(dir)))
+	return nil
+}
+
+func newTermsExportCmd(opts *termsOptions) *cobra.Command {
+	var format, output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the terminology and its translations as a TBX or CSV glossary",
+		Example: `  # TBX-Basic for translation vendors
+  jta terms export -o glossary.tbx
+
+  # CSV for spreadsheets
+  jta terms export --format csv -o glossary.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			format, err := glossaryFormat(format, output)
+			if err != nil {
+				return err
+			}
+			if _, err := loadTerms(manager, opts.dir); err != nil {
+				return err
+			}
+			glossary, err := manager.ExportGlossary(opts.dir)
+			if err != nil {
+				return fmt.Errorf("failed to export terminology: %w", err)
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return domain.NewIOError("failed to create output file", err).WithContext("path", output)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+
+			if format == "csv" {
+				err = terminology.WriteCSV(w, glossary)
+			} else {
+				err = terminology.WriteTBX(w, glossary, Version)
+			}
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				printer.PrintSuccess(fmt.Sprintf("Exported %s terms in %d languages to %s",
+					printer.FormatNumber(len(glossary.Entries)), len(glossary.Languages()), output))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "Glossary format: tbx or csv (default: from the output extension, else tbx)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+
+	return cmd
+}
+
+func newTermsImportCmd(opts *termsOptions) *cobra.Command {
+	var format, sourceLang string
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "import <glossary.tbx|glossary.csv>...",
+		Short: "Merge TBX or CSV glossaries into the terminology",
+		Long: `Merge TBX or CSV glossaries into the terminology.
+
+New terms, details and translations are added. When an imported value differs from
+an existing one (term type, definition, translation, ...), the existing value is kept
+and the conflict is reported; use --overwrite to take the imported values instead.
+
+CSV glossaries need a header row: term (or the source language code), type
+(preserve/consistent), partOfSpeech, definition, context, caseSensitive, scope, one
+column per target language (zh, ja, pt-BR) and optionally <lang>:forbidden columns.
+List cells are separated by ";".`,
+		Example: `  # A customer's TBX file
+  jta terms import customer.tbx
+
+  # The brand team's spreadsheet, replacing conflicting translations
+  jta terms import brand-glossary.csv --overwrite`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+			manager, err := newTermManager(nil, opts.store)
+			if err != nil {
+				return err
+			}
+
+			lang := sourceLang
+			if lang == "" && manager.TerminologyExists(opts.dir) {
+				term, err := loadTerms(manager, opts.dir)
+				if err != nil {
+					return err
+				}
+				lang = term.SourceLanguage
+			}
+
+			for _, path := range args {
+				format, err := glossaryFormat(format, path)
+				if err != nil {
+					return err
+				}
+
+				f, err := os.Open(path)
+				if err != nil {
+					return domain.NewIOError("failed to open glossary", err).WithContext("path", path)
+				}
+				var glossary *terminology.Glossary
+				if format == "csv" {
+					csvLang := lang
+					if csvLang == "" {
+						csvLang = "en"
+					}
+					glossary, err = terminology.ParseCSV(f, csvLang)
+				} else {
+					glossary, err = terminology.ParseTBX(f, lang)
+				}
+				_ = f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", path, err)
+				}
+
+				report, err := manager.ImportGlossary(opts.dir, glossary, overwrite)
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", path, err)
+				}
+				lang = glossary.SourceLanguage
+
+				printer.PrintInfo(fmt.Sprintf("%s: %s terms, %s new terms, %s new translations",
+					path, printer.FormatNumber(len(glossary.Entries)),
+					printer.FormatNumber(report.AddedTerms), printer.FormatNumber(report.AddedTranslations)))
+				if len(report.UnknownLanguages) > 0 {
+					printer.PrintWarning(fmt.Sprintf("Unsupported language codes kept as-is: %s",
+						strings.Join(report.UnknownLanguages, ", ")))
+				}
+				printGlossaryConflicts(printer, report.Conflicts, overwrite)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "Glossary format: tbx or csv (default: from the file extension)")
+	cmd.Flags().StringVar(&sourceLang, "source-lang", "", "Source language (default: the existing terminology's, the TBX xml:lang, or en for CSV)")
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Replace conflicting existing values with the imported ones")
+
+	return cmd
+}
+
+// printGlossaryConflicts lists values that differ between the glossary and the terminology
+func printGlossaryConflicts(printer *ui.Printer, conflicts []terminology.GlossaryConflict, overwrite bool) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	action := "kept existing values"
+	if overwrite {
+		action = "replaced with imported values"
+	}
+	printer.PrintWarning(fmt.Sprintf("%d conflicts (%s):", len(conflicts), action))
+	for _, c := range conflicts {
+		field := c.Field
+		if c.Language != "" {
+			field = c.Language + " " + field
+		}
+		printer.PrintSubtle(fmt.Sprintf("  %s [%s]: %q (existing) vs %q (imported)", c.Term, field, c.Existing, c.Imported))
+	}
+	if !overwrite {
+		printer.PrintSubtle("Resolve them with 'jta terms set', or re-run with --overwrite")
+	}
+}
+
+// glossaryFormat returns the explicit glossary format or the one named by the file extension
+func glossaryFormat(format, path string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = "csv"
+		default:
+			format = "tbx"
+		}
+	}
+	if format != "tbx" && format != "csv" {
+		return "", domain.NewValidationError(fmt.Sprintf("unsupported glossary format %q (supported: tbx, csv)", format), nil)
+	}
+	return format, nil
+}
+
+// newApp creates an application for subcommands that call the AI provider
+func (o *termsOptions) newApp(ctx context.Context) (*App, error) {
+	app, err := NewApp(ctx, AppConfig{
+		Provider:         o.provider,
+		Model:            o.model,
+		APIKey:           o.apiKey,
+		TerminologyStore: o.store,
+		Logger:           slog.New(ui.NewLogHandler(os.Stdout, slog.LevelInfo)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize application: %w", err)
+	}
+	if err := app.usePrompts(o.dir); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to load prompt templates: %w", err), app.Close())
+	}
+	return app, nil
+}
+
+// loadTerms loads the terminology, explaining how to create one when it's missing
+func loadTerms(manager *terminology.Manager, dir string) (*domain.Terminology, error) {
+	if !manager.TerminologyExists(dir) {
+		return nil, domain.NewValidationError(
+			fmt.Sprintf("no terminology in %s (create one with 'jta terms add' or 'jta terms detect')", manager.Location(dir)), nil)
+	}
+	term, err := manager.LoadTerminology(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load terminology: %w", err)
+	}
+	return term, nil
+}
+
+// termsLanguages returns the requested languages, or every language with a terminology translation
+func termsLanguages(manager *terminology.Manager, dir, to string) ([]string, error) {
+	if to != "" {
+		return splitList(to), nil
+	}
+	langs, err := manager.TranslationLanguages(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terminology translations: %w", err)
+	}
+	return langs, nil
+}
+
+// splitList parses a comma-separated list, e.g. of languages, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for item := range strings.SplitSeq(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// termDetails formats the details of a term for listing
+func termDetails(info domain.TermInfo) string {
+	var parts []string
+	if desc := info.Describe(); desc != "" {
+		parts = append(parts, desc)
+	}
+	if info.CaseSensitive {
+		parts = append(parts, "case-sensitive")
+	}
+	if len(info.Scope) > 0 {
+		parts = append(parts, "scope: "+strings.Join(info.Scope, ","))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hikanner/jta/internal/config"
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/tm"
+	"github.com/hikanner/jta/internal/ui"
+	"github.com/hikanner/jta/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// newTMCmd creates the "tm" command for exchanging the translation memory with other tools
+func newTMCmd() *cobra.Command {
+	tmCmd := &cobra.Command{
+		Use:   "tm",
+		Short: "Import and export translation memory",
+		Long: `Import and export translation memory.
+
+Jta records approved translations in <terminology-dir>/tm.jsonl and reuses them on
+later runs. Use these commands to hand your translation history to a vendor as TMX,
+or to seed the memory from a vendor's TMX file.`,
+	}
+
+	tmCmd.AddCommand(newTMExportCmd(), newTMImportCmd())
+
+	return tmCmd
+}
+
+func newTMExportCmd() *cobra.Command {
+	var format, output, sourceLang, dir string
+	var includeMemory bool
+
+	cmd := &cobra.Command{
+		Use:   "export <source.json> <target.json>...",
+		Short: "Export source/target JSON pairs as TMX 1.4",
+		Example: `  # One unit per key, with a variant for each target language
+  jta tm export locales/en.json locales/zh.json locales/ja.json -o history.tmx
+
+  # Also include translations recorded in the translation memory
+  jta tm export locales/en.json locales/zh.json --include-memory -o history.tmx`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+
+			if format != "tmx" {
+				return domain.NewValidationError(fmt.Sprintf("unsupported export format %q (supported: tmx)", format), nil)
+			}
+			if len(args) < 2 && !includeMemory {
+				return domain.NewValidationError("at least one target file is required (or use --include-memory)", nil)
+			}
+
+			jsonUtil := utils.NewJSONUtil()
+			source, err := jsonUtil.LoadJSON(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load source file: %w", err)
+			}
+			srcLang := languageFromPath(args[0], sourceLang)
+
+			var entries []tm.Entry
+			for _, targetPath := range args[1:] {
+				target, err := jsonUtil.LoadJSON(targetPath)
+				if err != nil {
+					return fmt.Errorf("failed to load target file: %w", err)
+				}
+				entries = append(entries, tm.EntriesFromFiles(source, target, srcLang, languageFromPath(targetPath, ""))...)
+			}
+
+			if includeMemory {
+				memory, err := tm.Load(tm.Path(dir))
+				if err != nil {
+					return fmt.Errorf("failed to load translation memory: %w", err)
+				}
+				entries = append(entries, memory.Entries()...)
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return domain.NewIOError("failed to create output file", err).WithContext("path", output)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+
+			if err := tm.WriteTMX(w, entries, Version); err != nil {
+				return err
+			}
+
+			if output != "" {
+				printer.PrintSuccess(fmt.Sprintf("Exported %s translation pairs to %s",
+					printer.FormatNumber(len(entries)), output))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "tmx", "Export format (tmx)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file (default: stdout)")
+	cmd.Flags().StringVar(&sourceLang, "source-lang", "", "Source language (auto-detected from filename if not specified)")
+	cmd.Flags().StringVar(&dir, "terminology-dir", ".jta", "Terminology directory containing the translation memory")
+	cmd.Flags().BoolVar(&includeMemory, "include-memory", false, "Also export entries from the translation memory")
+
+	return cmd
+}
+
+func newTMImportCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "import <file.tmx>...",
+		Short: "Seed the translation memory from TMX files",
+		Example: `  # Reuse a vendor's translations on the next run
+  jta tm import vendor-export.tmx`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			printer := ui.NewPrinter(false)
+
+			memory, err := tm.Load(tm.Path(dir))
+			if err != nil {
+				return fmt.Errorf("failed to load translation memory: %w", err)
+			}
+
+			for _, path := range args {
+				f, err := os.Open(path)
+				if err != nil {
+					return domain.NewIOError("failed to open TMX file", err).WithContext("path", path)
+				}
+				stats, err := memory.ImportTMX(f, "tmx:"+filepath.Base(path))
+				_ = f.Close()
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", path, err)
+				}
+
+				printer.PrintInfo(fmt.Sprintf("%s: %s translation pairs, %s new or updated",
+					path, printer.FormatNumber(stats.Entries), printer.FormatNumber(stats.Changed)))
+				if len(stats.UnknownLanguages) > 0 {
+					printer.PrintWarning(fmt.Sprintf("Unsupported language codes kept as-is: %s",
+						strings.Join(stats.UnknownLanguages, ", ")))
+				}
+			}
+
+			if err := memory.Save(); err != nil {
+				return fmt.Errorf("failed to save translation memory: %w", err)
+			}
+
+			printer.PrintSuccess(fmt.Sprintf("Translation memory now holds %s entries (%s)",
+				printer.FormatNumber(memory.Len()), tm.Path(dir)))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "terminology-dir", ".jta", "Terminology directory containing the translation memory")
+
+	return cmd
+}
+
+// languageFromPath returns the explicit language or the one named by the file
+// ("locales/pt-BR.json" -> "pt"), normalized to a supported code
+func languageFromPath(path, explicit string) string {
+	lang := explicit
+	if lang == "" {
+		lang, _ = config.SourceLanguage(path)
+	}
+	normalized, _ := domain.NormalizeLanguageCode(lang)
+	return normalized
+}
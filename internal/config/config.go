@@ -0,0 +1,305 @@
+// Package config loads the project configuration file (jta.yaml or .jtarc): the source
+// files, target languages, output paths, provider and model per language, key filters
+// and style settings that would otherwise be given as flags on every run.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the config file names looked for in each directory, in order
+var FileNames = []string{"jta.yaml", "jta.yml", ".jtarc"}
+
+// Config is a project configuration. Settings are named after the flags they stand in
+// for; flags given on the command line override them.
+type Config struct {
+	Sources    []Source `yaml:"sources,omitempty"`
+	SourceLang string   `yaml:"source-lang,omitempty"`
+	Targets    []string `yaml:"targets,omitempty"`
+	Output     string   `yaml:"output,omitempty"` // path template, see OutputPath
+
+	Provider string `yaml:"provider,omitempty"`
+	Model    string `yaml:"model,omitempty"`
+
+	TerminologyDir   string `yaml:"terminology-dir,omitempty"`
+	TerminologyStore string `yaml:"terminology-store,omitempty"`
+	SkipTerminology  bool   `yaml:"skip-terminology,omitempty"`
+
+	Keys        string `yaml:"keys,omitempty"`
+	ExcludeKeys string `yaml:"exclude-keys,omitempty"`
+	Locked      string `yaml:"locked,omitempty"` // key patterns whose translations are never overwritten
+	Incremental bool   `yaml:"incremental,omitempty"`
+
+	BatchSize         int     `yaml:"batch-size,omitempty"`
+	Concurrency       int     `yaml:"concurrency,omitempty"`
+	Parallel          int     `yaml:"parallel,omitempty"`
+	RequestsPerMinute int     `yaml:"requests-per-minute,omitempty"`
+	TokensPerMinute   int     `yaml:"tokens-per-minute,omitempty"`
+	MaxCost           float64 `yaml:"max-cost,omitempty"`
+	Pricing           string  `yaml:"pricing,omitempty"`
+
+	NoCache     bool          `yaml:"no-cache,omitempty"`
+	CacheTTL    time.Duration `yaml:"cache-ttl,omitempty"`
+	NoTM        bool          `yaml:"no-tm,omitempty"`
+	TMThreshold float64       `yaml:"tm-threshold,omitempty"`
+
+	PseudoExpansion int `yaml:"pseudo-expansion,omitempty"`
+
+	Languages map[string]Language `yaml:"languages,omitempty"` // settings per target language
+
+	Path string          `yaml:"-"` // file the config was loaded from
+	set  map[string]bool // top-level settings present in the file, even if zero
+}
+
+// Source is a source file, directory or glob pattern with its own settings; empty
+// settings fall back to the top-level ones. In the config file a source is either a
+// path or a mapping.
+type Source struct {
+	Path        string   `yaml:"path"`
+	SourceLang  string   `yaml:"source-lang,omitempty"`
+	Targets     []string `yaml:"targets,omitempty"`
+	Output      string   `yaml:"output,omitempty"`
+	Keys        string   `yaml:"keys,omitempty"`
+	ExcludeKeys string   `yaml:"exclude-keys,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain path as well as a mapping
+func (s *Source) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		s.Path = node.Value
+		return nil
+	}
+	type plain Source
+	return node.Decode((*plain)(s))
+}
+
+// Language is the settings of one target language
+type Language struct {
+	Provider string             `yaml:"provider,omitempty"`
+	Model    string             `yaml:"model,omitempty"`
+	Style    *domain.StyleGuide `yaml:"style,omitempty"` // used when there is no style guide file
+}
+
+// Find returns the config file in dir or the closest parent directory, or "" if there is none
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", domain.NewIOError("failed to resolve directory", err).
+			WithContext("dir", dir)
+	}
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			info, err := os.Stat(path)
+			if err == nil && !info.IsDir() {
+				return path, nil
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", domain.NewIOError("failed to check config file", err).
+					WithContext("path", path)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Discover loads the config file of dir (see Find). It returns nil if there is none.
+func Discover(dir string) (*Config, error) {
+	path, err := Find(dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Load loads a config file. Relative paths in it are resolved against the file's
+// directory, so jta can run from any directory below it; the terminology directory
+// defaults to .jta next to the file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, domain.NewIOError("failed to read config file", err).
+			WithContext("path", path)
+	}
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, domain.NewConfigError("invalid config file", err).
+			WithContext("path", path)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, domain.NewConfigError("invalid config file", err).
+			WithContext("path", path)
+	}
+	cfg.set = settingNames(data)
+
+	cfg.Path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, domain.NewIOError("failed to resolve config file", err).
+			WithContext("path", path)
+	}
+	cfg.resolvePaths(filepath.Dir(cfg.Path))
+	return cfg, nil
+}
+
+// settingNames returns the top-level keys of a config file
+func settingNames(data []byte) map[string]bool {
+	names := make(map[string]bool)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return names
+	}
+	mapping := doc.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		names[mapping.Content[i].Value] = true
+	}
+	return names
+}
+
+// IsSet reports whether the config file gives a top-level setting, so that a zero
+// value like "cache-ttl: 0" still overrides the flag's default
+func (c *Config) IsSet(name string) bool {
+	return c != nil && c.set[name]
+}
+
+// validate checks settings that would otherwise only fail halfway through a run
+func (c *Config) validate() error {
+	for i, src := range c.Sources {
+		if src.Path == "" {
+			return fmt.Errorf("source %d has no path", i+1)
+		}
+	}
+	for lang, settings := range c.Languages {
+		if _, ok := domain.NormalizeLanguageCode(lang); !ok {
+			return fmt.Errorf("unsupported language %q in languages", lang)
+		}
+		if settings.Model != "" && settings.Provider == "" && c.Provider == "" {
+			return fmt.Errorf("languages.%s sets a model without a provider", lang)
+		}
+	}
+	if c.BatchSize < 0 || c.Concurrency < 0 || c.Parallel < 0 || c.MaxCost < 0 {
+		return errors.New("batch-size, concurrency, parallel and max-cost must not be negative")
+	}
+	return nil
+}
+
+// resolvePaths makes the relative paths of the config relative to its directory
+func (c *Config) resolvePaths(dir string) {
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	if c.TerminologyDir == "" {
+		c.TerminologyDir = ".jta"
+	}
+	c.TerminologyDir = resolve(c.TerminologyDir)
+	// Output templates relative to the source stay as they are
+	resolveOutput := func(template string) string {
+		if strings.HasPrefix(template, "{dir}") || (strings.Contains(template, "{") && !strings.ContainsAny(template, `/\`)) {
+			return template
+		}
+		return resolve(template)
+	}
+
+	c.Pricing = resolve(c.Pricing)
+	c.Output = resolveOutput(c.Output)
+	// Glossary services are URLs, not paths
+	if !strings.Contains(c.TerminologyStore, "://") {
+		c.TerminologyStore = resolve(c.TerminologyStore)
+	}
+	for i := range c.Sources {
+		c.Sources[i].Path = resolve(c.Sources[i].Path)
+		c.Sources[i].Output = resolveOutput(c.Sources[i].Output)
+	}
+}
+
+// Source returns the settings of a source file: those of the matching configured
+// source, completed with the top-level settings. A nil config has no settings.
+func (c *Config) Source(path string) Source {
+	if c == nil {
+		return Source{Path: path}
+	}
+
+	src := Source{Path: path}
+	for _, configured := range c.Sources {
+		if samePath(configured.Path, path) {
+			src = configured
+			src.Path = path
+			break
+		}
+	}
+	if src.SourceLang == "" {
+		src.SourceLang = c.SourceLang
+	}
+	if len(src.Targets) == 0 {
+		src.Targets = c.Targets
+	}
+	if src.Output == "" {
+		src.Output = c.Output
+	}
+	if src.Keys == "" {
+		src.Keys = c.Keys
+	}
+	if src.ExcludeKeys == "" {
+		src.ExcludeKeys = c.ExcludeKeys
+	}
+	return src
+}
+
+// Language returns the settings of a target language, falling back to the base
+// language (pt for pt-BR) and to the top-level provider and model
+func (c *Config) Language(lang string) Language {
+	if c == nil {
+		return Language{}
+	}
+
+	settings, ok := c.Languages[lang]
+	if !ok {
+		if base, _, found := strings.Cut(lang, "-"); found {
+			settings = c.Languages[base]
+		}
+	}
+	if settings.Provider == "" {
+		settings.Provider = c.Provider
+		// A model only applies to the provider it was given for
+		if settings.Model == "" {
+			settings.Model = c.Model
+		}
+	}
+	if settings.Style != nil {
+		style := *settings.Style
+		style.Language = lang
+		style.Path = c.Path
+		settings.Style = &style
+	}
+	return settings
+}
+
+// samePath reports whether two paths name the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
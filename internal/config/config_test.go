@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	deep := filepath.Join(root, "app", "src")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	rc := writeConfig(t, root, ".jtarc", "targets: [de]\n")
+	if path, err := Find(deep); err != nil || path != rc {
+		t.Errorf("Find() = %q, %v; want %q", path, err, rc)
+	}
+
+	// jta.yaml wins over .jtarc, and the closest directory wins
+	yamlPath := writeConfig(t, root, "jta.yaml", "targets: [fr]\n")
+	if path, _ := Find(deep); path != yamlPath {
+		t.Errorf("Find() = %q, want %q", path, yamlPath)
+	}
+	closer := writeConfig(t, filepath.Join(root, "app"), ".jtarc", "targets: [ja]\n")
+	if path, _ := Find(deep); path != closer {
+		t.Errorf("Find() = %q, want %q", path, closer)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "jta.yaml", `
+sources:
+  - locales/en.json
+  - path: marketing/en.json
+    targets: [de]
+    output: marketing/{lang}.json
+    keys: "hero.*"
+source-lang: en
+targets: [de, ja, pt-BR]
+output: locales/{lang}.json
+provider: openai
+model: gpt-5
+keys: "app.*"
+batch-size: 10
+cache-ttl: 24h
+terminology-store: https://glossary.example.com/api
+languages:
+  ja:
+    provider: anthropic
+  pt:
+    model: gpt-5-mini
+  de:
+    style:
+      formality: formal
+      forbidden: [Du]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Path != path || cfg.TerminologyDir != filepath.Join(dir, ".jta") {
+		t.Errorf("Path = %q, TerminologyDir = %q", cfg.Path, cfg.TerminologyDir)
+	}
+	if cfg.BatchSize != 10 || cfg.CacheTTL != 24*time.Hour {
+		t.Errorf("BatchSize = %d, CacheTTL = %v", cfg.BatchSize, cfg.CacheTTL)
+	}
+	if cfg.TerminologyStore != "https://glossary.example.com/api" {
+		t.Errorf("TerminologyStore = %q, URLs should be kept as is", cfg.TerminologyStore)
+	}
+
+	// Top-level settings apply to sources without their own
+	src := cfg.Source(filepath.Join(dir, "locales", "en.json"))
+	if src.Output != filepath.Join(dir, "locales", "{lang}.json") || src.Keys != "app.*" || src.SourceLang != "en" ||
+		!slices.Equal(src.Targets, []string{"de", "ja", "pt-BR"}) {
+		t.Errorf("Source(locales/en.json) = %+v", src)
+	}
+	src = cfg.Source(filepath.Join(dir, "marketing", "en.json"))
+	if src.Output != filepath.Join(dir, "marketing", "{lang}.json") || src.Keys != "hero.*" || !slices.Equal(src.Targets, []string{"de"}) {
+		t.Errorf("Source(marketing/en.json) = %+v", src)
+	}
+	if got := OutputPath(src.Output, src.Path, "de"); got != filepath.Join(dir, "marketing", "de.json") {
+		t.Errorf("OutputPath() = %q", got)
+	}
+
+	// A language's provider doesn't inherit the top-level model
+	if lang := cfg.Language("ja"); lang.Provider != "anthropic" || lang.Model != "" {
+		t.Errorf("Language(ja) = %+v", lang)
+	}
+	if lang := cfg.Language("pt-BR"); lang.Provider != "openai" || lang.Model != "gpt-5-mini" {
+		t.Errorf("Language(pt-BR) = %+v, want the pt settings", lang)
+	}
+	if lang := cfg.Language("fr"); lang.Provider != "openai" || lang.Model != "gpt-5" || lang.Style != nil {
+		t.Errorf("Language(fr) = %+v, want the top-level settings", lang)
+	}
+	style := cfg.Language("de").Style
+	if style == nil || style.Formality != "formal" || style.Language != "de" || style.Path != path {
+		t.Errorf("Language(de).Style = %+v", style)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"unknown setting", "batchsize: 10\n"},
+		{"source without path", "sources:\n  - targets: [de]\n"},
+		{"unsupported language", "languages:\n  klingon:\n    provider: openai\n"},
+		{"model without provider", "languages:\n  de:\n    model: gpt-5\n"},
+		{"negative batch size", "batch-size: -1\n"},
+		{"not a mapping", "- de\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, t.TempDir(), "jta.yaml", tt.config)
+			_, err := Load(path)
+			if !domain.IsErrorType(err, domain.ErrorTypeConfig) {
+				t.Errorf("expected a config error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_Empty(t *testing.T) {
+	cfg, err := Load(writeConfig(t, t.TempDir(), ".jtarc", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Sources) != 0 || cfg.TerminologyDir == "" {
+		t.Errorf("Load() = %+v", cfg)
+	}
+}
+
+func TestLoad_ZeroSettings(t *testing.T) {
+	cfg, err := Load(writeConfig(t, t.TempDir(), "jta.yaml", "cache-ttl: 0s\nmax-cost: 0\nincremental: false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cache-ttl", "max-cost", "incremental"} {
+		if !cfg.IsSet(name) {
+			t.Errorf("IsSet(%q) = false, want true", name)
+		}
+	}
+	if cfg.IsSet("batch-size") {
+		t.Error("IsSet(batch-size) = true for a setting not in the file")
+	}
+}
+
+func TestNilConfig(t *testing.T) {
+	var cfg *Config
+	if src := cfg.Source("en.json"); src.Path != "en.json" || src.Output != "" {
+		t.Errorf("Source() = %+v", src)
+	}
+	if lang := cfg.Language("de"); lang != (Language{}) {
+		t.Errorf("Language() = %+v", lang)
+	}
+	if cfg.IsSet("provider") {
+		t.Error("IsSet() = true for a nil config")
+	}
+}
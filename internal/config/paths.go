@@ -0,0 +1,172 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/pseudo"
+)
+
+// ExpandSource returns the source files of a path: the file itself, the JSON files of a
+// directory, or the JSON files matching a glob pattern, sorted. Sidecar metadata files
+// (en.meta.json) are not sources, and neither are the translations a directory or glob
+// also matches: files named after a target language or a pseudo-locale, and files in
+// another language than the source language. Without a source language the files left
+// must share one.
+func ExpandSource(path, sourceLang string, targets []string) ([]string, error) {
+	var matches []string
+	if strings.ContainsAny(path, "*?[") {
+		globbed, err := filepath.Glob(path)
+		if err != nil {
+			return nil, domain.NewValidationError("invalid source pattern", err).
+				WithContext("pattern", path)
+		}
+		matches = globbed
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, domain.NewIOError("source file not found", err).
+				WithContext("path", path)
+		}
+		if !info.IsDir() {
+			// A file named explicitly is a source, whatever its name
+			return []string{path}, nil
+		}
+		matches, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, domain.NewIOError("failed to list source directory", err).
+				WithContext("path", path)
+		}
+	}
+
+	if sourceLang != "" {
+		sourceLang, _ = domain.NormalizeLanguageCode(sourceLang)
+	}
+	var files []string
+	languages := make(map[string]bool)
+	for _, match := range matches {
+		if strings.HasSuffix(match, ".meta.json") || filepath.Ext(match) != ".json" {
+			continue
+		}
+		segment, lang, _ := locateLanguage(match)
+		if segment != "" && !isSourceLanguage(segment, lang, sourceLang, targets) {
+			continue
+		}
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+			if lang != "" {
+				languages[lang] = true
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, domain.NewValidationError("no JSON source files found", nil).
+			WithContext("path", path)
+	}
+	if len(languages) > 1 {
+		return nil, domain.NewValidationError("source files in several languages found; set the source language", nil).
+			WithContext("path", path).
+			WithContext("languages", strings.Join(slices.Sorted(maps.Keys(languages)), ", "))
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// isSourceLanguage reports whether a file whose path names a language (the segment,
+// e.g. "pt_BR", normalized to lang) is a source rather than a translation
+func isSourceLanguage(segment, lang, sourceLang string, targets []string) bool {
+	if _, ok := pseudo.Normalize(segment); ok {
+		return false
+	}
+	for _, target := range targets {
+		normalized, _ := domain.NormalizeLanguageCode(target)
+		if strings.EqualFold(strings.ReplaceAll(segment, "_", "-"), strings.ReplaceAll(target, "_", "-")) ||
+			(normalized == lang && normalized != sourceLang) {
+			return false
+		}
+	}
+	return sourceLang == "" || lang == sourceLang
+}
+
+// SourceLanguage finds the language of a source file in its path and returns it with
+// the default output template, which puts translations where the language was found:
+//
+//	locales/en.json         -> en, {dir}/{lang}.json
+//	locales/common.en.json  -> en, {dir}/{name}.{lang}.json
+//	locales/en/common.json  -> en, locales/{lang}/{name}.json
+//
+// The language is "" if the path has none; the template is then {dir}/{lang}.json.
+func SourceLanguage(path string) (string, string) {
+	_, lang, template := locateLanguage(path)
+	return lang, template
+}
+
+// locateLanguage finds the language of a path like SourceLanguage, and also returns the
+// part of the path naming it, e.g. "en-US" for en; both are "" if the path has none
+func locateLanguage(path string) (segment, lang, template string) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+
+	if lang, ok := domain.NormalizeLanguageCode(base); ok {
+		return base, lang, filepath.Join("{dir}", "{lang}"+ext)
+	}
+	if _, suffix, found := cutLast(base, "."); found {
+		if lang, ok := domain.NormalizeLanguageCode(suffix); ok {
+			return suffix, lang, filepath.Join("{dir}", "{name}.{lang}"+ext)
+		}
+	}
+
+	// The closest directory named after a language
+	segments := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if lang, ok := domain.NormalizeLanguageCode(segments[i]); ok {
+			segment = segments[i]
+			segments[i] = "{lang}"
+			dir := filepath.FromSlash(strings.Join(segments, "/"))
+			return segment, lang, filepath.Join(dir, "{name}"+ext)
+		}
+	}
+	return "", "", filepath.Join("{dir}", "{lang}"+ext)
+}
+
+// OutputPath expands an output path template for a source file and target language:
+// {lang} is the target language, {name} the source file name without extension and
+// language suffix (common for common.en.json), {dir} the source directory and {ext}
+// the extension. A template with placeholders but no directory, like {name}.{lang}.json,
+// is placed next to the source.
+func OutputPath(template, sourcePath, lang string) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	ext := filepath.Ext(sourcePath)
+	name := strings.TrimSuffix(filepath.Base(sourcePath), ext)
+	if rest, suffix, found := cutLast(name, "."); found {
+		if _, ok := domain.NormalizeLanguageCode(suffix); ok {
+			name = rest
+		}
+	}
+
+	path := strings.NewReplacer(
+		"{lang}", lang,
+		"{name}", name,
+		"{dir}", filepath.Dir(sourcePath),
+		"{ext}", ext,
+	).Replace(template)
+	if !strings.ContainsAny(template, `/\`) {
+		path = filepath.Join(filepath.Dir(sourcePath), path)
+	}
+	return filepath.Clean(path)
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func TestExpandSource(t *testing.T) {
+	dir := t.TempDir()
+	en := filepath.Join(dir, "locales", "en")
+	if err := os.MkdirAll(filepath.Join(en, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"common.json", "auth.json", "billing.json", "common.meta.json", "README.md"} {
+		if err := os.WriteFile(filepath.Join(en, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{filepath.Join(en, "auth.json"), filepath.Join(en, "billing.json"), filepath.Join(en, "common.json")}
+
+	// A directory and a glob find the same JSON files, without sidecars
+	for _, path := range []string{en, filepath.Join(dir, "locales", "*", "*.json")} {
+		files, err := ExpandSource(path, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(files, want) {
+			t.Errorf("ExpandSource(%q) = %v, want %v", path, files, want)
+		}
+	}
+
+	files, err := ExpandSource(want[0], "", nil)
+	if err != nil || !slices.Equal(files, want[:1]) {
+		t.Errorf("ExpandSource(file) = %v, %v", files, err)
+	}
+
+	if _, err := ExpandSource(filepath.Join(dir, "*.yaml"), "", nil); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("expected a validation error without matches, got %v", err)
+	}
+	if _, err := ExpandSource(filepath.Join(dir, "missing.json"), "", nil); !domain.IsErrorType(err, domain.ErrorTypeIO) {
+		t.Errorf("expected an IO error for a missing file, got %v", err)
+	}
+}
+
+func TestExpandSource_SkipsTranslations(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"en.json", "fr.json", "pt_BR.json", "en-XA.json", "messages.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{filepath.Join(dir, "en.json"), filepath.Join(dir, "messages.json")}
+
+	tests := []struct {
+		name       string
+		sourceLang string
+		targets    []string
+	}{
+		{"source language", "en", nil},
+		{"targets", "", []string{"fr", "pt-BR"}},
+	}
+	for _, tt := range tests {
+		files, err := ExpandSource(dir, tt.sourceLang, tt.targets)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !slices.Equal(files, want) {
+			t.Errorf("%s: ExpandSource() = %v, want %v", tt.name, files, want)
+		}
+	}
+
+	// Several languages and no way to tell the source
+	if _, err := ExpandSource(dir, "", []string{"de"}); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("expected a validation error for sources in several languages, got %v", err)
+	}
+	// A file named explicitly is always a source
+	if files, err := ExpandSource(filepath.Join(dir, "fr.json"), "en", []string{"fr"}); err != nil || len(files) != 1 {
+		t.Errorf("ExpandSource(fr.json) = %v, %v", files, err)
+	}
+}
+
+func TestSourceLanguage(t *testing.T) {
+	tests := []struct {
+		path     string
+		lang     string
+		template string
+	}{
+		{"locales/en.json", "en", "{dir}/{lang}.json"},
+		{"locales/common.en.json", "en", "{dir}/{name}.{lang}.json"},
+		{"locales/en/common.json", "en", "locales/{lang}/{name}.json"},
+		{"app/i18n/ja/auth/login.json", "ja", "app/i18n/{lang}/auth/{name}.json"},
+		{"locales/messages.json", "", "{dir}/{lang}.json"},
+	}
+
+	for _, tt := range tests {
+		lang, template := SourceLanguage(filepath.FromSlash(tt.path))
+		if lang != tt.lang || template != filepath.FromSlash(tt.template) {
+			t.Errorf("SourceLanguage(%q) = %q, %q; want %q, %q", tt.path, lang, template, tt.lang, tt.template)
+		}
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	tests := []struct {
+		template string
+		source   string
+		want     string
+	}{
+		{"{dir}/{lang}.json", "locales/en.json", "locales/de.json"},
+		{"locales/{lang}/{name}.json", "locales/en/common.json", "locales/de/common.json"},
+		{"{name}.{lang}.json", "locales/common.en.json", "locales/common.de.json"},
+		{"{name}.{lang}{ext}", "locales/en/auth.json", "locales/en/auth.de.json"},
+		{"out/de.json", "locales/en.json", "out/de.json"},
+	}
+
+	for _, tt := range tests {
+		got := OutputPath(filepath.FromSlash(tt.template), filepath.FromSlash(tt.source), "de")
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("OutputPath(%q, %q) = %q, want %q", tt.template, tt.source, got, tt.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ const (
 	ErrorTypeTerminology ErrorType = "terminology"
 	// ErrorTypeConfig represents configuration errors
 	ErrorTypeConfig ErrorType = "config"
+	// ErrorTypeBudget represents a spending cap being reached
+	ErrorTypeBudget ErrorType = "budget"
 )
 
 // Error represents a domain error with additional context
@@ -126,6 +128,11 @@ func NewConfigError(message string, err error) *Error {
 	return NewError(ErrorTypeConfig, message, err)
 }
 
+// NewBudgetError creates a budget error
+func NewBudgetError(message string, err error) *Error {
+	return NewError(ErrorTypeBudget, message, err)
+}
+
 // IsErrorType checks if an error is of a specific type
 func IsErrorType(err error, errType ErrorType) bool {
 	if e, ok := err.(*Error); ok {
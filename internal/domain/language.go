@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // Language represents a supported language
 type Language struct {
 	Code         string
@@ -300,3 +302,43 @@ func ValidateLanguageCode(langCode string) bool {
 	_, exists := SupportedLanguages[langCode]
 	return exists
 }
+
+// chineseVariants maps Chinese region and script subtags to the supported Chinese codes
+var chineseVariants = map[string]string{
+	"hans": LangChineseSimplified,
+	"cn":   LangChineseSimplified,
+	"sg":   LangChineseSimplified,
+	"hant": LangChineseTraditional,
+	"tw":   LangChineseTraditional,
+	"hk":   LangChineseTraditional,
+	"mo":   LangChineseTraditional,
+}
+
+// NormalizeLanguageCode maps a locale identifier from other tools (e.g. "en-US", "pt_BR",
+// "zh-Hant-HK") to a supported language code. Unknown codes are returned cleaned up
+// with ok set to false.
+func NormalizeLanguageCode(code string) (string, bool) {
+	cleaned := strings.ReplaceAll(strings.TrimSpace(code), "_", "-")
+	lower := strings.ToLower(cleaned)
+
+	for supported := range SupportedLanguages {
+		if strings.ToLower(supported) == lower {
+			return supported, true
+		}
+	}
+
+	subtags := strings.Split(lower, "-")
+	if subtags[0] == "zh" {
+		for _, subtag := range subtags[1:] {
+			if variant, ok := chineseVariants[subtag]; ok {
+				return variant, true
+			}
+		}
+	}
+
+	if _, ok := SupportedLanguages[subtags[0]]; ok {
+		return subtags[0], true
+	}
+
+	return cleaned, false
+}
@@ -227,3 +227,32 @@ func TestRTLLanguageCoverage(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeLanguageCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected string
+		expectOk bool
+	}{
+		{"en", "en", true},
+		{"en-US", "en", true},
+		{"pt_BR", "pt", true},
+		{"DE-de", "de", true},
+		{"zh-TW", "zh-TW", true},
+		{"zh-tw", "zh-TW", true},
+		{"zh-Hant-HK", "zh-TW", true},
+		{"zh-CN", "zh", true},
+		{"zh-Hans", "zh", true},
+		{" ja ", "ja", true},
+		{"xx_YY", "xx-YY", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := NormalizeLanguageCode(tt.code)
+			if got != tt.expected || ok != tt.expectOk {
+				t.Errorf("NormalizeLanguageCode(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.expected, tt.expectOk)
+			}
+		})
+	}
+}
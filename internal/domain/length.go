@@ -0,0 +1,58 @@
+package domain
+
+import "fmt"
+
+// LengthUnit is how the length of a translation is measured
+type LengthUnit string
+
+const (
+	// LengthUnitChars counts characters (Unicode code points)
+	LengthUnitChars LengthUnit = "chars"
+	// LengthUnitWidth counts display columns: full-width characters (CJK, emoji) count as 2
+	LengthUnitWidth LengthUnit = "width"
+)
+
+// ParseLengthUnit parses a length unit; empty means characters
+func ParseLengthUnit(s string) (LengthUnit, error) {
+	switch LengthUnit(s) {
+	case "", LengthUnitChars:
+		return LengthUnitChars, nil
+	case LengthUnitWidth:
+		return LengthUnitWidth, nil
+	}
+	return "", NewValidationError(fmt.Sprintf("unknown length unit %q (supported: chars, width)", s), nil)
+}
+
+// LengthLimit is the maximum length of a translation, e.g. for a fixed-width button
+type LengthLimit struct {
+	Max  int        `json:"max" yaml:"max"`
+	Unit LengthUnit `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// IsZero reports whether there is no limit
+func (l LengthLimit) IsZero() bool {
+	return l.Max <= 0
+}
+
+// String describes the limit, e.g. "12 characters" or "20 display columns"
+func (l LengthLimit) String() string {
+	if l.Unit == LengthUnitWidth {
+		return fmt.Sprintf("%d display columns", l.Max)
+	}
+	return fmt.Sprintf("%d characters", l.Max)
+}
+
+// LengthRule limits the length of the translations of keys matching a pattern
+// (same syntax as --keys)
+type LengthRule struct {
+	Keys        string `json:"keys" yaml:"keys"`
+	LengthLimit `yaml:",inline"`
+}
+
+// LengthViolation is a translation that is still longer than its limit
+type LengthViolation struct {
+	Key    string      `json:"key"`
+	Text   string      `json:"text"`
+	Length int         `json:"length"`
+	Limit  LengthLimit `json:"limit"`
+}
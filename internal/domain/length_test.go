@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestParseLengthUnit(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LengthUnit
+		wantErr bool
+	}{
+		{"", LengthUnitChars, false},
+		{"chars", LengthUnitChars, false},
+		{"width", LengthUnitWidth, false},
+		{"pixels", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLengthUnit(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLengthUnit(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestLengthLimit_String(t *testing.T) {
+	if got := (LengthLimit{Max: 12}).String(); got != "12 characters" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (LengthLimit{Max: 20, Unit: LengthUnitWidth}).String(); got != "20 display columns" {
+		t.Errorf("String() = %q", got)
+	}
+	if !(LengthLimit{}).IsZero() {
+		t.Error("IsZero() should be true without a max")
+	}
+}
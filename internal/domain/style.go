@@ -0,0 +1,25 @@
+package domain
+
+// StyleGuide describes how translations into one language should read
+type StyleGuide struct {
+	Language    string         `json:"language,omitempty" yaml:"language,omitempty"`
+	Formality   string         `json:"formality,omitempty" yaml:"formality,omitempty"`     // e.g. "formal, address the user as Sie"
+	Tone        string         `json:"tone,omitempty" yaml:"tone,omitempty"`               // e.g. "friendly, concise"
+	Punctuation string         `json:"punctuation,omitempty" yaml:"punctuation,omitempty"` // e.g. "full-width 。、, no space before !"
+	Forbidden   []string       `json:"forbidden,omitempty" yaml:"forbidden,omitempty"`     // phrases that must not appear in translations
+	Examples    []StyleExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Notes       string         `json:"notes,omitempty" yaml:"notes,omitempty"` // free-form guidance
+	Path        string         `json:"-" yaml:"-"`                             // file the guide was loaded from
+}
+
+// StyleExample is a translation that shows the expected style
+type StyleExample struct {
+	Source string `json:"source" yaml:"source"`
+	Target string `json:"target" yaml:"target"`
+}
+
+// IsZero reports whether the guide has no guidance
+func (g *StyleGuide) IsZero() bool {
+	return g == nil || (g.Formality == "" && g.Tone == "" && g.Punctuation == "" &&
+		len(g.Forbidden) == 0 && len(g.Examples) == 0 && g.Notes == "")
+}
@@ -14,24 +14,47 @@ const (
 
 // Term represents a single terminology entry
 type Term struct {
-	Term    string   `json:"term"`
-	Type    TermType `json:"type"`
-	Context string   `json:"context,omitempty"` // Context provided by LLM
-	Reason  string   `json:"reason,omitempty"`  // Why detected as term
+	Term         string   `json:"term"`
+	Type         TermType `json:"type"`
+	Context      string   `json:"context,omitempty"`      // Context provided by LLM
+	Reason       string   `json:"reason,omitempty"`       // Why detected as term
+	PartOfSpeech string   `json:"partOfSpeech,omitempty"` // noun, verb, adjective...
+	Definition   string   `json:"definition,omitempty"`   // Meaning in this product
+}
+
+// TermInfo holds optional details about a term
+type TermInfo struct {
+	PartOfSpeech  string   `json:"partOfSpeech,omitempty" yaml:"partOfSpeech,omitempty"`   // noun, verb, adjective...
+	Definition    string   `json:"definition,omitempty" yaml:"definition,omitempty"`       // Meaning in this product
+	Context       string   `json:"context,omitempty" yaml:"context,omitempty"`             // Usage notes or examples
+	Reason        string   `json:"reason,omitempty" yaml:"reason,omitempty"`               // Why the term was detected
+	CaseSensitive bool     `json:"caseSensitive,omitempty" yaml:"caseSensitive,omitempty"` // Only match the term with this exact casing
+	Scope         []string `json:"scope,omitempty" yaml:"scope,omitempty"`                 // Key globs the term applies to (e.g. "billing.**"), empty = everywhere
+}
+
+// TermViolation is a translation that doesn't follow the terminology
+type TermViolation struct {
+	Key      string   `json:"key"`
+	Term     string   `json:"term"`
+	Type     TermType `json:"type"`
+	Expected string   `json:"expected"` // text that should appear in the translation
+	Message  string   `json:"message"`
 }
 
 // Terminology represents the terminology definition (source language only)
 type Terminology struct {
-	SourceLanguage  string   `json:"sourceLanguage"`
-	PreserveTerms   []string `json:"preserveTerms"`
-	ConsistentTerms []string `json:"consistentTerms"`
+	SourceLanguage  string              `json:"sourceLanguage"`
+	PreserveTerms   []string            `json:"preserveTerms"`
+	ConsistentTerms []string            `json:"consistentTerms"`
+	Details         map[string]TermInfo `json:"details,omitempty"` // term -> optional details
 }
 
 // TerminologyTranslation represents terminology translations for a specific target language
 type TerminologyTranslation struct {
-	SourceLanguage string            `json:"sourceLanguage"`
-	TargetLanguage string            `json:"targetLanguage"`
-	Translations   map[string]string `json:"translations"` // term -> translation
+	SourceLanguage string              `json:"sourceLanguage"`
+	TargetLanguage string              `json:"targetLanguage"`
+	Translations   map[string]string   `json:"translations"`        // term -> translation
+	Forbidden      map[string][]string `json:"forbidden,omitempty"` // term -> translations that must not be used
 }
 
 // GetTermTranslation returns the translation for a term
@@ -75,6 +98,97 @@ func (t *Terminology) AddConsistentTerm(term string) {
 	t.ConsistentTerms = append(t.ConsistentTerms, term)
 }
 
+// TermType returns the type of a term, if the terminology contains it
+func (t *Terminology) TermType(term string) (TermType, bool) {
+	if slices.Contains(t.PreserveTerms, term) {
+		return TermTypePreserve, true
+	}
+	if slices.Contains(t.ConsistentTerms, term) {
+		return TermTypeConsistent, true
+	}
+	return "", false
+}
+
+// RemoveTerm removes a term from both lists and reports whether it was present
+func (t *Terminology) RemoveTerm(term string) bool {
+	before := len(t.PreserveTerms) + len(t.ConsistentTerms)
+	t.PreserveTerms = slices.DeleteFunc(t.PreserveTerms, func(s string) bool { return s == term })
+	t.ConsistentTerms = slices.DeleteFunc(t.ConsistentTerms, func(s string) bool { return s == term })
+	delete(t.Details, term)
+	return len(t.PreserveTerms)+len(t.ConsistentTerms) != before
+}
+
+// AddTerm adds a detected term to the list for its type, keeping the detector's
+// notes as details unless the term already has some
+func (t *Terminology) AddTerm(term Term) {
+	if term.Type == TermTypePreserve {
+		t.AddPreserveTerm(term.Term)
+	} else {
+		t.AddConsistentTerm(term.Term)
+	}
+
+	info := TermInfo{
+		PartOfSpeech: term.PartOfSpeech,
+		Definition:   term.Definition,
+		Context:      term.Context,
+		Reason:       term.Reason,
+	}
+	if _, exists := t.Details[term.Term]; !exists && !info.IsZero() {
+		t.SetInfo(term.Term, info)
+	}
+}
+
+// Info returns the details of a term (zero value if none)
+func (t *Terminology) Info(term string) TermInfo {
+	if t == nil {
+		return TermInfo{}
+	}
+	return t.Details[term]
+}
+
+// SetInfo sets the details of a term; zero details are removed
+func (t *Terminology) SetInfo(term string, info TermInfo) {
+	if info.IsZero() {
+		delete(t.Details, term)
+		return
+	}
+	if t.Details == nil {
+		t.Details = make(map[string]TermInfo)
+	}
+	t.Details[term] = info
+}
+
+// HasScopedTerms reports whether any term is limited to certain keys
+func (t *Terminology) HasScopedTerms() bool {
+	if t == nil {
+		return false
+	}
+	for _, info := range t.Details {
+		if len(info.Scope) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZero reports whether no detail is set
+func (i TermInfo) IsZero() bool {
+	return i.PartOfSpeech == "" && i.Definition == "" && i.Context == "" && i.Reason == "" &&
+		!i.CaseSensitive && len(i.Scope) == 0
+}
+
+// Describe returns a short description for prompts, e.g. "noun: a shared folder"
+func (i TermInfo) Describe() string {
+	switch {
+	case i.PartOfSpeech != "" && i.Definition != "":
+		return i.PartOfSpeech + ": " + i.Definition
+	case i.Definition != "":
+		return i.Definition
+	default:
+		return i.PartOfSpeech
+	}
+}
+
 // NewTerminologyTranslation creates a new terminology translation
 func NewTerminologyTranslation(sourceLang, targetLang string) *TerminologyTranslation {
 	return &TerminologyTranslation{
@@ -88,3 +202,22 @@ func NewTerminologyTranslation(sourceLang, targetLang string) *TerminologyTransl
 func (tt *TerminologyTranslation) AddTranslation(term, translation string) {
 	tt.Translations[term] = translation
 }
+
+// AddForbidden records a translation of a term that must not be used
+func (tt *TerminologyTranslation) AddForbidden(term, translation string) {
+	if slices.Contains(tt.Forbidden[term], translation) {
+		return
+	}
+	if tt.Forbidden == nil {
+		tt.Forbidden = make(map[string][]string)
+	}
+	tt.Forbidden[term] = append(tt.Forbidden[term], translation)
+}
+
+// GetForbidden returns the translations of a term that must not be used
+func (tt *TerminologyTranslation) GetForbidden(term string) []string {
+	if tt == nil {
+		return nil
+	}
+	return tt.Forbidden[term]
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "testing"
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestTerminologyTranslation_GetTermTranslation(t *testing.T) {
 	tests := []struct {
@@ -342,3 +347,108 @@ func TestTerminologyTranslationStruct(t *testing.T) {
 		t.Errorf("Translations length = %d, want 2", len(translation.Translations))
 	}
 }
+
+func TestTerminology_TermTypeAndRemoveTerm(t *testing.T) {
+	terminology := &Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"API", "SDK"},
+		ConsistentTerms: []string{"user", "account"},
+	}
+
+	if typ, ok := terminology.TermType("API"); !ok || typ != TermTypePreserve {
+		t.Errorf("TermType(API) = %q, %v", typ, ok)
+	}
+	if typ, ok := terminology.TermType("account"); !ok || typ != TermTypeConsistent {
+		t.Errorf("TermType(account) = %q, %v", typ, ok)
+	}
+	if _, ok := terminology.TermType("missing"); ok {
+		t.Error("TermType(missing) should not be found")
+	}
+
+	if !terminology.RemoveTerm("SDK") || !terminology.RemoveTerm("user") {
+		t.Error("RemoveTerm() should report removal of existing terms")
+	}
+	if terminology.RemoveTerm("missing") {
+		t.Error("RemoveTerm() should report false for unknown terms")
+	}
+	if len(terminology.PreserveTerms) != 1 || len(terminology.ConsistentTerms) != 1 {
+		t.Errorf("after RemoveTerm() = %v / %v", terminology.PreserveTerms, terminology.ConsistentTerms)
+	}
+}
+
+func TestTerminology_AddTermKeepsDetails(t *testing.T) {
+	terminology := &Terminology{SourceLanguage: "en"}
+
+	terminology.AddTerm(Term{Term: "Plan", Type: TermTypeConsistent, PartOfSpeech: "noun", Definition: "subscription tier", Reason: "Core concept"})
+	terminology.AddTerm(Term{Term: "API", Type: TermTypePreserve})
+	// Details that were already curated are not overwritten by detection
+	terminology.AddTerm(Term{Term: "Plan", Type: TermTypeConsistent, Definition: "to schedule"})
+
+	if !slices.Equal(terminology.ConsistentTerms, []string{"Plan"}) || !slices.Equal(terminology.PreserveTerms, []string{"API"}) {
+		t.Fatalf("terms = %v / %v", terminology.PreserveTerms, terminology.ConsistentTerms)
+	}
+	if info := terminology.Info("Plan"); info.Describe() != "noun: subscription tier" || info.Reason != "Core concept" {
+		t.Errorf("Info(Plan) = %+v", info)
+	}
+	if !terminology.Info("API").IsZero() {
+		t.Errorf("Info(API) = %+v, want zero", terminology.Info("API"))
+	}
+
+	terminology.SetInfo("Plan", TermInfo{Scope: []string{"billing.*"}})
+	if !terminology.HasScopedTerms() {
+		t.Error("HasScopedTerms() = false after setting a scope")
+	}
+	terminology.SetInfo("Plan", TermInfo{})
+	if terminology.HasScopedTerms() || len(terminology.Details) != 0 {
+		t.Errorf("SetInfo(zero) should remove details, got %+v", terminology.Details)
+	}
+
+	var nilTerminology *Terminology
+	if !nilTerminology.Info("x").IsZero() || nilTerminology.HasScopedTerms() {
+		t.Error("nil terminology should have no details")
+	}
+}
+
+func TestTerminologyTranslation_Forbidden(t *testing.T) {
+	translation := NewTerminologyTranslation("en", "zh")
+	translation.AddForbidden("Account", "账户")
+	translation.AddForbidden("Account", "账户")
+	translation.AddForbidden("Account", "户口")
+
+	if got := translation.GetForbidden("Account"); !slices.Equal(got, []string{"账户", "户口"}) {
+		t.Errorf("GetForbidden() = %v", got)
+	}
+
+	var nilTranslation *TerminologyTranslation
+	if nilTranslation.GetForbidden("Account") != nil {
+		t.Error("nil translation should have no forbidden translations")
+	}
+}
+
+func TestTerminology_LoadsFilesWithoutDetails(t *testing.T) {
+	// Files written before details and forbidden translations existed
+	var terminology Terminology
+	if err := json.Unmarshal([]byte(`{"version":"1.0","sourceLanguage":"en","preserveTerms":["API"],"consistentTerms":["user"]}`), &terminology); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !terminology.Info("user").IsZero() || terminology.HasScopedTerms() {
+		t.Errorf("old terminology has details: %+v", terminology.Details)
+	}
+
+	var translation TerminologyTranslation
+	if err := json.Unmarshal([]byte(`{"sourceLanguage":"en","targetLanguage":"zh","translations":{"user":"用户"}}`), &translation); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if translation.GetForbidden("user") != nil {
+		t.Errorf("old translation has forbidden terms: %v", translation.Forbidden)
+	}
+
+	// New fields are omitted when empty, so old readers see the same files
+	data, err := json.Marshal(terminology)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "details") {
+		t.Errorf("Marshal() = %s, should omit empty details", data)
+	}
+}
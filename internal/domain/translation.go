@@ -9,9 +9,32 @@ type TranslationInput struct {
 	TargetLang             string
 	Terminology            *Terminology
 	TerminologyTranslation *TerminologyTranslation
+	Prefilled              map[string]PrefilledTranslation   // key path -> translation reused without an API call
+	References             map[string][]TranslationReference // key path -> similar earlier translations for the prompt
+	StyleGuide             *StyleGuide                       // style guide of the target language, if any
+	LengthLimits           map[string]LengthLimit            // key path -> maximum length (e.g. from sidecar metadata)
+	LengthRules            []LengthRule                      // maximum lengths by key pattern; LengthLimits and earlier rules win
+	Notes                  map[string]string                 // key path -> reviewer's note on how to translate it
+	Locked                 map[string]string                 // key path -> translation kept as is, never sent for translation
 	Options                TranslationOptions
 }
 
+// PrefilledTranslation is a translation known before the run (e.g. from a checkpoint).
+// It is used only while the source text it was made from is unchanged.
+type PrefilledTranslation struct {
+	SourceText string // source text the translation was made from (empty = always reuse)
+	Text       string
+	Origin     string // where the translation came from, e.g. "checkpoint"
+}
+
+// TranslationReference is an earlier translation of a similar source text
+// (a fuzzy translation memory match) offered to the model as a reference
+type TranslationReference struct {
+	Source     string
+	Target     string
+	Similarity float64 // 0-1
+}
+
 // TranslationOptions contains options for translation
 type TranslationOptions struct {
 	BatchSize     int
@@ -25,9 +48,41 @@ type TranslationOptions struct {
 
 // TranslationResult represents the result of translation
 type TranslationResult struct {
-	Target map[string]any // Translated JSON data
-	Stats  TranslationStats
-	Errors []TranslationError
+	Target       map[string]any    // Translated JSON data
+	Translations map[string]string // key path -> translated text (translated keys only)
+	Stats        TranslationStats
+	Errors       []TranslationError
+	// TermViolations lists translations that still don't follow the terminology
+	TermViolations []TermViolation
+	// LengthViolations lists translations that are still too long after shortening
+	LengthViolations []LengthViolation
+	// Keys records how each key was translated, for run reports
+	Keys map[string]KeyDetail
+}
+
+// KeyStatus is the outcome of translating a key
+type KeyStatus string
+
+const (
+	KeyStatusTranslated KeyStatus = "translated"
+	KeyStatusReused     KeyStatus = "reused"  // prefilled from the translation memory or a checkpoint
+	KeyStatusFailed     KeyStatus = "failed"  // its batch failed, or the model returned no translation
+	KeyStatusSkipped    KeyStatus = "skipped" // its batch was not started because the budget ran out
+	KeyStatusLocked     KeyStatus = "locked"  // kept as is: locked, or approved by a reviewer
+)
+
+// KeyDetail is how a key was translated: the draft, what reflection made of it and
+// what it cost. The final text is in TranslationResult.Translations.
+type KeyDetail struct {
+	Status      KeyStatus
+	Batch       int    // 1-based batch number, 0 for reused and locked keys
+	Draft       string // first translation, before reflection and shortening
+	Suggestion  string // reflection's suggestion, empty when it had none
+	Shortened   bool   // sent back because it was over its length limit
+	Attempts    int    // translation attempts of the batch (1 + retries)
+	BatchTokens int    // tokens of all API calls of the batch, shared by its keys
+	Origin      string // where a reused translation came from, e.g. "tm" or "checkpoint"
+	Error       string // why the key failed
 }
 
 // TranslationStats contains statistics about the translation
@@ -36,14 +91,29 @@ type TranslationStats struct {
 	SuccessItems     int
 	FailedItems      int
 	SkippedItems     int
+	ReusedItems      int // items filled from Prefilled without an API call
+	LockedItems      int // items kept from Locked
+	CacheHits        int // API calls served from the response cache
 	Duration         time.Duration
 	APICallsCount    int
+	PromptTokens     int
+	CompletionTokens int
 	TotalTokens      int
-	EstimatedCost    float64
+	EstimatedCost    float64           // USD, based on the model's pricing
 	IncrementalStats *IncrementalStats // Only present for incremental translation
 	FilterStats      *FilterStats      // Only present when key filtering is used
 }
 
+// CostEstimate is the expected size and cost of a translation run (dry run)
+type CostEstimate struct {
+	Items            int
+	Batches          int
+	APICalls         int // translate + reflect + improve per batch
+	PromptTokens     int
+	CompletionTokens int
+	EstimatedCost    float64 // USD, 0 when the model's pricing is unknown
+}
+
 // IncrementalStats contains statistics for incremental translation
 type IncrementalStats struct {
 	NewKeys       int
@@ -68,10 +138,13 @@ type TranslationError struct {
 
 // BatchItem represents a single item in a translation batch
 type BatchItem struct {
-	Key     string // JSON key path (e.g., "settings.title")
-	Text    string // Text to translate
-	Context string // Context for the translation
-	Value   any    // Original value (for non-string types)
+	Key        string                 // JSON key path (e.g., "settings.title")
+	Text       string                 // Text to translate
+	Context    string                 // Context for the translation
+	Value      any                    // Original value (for non-string types)
+	References []TranslationReference // Similar earlier translations (optional)
+	MaxLength  LengthLimit            // Maximum length of the translation (optional)
+	Note       string                 // Reviewer's note on how to translate it (optional)
 }
 
 // TranslatedItem represents a translated item
@@ -0,0 +1,122 @@
+package incremental
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// Checkpoint holds the translations of an interrupted run (e.g. when the cost budget ran out)
+// so the next run can reuse them instead of paying for them again
+type Checkpoint struct {
+	SourcePath     string                     `json:"source_path"`
+	SourceLanguage string                     `json:"source_language"`
+	TargetLanguage string                     `json:"target_language"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	Entries        map[string]CheckpointEntry `json:"entries"` // key path -> entry
+}
+
+// CheckpointEntry is a single translated key together with the source text it was made from
+type CheckpointEntry struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// CheckpointPath returns where the checkpoint for a source file and target language is stored
+func CheckpointPath(dir, sourcePath, targetLang string) string {
+	return filepath.Join(dir, "checkpoints", fmt.Sprintf("%s.%s.json", SourceName(dir, sourcePath), targetLang))
+}
+
+// SourceName names what a terminology directory keeps for a source file, e.g. en-1a2b3c4d
+// for locales/en.json: the file name, and a hash of the path relative to the project (the
+// directory holding the terminology directory), so sources with the same file name in
+// different directories, like packages/*/locales/en.json, get their own
+func SourceName(dir, sourcePath string) string {
+	base := strings.TrimSuffix(filepath.Base(sourcePath), filepath.Ext(sourcePath))
+	path, err := filepath.Abs(sourcePath)
+	if err != nil {
+		path = sourcePath
+	}
+	if project, err := filepath.Abs(filepath.Dir(filepath.Clean(dir))); err == nil {
+		// Outside the project the absolute path is used
+		if rel, err := filepath.Rel(project, path); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			path = rel
+		}
+	}
+	sum := sha256.Sum256([]byte(filepath.ToSlash(path)))
+	return fmt.Sprintf("%s-%x", base, sum[:4])
+}
+
+// LoadCheckpoint loads a checkpoint; it returns nil without error if none exists
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, domain.NewIOError("failed to read checkpoint", err).
+			WithContext("path", path)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, domain.NewFormatError("failed to parse checkpoint", err).
+			WithContext("path", path)
+	}
+
+	return &cp, nil
+}
+
+// SaveCheckpoint writes a checkpoint, creating its directory if needed
+func SaveCheckpoint(path string, cp *Checkpoint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return domain.NewIOError("failed to create checkpoint directory", err).
+			WithContext("path", path)
+	}
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to marshal checkpoint", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return domain.NewIOError("failed to write checkpoint", err).
+			WithContext("path", path)
+	}
+
+	return nil
+}
+
+// RemoveCheckpoint deletes a checkpoint once the run it belongs to has completed
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return domain.NewIOError("failed to remove checkpoint", err).
+			WithContext("path", path)
+	}
+	return nil
+}
+
+// Prefilled converts the checkpoint into translations the engine can reuse
+func (cp *Checkpoint) Prefilled() map[string]domain.PrefilledTranslation {
+	if cp == nil {
+		return nil
+	}
+
+	prefilled := make(map[string]domain.PrefilledTranslation, len(cp.Entries))
+	for key, entry := range cp.Entries {
+		prefilled[key] = domain.PrefilledTranslation{
+			SourceText: entry.Source,
+			Text:       entry.Target,
+			Origin:     "checkpoint",
+		}
+	}
+	return prefilled
+}
@@ -0,0 +1,77 @@
+package incremental
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckpointPath(t *testing.T) {
+	got := CheckpointPath(".jta", "locales/en.json", "zh")
+	want := filepath.Join(".jta", "checkpoints", SourceName(".jta", "locales/en.json")+".zh.json")
+	if got != want {
+		t.Errorf("CheckpointPath() = %q, want %q", got, want)
+	}
+}
+
+func TestSourceName(t *testing.T) {
+	name := SourceName(".jta", filepath.FromSlash("packages/web/locales/en.json"))
+	if !strings.HasPrefix(name, "en-") {
+		t.Errorf("SourceName() = %q, want the file name first", name)
+	}
+	// Same file name in another directory
+	if other := SourceName(".jta", filepath.FromSlash("packages/api/locales/en.json")); other == name {
+		t.Errorf("SourceName() = %q for sources in different directories", name)
+	}
+	// The same source, named relative to the project from elsewhere
+	project := filepath.Join(t.TempDir(), "app")
+	a := SourceName(filepath.Join(project, ".jta"), filepath.Join(project, "locales", "en.json"))
+	b := SourceName(filepath.Join(project, ".jta"), filepath.Join(project, "locales", ".", "en.json"))
+	if a != b {
+		t.Errorf("SourceName() = %q and %q for the same source", a, b)
+	}
+}
+
+func TestCheckpoint_SaveLoadRemove(t *testing.T) {
+	path := CheckpointPath(t.TempDir(), "en.json", "ja")
+
+	// Missing checkpoint is not an error
+	cp, err := LoadCheckpoint(path)
+	if err != nil || cp != nil {
+		t.Fatalf("LoadCheckpoint(missing) = %v, %v, want nil, nil", cp, err)
+	}
+	if cp.Prefilled() != nil {
+		t.Error("nil checkpoint should have no prefilled translations")
+	}
+
+	saved := &Checkpoint{
+		SourcePath:     "en.json",
+		SourceLanguage: "en",
+		TargetLanguage: "ja",
+		CreatedAt:      time.Now(),
+		Entries: map[string]CheckpointEntry{
+			"app.title": {Source: "Hello", Target: "こんにちは"},
+		},
+	}
+	if err := SaveCheckpoint(path, saved); err != nil {
+		t.Fatalf("SaveCheckpoint() error = %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	prefilled := loaded.Prefilled()
+	entry, ok := prefilled["app.title"]
+	if !ok || entry.Text != "こんにちは" || entry.SourceText != "Hello" || entry.Origin != "checkpoint" {
+		t.Errorf("Prefilled() = %v", prefilled)
+	}
+
+	if err := RemoveCheckpoint(path); err != nil {
+		t.Fatalf("RemoveCheckpoint() error = %v", err)
+	}
+	if err := RemoveCheckpoint(path); err != nil {
+		t.Errorf("RemoveCheckpoint() on missing file error = %v", err)
+	}
+}
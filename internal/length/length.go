@@ -0,0 +1,130 @@
+// Package length limits the length of translations for fixed-size UI elements: it loads
+// the limits (rules by key pattern and sidecar metadata) and measures translations.
+package length
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/mattn/go-runewidth"
+	"gopkg.in/yaml.v3"
+)
+
+// RulesPath returns the length rules file inside a terminology directory
+func RulesPath(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "lengths.yaml")
+}
+
+// SidecarPath returns the metadata file of a source file: locales/en.json -> locales/en.meta.json
+func SidecarPath(sourcePath string) string {
+	ext := filepath.Ext(sourcePath)
+	return strings.TrimSuffix(sourcePath, ext) + ".meta" + ext
+}
+
+// LoadRules loads the length rules of a terminology directory, a YAML (or JSON) list of
+// {keys, max, unit}. It returns nil if there is no rules file.
+func LoadRules(terminologyDir string) ([]domain.LengthRule, error) {
+	path := RulesPath(terminologyDir)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, domain.NewIOError("failed to read length rules", err).
+			WithContext("path", path)
+	}
+
+	var rules []domain.LengthRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, domain.NewFormatError("invalid length rules", err).
+			WithContext("path", path)
+	}
+	for i := range rules {
+		if rules[i].Keys == "" {
+			return nil, domain.NewFormatError(fmt.Sprintf("length rule %d has no keys", i+1), nil).
+				WithContext("path", path)
+		}
+		if err := normalize(&rules[i].LengthLimit); err != nil {
+			return nil, domain.NewFormatError(fmt.Sprintf("invalid length rule for %q", rules[i].Keys), err).
+				WithContext("path", path)
+		}
+	}
+	return rules, nil
+}
+
+// keyMeta is the metadata of one key in a sidecar file
+type keyMeta struct {
+	MaxLength  int    `json:"maxLength"`
+	LengthUnit string `json:"lengthUnit"`
+}
+
+// LoadSidecar loads the length limits from the metadata file next to a source file,
+// a JSON object of key path -> {"maxLength": 12, "lengthUnit": "width"}. Other metadata
+// is ignored. It returns nil if there is no sidecar file.
+func LoadSidecar(sourcePath string) (map[string]domain.LengthLimit, error) {
+	path := SidecarPath(sourcePath)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, domain.NewIOError("failed to read key metadata", err).
+			WithContext("path", path)
+	}
+
+	var meta map[string]keyMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, domain.NewFormatError("invalid key metadata", err).
+			WithContext("path", path)
+	}
+
+	limits := make(map[string]domain.LengthLimit)
+	for key, m := range meta {
+		if m.MaxLength == 0 {
+			continue
+		}
+		limit := domain.LengthLimit{Max: m.MaxLength, Unit: domain.LengthUnit(m.LengthUnit)}
+		if err := normalize(&limit); err != nil {
+			return nil, domain.NewFormatError(fmt.Sprintf("invalid length limit for %q", key), err).
+				WithContext("path", path)
+		}
+		limits[key] = limit
+	}
+	return limits, nil
+}
+
+// normalize validates a limit and fills in the default unit
+func normalize(limit *domain.LengthLimit) error {
+	if limit.Max <= 0 {
+		return fmt.Errorf("max must be positive, got %d", limit.Max)
+	}
+	unit, err := domain.ParseLengthUnit(string(limit.Unit))
+	if err != nil {
+		return err
+	}
+	limit.Unit = unit
+	return nil
+}
+
+// Measure returns the length of a text in a unit
+func Measure(text string, unit domain.LengthUnit) int {
+	if unit == domain.LengthUnitWidth {
+		return runewidth.StringWidth(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// Exceeds reports whether a text is longer than a limit, and its length
+func Exceeds(text string, limit domain.LengthLimit) (bool, int) {
+	if limit.IsZero() {
+		return false, 0
+	}
+	n := Measure(text, limit.Unit)
+	return n > limit.Max, n
+}
@@ -0,0 +1,136 @@
+package length
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func TestSidecarPath(t *testing.T) {
+	if got := SidecarPath(filepath.Join("locales", "en.json")); got != filepath.Join("locales", "en.meta.json") {
+		t.Errorf("SidecarPath() = %q", got)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	rules := "- keys: \"buttons.*\"\n  max: 12\n- keys: \"tabs.*, menu.**\"\n  max: 20\n  unit: width\n"
+	if err := os.WriteFile(RulesPath(dir), []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadRules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.LengthRule{
+		{Keys: "buttons.*", LengthLimit: domain.LengthLimit{Max: 12, Unit: domain.LengthUnitChars}},
+		{Keys: "tabs.*, menu.**", LengthLimit: domain.LengthLimit{Max: 20, Unit: domain.LengthUnitWidth}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("LoadRules() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadRules_Missing(t *testing.T) {
+	rules, err := LoadRules(t.TempDir())
+	if err != nil || rules != nil {
+		t.Errorf("LoadRules() = %v, %v; want nil, nil", rules, err)
+	}
+}
+
+func TestLoadRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"no keys", "- max: 12\n"},
+		{"no max", "- keys: \"buttons.*\"\n"},
+		{"unknown unit", "- keys: \"buttons.*\"\n  max: 12\n  unit: pixels\n"},
+		{"not a list", "keys: buttons\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(RulesPath(dir), []byte(tt.rules), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadRules(dir)
+			if !domain.IsErrorType(err, domain.ErrorTypeFormat) {
+				t.Errorf("expected a format error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSidecar(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	meta := `{
+  "buttons.save": {"maxLength": 10},
+  "tabs.home": {"maxLength": 8, "lengthUnit": "width"},
+  "app.title": {"description": "Window title"}
+}`
+	if err := os.WriteFile(SidecarPath(source), []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	limits, err := LoadSidecar(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 2 {
+		t.Fatalf("LoadSidecar() = %+v, want 2 limits", limits)
+	}
+	if limits["buttons.save"] != (domain.LengthLimit{Max: 10, Unit: domain.LengthUnitChars}) {
+		t.Errorf("buttons.save = %+v", limits["buttons.save"])
+	}
+	if limits["tabs.home"] != (domain.LengthLimit{Max: 8, Unit: domain.LengthUnitWidth}) {
+		t.Errorf("tabs.home = %+v", limits["tabs.home"])
+	}
+
+	if limits, err := LoadSidecar(filepath.Join(dir, "fr.json")); err != nil || limits != nil {
+		t.Errorf("LoadSidecar() without a sidecar = %v, %v; want nil, nil", limits, err)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		text string
+		unit domain.LengthUnit
+		want int
+	}{
+		{"Speichern", domain.LengthUnitChars, 9},
+		{"Speichern", domain.LengthUnitWidth, 9},
+		{"保存する", domain.LengthUnitChars, 4},
+		{"保存する", domain.LengthUnitWidth, 8},
+		{"Größe", domain.LengthUnitChars, 5},
+	}
+
+	for _, tt := range tests {
+		if got := Measure(tt.text, tt.unit); got != tt.want {
+			t.Errorf("Measure(%q, %s) = %d, want %d", tt.text, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestExceeds(t *testing.T) {
+	limit := domain.LengthLimit{Max: 8, Unit: domain.LengthUnitChars}
+	if exceeds, n := Exceeds("Speichern", limit); !exceeds || n != 9 {
+		t.Errorf("Exceeds() = %v, %d; want true, 9", exceeds, n)
+	}
+	if exceeds, _ := Exceeds("Sichern", limit); exceeds {
+		t.Error("Exceeds() should be false within the limit")
+	}
+	if exceeds, _ := Exceeds("Speichern", domain.LengthLimit{}); exceeds {
+		t.Error("Exceeds() should be false without a limit")
+	}
+}
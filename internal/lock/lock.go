@@ -0,0 +1,111 @@
+// Package lock finds the keys whose translations jta never overwrites: keys locked by
+// pattern in the config or in the lock file of the terminology directory, and keys
+// marked "locked" in the sidecar metadata of a source file.
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/keyfilter"
+	"github.com/hikanner/jta/internal/length"
+	"gopkg.in/yaml.v3"
+)
+
+// Path returns the lock file inside a terminology directory
+func Path(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "locked.yaml")
+}
+
+// Set is the locked keys of a source file
+type Set struct {
+	filter   *keyfilter.Filter
+	patterns []*keyfilter.KeyPattern
+	keys     map[string]bool
+}
+
+// Load collects the locks of a source file: the given comma-separated key patterns
+// (e.g. from the config), the patterns of the lock file, a YAML list like
+// ["legal.**", "brand.name"], and the keys marked {"locked": true} in the source's
+// sidecar metadata
+func Load(terminologyDir, sourcePath, patterns string) (*Set, error) {
+	s := &Set{filter: keyfilter.NewFilter(), keys: make(map[string]bool)}
+	if err := s.addPatterns([]string{patterns}, "config"); err != nil {
+		return nil, err
+	}
+
+	path := Path(terminologyDir)
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, domain.NewIOError("failed to read lock file", err).
+			WithContext("path", path)
+	}
+	if err == nil {
+		var filePatterns []string
+		if err := yaml.Unmarshal(data, &filePatterns); err != nil {
+			return nil, domain.NewFormatError("invalid lock file", err).
+				WithContext("path", path)
+		}
+		if err := s.addPatterns(filePatterns, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Other metadata of the sidecar (length limits) is read by the length package
+	path = length.SidecarPath(sourcePath)
+	data, err = os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, domain.NewIOError("failed to read key metadata", err).
+			WithContext("path", path)
+	}
+	if err == nil {
+		var meta map[string]struct {
+			Locked bool `json:"locked"`
+		}
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, domain.NewFormatError("invalid key metadata", err).
+				WithContext("path", path)
+		}
+		for key, m := range meta {
+			if m.Locked {
+				s.keys[key] = true
+			}
+		}
+	}
+	return s, nil
+}
+
+// addPatterns parses key patterns locking keys
+func (s *Set) addPatterns(patterns []string, origin string) error {
+	for _, p := range patterns {
+		parsed, err := s.filter.ParsePatterns(p)
+		if err != nil {
+			return domain.NewValidationError(fmt.Sprintf("invalid lock pattern %q", p), err).
+				WithContext("origin", origin)
+		}
+		s.patterns = append(s.patterns, parsed...)
+	}
+	return nil
+}
+
+// Add locks a key
+func (s *Set) Add(key string) {
+	s.keys[key] = true
+}
+
+// Locked reports whether a key is locked
+func (s *Set) Locked(key string) bool {
+	if s.keys[key] {
+		return true
+	}
+	for _, p := range s.patterns {
+		if s.filter.MatchKey(key, p) {
+			return true
+		}
+	}
+	return false
+}
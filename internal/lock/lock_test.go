@@ -0,0 +1,62 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "en.json")
+	if err := os.WriteFile(Path(dir), []byte("- \"legal.**\"\n- brand.name\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"home.title": {"locked": true}, "home.subtitle": {"max": 40}}`
+	if err := os.WriteFile(filepath.Join(dir, "en.meta.json"), []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := Load(dir, source, "settings.*, brand.name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Add("home.footer")
+
+	tests := map[string]bool{
+		"legal.terms.title": true,
+		"brand.name":        true,
+		"brand.slogan":      false,
+		"settings.theme":    true,
+		"settings.a.b":      false,
+		"home.title":        true,
+		"home.subtitle":     false,
+		"home.footer":       true,
+	}
+	for key, want := range tests {
+		if got := set.Locked(key); got != want {
+			t.Errorf("Locked(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLoad_Missing(t *testing.T) {
+	dir := t.TempDir()
+	set, err := Load(dir, filepath.Join(dir, "en.json"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Locked("home.title") {
+		t.Error("Locked() = true without locks")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(Path(dir), []byte("legal: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, filepath.Join(dir, "en.json"), ""); err == nil {
+		t.Error("Load() with an invalid lock file succeeded")
+	}
+}
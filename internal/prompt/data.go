@@ -0,0 +1,185 @@
+package prompt
+
+// TranslateData is the data of the translate template
+type TranslateData struct {
+	SourceLang    string
+	TargetLang    string
+	Terminology   string // terminology dictionary lines ("- term: translation"), empty if none
+	StyleGuide    string // style guide lines of the target language, empty if none
+	Items         []Item
+	HasReferences bool // whether any item has translation memory references
+	HasLimits     bool // whether any item has a length limit
+	HasNotes      bool // whether any item has a reviewer's note
+}
+
+// Item is a text to translate. The model answers with "[Number] translation".
+type Item struct {
+	Number     int // 1-based position in the batch
+	Key        string
+	Text       string
+	References []Reference // translation memory matches, best first
+	Limit      string      // maximum length, e.g. "12 characters"; empty without a limit
+	Note       string      // reviewer's note on how to translate it, empty if none
+}
+
+// Reference is an approved translation of a similar text
+type Reference struct {
+	Source     string
+	Target     string
+	Similarity float64 // 0-1
+}
+
+// ReflectData is the data of the reflect template
+type ReflectData struct {
+	SourceLang      string
+	TargetLang      string
+	PreserveTerms   []string
+	ConsistentTerms []TermTranslation
+	StyleGuide      string
+	Sources         []Text // source texts, sorted by key
+	Translations    []Text // translations, sorted by key
+	Violations      []Violation
+}
+
+// ImproveData is the data of the improve template
+type ImproveData struct {
+	SourceLang   string
+	TargetLang   string
+	StyleGuide   string
+	Sources      []Text
+	Translations []Text
+	Suggestions  []Text // reviewer suggestions, sorted by key
+}
+
+// ShortenData is the data of the shorten template
+type ShortenData struct {
+	SourceLang string
+	TargetLang string
+	StyleGuide string
+	Items      []ShortenItem
+}
+
+// ShortenItem is a translation that is longer than its limit
+type ShortenItem struct {
+	Key         string
+	Source      string
+	Translation string
+	Length      int    // current length, in the unit of the limit
+	Limit       string // e.g. "12 characters" or "20 display columns"
+}
+
+// Text is a keyed text. The model answers with "[Key] text".
+type Text struct {
+	Key  string
+	Text string
+}
+
+// TermTranslation is the agreed translation of a consistent term
+type TermTranslation struct {
+	Term        string
+	Translation string
+	Forbidden   []string // translations that must not be used
+}
+
+// Violation lists the terminology violations found in a translation
+type Violation struct {
+	Key      string
+	Messages []string
+}
+
+// DetectTermsData is the data of the detect-terms template
+type DetectTermsData struct {
+	Language string
+	Count    int    // number of texts
+	Document string // "[N] text" lines
+}
+
+// ValidateTermsData is the data of the validate-terms template
+type ValidateTermsData struct {
+	Language   string
+	Candidates []Candidate
+}
+
+// Candidate is a term candidate found by statistical analysis
+type Candidate struct {
+	Word      string
+	Frequency int
+	Signals   []string // e.g. "acronym", "capitalized"
+	Examples  []string // up to three texts containing the candidate
+}
+
+// TranslateTermsData is the data of the translate-terms template
+type TranslateTermsData struct {
+	SourceLang string
+	TargetLang string
+	Terms      []string
+}
+
+// Example returns example data of a template, used to validate templates and to
+// preview them without a source file
+func Example(name Name) any {
+	style := "- Formality: informal, address the user as du"
+	switch name {
+	case Translate:
+		return TranslateData{
+			SourceLang:  "en",
+			TargetLang:  "de",
+			Terminology: "- API: API (preserve)\n- credits: Guthaben",
+			StyleGuide:  style,
+			Items: []Item{
+				{Number: 1, Key: "app.welcome", Text: "Welcome to {appName}", Note: "Too formal, greet like a friend"},
+				{Number: 2, Key: "billing.buy", Text: "Buy more credits", Limit: "20 characters", References: []Reference{
+					{Source: "Buy credits", Target: "Guthaben kaufen", Similarity: 0.86},
+				}},
+			},
+			HasReferences: true,
+			HasLimits:     true,
+			HasNotes:      true,
+		}
+	case Reflect:
+		return ReflectData{
+			SourceLang:      "en",
+			TargetLang:      "de",
+			PreserveTerms:   []string{"API"},
+			ConsistentTerms: []TermTranslation{{Term: "credits", Translation: "Guthaben", Forbidden: []string{"Credits"}}},
+			StyleGuide:      style,
+			Sources:         []Text{{Key: "billing.buy", Text: "Buy more credits"}},
+			Translations:    []Text{{Key: "billing.buy", Text: "Mehr Credits kaufen"}},
+			Violations:      []Violation{{Key: "billing.buy", Messages: []string{`use "Guthaben" for "credits", not "Credits"`}}},
+		}
+	case Improve:
+		return ImproveData{
+			SourceLang:   "en",
+			TargetLang:   "de",
+			StyleGuide:   style,
+			Sources:      []Text{{Key: "billing.buy", Text: "Buy more credits"}},
+			Translations: []Text{{Key: "billing.buy", Text: "Mehr Credits kaufen"}},
+			Suggestions:  []Text{{Key: "billing.buy", Text: `Use "Guthaben" for "credits".`}},
+		}
+	case Shorten:
+		return ShortenData{
+			SourceLang: "en",
+			TargetLang: "de",
+			StyleGuide: style,
+			Items: []ShortenItem{
+				{Key: "billing.buy", Source: "Buy more credits", Translation: "Mehr Guthaben kaufen", Length: 20, Limit: "16 characters"},
+			},
+		}
+	case DetectTerms:
+		return DetectTermsData{
+			Language: "en",
+			Count:    2,
+			Document: "Total texts: 2\n\n[1] Create an API key\n[2] Buy more credits\n",
+		}
+	case ValidateTerms:
+		return ValidateTermsData{
+			Language: "en",
+			Candidates: []Candidate{
+				{Word: "API", Frequency: 12, Signals: []string{"acronym"}, Examples: []string{"Create an API key"}},
+			},
+		}
+	case TranslateTerms:
+		return TranslateTermsData{SourceLang: "en", TargetLang: "de", Terms: []string{"credits", "workspace"}}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package prompt holds the prompts sent to the model as text/template templates.
+// The defaults are embedded; any of them can be overridden with a <name>.tmpl file
+// in the prompts directory of the terminology directory.
+package prompt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// Name identifies a prompt template
+type Name string
+
+const (
+	Translate      Name = "translate"       // batch translation
+	Reflect        Name = "reflect"         // review of a translated batch
+	Improve        Name = "improve"         // rewrite of a batch using the review
+	Shorten        Name = "shorten"         // shortening of translations over their length limit
+	DetectTerms    Name = "detect-terms"    // term detection on a whole source file
+	ValidateTerms  Name = "validate-terms"  // validation of statistical term candidates
+	TranslateTerms Name = "translate-terms" // translation of consistent terms
+)
+
+// Names lists all prompt templates
+var Names = []Name{Translate, Reflect, Improve, Shorten, DetectTerms, ValidateTerms, TranslateTerms}
+
+//go:embed templates/*.tmpl
+var defaultFS embed.FS
+
+// funcs are the functions available in templates besides the text/template builtins
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"inc":  func(i int) int { return i + 1 },
+	"percent": func(ratio float64) string {
+		return fmt.Sprintf("%.0f%%", ratio*100)
+	},
+}
+
+// Templates is a set of prompt templates
+type Templates struct {
+	templates map[Name]*template.Template
+	sources   map[Name]string
+	paths     map[Name]string // override files; defaults have none
+}
+
+// Default returns the embedded default templates
+var Default = sync.OnceValue(func() *Templates {
+	t := &Templates{
+		templates: make(map[Name]*template.Template),
+		sources:   make(map[Name]string),
+		paths:     make(map[Name]string),
+	}
+	for _, name := range Names {
+		data, err := defaultFS.ReadFile("templates/" + string(name) + ".tmpl")
+		if err != nil {
+			panic(err)
+		}
+		t.templates[name] = template.Must(parse(name, string(data)))
+		t.sources[name] = string(data)
+	}
+	return t
+})
+
+// Dir returns the prompt template directory inside a terminology directory
+func Dir(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "prompts")
+}
+
+// Path returns the file a template is overridden with in a terminology directory
+func Path(terminologyDir string, name Name) string {
+	return filepath.Join(Dir(terminologyDir), string(name)+".tmpl")
+}
+
+// Load returns the default templates with the overrides found in
+// <terminology-dir>/prompts. Each override is checked by rendering it with example data.
+func Load(terminologyDir string) (*Templates, error) {
+	defaults := Default()
+	t := &Templates{
+		templates: make(map[Name]*template.Template, len(Names)),
+		sources:   make(map[Name]string, len(Names)),
+		paths:     make(map[Name]string),
+	}
+	for _, name := range Names {
+		path := Path(terminologyDir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			t.templates[name] = defaults.templates[name]
+			t.sources[name] = defaults.sources[name]
+			continue
+		}
+		if err != nil {
+			return nil, domain.NewIOError("failed to read prompt template", err).
+				WithContext("path", path)
+		}
+
+		tmpl, err := parse(name, string(data))
+		if err == nil && strings.TrimSpace(string(data)) == "" {
+			err = errors.New("template is empty")
+		}
+		if err == nil {
+			err = tmpl.Execute(&bytes.Buffer{}, Example(name))
+		}
+		if err != nil {
+			return nil, domain.NewFormatError("invalid prompt template", err).
+				WithContext("path", path)
+		}
+		t.templates[name] = tmpl
+		t.sources[name] = string(data)
+		t.paths[name] = path
+	}
+	return t, nil
+}
+
+// parse parses a template with the prompt functions
+func parse(name Name, text string) (*template.Template, error) {
+	return template.New(string(name)).Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+// Render renders a template. Trailing whitespace is dropped, so template files
+// may end with a newline.
+func (t *Templates) Render(name Name, data any) (string, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return "", domain.NewValidationError("unknown prompt template", nil).
+			WithContext("name", string(name))
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", domain.NewFormatError("failed to render prompt template", err).
+			WithContext("name", string(name))
+	}
+	return strings.TrimRight(buf.String(), " \t\r\n"), nil
+}
+
+// Text returns the source of a template
+func (t *Templates) Text(name Name) string {
+	return t.sources[name]
+}
+
+// Source returns the file a template was loaded from, or "" for the default
+func (t *Templates) Source(name Name) string {
+	return t.paths[name]
+}
+
+// ParseName parses a template name
+func ParseName(s string) (Name, error) {
+	for _, name := range Names {
+		if string(name) == s {
+			return name, nil
+		}
+	}
+	return "", domain.NewValidationError("unknown prompt template", nil).
+		WithContext("name", s)
+}
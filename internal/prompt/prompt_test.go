@@ -0,0 +1,164 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func TestDefault_RendersExamples(t *testing.T) {
+	for _, name := range Names {
+		text, err := Default().Render(name, Example(name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if text == "" || strings.HasSuffix(text, "\n") {
+			t.Errorf("%s: rendered %q, want non-empty text without trailing newline", name, text)
+		}
+		if Default().Source(name) != "" {
+			t.Errorf("%s: default template should have no source file", name)
+		}
+	}
+}
+
+func TestDefault_Translate(t *testing.T) {
+	text, err := Default().Render(Translate, Example(Translate))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"Translate the following en texts to de",
+		"【Terminology Dictionary】\n- API: API (preserve)\n- credits: Guthaben\n\n",
+		"【Style Guide】\n- Formality: informal, address the user as du\n\n",
+		`[2] "Buy credits" → "Guthaben kaufen" (86% match)`,
+		"(e.g., {variable}, {{count}})",
+		"6. ✍️ Follow the style guide",
+		"【Reviewer Notes】\nA reviewer rejected earlier translations of these texts. Follow their notes:\n[1] Too formal, greet like a friend\n",
+		"【Texts to Translate】\n[1] Welcome to {appName}\n[2] Buy more credits\n\n【Translation Results】",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("translate prompt missing %q:\n%s", want, text)
+		}
+	}
+
+	// Optional sections disappear without data
+	text, err = Default().Render(Translate, TranslateData{
+		SourceLang: "en",
+		TargetLang: "de",
+		Items:      []Item{{Number: 1, Key: "a", Text: "Save"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unwanted := range []string{"Terminology Dictionary", "Style Guide", "Translation Memory", "Reviewer Notes", "6. "} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("translate prompt should not contain %q:\n%s", unwanted, text)
+		}
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(Dir(dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := "Translate to {{.TargetLang}}:\n{{range .Items}}[{{.Number}}] {{.Text}}\n{{end}}"
+	if err := os.WriteFile(Path(dir, Translate), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if templates.Source(Translate) != Path(dir, Translate) || templates.Text(Translate) != override {
+		t.Errorf("translate template not loaded from %s", Path(dir, Translate))
+	}
+	if templates.Source(Reflect) != "" || templates.Text(Reflect) != Default().Text(Reflect) {
+		t.Error("templates without an override should be the defaults")
+	}
+
+	text, err := templates.Render(Translate, TranslateData{TargetLang: "fr", Items: []Item{{Number: 1, Text: "Save"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Translate to fr:\n[1] Save" {
+		t.Errorf("Render() = %q", text)
+	}
+}
+
+func TestLoad_NoDirectory(t *testing.T) {
+	templates, err := Load(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range Names {
+		if templates.Text(name) != Default().Text(name) {
+			t.Errorf("%s: expected the default template", name)
+		}
+	}
+}
+
+func TestLoad_InvalidTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{"syntax error", "{{if .TargetLang}}unclosed"},
+		{"unknown variable", "Translate to {{.Language}}"},
+		{"unknown function", "{{upper .TargetLang}}"},
+		{"empty", "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(Dir(dir), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(Path(dir, Translate), []byte(tt.template), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(dir)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !domain.IsErrorType(err, domain.ErrorTypeFormat) {
+				t.Errorf("expected a format error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRender_UnknownTemplate(t *testing.T) {
+	if _, err := Default().Render("summary", nil); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestParseName(t *testing.T) {
+	for _, name := range Names {
+		if got, err := ParseName(string(name)); err != nil || got != name {
+			t.Errorf("ParseName(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseName("translation"); err == nil {
+		t.Error("expected an error for an unknown name")
+	}
+}
+
+func TestDefault_Shorten(t *testing.T) {
+	text, err := Default().Render(Shorten, Example(Shorten))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[billing.buy] Mehr Guthaben kaufen\n    source: Buy more credits\n    limit: 16 characters (currently 20)\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("shorten prompt missing %q:\n%s", want, text)
+	}
+}
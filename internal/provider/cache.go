@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// DefaultCacheTTL is how long cached responses are reused by default
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// CacheStats contains counters collected by a caching provider
+type CacheStats struct {
+	Hits   int
+	Misses int
+}
+
+// cacheEntry is the on-disk representation of a cached response
+type cacheEntry struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Provider  string             `json:"provider"`
+	Model     string             `json:"model"`
+	Response  CompletionResponse `json:"response"`
+}
+
+// ResponseCache stores completion responses on disk, one file per request.
+// Files live under <dir>/<first two hash chars>/<hash>.json.
+type ResponseCache struct {
+	dir string
+	ttl time.Duration // 0 = entries never expire
+}
+
+// NewResponseCache creates a response cache rooted at dir
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{dir: dir, ttl: ttl}
+}
+
+// CacheKey returns the cache key for a request sent to the given provider and model
+func CacheKey(providerName, model string, req *CompletionRequest) string {
+	h := sha256.New()
+	for _, part := range []string{providerName, model, req.SystemMsg, req.Prompt} {
+		// JSON-encode each part so field boundaries are unambiguous
+		_ = json.NewEncoder(h).Encode(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the file that stores the entry for key
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the cached response for key if present and not expired
+func (c *ResponseCache) Get(key string) (*CompletionResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if c.expired(entry.CreatedAt, time.Now()) {
+		return nil, false
+	}
+
+	return &entry.Response, true
+}
+
+// Put stores a response under key
+func (c *ResponseCache) Put(key, providerName, model string, resp *CompletionResponse) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return domain.NewIOError("failed to create cache directory", err).
+			WithContext("path", path)
+	}
+
+	data, err := json.Marshal(cacheEntry{
+		CreatedAt: time.Now(),
+		Provider:  providerName,
+		Model:     model,
+		Response:  *resp,
+	})
+	if err != nil {
+		return domain.NewFormatError("failed to marshal cache entry", err)
+	}
+
+	// Write to a temp file first so concurrent readers never see partial entries
+	tmp, err := os.CreateTemp(filepath.Dir(path), "entry-*.tmp")
+	if err != nil {
+		return domain.NewIOError("failed to write cache entry", err).
+			WithContext("path", path)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		_ = os.Remove(tmp.Name())
+		return domain.NewIOError("failed to write cache entry", err).
+			WithContext("path", path)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return domain.NewIOError("failed to write cache entry", err).
+			WithContext("path", path)
+	}
+
+	return nil
+}
+
+// Delete removes the entry for key, if any
+func (c *ResponseCache) Delete(key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return domain.NewIOError("failed to delete cache entry", err).
+			WithContext("path", c.path(key))
+	}
+	return nil
+}
+
+// Prune removes expired entries (or all entries when all is true) and
+// returns how many were removed
+func (c *ResponseCache) Prune(all bool) (int, error) {
+	now := time.Now()
+	removed := 0
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		if !all {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var entry cacheEntry
+			// Unreadable entries are removed as well
+			if json.Unmarshal(data, &entry) == nil && !c.expired(entry.CreatedAt, now) {
+				return nil
+			}
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, domain.NewIOError("failed to prune cache", err).
+			WithContext("dir", c.dir)
+	}
+
+	return removed, nil
+}
+
+func (c *ResponseCache) expired(createdAt, now time.Time) bool {
+	return c.ttl > 0 && now.Sub(createdAt) > c.ttl
+}
+
+// CachingProvider is an AIProvider decorator that serves repeated requests from a ResponseCache.
+// Cached responses report zero usage, since nothing was billed for them.
+type CachingProvider struct {
+	inner  AIProvider
+	cache  *ResponseCache
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewCachingProvider wraps a provider with a response cache
+func NewCachingProvider(inner AIProvider, cache *ResponseCache) *CachingProvider {
+	return &CachingProvider{
+		inner: inner,
+		cache: cache,
+	}
+}
+
+// model returns the model a request is sent to
+func (p *CachingProvider) model(req *CompletionRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return p.inner.GetModelName()
+}
+
+// Complete implements AIProvider interface
+func (p *CachingProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	model := p.model(req)
+	key := CacheKey(p.inner.Name(), model, req)
+
+	if resp, ok := p.cache.Get(key); ok {
+		p.hits.Add(1)
+		resp.Usage = Usage{}
+		resp.Cached = true
+		return resp, nil
+	}
+
+	p.misses.Add(1)
+	resp, err := p.inner.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed write only costs a future cache miss
+	_ = p.cache.Put(key, p.inner.Name(), model, resp)
+
+	return resp, nil
+}
+
+// Invalidate forgets the cached response to a request, so asking again reaches the model
+func (p *CachingProvider) Invalidate(req *CompletionRequest) {
+	// A failed removal only means the bad response is served once more
+	_ = p.cache.Delete(CacheKey(p.inner.Name(), p.model(req), req))
+}
+
+// Invalidate tells a provider that its response to a request was unusable, e.g. it
+// could not be parsed. A caching provider forgets it, so a retry doesn't get it back.
+func Invalidate(p AIProvider, req *CompletionRequest) {
+	if c, ok := p.(interface{ Invalidate(req *CompletionRequest) }); ok {
+		c.Invalidate(req)
+	}
+}
+
+// Stats returns the cache hit and miss counts so far
+func (p *CachingProvider) Stats() CacheStats {
+	return CacheStats{
+		Hits:   int(p.hits.Load()),
+		Misses: int(p.misses.Load()),
+	}
+}
+
+// Name implements AIProvider interface
+func (p *CachingProvider) Name() string {
+	return p.inner.Name()
+}
+
+// GetModelName implements AIProvider interface
+func (p *CachingProvider) GetModelName() string {
+	return p.inner.GetModelName()
+}
+
+// ValidateConfig implements AIProvider interface
+func (p *CachingProvider) ValidateConfig() error {
+	return p.inner.ValidateConfig()
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	req := &CompletionRequest{Prompt: "hello", SystemMsg: "sys"}
+	key := CacheKey("openai", "gpt-5", req)
+
+	if key != CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "hello", SystemMsg: "sys"}) {
+		t.Error("CacheKey() should be deterministic")
+	}
+
+	others := []string{
+		CacheKey("anthropic", "gpt-5", req),
+		CacheKey("openai", "gpt-5-mini", req),
+		CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "hello"}),
+		CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "hello!", SystemMsg: "sys"}),
+		// Moving text between fields must change the key
+		CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "syshello"}),
+	}
+	for i, other := range others {
+		if other == key {
+			t.Errorf("CacheKey() variant %d collides with base key", i)
+		}
+	}
+}
+
+func TestCachingProvider(t *testing.T) {
+	mock := NewMockProvider("gpt-5")
+	mock.AddResponse("translated")
+
+	p := NewCachingProvider(mock, NewResponseCache(t.TempDir(), time.Hour))
+	req := &CompletionRequest{Prompt: "translate me"}
+
+	first, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if first.Cached || first.Usage.TotalTokens == 0 {
+		t.Errorf("first response = %+v, want uncached with usage", first)
+	}
+
+	// Second identical call is served from disk; the mock has no responses left
+	second, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() cached error = %v", err)
+	}
+	if !second.Cached || second.Content != "translated" || second.Usage.TotalTokens != 0 {
+		t.Errorf("second response = %+v, want cached copy without usage", second)
+	}
+
+	if got := p.Stats(); got.Hits != 1 || got.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit, 1 miss", got)
+	}
+	if mock.GetCallCount() != 1 {
+		t.Errorf("inner calls = %d, want 1", mock.GetCallCount())
+	}
+
+	// Errors are not cached
+	mock.SetError("boom")
+	if _, err := p.Complete(context.Background(), &CompletionRequest{Prompt: "other"}); err == nil {
+		t.Error("Complete() should return inner errors")
+	}
+}
+
+func TestCachingProvider_Invalidate(t *testing.T) {
+	mock := NewMockProvider("gpt-5")
+	mock.AddResponse("garbled")
+	mock.AddResponse("translated")
+
+	p := NewCachingProvider(mock, NewResponseCache(t.TempDir(), time.Hour))
+	req := &CompletionRequest{Prompt: "translate me"}
+
+	if _, err := p.Complete(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	Invalidate(p, req)
+
+	// The unusable response is gone: asking again reaches the model
+	resp, err := p.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Cached || resp.Content != "translated" {
+		t.Errorf("response after Invalidate() = %+v", resp)
+	}
+	// Invalidating an uncached request and a provider without a cache is harmless
+	Invalidate(p, &CompletionRequest{Prompt: "never sent"})
+	Invalidate(mock, req)
+}
+
+func TestResponseCache_TTLAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewResponseCache(dir, time.Hour)
+
+	fresh := CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "fresh"})
+	stale := CacheKey("openai", "gpt-5", &CompletionRequest{Prompt: "stale"})
+	for _, key := range []string{fresh, stale} {
+		if err := cache.Put(key, "openai", "gpt-5", &CompletionResponse{Content: key}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	// Age the stale entry past the TTL
+	path := cache.path(stale)
+	data, _ := os.ReadFile(path)
+	var entry cacheEntry
+	_ = json.Unmarshal(data, &entry)
+	entry.CreatedAt = time.Now().Add(-2 * time.Hour)
+	data, _ = json.Marshal(entry)
+	_ = os.WriteFile(path, data, 0644)
+
+	if _, ok := cache.Get(stale); ok {
+		t.Error("Get() returned an expired entry")
+	}
+	if _, ok := cache.Get(fresh); !ok {
+		t.Error("Get() missed a fresh entry")
+	}
+
+	removed, err := cache.Prune(false)
+	if err != nil || removed != 1 {
+		t.Errorf("Prune(false) = %d, %v, want 1, nil", removed, err)
+	}
+	removed, err = cache.Prune(true)
+	if err != nil || removed != 1 {
+		t.Errorf("Prune(true) = %d, %v, want 1, nil", removed, err)
+	}
+
+	// Pruning a cache that was never written is a no-op
+	if removed, err := NewResponseCache(filepath.Join(dir, "missing"), 0).Prune(true); err != nil || removed != 0 {
+		t.Errorf("Prune() on missing dir = %d, %v", removed, err)
+	}
+}
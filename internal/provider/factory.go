@@ -15,13 +15,16 @@ const (
 	ProviderTypeOpenAI    ProviderType = "openai"
 	ProviderTypeAnthropic ProviderType = "anthropic"
 	ProviderTypeGemini    ProviderType = "gemini"
+	// ProviderTypeReplay answers from a recorded cassette (offline, for tests)
+	ProviderTypeReplay ProviderType = "replay"
 )
 
 // ProviderConfig holds the configuration for creating a provider
 type ProviderConfig struct {
-	Type   ProviderType
-	APIKey string
-	Model  string
+	Type     ProviderType
+	APIKey   string
+	Model    string
+	Cassette string // cassette file, required for the replay provider
 }
 
 // NewProvider creates a new AI provider based on the configuration
@@ -42,6 +45,20 @@ func NewProvider(ctx context.Context, config *ProviderConfig) (AIProvider, error
 	case ProviderTypeGemini:
 		return NewGeminiProvider(ctx, config.APIKey, modelName)
 
+	case ProviderTypeReplay:
+		if config.Cassette == "" {
+			return nil, domain.NewConfigError("replay provider requires a cassette file", nil).
+				WithContext("provider", "replay")
+		}
+		cassette, err := LoadCassette(config.Cassette)
+		if err != nil {
+			return nil, err
+		}
+		if config.Model != "" {
+			cassette.Model = config.Model
+		}
+		return NewReplayProvider(cassette), nil
+
 	default:
 		return nil, domain.NewValidationError(fmt.Sprintf("unsupported provider type: %s", config.Type), nil).
 			WithContext("provider_type", string(config.Type))
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// ModelPricing is the price of a model in USD per million tokens
+type ModelPricing struct {
+	InputPerMillion  float64 `json:"input"`
+	OutputPerMillion float64 `json:"output"`
+}
+
+// Cost returns the USD cost of the given usage
+func (p ModelPricing) Cost(usage Usage) float64 {
+	return (float64(usage.PromptTokens)*p.InputPerMillion +
+		float64(usage.CompletionTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+// PricingTable maps model names to prices
+type PricingTable map[string]ModelPricing
+
+// DefaultPricing returns list prices for the supported models (USD per million tokens).
+// Prices change; override them with a pricing file when accuracy matters.
+func DefaultPricing() PricingTable {
+	return PricingTable{
+		// OpenAI
+		"gpt-5":       {InputPerMillion: 1.25, OutputPerMillion: 10},
+		"gpt-5-mini":  {InputPerMillion: 0.25, OutputPerMillion: 2},
+		"gpt-5-nano":  {InputPerMillion: 0.05, OutputPerMillion: 0.4},
+		"gpt-5-pro":   {InputPerMillion: 15, OutputPerMillion: 120},
+		"gpt-4o":      {InputPerMillion: 2.5, OutputPerMillion: 10},
+		"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+
+		// Anthropic
+		"claude-sonnet-4-5":          {InputPerMillion: 3, OutputPerMillion: 15},
+		"claude-haiku-4-5":           {InputPerMillion: 1, OutputPerMillion: 5},
+		"claude-opus-4-1":            {InputPerMillion: 15, OutputPerMillion: 75},
+		"claude-sonnet-4-0":          {InputPerMillion: 3, OutputPerMillion: 15},
+		"claude-3-5-sonnet-20250116": {InputPerMillion: 3, OutputPerMillion: 15},
+
+		// Gemini
+		"gemini-2.5-flash":      {InputPerMillion: 0.3, OutputPerMillion: 2.5},
+		"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10},
+		"gemini-2.5-flash-lite": {InputPerMillion: 0.1, OutputPerMillion: 0.4},
+		"gemini-2.0-flash-exp":  {InputPerMillion: 0.1, OutputPerMillion: 0.4},
+	}
+}
+
+// LoadPricingTable loads price overrides from a JSON file and merges them over the defaults.
+// The file maps model names to {"input": ..., "output": ...} prices per million tokens.
+func LoadPricingTable(path string) (PricingTable, error) {
+	table := DefaultPricing()
+	if path == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, domain.NewIOError("failed to read pricing file", err).
+			WithContext("path", path)
+	}
+
+	var overrides PricingTable
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, domain.NewConfigError("failed to parse pricing file", err).
+			WithContext("path", path)
+	}
+
+	maps.Copy(table, overrides)
+	return table, nil
+}
+
+// Lookup returns the pricing for a model, falling back to the longest matching
+// prefix so dated snapshots (e.g. "gpt-4o-2024-08-06") resolve to their family
+func (t PricingTable) Lookup(model string) (ModelPricing, bool) {
+	if pricing, ok := t[model]; ok {
+		return pricing, true
+	}
+
+	best := ""
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPricing{}, false
+	}
+	return t[best], true
+}
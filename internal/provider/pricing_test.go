@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestModelPricing_Cost(t *testing.T) {
+	p := ModelPricing{InputPerMillion: 2, OutputPerMillion: 10}
+	got := p.Cost(Usage{PromptTokens: 500_000, CompletionTokens: 100_000})
+	if math.Abs(got-2.0) > 1e-9 {
+		t.Errorf("Cost() = %v, want 2.0", got)
+	}
+}
+
+func TestPricingTable_Lookup(t *testing.T) {
+	table := DefaultPricing()
+
+	if _, ok := table.Lookup("gpt-5"); !ok {
+		t.Error("Lookup(gpt-5) should find exact match")
+	}
+
+	// Dated snapshot resolves to the longest matching family
+	got, ok := table.Lookup("gpt-4o-mini-2024-07-18")
+	if !ok || got != table["gpt-4o-mini"] {
+		t.Errorf("Lookup(gpt-4o-mini-2024-07-18) = %v, %v, want gpt-4o-mini pricing", got, ok)
+	}
+
+	if _, ok := table.Lookup("unknown-model"); ok {
+		t.Error("Lookup(unknown-model) should not match")
+	}
+}
+
+func TestLoadPricingTable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pricing.json")
+	content := `{"gpt-5": {"input": 9, "output": 99}, "my-model": {"input": 1, "output": 2}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := LoadPricingTable(path)
+	if err != nil {
+		t.Fatalf("LoadPricingTable() error = %v", err)
+	}
+	if table["gpt-5"].InputPerMillion != 9 {
+		t.Errorf("override not applied: %v", table["gpt-5"])
+	}
+	if _, ok := table["my-model"]; !ok {
+		t.Error("custom model missing")
+	}
+	if _, ok := table["gemini-2.5-pro"]; !ok {
+		t.Error("defaults should be kept")
+	}
+
+	// Empty path returns defaults
+	if table, err := LoadPricingTable(""); err != nil || len(table) != len(DefaultPricing()) {
+		t.Errorf("LoadPricingTable(\"\") = %d entries, %v", len(table), err)
+	}
+
+	// Invalid files fail
+	bad := filepath.Join(dir, "bad.json")
+	_ = os.WriteFile(bad, []byte("{"), 0644)
+	if _, err := LoadPricingTable(bad); err == nil {
+		t.Error("LoadPricingTable() should fail on invalid JSON")
+	}
+	if _, err := LoadPricingTable(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadPricingTable() should fail on missing file")
+	}
+}
@@ -16,6 +16,7 @@ type CompletionResponse struct {
 	Content      string
 	FinishReason string
 	Usage        Usage
+	Cached       bool // served from the response cache (no tokens were billed)
 }
 
 // Usage represents token usage information
@@ -0,0 +1,506 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+const (
+	// defaultRateLimitRetries is how many times a rate-limited call is retried
+	// by the limiter before the error is returned to the caller
+	defaultRateLimitRetries = 3
+
+	// defaultRateLimitBackoff is used when a 429 carries no retry hint
+	defaultRateLimitBackoff = 2 * time.Second
+)
+
+// RateLimits configures the shared budget for a provider+model pair
+type RateLimits struct {
+	RequestsPerMinute int // 0 = unlimited
+	TokensPerMinute   int // 0 = unlimited
+	MaxConcurrency    int // upper bound for in-flight requests (0 = unlimited)
+}
+
+// RateLimitStats contains counters collected by a rate limiter
+type RateLimitStats struct {
+	RateLimitHits int           // number of 429 responses observed
+	Retries       int           // number of retries performed by the limiter
+	WaitTime      time.Duration // total time callers spent waiting for budget
+	Concurrency   int           // current adaptive concurrency limit
+}
+
+// ErrRateLimitRetried marks a rate-limit error the limiter already retried, so callers
+// with retries of their own don't retry it again
+var ErrRateLimitRetried = errors.New("rate limit retries exhausted")
+
+// RateLimitInfo describes rate-limit hints extracted from a provider error
+type RateLimitInfo struct {
+	RateLimited bool
+	RetryAfter  time.Duration
+}
+
+// RateLimiter enforces requests/min and tokens/min budgets with adaptive concurrency.
+// Concurrency is halved on a 429 (once per Retry-After pause: the other calls in flight
+// hit the same limit) and recovers by one slot after a full window of successful
+// calls (AIMD).
+type RateLimiter struct {
+	mu sync.Mutex
+
+	limits   RateLimits
+	requests *tokenBucket
+	tokens   *tokenBucket
+
+	concurrency int
+	inFlight    int
+	successes   int
+	pausedUntil time.Time
+	changed     chan struct{}
+
+	stats RateLimitStats
+}
+
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	rl := &RateLimiter{
+		limits:      limits,
+		concurrency: limits.MaxConcurrency,
+		changed:     make(chan struct{}),
+	}
+	if limits.RequestsPerMinute > 0 {
+		rl.requests = newTokenBucket(float64(limits.RequestsPerMinute))
+	}
+	if limits.TokensPerMinute > 0 {
+		rl.tokens = newTokenBucket(float64(limits.TokensPerMinute))
+	}
+	return rl
+}
+
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = make(map[string]*RateLimiter)
+)
+
+// SharedRateLimiter returns the process-wide limiter for a provider+model pair.
+// The limits passed by the first caller win; later callers share the same budget.
+func SharedRateLimiter(providerName, model string, limits RateLimits) *RateLimiter {
+	key := providerName + "/" + model
+
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+
+	if rl, ok := sharedLimiters[key]; ok {
+		return rl
+	}
+	rl := NewRateLimiter(limits)
+	sharedLimiters[key] = rl
+	return rl
+}
+
+// Acquire blocks until a request slot and the estimated token budget are available
+func (rl *RateLimiter) Acquire(ctx context.Context, estimatedTokens int) error {
+	start := time.Now()
+	defer func() {
+		rl.mu.Lock()
+		rl.stats.WaitTime += time.Since(start)
+		rl.mu.Unlock()
+	}()
+
+	// Wait for a concurrency slot (and for any global 429 pause to end)
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if now.Before(rl.pausedUntil) {
+			wait = rl.pausedUntil.Sub(now)
+		} else if rl.concurrency <= 0 || rl.inFlight < rl.concurrency {
+			rl.inFlight++
+			rl.mu.Unlock()
+			break
+		}
+		changed := rl.changed
+		rl.mu.Unlock()
+
+		if err := WaitFor(ctx, wait, changed); err != nil {
+			return err
+		}
+	}
+
+	// Reserve request and token budget
+	rl.mu.Lock()
+	var wait time.Duration
+	if rl.requests != nil {
+		wait = max(wait, rl.requests.reserve(1, time.Now()))
+	}
+	if rl.tokens != nil && estimatedTokens > 0 {
+		wait = max(wait, rl.tokens.reserve(float64(estimatedTokens), time.Now()))
+	}
+	rl.mu.Unlock()
+
+	if wait > 0 {
+		if err := WaitFor(ctx, wait, nil); err != nil {
+			// The request is not sent: others may use what it reserved
+			rl.mu.Lock()
+			if rl.requests != nil {
+				rl.requests.refund(1)
+			}
+			if rl.tokens != nil && estimatedTokens > 0 {
+				rl.tokens.refund(float64(estimatedTokens))
+			}
+			rl.mu.Unlock()
+			rl.release()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Release returns a slot and reconciles the token estimate with actual usage
+func (rl *RateLimiter) Release(estimatedTokens, actualTokens int, info RateLimitInfo) {
+	rl.mu.Lock()
+	if rl.tokens != nil && actualTokens > 0 {
+		rl.tokens.adjust(float64(actualTokens - estimatedTokens))
+	}
+
+	if info.RateLimited {
+		rl.stats.RateLimitHits++
+		rl.successes = 0
+		now := time.Now()
+		// 429s arriving during the pause of an earlier one were sent before it
+		if !now.Before(rl.pausedUntil) {
+			if rl.concurrency > 1 {
+				rl.concurrency /= 2
+			} else if rl.concurrency == 0 && rl.inFlight > 1 {
+				// Unlimited concurrency: clamp to what was in flight, then halve
+				rl.concurrency = max(1, rl.inFlight/2)
+			}
+		}
+		backoff := info.RetryAfter
+		if backoff <= 0 {
+			backoff = defaultRateLimitBackoff
+		}
+		if until := now.Add(backoff); until.After(rl.pausedUntil) {
+			rl.pausedUntil = until
+		}
+	} else {
+		rl.successes++
+		limit := rl.limits.MaxConcurrency
+		if rl.concurrency > 0 && rl.successes >= rl.concurrency && (limit == 0 || rl.concurrency < limit) {
+			rl.concurrency++
+			rl.successes = 0
+		}
+	}
+	rl.mu.Unlock()
+
+	rl.release()
+}
+
+// Stats returns a snapshot of limiter counters
+func (rl *RateLimiter) Stats() RateLimitStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	stats := rl.stats
+	stats.Concurrency = rl.concurrency
+	return stats
+}
+
+func (rl *RateLimiter) release() {
+	rl.mu.Lock()
+	rl.inFlight--
+	close(rl.changed)
+	rl.changed = make(chan struct{})
+	rl.mu.Unlock()
+}
+
+func (rl *RateLimiter) recordRetry() {
+	rl.mu.Lock()
+	rl.stats.Retries++
+	rl.mu.Unlock()
+}
+
+// WaitFor sleeps for d (or until changed is closed when d is zero) honoring ctx
+func WaitFor(ctx context.Context, d time.Duration, changed <-chan struct{}) error {
+	var timer <-chan time.Time
+	if d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timer = t.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+	case <-changed:
+	}
+	return nil
+}
+
+// tokenBucket is a per-minute budget refilled continuously.
+// Reservations may drive the balance negative; callers wait until it recovers.
+type tokenBucket struct {
+	capacity float64
+	balance  float64
+	rate     float64 // units per second
+	last     time.Time
+}
+
+func newTokenBucket(perMinute float64) *tokenBucket {
+	return &tokenBucket{
+		capacity: perMinute,
+		balance:  perMinute,
+		rate:     perMinute / 60,
+		last:     time.Now(),
+	}
+}
+
+// reserve takes n units and returns how long the caller must wait before using them
+func (b *tokenBucket) reserve(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	n = min(n, b.capacity)
+	b.balance -= n
+	if b.balance >= 0 {
+		return 0
+	}
+	return time.Duration(-b.balance / b.rate * float64(time.Second))
+}
+
+// refund gives back a reservation of n units that was not used
+func (b *tokenBucket) refund(n float64) {
+	b.adjust(-min(n, b.capacity))
+}
+
+// adjust debits (or credits, if negative) the bucket after the actual cost is known
+func (b *tokenBucket) adjust(delta float64) {
+	b.balance = min(b.balance-delta, b.capacity)
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.balance = min(b.capacity, b.balance+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// RateLimitedProvider wraps an AIProvider with a shared rate limiter
+type RateLimitedProvider struct {
+	inner      AIProvider
+	limiter    *RateLimiter
+	maxRetries int
+}
+
+// NewRateLimitedProvider wraps a provider with the shared limiter for its provider+model
+func NewRateLimitedProvider(inner AIProvider, limits RateLimits) *RateLimitedProvider {
+	return &RateLimitedProvider{
+		inner:      inner,
+		limiter:    SharedRateLimiter(inner.Name(), inner.GetModelName(), limits),
+		maxRetries: defaultRateLimitRetries,
+	}
+}
+
+// Complete waits for budget, calls the wrapped provider and retries on 429. A 429 still
+// failing after the retries is returned wrapped in ErrRateLimitRetried.
+func (p *RateLimitedProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	estimated := EstimateRequestTokens(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := p.limiter.Acquire(ctx, estimated); err != nil {
+			return nil, err
+		}
+
+		resp, err := p.inner.Complete(ctx, req)
+
+		actual := 0
+		if resp != nil {
+			actual = resp.Usage.TotalTokens
+		}
+		info := ParseRateLimitInfo(err)
+		p.limiter.Release(estimated, actual, info)
+
+		if err == nil || !info.RateLimited {
+			return resp, err
+		}
+		if attempt >= p.maxRetries {
+			return resp, fmt.Errorf("%w: %w", ErrRateLimitRetried, err)
+		}
+		p.limiter.recordRetry()
+	}
+}
+
+// Name returns the wrapped provider name
+func (p *RateLimitedProvider) Name() string {
+	return p.inner.Name()
+}
+
+// GetModelName returns the wrapped provider model name
+func (p *RateLimitedProvider) GetModelName() string {
+	return p.inner.GetModelName()
+}
+
+// ValidateConfig validates the wrapped provider configuration
+func (p *RateLimitedProvider) ValidateConfig() error {
+	return p.inner.ValidateConfig()
+}
+
+// Limiter returns the shared limiter used by this provider
+func (p *RateLimitedProvider) Limiter() *RateLimiter {
+	return p.limiter
+}
+
+// EstimateRequestTokens roughly estimates the total tokens a request will consume.
+// Translation output is about as long as its input, so the prompt estimate is doubled
+// unless MaxTokens bounds the completion.
+func EstimateRequestTokens(req *CompletionRequest) int {
+	prompt := (len(req.Prompt) + len(req.SystemMsg)) / 4
+	if req.MaxTokens > 0 {
+		return prompt + req.MaxTokens
+	}
+	return prompt * 2
+}
+
+// RetryAfter returns the retry delay suggested by a rate-limit error, if any
+func RetryAfter(err error) (time.Duration, bool) {
+	info := ParseRateLimitInfo(err)
+	if !info.RateLimited || info.RetryAfter <= 0 {
+		return 0, false
+	}
+	return info.RetryAfter, true
+}
+
+// ParseRateLimitInfo inspects an error chain for SDK rate-limit errors and their headers
+func ParseRateLimitInfo(err error) RateLimitInfo {
+	if err == nil {
+		return RateLimitInfo{}
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return rateLimitInfoFromHTTP(openaiErr.StatusCode, openaiErr.Response)
+	}
+
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return rateLimitInfoFromHTTP(anthropicErr.StatusCode, anthropicErr.Response)
+	}
+
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		if geminiErr.Code != http.StatusTooManyRequests {
+			return RateLimitInfo{}
+		}
+		return RateLimitInfo{RateLimited: true, RetryAfter: geminiRetryDelay(geminiErr.Details)}
+	}
+
+	return RateLimitInfo{}
+}
+
+func rateLimitInfoFromHTTP(statusCode int, resp *http.Response) RateLimitInfo {
+	if statusCode == 0 && resp != nil {
+		statusCode = resp.StatusCode
+	}
+	if statusCode != http.StatusTooManyRequests {
+		return RateLimitInfo{}
+	}
+
+	info := RateLimitInfo{RateLimited: true}
+	if resp != nil {
+		info.RetryAfter = parseRateLimitHeaders(resp.Header, time.Now())
+	}
+	return info
+}
+
+// parseRateLimitHeaders extracts the retry delay from Retry-After and x-ratelimit-* headers
+func parseRateLimitHeaders(h http.Header, now time.Time) time.Duration {
+	// retry-after-ms is more precise than Retry-After when both are present
+	if v := h.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			return t.Sub(now)
+		}
+	}
+
+	// OpenAI: x-ratelimit-reset-{requests,tokens} as durations ("1s", "6m0s")
+	// Anthropic: anthropic-ratelimit-{requests,tokens}-reset as RFC 3339 timestamps
+	var wait time.Duration
+	for _, name := range []string{
+		"x-ratelimit-reset-requests",
+		"x-ratelimit-reset-tokens",
+		"anthropic-ratelimit-requests-reset",
+		"anthropic-ratelimit-tokens-reset",
+		"anthropic-ratelimit-input-tokens-reset",
+		"anthropic-ratelimit-output-tokens-reset",
+	} {
+		if !exhausted(h, name) {
+			continue
+		}
+		wait = max(wait, parseResetValue(h.Get(name), now))
+	}
+
+	return wait
+}
+
+// exhausted reports whether the "remaining" counterpart of a reset header is zero.
+// When no remaining header is sent the reset is assumed relevant.
+func exhausted(h http.Header, resetHeader string) bool {
+	var remaining string
+	switch {
+	case strings.HasPrefix(resetHeader, "x-ratelimit-reset-"):
+		remaining = "x-ratelimit-remaining-" + strings.TrimPrefix(resetHeader, "x-ratelimit-reset-")
+	case strings.HasSuffix(resetHeader, "-reset"):
+		remaining = strings.TrimSuffix(resetHeader, "-reset") + "-remaining"
+	}
+
+	v := h.Get(remaining)
+	if v == "" {
+		return true
+	}
+	n, err := strconv.Atoi(v)
+	return err != nil || n <= 0
+}
+
+func parseResetValue(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// geminiRetryDelay reads google.rpc.RetryInfo from Gemini error details
+func geminiRetryDelay(details []map[string]any) time.Duration {
+	for _, detail := range details {
+		if t, _ := detail["@type"].(string); !strings.HasSuffix(t, "google.rpc.RetryInfo") {
+			continue
+		}
+		if delay, ok := detail["retryDelay"].(string); ok {
+			if d, err := time.ParseDuration(delay); err == nil {
+				return d
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,323 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// rateLimitedStub fails with a 429 for the first n calls, then succeeds
+type rateLimitedStub struct {
+	failures int
+	calls    int
+	header   http.Header
+}
+
+func (s *rateLimitedStub) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, domain.NewProviderError("OpenAI API call failed", newOpenAI429(s.header))
+	}
+	return &CompletionResponse{Content: "ok", Usage: Usage{TotalTokens: 10}}, nil
+}
+
+func (s *rateLimitedStub) Name() string          { return "stub" }
+func (s *rateLimitedStub) GetModelName() string  { return "stub-model" }
+func (s *rateLimitedStub) ValidateConfig() error { return nil }
+
+func newOpenAI429(header http.Header) *openai.Error {
+	return &openai.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Response:   &http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+	}
+}
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{
+			name:   "retry-after seconds",
+			header: http.Header{"Retry-After": []string{"7"}},
+			want:   7 * time.Second,
+		},
+		{
+			name:   "retry-after-ms wins",
+			header: http.Header{"Retry-After": []string{"7"}, "Retry-After-Ms": []string{"1500"}},
+			want:   1500 * time.Millisecond,
+		},
+		{
+			name:   "retry-after http date",
+			header: http.Header{"Retry-After": []string{now.Add(30 * time.Second).Format(http.TimeFormat)}},
+			want:   30 * time.Second,
+		},
+		{
+			name: "openai reset of exhausted budget",
+			header: http.Header{
+				"X-Ratelimit-Remaining-Requests": []string{"10"},
+				"X-Ratelimit-Reset-Requests":     []string{"1s"},
+				"X-Ratelimit-Remaining-Tokens":   []string{"0"},
+				"X-Ratelimit-Reset-Tokens":       []string{"6m0s"},
+			},
+			want: 6 * time.Minute,
+		},
+		{
+			name: "anthropic reset timestamp",
+			header: http.Header{
+				"Anthropic-Ratelimit-Requests-Remaining": []string{"0"},
+				"Anthropic-Ratelimit-Requests-Reset":     []string{now.Add(12 * time.Second).Format(time.RFC3339)},
+			},
+			want: 12 * time.Second,
+		},
+		{
+			name:   "no hints",
+			header: http.Header{},
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRateLimitHeaders(tt.header, now)
+			if got != tt.want {
+				t.Errorf("parseRateLimitHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRateLimitInfo(t *testing.T) {
+	// Wrapped SDK error
+	err := domain.NewProviderError("OpenAI API call failed", newOpenAI429(http.Header{"Retry-After": []string{"3"}}))
+	info := ParseRateLimitInfo(err)
+	if !info.RateLimited {
+		t.Fatal("ParseRateLimitInfo() RateLimited = false, want true")
+	}
+	if info.RetryAfter != 3*time.Second {
+		t.Errorf("RetryAfter = %v, want 3s", info.RetryAfter)
+	}
+
+	// Gemini RetryInfo detail
+	geminiErr := genai.APIError{
+		Code: http.StatusTooManyRequests,
+		Details: []map[string]any{
+			{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "20s"},
+		},
+	}
+	if d, ok := RetryAfter(domain.NewProviderError("Gemini API error", geminiErr)); !ok || d != 20*time.Second {
+		t.Errorf("RetryAfter(gemini) = %v, %v, want 20s, true", d, ok)
+	}
+
+	// Non rate-limit errors
+	serverErr := &openai.Error{StatusCode: http.StatusInternalServerError}
+	if ParseRateLimitInfo(serverErr).RateLimited {
+		t.Error("500 error should not be treated as rate limited")
+	}
+	if ParseRateLimitInfo(nil).RateLimited {
+		t.Error("nil error should not be treated as rate limited")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(60) // 1 unit per second
+	b.last = now
+
+	if wait := b.reserve(60, now); wait != 0 {
+		t.Errorf("first reserve wait = %v, want 0", wait)
+	}
+	if wait := b.reserve(2, now); wait != 2*time.Second {
+		t.Errorf("second reserve wait = %v, want 2s", wait)
+	}
+
+	// Refill after 2 seconds brings the balance back to zero
+	if wait := b.reserve(0, now.Add(2*time.Second)); wait != 0 {
+		t.Errorf("reserve after refill wait = %v, want 0", wait)
+	}
+
+	// Actual usage lower than estimate credits the bucket
+	b.adjust(-10)
+	if b.balance != 10 {
+		t.Errorf("balance after credit = %v, want 10", b.balance)
+	}
+}
+
+func TestRateLimiter_AdaptiveConcurrency(t *testing.T) {
+	ctx := context.Background()
+	rl := NewRateLimiter(RateLimits{MaxConcurrency: 4})
+
+	for range 4 {
+		if err := rl.Acquire(ctx, 0); err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+	}
+
+	// A 429 halves concurrency and pauses callers
+	rl.Release(0, 0, RateLimitInfo{RateLimited: true, RetryAfter: 10 * time.Millisecond})
+	if got := rl.Stats().Concurrency; got != 2 {
+		t.Errorf("Concurrency after 429 = %d, want 2", got)
+	}
+	if got := rl.Stats().RateLimitHits; got != 1 {
+		t.Errorf("RateLimitHits = %d, want 1", got)
+	}
+
+	for range 3 {
+		rl.Release(0, 0, RateLimitInfo{})
+	}
+
+	// Two successes at concurrency 2 recover one slot
+	if got := rl.Stats().Concurrency; got != 3 {
+		t.Errorf("Concurrency after recovery = %d, want 3", got)
+	}
+
+	// Acquire honours the pause and the context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	rl.Release(0, 0, RateLimitInfo{RateLimited: true, RetryAfter: time.Hour})
+	if err := rl.Acquire(cancelled, 0); err == nil {
+		t.Error("Acquire() with cancelled context during pause should fail")
+	}
+}
+
+func TestRateLimiter_HalvesOncePerPause(t *testing.T) {
+	ctx := context.Background()
+	rl := NewRateLimiter(RateLimits{MaxConcurrency: 8})
+	for range 8 {
+		if err := rl.Acquire(ctx, 0); err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+	}
+
+	// Every call in flight hits the same limit: one decrease, not one per response
+	for range 4 {
+		rl.Release(0, 0, RateLimitInfo{RateLimited: true, RetryAfter: time.Hour})
+	}
+	stats := rl.Stats()
+	if stats.Concurrency != 4 || stats.RateLimitHits != 4 {
+		t.Errorf("after 429s in one pause: concurrency = %d, hits = %d; want 4, 4", stats.Concurrency, stats.RateLimitHits)
+	}
+
+	// A 429 after the pause is a new one
+	rl.mu.Lock()
+	rl.pausedUntil = time.Now().Add(-time.Second)
+	rl.mu.Unlock()
+	rl.Release(0, 0, RateLimitInfo{RateLimited: true, RetryAfter: time.Hour})
+	if got := rl.Stats().Concurrency; got != 2 {
+		t.Errorf("Concurrency after a later 429 = %d, want 2", got)
+	}
+}
+
+func TestRateLimiter_RefundsCancelledReservation(t *testing.T) {
+	rl := NewRateLimiter(RateLimits{TokensPerMinute: 60})
+	if err := rl.Acquire(context.Background(), 60); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	rl.Release(60, 60, RateLimitInfo{})
+
+	// Waiting for 30 more tokens is cancelled: they go back to the bucket
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rl.Acquire(ctx, 30); err == nil {
+		t.Fatal("Acquire() should fail when the context ends during the wait")
+	}
+	rl.mu.Lock()
+	balance := rl.tokens.balance
+	rl.mu.Unlock()
+	if balance < -1 {
+		t.Errorf("token balance = %v, want the cancelled reservation refunded", balance)
+	}
+}
+
+func TestWaitFor_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := WaitFor(ctx, time.Minute, nil); err == nil {
+		t.Error("WaitFor() with cancelled context should return an error")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("WaitFor() should return immediately when context is cancelled")
+	}
+}
+
+func TestRateLimitedProvider_RetriesOn429(t *testing.T) {
+	stub := &rateLimitedStub{failures: 2, header: http.Header{"Retry-After-Ms": []string{"1"}}}
+	p := &RateLimitedProvider{
+		inner:      stub,
+		limiter:    NewRateLimiter(RateLimits{MaxConcurrency: 2}),
+		maxRetries: 3,
+	}
+
+	resp, err := p.Complete(context.Background(), &CompletionRequest{Prompt: "hello"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Content = %q, want ok", resp.Content)
+	}
+	if stub.calls != 3 {
+		t.Errorf("inner calls = %d, want 3", stub.calls)
+	}
+	if got := p.Limiter().Stats().Retries; got != 2 {
+		t.Errorf("Retries = %d, want 2", got)
+	}
+}
+
+func TestRateLimitedProvider_GivesUp(t *testing.T) {
+	stub := &rateLimitedStub{failures: 10, header: http.Header{"Retry-After-Ms": []string{"1"}}}
+	p := &RateLimitedProvider{
+		inner:      stub,
+		limiter:    NewRateLimiter(RateLimits{}),
+		maxRetries: 1,
+	}
+
+	_, err := p.Complete(context.Background(), &CompletionRequest{Prompt: "hello"})
+	if err == nil {
+		t.Fatal("Complete() error = nil, want rate limit error")
+	}
+	if _, ok := RetryAfter(err); !ok {
+		t.Error("returned error should carry the Retry-After hint")
+	}
+	if !errors.Is(err, ErrRateLimitRetried) {
+		t.Errorf("error = %v, want ErrRateLimitRetried so callers don't retry again", err)
+	}
+	if stub.calls != 2 {
+		t.Errorf("inner calls = %d, want 2", stub.calls)
+	}
+}
+
+func TestSharedRateLimiter(t *testing.T) {
+	a := SharedRateLimiter("openai", "shared-test-model", RateLimits{RequestsPerMinute: 10})
+	b := SharedRateLimiter("openai", "shared-test-model", RateLimits{RequestsPerMinute: 99})
+	c := SharedRateLimiter("anthropic", "shared-test-model", RateLimits{})
+
+	if a != b {
+		t.Error("SharedRateLimiter() should return the same limiter for the same provider+model")
+	}
+	if a == c {
+		t.Error("SharedRateLimiter() should return distinct limiters for different providers")
+	}
+}
+
+func TestEstimateRequestTokens(t *testing.T) {
+	req := &CompletionRequest{Prompt: "12345678", SystemMsg: "1234"}
+	if got := EstimateRequestTokens(req); got != 6 {
+		t.Errorf("EstimateRequestTokens() = %d, want 6", got)
+	}
+
+	req.MaxTokens = 100
+	if got := EstimateRequestTokens(req); got != 103 {
+		t.Errorf("EstimateRequestTokens() with MaxTokens = %d, want 103", got)
+	}
+}
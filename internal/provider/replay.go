@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// Cassette is a recorded sequence of requests and responses.
+// Interactions are matched by PromptHash, so replay doesn't depend on call order.
+type Cassette struct {
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Hash     string             `json:"hash"`
+	Request  CompletionRequest  `json:"request"`
+	Response CompletionResponse `json:"response"`
+}
+
+// PromptHash identifies a request by its system message and prompt
+func PromptHash(req *CompletionRequest) string {
+	h := sha256.New()
+	_ = json.NewEncoder(h).Encode(req.SystemMsg)
+	_ = json.NewEncoder(h).Encode(req.Prompt)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LoadCassette reads a cassette from a JSON file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, domain.NewIOError("failed to read cassette", err).
+			WithContext("path", path)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, domain.NewFormatError("failed to parse cassette", err).
+			WithContext("path", path)
+	}
+
+	return &cassette, nil
+}
+
+// Save writes the cassette to a JSON file
+func (c *Cassette) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return domain.NewIOError("failed to create cassette directory", err).
+				WithContext("path", path)
+		}
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to marshal cassette", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return domain.NewIOError("failed to write cassette", err).
+			WithContext("path", path)
+	}
+
+	return nil
+}
+
+// ReplayProvider answers requests from a cassette without any network access.
+// When the same prompt was recorded several times, responses are returned in
+// recorded order and the last one is repeated.
+type ReplayProvider struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	byHash   map[string][]Interaction
+	served   map[string]int
+}
+
+// NewReplayProvider creates a provider that replays the given cassette
+func NewReplayProvider(cassette *Cassette) *ReplayProvider {
+	byHash := make(map[string][]Interaction)
+	for _, in := range cassette.Interactions {
+		hash := in.Hash
+		if hash == "" {
+			hash = PromptHash(&in.Request)
+		}
+		byHash[hash] = append(byHash[hash], in)
+	}
+
+	return &ReplayProvider{
+		cassette: cassette,
+		byHash:   byHash,
+		served:   make(map[string]int),
+	}
+}
+
+// Complete implements AIProvider interface
+func (p *ReplayProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	hash := PromptHash(req)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	recorded, ok := p.byHash[hash]
+	if !ok {
+		return nil, domain.NewProviderError("no recorded response for request", nil).
+			WithContext("hash", hash).
+			WithContext("prompt", truncate(req.Prompt, 80))
+	}
+
+	i := min(p.served[hash], len(recorded)-1)
+	p.served[hash]++
+
+	resp := recorded[i].Response
+	return &resp, nil
+}
+
+// Name implements AIProvider interface
+func (p *ReplayProvider) Name() string {
+	return string(ProviderTypeReplay)
+}
+
+// GetModelName implements AIProvider interface
+func (p *ReplayProvider) GetModelName() string {
+	return p.cassette.Model
+}
+
+// ValidateConfig implements AIProvider interface
+func (p *ReplayProvider) ValidateConfig() error {
+	if len(p.cassette.Interactions) == 0 {
+		return domain.NewConfigError("cassette has no recorded interactions", nil)
+	}
+	return nil
+}
+
+// RecordingProvider is an AIProvider decorator that records every successful
+// request/response pair into a cassette
+type RecordingProvider struct {
+	mu       sync.Mutex
+	inner    AIProvider
+	cassette *Cassette
+}
+
+// NewRecordingProvider wraps a provider and records its traffic
+func NewRecordingProvider(inner AIProvider) *RecordingProvider {
+	return &RecordingProvider{
+		inner: inner,
+		cassette: &Cassette{
+			Provider: inner.Name(),
+			Model:    inner.GetModelName(),
+		},
+	}
+}
+
+// Complete implements AIProvider interface
+func (p *RecordingProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	resp, err := p.inner.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.cassette.Interactions = append(p.cassette.Interactions, Interaction{
+		Hash:     PromptHash(req),
+		Request:  *req,
+		Response: *resp,
+	})
+	p.mu.Unlock()
+
+	return resp, nil
+}
+
+// Save writes everything recorded so far to a cassette file
+func (p *RecordingProvider) Save(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cassette.Save(path)
+}
+
+// Name implements AIProvider interface
+func (p *RecordingProvider) Name() string {
+	return p.inner.Name()
+}
+
+// GetModelName implements AIProvider interface
+func (p *RecordingProvider) GetModelName() string {
+	return p.inner.GetModelName()
+}
+
+// ValidateConfig implements AIProvider interface
+func (p *RecordingProvider) ValidateConfig() error {
+	return p.inner.ValidateConfig()
+}
+
+// truncate shortens s to at most n runes for error messages
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return fmt.Sprintf("%s...", string(r[:n]))
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockProvider("test-model")
+	mock.AddResponse("first")
+	mock.AddResponse("second")
+	mock.AddResponse("again")
+
+	rec := NewRecordingProvider(mock)
+	reqA := &CompletionRequest{Prompt: "prompt A", SystemMsg: "sys"}
+	reqB := &CompletionRequest{Prompt: "prompt B"}
+	for _, req := range []*CompletionRequest{reqA, reqB, reqA} {
+		if _, err := rec.Complete(ctx, req); err != nil {
+			t.Fatalf("record Complete() error = %v", err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "cassettes", "run.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	p, err := NewProvider(ctx, &ProviderConfig{Type: ProviderTypeReplay, Cassette: path})
+	if err != nil {
+		t.Fatalf("NewProvider(replay) error = %v", err)
+	}
+	if p.GetModelName() != "test-model" || p.Name() != "replay" {
+		t.Errorf("replay identity = %s/%s", p.Name(), p.GetModelName())
+	}
+
+	// Replay is matched by prompt, not call order
+	tests := []struct {
+		req  *CompletionRequest
+		want string
+	}{
+		{reqB, "second"},
+		{reqA, "first"},
+		{reqA, "again"},
+		{reqA, "again"}, // last recording repeats
+	}
+	for i, tt := range tests {
+		resp, err := p.Complete(ctx, tt.req)
+		if err != nil {
+			t.Fatalf("replay %d error = %v", i, err)
+		}
+		if resp.Content != tt.want {
+			t.Errorf("replay %d = %q, want %q", i, resp.Content, tt.want)
+		}
+	}
+
+	// Unknown prompts fail loudly
+	_, err = p.Complete(ctx, &CompletionRequest{Prompt: "never recorded"})
+	if !domain.IsErrorType(err, domain.ErrorTypeProvider) {
+		t.Errorf("unknown prompt error = %v, want provider error", err)
+	}
+}
+
+func TestPromptHash(t *testing.T) {
+	a := PromptHash(&CompletionRequest{Prompt: "x", SystemMsg: "y"})
+	if a != PromptHash(&CompletionRequest{Prompt: "x", SystemMsg: "y", Model: "other"}) {
+		t.Error("PromptHash() should ignore the model")
+	}
+	if a == PromptHash(&CompletionRequest{Prompt: "yx"}) {
+		t.Error("PromptHash() should separate system message and prompt")
+	}
+}
+
+func TestNewProvider_ReplayRequiresCassette(t *testing.T) {
+	if _, err := NewProvider(context.Background(), &ProviderConfig{Type: ProviderTypeReplay}); err == nil {
+		t.Error("NewProvider(replay) without cassette should fail")
+	}
+	if _, err := NewProvider(context.Background(), &ProviderConfig{
+		Type:     ProviderTypeReplay,
+		Cassette: filepath.Join(t.TempDir(), "missing.json"),
+	}); err == nil {
+		t.Error("NewProvider(replay) with missing cassette should fail")
+	}
+}
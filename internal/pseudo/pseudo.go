@@ -0,0 +1,225 @@
+// Package pseudo pseudo-localizes strings without a model, to find hardcoded strings,
+// truncation and layout problems before real translations exist. Placeholders, HTML
+// tags and URLs are kept intact, so a pseudo-localized file works like a translation.
+package pseudo
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/rtl"
+)
+
+// Pseudo-locales
+const (
+	Accented = "en-XA" // accented, expanded and between brackets
+	Bidi     = "ar-XB" // mirrored right-to-left
+)
+
+// DefaultExpansion is how many percent en-XA strings are made longer by default.
+// Translations are often about a third longer than English.
+const DefaultExpansion = 30
+
+// Locales lists the pseudo-locales
+var Locales = []string{Accented, Bidi}
+
+const (
+	rlo = "\u202E" // Right-to-Left Override
+	pdf = "\u202C" // Pop Directional Formatting
+)
+
+// accents maps ASCII letters to accented look-alikes that stay readable
+var accents = map[rune]rune{
+	'a': 'å', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// fillerWords pad expanded strings; counting words show how much was cut off
+var fillerWords = []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+
+// Normalize returns the pseudo-locale of a language code, e.g. "en_xa" -> "en-XA"
+func Normalize(code string) (string, bool) {
+	cleaned := strings.ReplaceAll(strings.TrimSpace(code), "_", "-")
+	for _, locale := range Locales {
+		if strings.EqualFold(cleaned, locale) {
+			return locale, true
+		}
+	}
+	return cleaned, false
+}
+
+// Localizer pseudo-localizes strings
+type Localizer struct {
+	protector     *format.Protector
+	rtlProcessor  *rtl.Processor
+	entityPattern *regexp.Regexp
+	expansion     int
+}
+
+// NewLocalizer creates a pseudo-localizer expanding strings by DefaultExpansion
+func NewLocalizer() *Localizer {
+	return &Localizer{
+		protector:     format.NewProtector(),
+		rtlProcessor:  rtl.NewProcessor(),
+		entityPattern: regexp.MustCompile(`&(?:[a-zA-Z]+|#\d+|#x[0-9a-fA-F]+);`),
+		expansion:     DefaultExpansion,
+	}
+}
+
+// SetExpansion sets how many percent en-XA strings are made longer (0 = not at all)
+func (l *Localizer) SetExpansion(percent int) {
+	l.expansion = max(percent, 0)
+}
+
+// Localize pseudo-localizes every string of JSON data
+func (l *Localizer) Localize(data map[string]any, locale string) map[string]any {
+	if localized, ok := l.localizeValue(data, locale).(map[string]any); ok {
+		return localized
+	}
+	return data
+}
+
+// localizeValue pseudo-localizes the strings of a JSON value
+func (l *Localizer) localizeValue(data any, locale string) any {
+	switch v := data.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			result[key] = l.localizeValue(value, locale)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, value := range v {
+			result[i] = l.localizeValue(value, locale)
+		}
+		return result
+	case string:
+		return l.Text(v, locale)
+	default:
+		return v
+	}
+}
+
+// Text pseudo-localizes a string. Blank strings and unknown locales are returned as is.
+func (l *Localizer) Text(text, locale string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	switch locale {
+	case Accented:
+		return l.accented(text)
+	case Bidi:
+		return l.bidi(text)
+	default:
+		return text
+	}
+}
+
+// accented replaces letters with accented ones, pads the text by the expansion and
+// puts it between brackets, so cut-off and concatenated strings stand out
+func (l *Localizer) accented(text string) string {
+	var b strings.Builder
+	length := 0 // of the text without protected elements
+	for _, seg := range l.split(text) {
+		if seg.protected {
+			b.WriteString(seg.text)
+			continue
+		}
+		for _, r := range seg.text {
+			if accented, ok := accents[r]; ok {
+				r = accented
+			}
+			b.WriteRune(r)
+			length++
+		}
+	}
+
+	if extra := (length*l.expansion + 99) / 100; extra > 0 {
+		var pad []string
+		for i, n := 0, 0; n < extra; i++ {
+			word := fillerWords[i%len(fillerWords)]
+			pad = append(pad, word)
+			n += len(word) + 1
+		}
+		b.WriteString(" " + strings.Join(pad, " "))
+	}
+	return "[" + b.String() + "]"
+}
+
+// bidi mirrors the text right-to-left with directional overrides and Arabic
+// punctuation. Placeholders and tags are kept as they are, URLs left-to-right.
+func (l *Localizer) bidi(text string) string {
+	var b strings.Builder
+	for _, seg := range l.split(text) {
+		switch {
+		case seg.url:
+			b.WriteString(l.rtlProcessor.AddLRM(seg.text))
+		case seg.protected || !strings.ContainsFunc(seg.text, unicode.IsLetter):
+			b.WriteString(seg.text)
+		default:
+			b.WriteString(rlo + l.rtlProcessor.ProcessText(seg.text, "ar") + pdf)
+		}
+	}
+	return l.rtlProcessor.AddRLM(b.String())
+}
+
+// segment is a part of a string: text to pseudo-localize, or a protected element
+type segment struct {
+	text      string
+	protected bool
+	url       bool // a protected URL
+}
+
+// split cuts a string into text and the elements that must stay intact: placeholders,
+// HTML tags and entities, URLs and the targets of Markdown links
+func (l *Localizer) split(text string) []segment {
+	type span struct {
+		start, end int
+		url        bool
+	}
+	var spans []span
+	for _, e := range l.protector.Extract(text) {
+		start := e.Position
+		if e.Type == format.ElementTypeMarkdown {
+			// The text of emphasis and links is localized, only link targets are kept
+			i := strings.Index(e.Value, "](")
+			if i < 0 {
+				continue
+			}
+			start += i + 1
+		}
+		spans = append(spans, span{start, e.Position + len(e.Value), e.Type == format.ElementTypeURL})
+	}
+	for _, m := range l.entityPattern.FindAllStringIndex(text, -1) {
+		spans = append(spans, span{m[0], m[1], false})
+	}
+	// Elements overlap, e.g. a URL in a Markdown link: the one starting first wins
+	slices.SortStableFunc(spans, func(a, b span) int { return cmp.Compare(a.start, b.start) })
+
+	var segments []segment
+	pos := 0
+	for _, sp := range spans {
+		if sp.end <= pos {
+			continue // inside an earlier element
+		}
+		start := max(sp.start, pos)
+		if start > pos {
+			segments = append(segments, segment{text: text[pos:start]})
+		}
+		segments = append(segments, segment{text: text[start:sp.end], protected: true, url: sp.url})
+		pos = sp.end
+	}
+	if pos < len(text) {
+		segments = append(segments, segment{text: text[pos:]})
+	}
+	return segments
+}
@@ -0,0 +1,80 @@
+package pseudo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/rtl"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{"en-XA": Accented, "en_xa": Accented, " AR-xb ": Bidi}
+	for code, want := range tests {
+		if got, ok := Normalize(code); !ok || got != want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", code, got, ok, want)
+		}
+	}
+	if _, ok := Normalize("de"); ok {
+		t.Error("Normalize(de) is a pseudo-locale")
+	}
+}
+
+func TestLocalizer_Accented(t *testing.T) {
+	l := NewLocalizer()
+	tests := []struct {
+		text      string
+		expansion int
+		want      string
+	}{
+		{"Save", 0, "[Šåṽé]"},
+		{"Save changes", 30, "[Šåṽé çĥåñĝéš one]"},
+		{"Hello {name}, see <b>news</b> &amp; https://example.com/help", 0,
+			"[Ĥéļļö {name}, šéé <b>ñéŵš</b> &amp; https://example.com/help]"},
+		{"Read the [guide](/docs/guide)", 0, "[Ŕéåð ţĥé [ĝûîðé](/docs/guide)]"},
+		{"%d files", 100, "[%d ƒîļéš one two]"},
+		{"", 30, ""},
+		{"  ", 30, "  "},
+	}
+	for _, tt := range tests {
+		l.SetExpansion(tt.expansion)
+		if got := l.Text(tt.text, Accented); got != tt.want {
+			t.Errorf("Text(%q, %d%%) = %q, want %q", tt.text, tt.expansion, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizer_Bidi(t *testing.T) {
+	l := NewLocalizer()
+	got := l.Text("Delete {count} files? See https://example.com", Bidi)
+
+	want := "\u200F" + rlo + "Delete " + pdf + "{count}" + rlo + " files؟ See " + pdf +
+		"\u200Ehttps://example.com\u200E\u200F"
+	if got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	// Directional marks next to a URL read as part of it
+	stripped := rtl.NewProcessor().StripDirectionalMarks(got)
+	if err := format.NewProtector().Validate("Delete {count} files? See https://example.com", stripped); err != nil {
+		t.Errorf("placeholders not kept: %v", err)
+	}
+}
+
+func TestLocalizer_Localize(t *testing.T) {
+	l := NewLocalizer()
+	l.SetExpansion(0)
+	got := l.Localize(map[string]any{
+		"title": "Home",
+		"menu":  map[string]any{"items": []any{"Help", 3}},
+		"count": 2,
+	}, Accented)
+
+	menu, _ := got["menu"].(map[string]any)
+	items, _ := menu["items"].([]any)
+	if got["title"] != "[Ĥöɱé]" || len(items) != 2 || items[0] != "[Ĥéļþ]" || items[1] != 3 || got["count"] != 2 {
+		t.Errorf("Localize() = %v", got)
+	}
+	if strings.Contains(got["title"].(string), "Home") {
+		t.Error("Localize() kept the source text")
+	}
+}
@@ -0,0 +1,243 @@
+// Package report builds the machine-readable report of a translation run: per target
+// language and key, the source, draft, reflection suggestion and final text, the
+// provider and model, tokens, retries and validation issues, with totals for the run.
+package report
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/format"
+)
+
+// Status of a translation in the report
+const (
+	StatusTranslated = "translated"
+	StatusUnchanged  = "unchanged" // incremental run without changes
+	StatusDryRun     = "dry-run"
+	StatusFailed     = "failed"
+)
+
+// Report is the report of a run
+type Report struct {
+	mu sync.Mutex
+
+	Version      string        `json:"version"` // jta version
+	StartedAt    time.Time     `json:"started_at"`
+	FinishedAt   time.Time     `json:"finished_at"`
+	Translations []Translation `json:"translations"`
+	Totals       Totals        `json:"totals"`
+}
+
+// Translation is one source file translated to one target language
+type Translation struct {
+	Source     string `json:"source"`
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+	Output     string `json:"output"`
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	Stats      Stats  `json:"stats"`
+	Keys       []Key  `json:"keys"`
+}
+
+// Stats are the counts of a translation
+type Stats struct {
+	Keys             int     `json:"keys"`
+	Translated       int     `json:"translated"`
+	Reused           int     `json:"reused"`
+	Locked           int     `json:"locked"`
+	Failed           int     `json:"failed"`
+	Skipped          int     `json:"skipped"`
+	Issues           int     `json:"issues"`
+	APICalls         int     `json:"api_calls"`
+	CacheHits        int     `json:"cache_hits"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // USD, 0 when the model's pricing is unknown
+	DurationMS       int64   `json:"duration_ms"`
+}
+
+// Key is how one key was translated
+type Key struct {
+	Key         string           `json:"key"`
+	Status      domain.KeyStatus `json:"status"`
+	Source      string           `json:"source"`
+	Draft       string           `json:"draft,omitempty"`
+	Suggestion  string           `json:"suggestion,omitempty"`
+	Final       string           `json:"final,omitempty"`
+	Batch       int              `json:"batch,omitempty"`
+	Attempts    int              `json:"attempts,omitempty"`
+	Retries     int              `json:"retries"`
+	BatchTokens int              `json:"batch_tokens,omitempty"` // shared by the keys of the batch
+	Shortened   bool             `json:"shortened,omitempty"`
+	Origin      string           `json:"origin,omitempty"` // of reused translations
+	Error       string           `json:"error,omitempty"`
+	Issues      []Issue          `json:"issues,omitempty"`
+}
+
+// Issue is a validation problem of a final translation
+type Issue struct {
+	Type    string `json:"type"` // "placeholder", "terminology" or "length"
+	Message string `json:"message"`
+}
+
+// Totals add up the translations of a run
+type Totals struct {
+	Translations int     `json:"translations"`
+	Failed       int     `json:"failed"` // translations that failed as a whole
+	Keys         int     `json:"keys"`
+	Translated   int     `json:"translated"`
+	Reused       int     `json:"reused"`
+	Locked       int     `json:"locked"`
+	FailedKeys   int     `json:"failed_keys"`
+	Skipped      int     `json:"skipped"`
+	Issues       int     `json:"issues"`
+	APICalls     int     `json:"api_calls"`
+	TotalTokens  int     `json:"total_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// New starts the report of a run
+func New(version string) *Report {
+	return &Report{
+		Version:      version,
+		StartedAt:    time.Now(),
+		Translations: []Translation{},
+	}
+}
+
+// Add records a translation. It is safe for concurrent use.
+func (r *Report) Add(t Translation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Translations = append(r.Translations, t)
+	r.Totals.Translations++
+	if t.Status == StatusFailed {
+		r.Totals.Failed++
+	}
+	r.Totals.Keys += t.Stats.Keys
+	r.Totals.Translated += t.Stats.Translated
+	r.Totals.Reused += t.Stats.Reused
+	r.Totals.Locked += t.Stats.Locked
+	r.Totals.FailedKeys += t.Stats.Failed
+	r.Totals.Skipped += t.Stats.Skipped
+	r.Totals.Issues += t.Stats.Issues
+	r.Totals.APICalls += t.Stats.APICalls
+	r.Totals.TotalTokens += t.Stats.TotalTokens
+	r.Totals.Cost += t.Stats.Cost
+}
+
+// Save writes the report as JSON, translations in source and language order
+func (r *Report) Save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = time.Now()
+	slices.SortStableFunc(r.Translations, func(a, b Translation) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.TargetLang, b.TargetLang))
+	})
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to encode report", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return domain.NewIOError("failed to create report directory", err).
+				WithContext("path", path)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return domain.NewIOError("failed to write report", err).
+			WithContext("path", path)
+	}
+	return nil
+}
+
+// FromResult builds the report of a translation from its result: every key the
+// engine handled, with the source text, final text and validation issues
+func FromResult(t Translation, sources map[string]string, result *domain.TranslationResult) Translation {
+	if t.Status == "" {
+		t.Status = StatusTranslated
+	}
+	t.Keys = []Key{}
+	if result == nil {
+		return t
+	}
+
+	stats := result.Stats
+	t.Stats.APICalls = stats.APICallsCount
+	t.Stats.CacheHits = stats.CacheHits
+	t.Stats.PromptTokens = stats.PromptTokens
+	t.Stats.CompletionTokens = stats.CompletionTokens
+	t.Stats.TotalTokens = stats.TotalTokens
+	t.Stats.Cost = stats.EstimatedCost
+	t.Stats.DurationMS = stats.Duration.Milliseconds()
+
+	issues := make(map[string][]Issue)
+	for _, v := range result.TermViolations {
+		issues[v.Key] = append(issues[v.Key], Issue{Type: "terminology", Message: v.Message})
+	}
+	for _, v := range result.LengthViolations {
+		issues[v.Key] = append(issues[v.Key], Issue{
+			Type:    "length",
+			Message: fmt.Sprintf("length %d exceeds %s", v.Length, v.Limit),
+		})
+	}
+
+	protector := format.NewProtector()
+	for _, key := range slices.Sorted(maps.Keys(result.Keys)) {
+		detail := result.Keys[key]
+		k := Key{
+			Key:         key,
+			Status:      detail.Status,
+			Source:      sources[key],
+			Draft:       detail.Draft,
+			Suggestion:  detail.Suggestion,
+			Final:       result.Translations[key],
+			Batch:       detail.Batch,
+			Attempts:    detail.Attempts,
+			Retries:     max(detail.Attempts-1, 0),
+			BatchTokens: detail.BatchTokens,
+			Shortened:   detail.Shortened,
+			Origin:      detail.Origin,
+			Error:       detail.Error,
+			Issues:      issues[key],
+		}
+		if k.Final != "" {
+			for _, msg := range protector.GetValidationReport(k.Source, k.Final).Errors {
+				k.Issues = append(k.Issues, Issue{Type: "placeholder", Message: msg})
+			}
+		}
+
+		t.Stats.Keys++
+		t.Stats.Issues += len(k.Issues)
+		switch detail.Status {
+		case domain.KeyStatusTranslated:
+			t.Stats.Translated++
+		case domain.KeyStatusReused:
+			t.Stats.Reused++
+		case domain.KeyStatusLocked:
+			t.Stats.Locked++
+		case domain.KeyStatusFailed:
+			t.Stats.Failed++
+		case domain.KeyStatusSkipped:
+			t.Stats.Skipped++
+		}
+		t.Keys = append(t.Keys, k)
+	}
+	return t
+}
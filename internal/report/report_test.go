@@ -0,0 +1,112 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+func TestFromResult(t *testing.T) {
+	sources := map[string]string{
+		"greeting": "Hello {name}",
+		"save":     "Save",
+		"title":    "Settings",
+		"broken":   "Broken",
+		"legal":    "Terms",
+	}
+	result := &domain.TranslationResult{
+		Translations: map[string]string{
+			"greeting": "Hallo {nom}",
+			"save":     "Speichern",
+			"title":    "Einstellungen",
+			"legal":    "AGB",
+		},
+		Keys: map[string]domain.KeyDetail{
+			"greeting": {Status: domain.KeyStatusTranslated, Batch: 1, Draft: "Hallo {name}", Attempts: 2, BatchTokens: 120},
+			"save":     {Status: domain.KeyStatusTranslated, Batch: 1, Draft: "Sichern", Suggestion: "Use the common term", Attempts: 1, BatchTokens: 120},
+			"title":    {Status: domain.KeyStatusReused, Origin: "tm"},
+			"broken":   {Status: domain.KeyStatusFailed, Batch: 2, Attempts: 3, Error: "no translation returned"},
+			"legal":    {Status: domain.KeyStatusLocked},
+		},
+		TermViolations:   []domain.TermViolation{{Key: "save", Message: "term not translated consistently"}},
+		LengthViolations: []domain.LengthViolation{{Key: "title", Length: 13, Limit: domain.LengthLimit{Max: 10}}},
+		Stats:            domain.TranslationStats{APICallsCount: 3, TotalTokens: 240},
+	}
+
+	got := FromResult(Translation{Source: "en.json", TargetLang: "de"}, sources, result)
+	if got.Status != StatusTranslated {
+		t.Errorf("Status = %q, want %q", got.Status, StatusTranslated)
+	}
+	want := Stats{Keys: 5, Translated: 2, Reused: 1, Locked: 1, Failed: 1, Issues: 3, APICalls: 3, TotalTokens: 240}
+	if got.Stats != want {
+		t.Errorf("Stats = %+v, want %+v", got.Stats, want)
+	}
+
+	keys := make(map[string]Key)
+	for _, k := range got.Keys {
+		keys[k.Key] = k
+	}
+	if k := keys["greeting"]; k.Source != "Hello {name}" || k.Draft != "Hallo {name}" || k.Final != "Hallo {nom}" || k.Retries != 1 ||
+		len(k.Issues) != 1 || k.Issues[0].Type != "placeholder" {
+		t.Errorf("greeting = %+v", k)
+	}
+	if k := keys["save"]; k.Suggestion != "Use the common term" || len(k.Issues) != 1 || k.Issues[0].Type != "terminology" {
+		t.Errorf("save = %+v", k)
+	}
+	if k := keys["title"]; k.Origin != "tm" || len(k.Issues) != 1 || k.Issues[0].Type != "length" {
+		t.Errorf("title = %+v", k)
+	}
+	if k := keys["broken"]; k.Final != "" || k.Error == "" || k.Retries != 2 {
+		t.Errorf("broken = %+v", k)
+	}
+	if got.Keys[0].Key != "broken" {
+		t.Errorf("keys should be sorted, first = %s", got.Keys[0].Key)
+	}
+}
+
+func TestReport_Save(t *testing.T) {
+	r := New("v1.2.3")
+
+	var wg sync.WaitGroup
+	for _, lang := range []string{"fr", "de", "ja"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Add(Translation{Source: "en.json", TargetLang: lang, Status: StatusTranslated, Stats: Stats{Keys: 2, Translated: 2, TotalTokens: 100}})
+		}()
+	}
+	wg.Wait()
+	r.Add(Translation{Source: "en.json", TargetLang: "es", Status: StatusFailed, Error: "provider error"})
+
+	path := filepath.Join(t.TempDir(), "reports", "run.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Report
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if saved.Version != "v1.2.3" || len(saved.Translations) != 4 || saved.FinishedAt.IsZero() {
+		t.Fatalf("report = %s", data)
+	}
+	var langs []string
+	for _, tr := range saved.Translations {
+		langs = append(langs, tr.TargetLang)
+	}
+	if langs[0] != "de" || langs[3] != "ja" {
+		t.Errorf("translations not sorted: %v", langs)
+	}
+	want := Totals{Translations: 4, Failed: 1, Keys: 6, Translated: 6, TotalTokens: 300}
+	if saved.Totals != want {
+		t.Errorf("Totals = %+v, want %+v", saved.Totals, want)
+	}
+}
@@ -0,0 +1,142 @@
+// Package review keeps the human review of translations: the strings a run changed,
+// which wait for a reviewer, and the translations the reviewer approved, which later
+// runs keep instead of translating again.
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/incremental"
+)
+
+// State is the review of one source file translated to one target language
+type State struct {
+	SourcePath     string              `json:"source_path"`
+	SourceLanguage string              `json:"source_language"`
+	TargetLanguage string              `json:"target_language"`
+	TargetPath     string              `json:"target_path"`
+	Pending        map[string]Change   `json:"pending"`  // key path -> change waiting for review
+	Approved       map[string]Approval `json:"approved"` // key path -> human-approved translation
+}
+
+// Change is a translation a run changed
+type Change struct {
+	Source     string    `json:"source"`
+	Old        string    `json:"old,omitempty"` // translation before the run, empty for new keys
+	New        string    `json:"new"`
+	Suggestion string    `json:"suggestion,omitempty"` // reflection's suggestion, if it had one
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// Approval is a translation a reviewer approved for a source text
+type Approval struct {
+	Source     string    `json:"source"`
+	Target     string    `json:"target"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// Path returns where the review of a source file and target language is stored
+func Path(dir, sourcePath, targetLang string) string {
+	name := incremental.SourceName(dir, sourcePath)
+	return filepath.Join(dir, "reviews", fmt.Sprintf("%s.%s.json", name, targetLang))
+}
+
+// List returns the paths of the reviews stored in a terminology directory
+func List(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "reviews", "*.json"))
+	if err != nil {
+		return nil, domain.NewIOError("failed to list reviews", err).WithContext("dir", dir)
+	}
+	return paths, nil
+}
+
+// Load loads a review; a missing file yields an empty review
+func Load(path string) (*State, error) {
+	s := &State{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, domain.NewIOError("failed to read review", err).
+			WithContext("path", path)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, domain.NewFormatError("failed to parse review", err).
+				WithContext("path", path)
+		}
+	}
+	if s.Pending == nil {
+		s.Pending = make(map[string]Change)
+	}
+	if s.Approved == nil {
+		s.Approved = make(map[string]Approval)
+	}
+	return s, nil
+}
+
+// Save writes a review, creating its directory if needed
+func Save(path string, s *State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return domain.NewIOError("failed to create review directory", err).
+			WithContext("path", path)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to encode review", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return domain.NewIOError("failed to write review", err).
+			WithContext("path", path)
+	}
+	return nil
+}
+
+// Record adds the changes of a run. A key changed again before review keeps the
+// translation from before its first change; a key changed back has nothing left to
+// review. A changed key is no longer approved: its source text changed. It reports
+// whether the review changed.
+func (s *State) Record(changes map[string]Change) bool {
+	changed := false
+	for key, c := range changes {
+		if earlier, ok := s.Pending[key]; ok {
+			c.Old = earlier.Old
+		}
+		if _, ok := s.Approved[key]; ok {
+			delete(s.Approved, key)
+			changed = true
+		}
+		if c.New == c.Old {
+			if _, ok := s.Pending[key]; ok {
+				delete(s.Pending, key)
+				changed = true
+			}
+			continue
+		}
+		s.Pending[key] = c
+		changed = true
+	}
+	return changed
+}
+
+// Keys returns the keys waiting for review, sorted
+func (s *State) Keys() []string {
+	return slices.Sorted(maps.Keys(s.Pending))
+}
+
+// Approve marks the translation of a key as approved by a reviewer
+func (s *State) Approve(key, source, target string) {
+	delete(s.Pending, key)
+	s.Approved[key] = Approval{Source: source, Target: target, ApprovedAt: time.Now()}
+}
+
+// Reject drops a change the reviewer reverted
+func (s *State) Reject(key string) {
+	delete(s.Pending, key)
+}
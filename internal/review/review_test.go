@@ -0,0 +1,158 @@
+package review
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestState_Record(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	s.Approve("cancel", "Cancel", "Abbrechen")
+
+	s.Record(map[string]Change{
+		"save":   {Source: "Save", Old: "Sichern", New: "Speichern"},
+		"cancel": {Source: "Cancel now", Old: "Abbrechen", New: "Jetzt abbrechen"},
+	})
+	if _, ok := s.Approved["cancel"]; ok {
+		t.Error("a key whose source changed should no longer be approved")
+	}
+
+	// Changed again before review: the translation before the first change is kept
+	s.Record(map[string]Change{"save": {Source: "Save", Old: "Speichern", New: "Speichern!"}})
+	if c := s.Pending["save"]; c.Old != "Sichern" || c.New != "Speichern!" {
+		t.Errorf("pending save = %+v", c)
+	}
+	// Changed back: nothing to review
+	s.Record(map[string]Change{"save": {Source: "Save", Old: "Speichern!", New: "Sichern"}})
+	if got := s.Keys(); len(got) != 1 || got[0] != "cancel" {
+		t.Errorf("Keys() = %v, want [cancel]", got)
+	}
+	if s.Record(nil) {
+		t.Error("Record(nil) reported a change")
+	}
+}
+
+func TestState_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := Path(dir, "locales/en.json", "de")
+	if !strings.HasPrefix(filepath.Base(path), "en-") || !strings.HasSuffix(path, ".de.json") {
+		t.Errorf("Path() = %s", path)
+	}
+	if Path(dir, "packages/web/locales/en.json", "de") == Path(dir, "packages/api/locales/en.json", "de") {
+		t.Error("Path() is the same for sources with the same file name")
+	}
+
+	s, _ := Load(path)
+	s.TargetPath = "locales/de.json"
+	s.Record(map[string]Change{"save": {Source: "Save", New: "Speichern"}})
+	s.Approve("title", "Title", "Titel")
+	if err := Save(path, s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.TargetPath != "locales/de.json" || loaded.Pending["save"].New != "Speichern" {
+		t.Errorf("loaded review = %+v", loaded)
+	}
+	if a := loaded.Approved["title"]; a.Target != "Titel" || a.Source != "Title" {
+		t.Errorf("Approved = %+v", loaded.Approved)
+	}
+}
+
+func TestSession_Run(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "review.json"))
+	s.Record(map[string]Change{
+		"a.accept":  {Source: "Save", Old: "Sichern", New: "Speichern", Suggestion: "Keep it short"},
+		"b.edit":    {Source: "Hello {name}", New: "Hallo {name}"},
+		"c.reject":  {Source: "Delete", Old: "Löschen", New: "Entfernen"},
+		"d.new":     {Source: "New", New: "Neu!"},
+		"e.again":   {Source: "Open", New: "Öffnen Sie"},
+		"f.skipped": {Source: "Close", New: "Schließen"},
+	})
+
+	var notes []string
+	input := strings.Join([]string{
+		"a",
+		"e", "Hallo {nom}", // broken placeholder, asked again
+		"e", "Hi {name}",
+		"r",
+		"r",
+		"t", "informal",
+		"a",
+		"", // skip
+	}, "\n") + "\n"
+	var out strings.Builder
+	session := NewSession(s, strings.NewReader(input), &out)
+	session.SetRetranslator(func(_ context.Context, key, source, note string) (string, string, error) {
+		notes = append(notes, key+": "+note)
+		return "Öffnen", "", nil
+	})
+
+	result, err := session.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Accepted != 2 || result.Edited != 1 || result.Rejected != 2 || result.Retranslated != 1 || result.Skipped != 1 || result.Remaining != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(notes) != 1 || notes[0] != "e.again: informal" {
+		t.Errorf("retranslated with %v", notes)
+	}
+	if !strings.Contains(out.String(), "Suggestion") || !strings.Contains(out.String(), "placeholder:{name}") {
+		t.Errorf("output:\n%s", out.String())
+	}
+
+	for key, want := range map[string]string{"a.accept": "Speichern", "b.edit": "Hi {name}", "e.again": "Öffnen"} {
+		if got := s.Approved[key].Target; got != want {
+			t.Errorf("approved %s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := s.Pending["f.skipped"]; !ok {
+		t.Error("skipped change should wait for review")
+	}
+
+	data := result.Apply(map[string]any{
+		"a": map[string]any{"accept": "Speichern"},
+		"b": map[string]any{"edit": "Hallo {name}"},
+		"c": map[string]any{"reject": "Entfernen"},
+		"d": map[string]any{"new": "Neu!", "other": "Andere"},
+		"e": map[string]any{"again": "Öffnen Sie"},
+		"f": map[string]any{"skipped": "Schließen"},
+	})
+	want := map[string]string{"b": "Hi {name}", "c": "Löschen", "e": "Öffnen", "f": "Schließen"}
+	for parent, text := range want {
+		for _, v := range data[parent].(map[string]any) {
+			if v != text {
+				t.Errorf("%s = %v, want %q", parent, v, text)
+			}
+		}
+	}
+	if d := data["d"].(map[string]any); len(d) != 1 || d["other"] != "Andere" {
+		t.Errorf("rejected new key should be removed: %v", d)
+	}
+}
+
+func TestSession_Run_Quit(t *testing.T) {
+	s, _ := Load(filepath.Join(t.TempDir(), "review.json"))
+	s.Record(map[string]Change{"a": {Source: "A", New: "Ä"}, "b": {Source: "B", New: "B!"}})
+
+	var out strings.Builder
+	result, err := NewSession(s, strings.NewReader("t\nq\n"), &out).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Remaining != 2 || result.Changed() {
+		t.Errorf("result = %+v", result)
+	}
+	if !strings.Contains(out.String(), "needs a provider") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,251 @@
+package review
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/ui"
+)
+
+const (
+	defaultWidth   = 100 // terminal width when unknown
+	minColumnWidth = 16
+	columnGap      = 2
+)
+
+// Retranslator translates the source text of a key again following a reviewer's note.
+// It returns the new translation and reflection's suggestion, if any.
+type Retranslator func(ctx context.Context, key, source, note string) (translation, suggestion string, err error)
+
+// Result is the outcome of a review session
+type Result struct {
+	Accepted     int
+	Edited       int
+	Rejected     int
+	Retranslated int
+	Skipped      int
+	Remaining    int               // changes still waiting for review
+	Values       map[string]string // key path -> text to write into the translation file
+	Removed      []string          // keys rejected without an earlier translation
+}
+
+// Changed reports whether the translation file needs updating
+func (r *Result) Changed() bool {
+	return len(r.Values) > 0 || len(r.Removed) > 0
+}
+
+// Session walks the changes of a translation with a reviewer on a terminal, showing
+// source, earlier and new translation and reflection's suggestion side by side
+type Session struct {
+	state       *State
+	in          *bufio.Reader
+	out         io.Writer
+	width       int
+	retranslate Retranslator
+	protector   *format.Protector
+}
+
+// NewSession creates a review session reading answers from in and writing to out
+func NewSession(state *State, in io.Reader, out io.Writer) *Session {
+	return &Session{
+		state:     state,
+		in:        bufio.NewReader(in),
+		out:       out,
+		width:     defaultWidth,
+		protector: format.NewProtector(),
+	}
+}
+
+// SetWidth sets the width of the terminal
+func (s *Session) SetWidth(width int) {
+	if width > 0 {
+		s.width = width
+	}
+}
+
+// SetRetranslator enables translating a key again with a note
+func (s *Session) SetRetranslator(r Retranslator) {
+	s.retranslate = r
+}
+
+// Run asks for a decision on every change waiting for review. The decisions are
+// recorded in the review; the returned result says how to update the translation file.
+// Quitting, or the end of the input, leaves the remaining changes for later.
+func (s *Session) Run(ctx context.Context) (*Result, error) {
+	result := &Result{Values: make(map[string]string)}
+	keys := s.state.Keys()
+
+review:
+	for i, key := range keys {
+		for {
+			change := s.state.Pending[key]
+			s.show(i+1, len(keys), key, change)
+
+			answer, err := s.ask("[a]ccept  [e]dit  [r]eject  [t]ranslate again  [s]kip  [q]uit > ")
+			if err != nil {
+				break review
+			}
+			switch answer {
+			case "a", "accept":
+				s.state.Approve(key, change.Source, change.New)
+				result.Values[key] = change.New
+				result.Accepted++
+			case "e", "edit":
+				text, err := s.ask("New translation (empty to go back): ")
+				if err != nil {
+					break review
+				}
+				if text == "" {
+					continue
+				}
+				if report := s.protector.GetValidationReport(change.Source, text); !report.IsValid {
+					s.printf("%s\n", ui.WarningStyle.Render(ui.IconWarning+" "+strings.Join(report.Errors, "; ")))
+					continue
+				}
+				s.state.Approve(key, change.Source, text)
+				result.Values[key] = text
+				result.Edited++
+			case "r", "reject":
+				s.state.Reject(key)
+				if change.Old == "" {
+					delete(result.Values, key)
+					result.Removed = append(result.Removed, key)
+				} else {
+					result.Values[key] = change.Old
+				}
+				result.Rejected++
+			case "t", "translate":
+				if s.retranslate == nil {
+					s.printf("%s\n", ui.WarningStyle.Render(ui.IconWarning+" Translating again needs a provider"))
+					continue
+				}
+				note, err := s.ask("Note for the translator: ")
+				if err != nil {
+					break review
+				}
+				s.printf("%s\n", ui.SubtleStyle.Render("Translating..."))
+				translation, suggestion, err := s.retranslate(ctx, key, change.Source, note)
+				if err != nil {
+					s.printf("%s\n", ui.ErrorStyle.Render(fmt.Sprintf("%s Translation failed: %v", ui.IconError, err)))
+					continue
+				}
+				change.New, change.Suggestion = translation, suggestion
+				s.state.Pending[key] = change
+				result.Values[key] = translation
+				result.Retranslated++
+				continue
+			case "s", "skip", "":
+				result.Skipped++
+			case "q", "quit":
+				break review
+			default:
+				s.printf("%s\n", ui.WarningStyle.Render(fmt.Sprintf("%s Unknown answer %q", ui.IconWarning, answer)))
+				continue
+			}
+			break
+		}
+	}
+
+	result.Remaining = len(s.state.Pending)
+	return result, nil
+}
+
+// ask prints a question and reads the answer; the end of the input is an error
+func (s *Session) ask(question string) (string, error) {
+	s.printf("%s", ui.LabelStyle.Render(question))
+	line, err := s.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		s.printf("\n")
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// show prints a change: a column each for the source, the earlier and the new
+// translation and the suggestion
+func (s *Session) show(n, total int, key string, c Change) {
+	s.printf("\n%s %s\n", ui.SubtleStyle.Render(fmt.Sprintf("%d/%d", n, total)), ui.HighlightStyle.Render(key))
+
+	titles := []string{"Source", "Before", "After"}
+	texts := []string{c.Source, c.Old, c.New}
+	if c.Old == "" {
+		texts[1] = ui.SubtleStyle.Render("(new)")
+	}
+	if c.Suggestion != "" {
+		titles = append(titles, "Suggestion")
+		texts = append(texts, c.Suggestion)
+	}
+
+	width := max((s.width-columnGap*(len(titles)-1))/len(titles), minColumnWidth)
+	columns := make([]string, len(titles))
+	for i := range titles {
+		style := lipgloss.NewStyle().Width(width)
+		if i < len(titles)-1 {
+			style = style.MarginRight(columnGap)
+		}
+		columns[i] = style.Render(ui.LabelStyle.Render(titles[i]) + "\n" + texts[i])
+	}
+	s.printf("%s\n", lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+}
+
+// printf writes to the reviewer's terminal
+func (s *Session) printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(s.out, format, a...)
+}
+
+// Apply writes the decisions of a session into the data of a translation file: the
+// values of reviewed keys are set and removed keys are dropped
+func (r *Result) Apply(data map[string]any) map[string]any {
+	removed := make(map[string]bool, len(r.Removed))
+	for _, key := range r.Removed {
+		removed[key] = true
+	}
+	if applied, ok := apply(data, "", r.Values, removed).(map[string]any); ok {
+		return applied
+	}
+	return data
+}
+
+// apply rebuilds a value of a translation file with the reviewed strings
+func apply(data any, prefix string, values map[string]string, removed map[string]bool) any {
+	switch v := data.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			keyPath := key
+			if prefix != "" {
+				keyPath = prefix + "." + key
+			}
+			if removed[keyPath] {
+				continue
+			}
+			result[key] = apply(value, keyPath, values, removed)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, value := range v {
+			keyPath := fmt.Sprintf("%s[%d]", prefix, i)
+			if removed[keyPath] {
+				// Removing an element would shift the ones after it
+				result[i] = ""
+				continue
+			}
+			result[i] = apply(value, keyPath, values, removed)
+		}
+		return result
+	case string:
+		if text, ok := values[prefix]; ok {
+			return text
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,106 @@
+// Package style loads per-language style guides: formality, tone, punctuation
+// conventions, forbidden phrases and example translations for the prompts.
+package style
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Dir returns the style guide directory inside a terminology directory
+func Dir(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "style")
+}
+
+// extensions are the style guide files tried for a language, in order
+var extensions = []string{".md", ".yaml", ".yml"}
+
+// Load loads the style guide of a target language from <terminology-dir>/style:
+// <lang>.md, <lang>.yaml or <lang>.yml, falling back to the base language
+// (pt.md for pt-BR). It returns nil if there is none.
+func Load(terminologyDir, lang string) (*domain.StyleGuide, error) {
+	candidates := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = append(candidates, base)
+	}
+
+	for _, name := range candidates {
+		for _, ext := range extensions {
+			path := filepath.Join(Dir(terminologyDir), name+ext)
+			data, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, domain.NewIOError("failed to read style guide", err).
+					WithContext("path", path)
+			}
+
+			var guide *domain.StyleGuide
+			if ext == ".md" {
+				guide, err = ParseMarkdown(data)
+			} else {
+				guide, err = ParseYAML(data)
+			}
+			if err != nil {
+				return nil, domain.NewFormatError("invalid style guide", err).
+					WithContext("path", path)
+			}
+			guide.Language = lang
+			guide.Path = path
+			return guide, nil
+		}
+	}
+	return nil, nil
+}
+
+// ParseMarkdown parses a Markdown style guide. Structured settings (formality, tone,
+// punctuation, forbidden, examples) go in optional YAML front matter; the Markdown
+// body is free-form guidance passed to the model as is.
+func ParseMarkdown(data []byte) (*domain.StyleGuide, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	guide := &domain.StyleGuide{}
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		frontMatter, body, found := strings.Cut("\n"+rest, "\n---")
+		if !found {
+			return nil, errors.New("front matter is not closed with ---")
+		}
+		if err := yaml.Unmarshal([]byte(frontMatter), guide); err != nil {
+			return nil, err
+		}
+		// Drop the rest of the closing line
+		if _, after, ok := strings.Cut(body, "\n"); ok {
+			body = after
+		} else {
+			body = ""
+		}
+		text = body
+	}
+
+	if notes := strings.TrimSpace(text); notes != "" {
+		if guide.Notes != "" {
+			notes = guide.Notes + "\n\n" + notes
+		}
+		guide.Notes = notes
+	}
+	return guide, nil
+}
+
+// ParseYAML parses a YAML style guide with the same fields as the Markdown front matter,
+// plus notes
+func ParseYAML(data []byte) (*domain.StyleGuide, error) {
+	guide := &domain.StyleGuide{}
+	if err := yaml.Unmarshal(data, guide); err != nil {
+		return nil, err
+	}
+	guide.Notes = strings.TrimSpace(guide.Notes)
+	return guide, nil
+}
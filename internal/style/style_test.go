@@ -0,0 +1,88 @@
+package style
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const germanGuide = `---
+formality: formal, always address the user as "Sie"
+tone: friendly and concise
+forbidden:
+  - Du
+  - Klicke
+examples:
+  - source: Save your changes
+    target: Speichern Sie Ihre Änderungen
+---
+
+# German UI style
+
+Use "Sie" consistently, also in error messages.
+`
+
+func writeGuide(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(Dir(dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(Dir(dir), name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	guide, err := ParseMarkdown([]byte(germanGuide))
+	if err != nil {
+		t.Fatalf("ParseMarkdown() error = %v", err)
+	}
+	if guide.Formality != `formal, always address the user as "Sie"` || guide.Tone != "friendly and concise" {
+		t.Errorf("front matter not parsed: %+v", guide)
+	}
+	if len(guide.Forbidden) != 2 || len(guide.Examples) != 1 || guide.Examples[0].Target != "Speichern Sie Ihre Änderungen" {
+		t.Errorf("lists not parsed: %+v", guide)
+	}
+	if !strings.HasPrefix(guide.Notes, "# German UI style") || strings.Contains(guide.Notes, "---") {
+		t.Errorf("Notes = %q, want the Markdown body", guide.Notes)
+	}
+
+	// Plain Markdown is all notes
+	guide, err = ParseMarkdown([]byte("Use です/ます throughout.\n"))
+	if err != nil || guide.Notes != "Use です/ます throughout." || guide.Formality != "" {
+		t.Errorf("ParseMarkdown(plain) = %+v, %v", guide, err)
+	}
+
+	if _, err := ParseMarkdown([]byte("---\nformality: formal\n")); err == nil {
+		t.Error("ParseMarkdown() should reject unclosed front matter")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	guide, err := Load(dir, "de")
+	if err != nil || guide != nil {
+		t.Fatalf("Load() without guides = %+v, %v; want nil, nil", guide, err)
+	}
+
+	writeGuide(t, dir, "de.md", germanGuide)
+	writeGuide(t, dir, "es.yaml", "formality: neutral\ntone: Latin American Spanish, avoid vosotros\n")
+
+	guide, err = Load(dir, "de")
+	if err != nil || guide.Language != "de" || guide.Path != filepath.Join(Dir(dir), "de.md") {
+		t.Fatalf("Load(de) = %+v, %v", guide, err)
+	}
+
+	// Regional variants fall back to the base language
+	guide, err = Load(dir, "es-419")
+	if err != nil || guide == nil || guide.Formality != "neutral" || guide.Language != "es-419" {
+		t.Fatalf("Load(es-419) = %+v, %v", guide, err)
+	}
+
+	writeGuide(t, dir, "ja.yaml", "formality: [unclosed\n")
+	if _, err := Load(dir, "ja"); err == nil {
+		t.Error("Load() should reject an invalid guide")
+	}
+}
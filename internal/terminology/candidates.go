@@ -0,0 +1,598 @@
+package terminology
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// Offline term candidate extraction. Source strings are cut into segments at punctuation,
+// placeholders and markup, so candidates never span them. Words of space-delimited scripts
+// become word n-grams; runs of Han, Thai and other scripts written without spaces become
+// character n-grams; Katakana runs (mostly loanwords) are kept whole. Candidates are scored
+// TF-IDF style with every source string as a document, and boosted for capitalization and
+// distinctive forms. The result only depends on the input, so it can be reviewed and diffed.
+
+const (
+	maxWordGram           = 4  // words in a word n-gram
+	maxHanGram            = 4  // characters in a Han n-gram
+	maxClusterGram        = 8  // grapheme clusters in an n-gram of Thai and similar scripts
+	maxCandidateLength    = 50 // runes
+	minCandidateFrequency = 2
+	maxCandidateContexts  = 5
+)
+
+// Signals explaining why a candidate scored high
+const (
+	signalCapitalized  = "capitalized"  // Title Case inside sentence-case text
+	signalMultiWord    = "multi-word"   // a phrase rather than a single word
+	signalAcronym      = "acronym"      // API, SSO
+	signalMixedCase    = "mixed-case"   // OAuth, GitHub
+	signalAlphanumeric = "alphanumeric" // GPT-4, FLUX.1
+)
+
+// stopWords are English function words that never start or end a candidate
+var stopWords = map[string]bool{
+	"the": true, "a": true, "an": true, "and": true, "or": true,
+	"but": true, "in": true, "on": true, "at": true, "to": true,
+	"for": true, "of": true, "with": true, "by": true, "from": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true,
+	"this": true, "that": true, "these": true, "those": true,
+	"your": true, "you": true, "it": true, "its": true,
+	"as": true, "if": true, "not": true, "no": true, "can": true,
+	"will": true, "has": true, "have": true, "been": true, "do": true,
+	"we": true, "our": true, "my": true, "i": true, "they": true,
+	"their": true, "so": true, "than": true, "then": true, "into": true,
+	"which": true, "what": true, "when": true, "how": true, "all": true,
+}
+
+// script classifies how a script separates words
+type script int
+
+const (
+	scriptSpaced   script = iota // Latin, Cyrillic, Greek, Hangul...: words separated by spaces
+	scriptHan                    // Chinese characters: no word separators
+	scriptKatakana               // Japanese Katakana: usually one loanword per run
+	scriptUnspaced               // Thai, Lao, Khmer, Myanmar: no spaces between words
+)
+
+// token is a word, or a run of characters of a script written without spaces
+type token struct {
+	text    string
+	script  script
+	initial bool // first word of a sentence
+}
+
+// scriptOf returns the script class of a letter; Hiragana (particles and inflections
+// between Japanese words) has none
+func scriptOf(r rune) (script, bool) {
+	switch {
+	case r == 'ー' || unicode.Is(unicode.Katakana, r):
+		return scriptKatakana, true
+	case unicode.Is(unicode.Han, r):
+		return scriptHan, true
+	case unicode.Is(unicode.Hiragana, r):
+		return 0, false
+	case unicode.In(r, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar):
+		return scriptUnspaced, true
+	}
+	return scriptSpaced, true
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isWordConnector reports whether r joins the parts of a word ("Sign-On", "FLUX.1", "don't")
+// when it sits between letters or digits
+func isWordConnector(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', '.', '\'', '’', '_':
+		return true
+	}
+	return false
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', ':', ';', '…', '。', '！', '？', '：', '；':
+		return true
+	}
+	return false
+}
+
+// skipEnclosed returns the index of the bracket closing the one at start ({name}, {{count}},
+// <b>), or start if it is never closed
+func skipEnclosed(runes []rune, start int) int {
+	open := runes[start]
+	closing := '}'
+	if open == '<' {
+		closing = '>'
+	}
+	depth := 0
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return start
+}
+
+// segmentText splits a source string into segments of tokens. Segments end at punctuation,
+// line breaks, placeholders, markup and Hiragana.
+func segmentText(text string) [][]token {
+	var (
+		segments   [][]token
+		current    []token
+		word       []rune
+		wordScript script
+		initial    = true
+	)
+
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		current = append(current, token{text: string(word), script: wordScript, initial: initial && wordScript == scriptSpaced})
+		initial = false
+		word = word[:0]
+	}
+	flushSegment := func() {
+		flushWord()
+		if len(current) > 0 {
+			segments = append(segments, current)
+			current = nil
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '{' || r == '<':
+			flushSegment()
+			i = skipEnclosed(runes, i)
+		case r == '%' && i+1 < len(runes) && isAlnum(runes[i+1]):
+			// printf verbs (%s, %d) are placeholders too
+			flushSegment()
+			for i+1 < len(runes) && isAlnum(runes[i+1]) {
+				i++
+			}
+		case unicode.In(r, unicode.Mn, unicode.Mc):
+			if len(word) > 0 {
+				word = append(word, r)
+			}
+		case isAlnum(r):
+			s, ok := scriptOf(r)
+			if !ok {
+				flushSegment()
+				continue
+			}
+			if len(word) > 0 && s != wordScript {
+				flushWord()
+			}
+			wordScript = s
+			word = append(word, r)
+		case isWordConnector(r) && len(word) > 0 && wordScript == scriptSpaced && i+1 < len(runes) && isAlnum(runes[i+1]):
+			word = append(word, r)
+		case r == '\n':
+			flushSegment()
+			initial = true
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushSegment()
+			if isSentenceEnd(r) {
+				initial = true
+			}
+		}
+	}
+	flushSegment()
+
+	return segments
+}
+
+// graphemeClusters splits text into base characters with their combining marks, so
+// n-grams of Thai never separate a vowel or tone mark from its consonant
+func graphemeClusters(text string) []string {
+	var clusters []string
+	for i, r := range text {
+		if len(clusters) > 0 && unicode.In(r, unicode.Mn, unicode.Mc) {
+			clusters[len(clusters)-1] += string(r)
+			continue
+		}
+		clusters = append(clusters, text[i:i+utf8.RuneLen(r)])
+	}
+	return clusters
+}
+
+// candidateStats accumulates the occurrences of one candidate
+type candidateStats struct {
+	parts       []string // lower-cased words, or characters, that make up the candidate
+	joiner      string   // " " between words, "" between characters
+	surfaces    map[string]int
+	frequency   int
+	documents   int
+	capitalized int
+	contexts    []string
+	lastDoc     int
+
+	// Neighbouring characters of character n-grams; an n-gram always followed or preceded
+	// by the same character is a fragment of a longer word
+	left, right map[string]bool
+	leftEdges   int // occurrences at the start of a run
+	rightEdges  int // occurrences at the end of a run
+}
+
+// isFragment reports whether a character n-gram lacks variety on either side
+func (s *candidateStats) isFragment() bool {
+	return s.joiner == "" && len(s.parts) > 1 && (len(s.left)+s.leftEdges < 2 || len(s.right)+s.rightEdges < 2)
+}
+
+// surface returns the most frequent spelling of the candidate
+func (s *candidateStats) surface() string {
+	best, count := "", 0
+	for _, surface := range slices.Sorted(maps.Keys(s.surfaces)) {
+		if s.surfaces[surface] > count {
+			best, count = surface, s.surfaces[surface]
+		}
+	}
+	return best
+}
+
+// candidateExtractor collects candidates from source strings
+type candidateExtractor struct {
+	stats     map[string]*candidateStats
+	documents int
+}
+
+// ExtractCandidates extracts term candidates from source strings without calling a model.
+// Candidates are sorted by descending score; limit <= 0 returns all of them.
+func ExtractCandidates(texts []string, limit int) []*CandidateWord {
+	x := &candidateExtractor{stats: make(map[string]*candidateStats)}
+	for _, text := range texts {
+		x.add(text)
+	}
+	return x.candidates(limit)
+}
+
+// add collects the candidates of one source string
+func (x *candidateExtractor) add(text string) {
+	doc := x.documents
+	x.documents++
+
+	segments := segmentText(text)
+	titleText := isTitleText(segments)
+
+	for _, segment := range segments {
+		for start := 0; start < len(segment); {
+			t := segment[start]
+			switch t.script {
+			case scriptSpaced:
+				end := start
+				for end < len(segment) && segment[end].script == scriptSpaced {
+					end++
+				}
+				x.addWordGrams(doc, text, segment[start:end], titleText)
+				start = end
+				continue
+			case scriptHan:
+				x.addCharGrams(doc, text, strings.Split(t.text, ""), maxHanGram)
+			case scriptKatakana:
+				if utf8.RuneCountInString(t.text) >= 2 {
+					x.observe(doc, text, []string{t.text}, "", t.text, false)
+				}
+			case scriptUnspaced:
+				x.addCharGrams(doc, text, graphemeClusters(t.text), maxClusterGram)
+			}
+			start++
+		}
+	}
+}
+
+// addWordGrams collects the word n-grams of a run of words. N-grams may contain stop
+// words ("Terms of Service") but don't start or end with one.
+func (x *candidateExtractor) addWordGrams(doc int, text string, words []token, titleText bool) {
+	for i := range words {
+		for n := 1; n <= maxWordGram && i+n <= len(words); n++ {
+			gram := words[i : i+n]
+			if !isCandidateEdge(gram[0].text) || !isCandidateEdge(gram[n-1].text) {
+				continue
+			}
+
+			parts := make([]string, n)
+			surfaces := make([]string, n)
+			for j, w := range gram {
+				parts[j] = strings.ToLower(w.text)
+				surfaces[j] = w.text
+			}
+			surface := strings.Join(surfaces, " ")
+			if utf8.RuneCountInString(surface) > maxCandidateLength {
+				break
+			}
+			x.observe(doc, text, parts, " ", surface, !titleText && isCapitalizedGram(gram))
+		}
+	}
+}
+
+// addCharGrams collects the character n-grams (2 to maxN characters) of a run of text
+// written without spaces
+func (x *candidateExtractor) addCharGrams(doc int, text string, chars []string, maxN int) {
+	for i := range chars {
+		for n := 2; n <= maxN && i+n <= len(chars); n++ {
+			parts := chars[i : i+n]
+			s := x.observe(doc, text, parts, "", strings.Join(parts, ""), false)
+			if i > 0 {
+				s.left[chars[i-1]] = true
+			} else {
+				s.leftEdges++
+			}
+			if i+n < len(chars) {
+				s.right[chars[i+n]] = true
+			} else {
+				s.rightEdges++
+			}
+		}
+	}
+}
+
+// observe records one occurrence of a candidate
+func (x *candidateExtractor) observe(doc int, text string, parts []string, joiner, surface string, capitalized bool) *candidateStats {
+	key := strings.Join(parts, joiner)
+	s, ok := x.stats[key]
+	if !ok {
+		s = &candidateStats{
+			parts:    slices.Clone(parts),
+			joiner:   joiner,
+			surfaces: make(map[string]int),
+			lastDoc:  -1,
+			left:     make(map[string]bool),
+			right:    make(map[string]bool),
+		}
+		x.stats[key] = s
+	}
+
+	s.frequency++
+	s.surfaces[surface]++
+	if capitalized {
+		s.capitalized++
+	}
+	if s.lastDoc != doc {
+		s.lastDoc = doc
+		s.documents++
+		if len(s.contexts) < maxCandidateContexts {
+			s.contexts = append(s.contexts, text)
+		}
+	}
+	return s
+}
+
+// candidates keeps the candidates seen often enough or with a distinctive form, drops
+// fragments of words written without spaces and candidates that only occur inside a
+// longer one ("Sign-On" in "Single Sign-On"), and scores the rest
+func (x *candidateExtractor) candidates(limit int) []*CandidateWord {
+	kept := make(map[string]*candidateStats)
+	for key, s := range x.stats {
+		distinctive := s.joiner == " " && len(s.parts) == 1 && isSpecialFormat(s.surface())
+		if (s.frequency >= minCandidateFrequency || distinctive) && !s.isFragment() {
+			kept[key] = s
+		}
+	}
+
+	covered := make(map[string]int) // highest frequency of a longer candidate containing the key
+	for _, s := range kept {
+		for i := range s.parts {
+			for j := i + 1; j <= len(s.parts); j++ {
+				if j-i == len(s.parts) {
+					continue
+				}
+				sub := strings.Join(s.parts[i:j], s.joiner)
+				covered[sub] = max(covered[sub], s.frequency)
+			}
+		}
+	}
+
+	result := make([]*CandidateWord, 0, len(kept))
+	for key, s := range kept {
+		if covered[key] >= s.frequency {
+			continue
+		}
+		result = append(result, x.score(s))
+	}
+
+	slices.SortFunc(result, func(a, b *CandidateWord) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(b.Frequency, a.Frequency),
+			strings.Compare(a.Word, b.Word),
+		)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// score computes the TF-IDF style score of a candidate: sublinear term frequency times the
+// inverse fraction of source strings containing it, boosted for phrases, capitalization
+// and distinctive forms
+func (x *candidateExtractor) score(s *candidateStats) *CandidateWord {
+	surface := s.surface()
+	var signals []string
+
+	score := (1 + math.Log(float64(s.frequency))) * math.Log(1+float64(x.documents)/float64(s.documents))
+
+	if s.joiner == " " {
+		if len(s.parts) > 1 {
+			score *= 1 + 0.5*float64(len(s.parts)-1)
+			signals = append(signals, signalMultiWord)
+		}
+		if ratio := float64(s.capitalized) / float64(s.frequency); ratio > 0 {
+			score *= 1 + 2*ratio
+			if ratio >= 0.5 {
+				signals = append(signals, signalCapitalized)
+			}
+		}
+		if len(s.parts) == 1 {
+			if form := wordForm(surface); form != "" {
+				score *= 2
+				signals = append(signals, form)
+			}
+		}
+	} else if len(s.parts) > 2 {
+		score *= 1 + 0.25*float64(len(s.parts)-2)
+	}
+
+	return &CandidateWord{
+		Word:      surface,
+		Frequency: s.frequency,
+		Documents: s.documents,
+		Score:     math.Round(score*1000) / 1000,
+		Signals:   signals,
+		Contexts:  s.contexts,
+	}
+}
+
+// isCandidateEdge reports whether a word can start or end a candidate: not a stop word,
+// at least two characters and at least one letter
+func isCandidateEdge(word string) bool {
+	if stopWords[strings.ToLower(word)] || utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// startsUpper reports whether a word starts with an upper-case letter
+func startsUpper(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// isCapitalizedGram reports whether every word of an n-gram but its stop words is
+// capitalized. A single capitalized word starting a sentence doesn't count.
+func isCapitalizedGram(gram []token) bool {
+	if len(gram) == 1 && gram[0].initial {
+		return false
+	}
+	for _, w := range gram {
+		if !startsUpper(w.text) && !stopWords[strings.ToLower(w.text)] {
+			return false
+		}
+	}
+	return true
+}
+
+// isTitleText reports whether a source string is written in Title Case ("Save Changes"),
+// in which case capitalization says nothing about its words
+func isTitleText(segments [][]token) bool {
+	words := 0
+	for _, segment := range segments {
+		for _, t := range segment {
+			if t.script != scriptSpaced || stopWords[strings.ToLower(t.text)] || strings.IndexFunc(t.text, unicode.IsLetter) < 0 {
+				continue
+			}
+			if !startsUpper(t.text) {
+				return false
+			}
+			words++
+		}
+	}
+	return words > 1
+}
+
+// wordForm returns the signal of a word with a distinctive form, if any
+func wordForm(word string) string {
+	var letters, upper, innerUpper, digits int
+	for i, r := range word {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsLetter(r):
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+				if i > 0 {
+					innerUpper++
+				}
+			}
+		}
+	}
+
+	switch {
+	case letters > 0 && digits > 0:
+		return signalAlphanumeric
+	case letters >= 2 && upper == letters:
+		return signalAcronym
+	case innerUpper > 0 && upper < letters:
+		return signalMixedCase
+	}
+	return ""
+}
+
+// isSpecialFormat checks if a word has special formatting (all-caps, version numbers, etc)
+func isSpecialFormat(word string) bool {
+	// All-caps (e.g., API, JSON)
+	if len(word) >= 2 && word == strings.ToUpper(word) && !strings.ContainsAny(word, " ") {
+		return true
+	}
+
+	// Contains version numbers (e.g., FLUX.1, GPT-4)
+	if strings.Contains(word, ".") || strings.ContainsAny(word, "0123456789") {
+		return true
+	}
+
+	// CamelCase (e.g., MyApp, OpenAI)
+	if len(word) > 1 && unicode.IsUpper(rune(word[0])) {
+		for i := 1; i < len(word); i++ {
+			if unicode.IsUpper(rune(word[i])) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// candidateReview is the candidates file written for review by offline detection
+type candidateReview struct {
+	SourceLanguage string           `json:"sourceLanguage"`
+	Strings        int              `json:"strings"` // source strings analysed
+	Candidates     []*CandidateWord `json:"candidates"`
+}
+
+// CandidatesPath returns the path of the candidates file in a terminology directory
+func CandidatesPath(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "candidates.json")
+}
+
+// saveCandidates writes candidates to the candidates file for review
+func saveCandidates(terminologyDir string, review *candidateReview) error {
+	data, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to marshal term candidates", err)
+	}
+	if err := os.MkdirAll(terminologyDir, 0755); err != nil {
+		return domain.NewIOError("failed to create terminology directory", err).
+			WithContext("path", terminologyDir)
+	}
+	if err := os.WriteFile(CandidatesPath(terminologyDir), data, 0644); err != nil {
+		return domain.NewIOError("failed to write term candidates", err).
+			WithContext("path", CandidatesPath(terminologyDir))
+	}
+	return nil
+}
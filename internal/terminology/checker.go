@@ -0,0 +1,225 @@
+package terminology
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// inflectionEndings are the endings nouns take with case or number in target languages
+// that inflect them, longest first. A pinned translation is matched without them.
+var inflectionEndings = map[string][]string{
+	domain.LangGerman:  {"en", "er", "es", "em", "e", "n", "s"},
+	domain.LangRussian: {"ами", "ями", "ого", "его", "ому", "ему", "ой", "ей", "ом", "ем", "ах", "ях", "ов", "ев", "ую", "юю", "ая", "яя", "ые", "ие", "ых", "их", "ым", "им", "а", "я", "ы", "и", "у", "ю", "е", "о", "ь", "й"},
+	domain.LangPolish:  {"ami", "ach", "owi", "om", "ów", "ie", "em", "a", "u", "y", "i", "e", "ę", "ą", "o"},
+	domain.LangTurkish: {"ları", "leri", "lar", "ler", "dan", "den", "tan", "ten", "nın", "nin", "nun", "nün", "da", "de", "ta", "te", "ın", "in", "un", "ün", "ı", "i", "u", "ü", "a", "e"},
+}
+
+// Checker verifies that translations follow the terminology: preserve terms must
+// appear verbatim and consistent terms must use their pinned translation
+type Checker struct {
+	terminology *domain.Terminology
+	translation *domain.TerminologyTranslation
+	endings     []string // inflectional endings of the target language
+}
+
+// NewChecker creates a terminology checker for a target language.
+// A nil terminology yields a checker that never reports violations.
+func NewChecker(terminology *domain.Terminology, translation *domain.TerminologyTranslation, targetLang string) *Checker {
+	lang, _ := domain.NormalizeLanguageCode(targetLang)
+	return &Checker{
+		terminology: terminology,
+		translation: translation,
+		endings:     inflectionEndings[lang],
+	}
+}
+
+// Check returns the terminology violations of a single translation
+func (c *Checker) Check(key, source, translated string) []domain.TermViolation {
+	if c == nil || c.terminology == nil {
+		return nil
+	}
+
+	var violations []domain.TermViolation
+
+	for _, term := range c.terminology.PreserveTerms {
+		info := c.terminology.Info(term)
+		if !inScope(info, key) || !containsWord(source, term, false) {
+			continue
+		}
+		if !strings.Contains(translated, term) {
+			violations = append(violations, domain.TermViolation{
+				Key:      key,
+				Term:     term,
+				Type:     domain.TermTypePreserve,
+				Expected: term,
+				Message:  fmt.Sprintf("%q must be kept untranslated", term),
+			})
+		}
+		violations = append(violations, c.checkForbidden(key, term, domain.TermTypePreserve, term, translated)...)
+	}
+
+	if c.translation == nil {
+		return violations
+	}
+	for _, term := range c.terminology.ConsistentTerms {
+		info := c.terminology.Info(term)
+		if !inScope(info, key) || !containsWord(source, term, !info.CaseSensitive) {
+			continue
+		}
+		expected := c.translation.Translations[term]
+		if expected != "" && !matchesTranslation(translated, expected, c.endings, info.CaseSensitive) {
+			violations = append(violations, domain.TermViolation{
+				Key:      key,
+				Term:     term,
+				Type:     domain.TermTypeConsistent,
+				Expected: expected,
+				Message:  fmt.Sprintf("%q must be translated as %q", term, expected),
+			})
+		}
+		violations = append(violations, c.checkForbidden(key, term, domain.TermTypeConsistent, expected, translated)...)
+	}
+
+	return violations
+}
+
+// checkForbidden reports forbidden translations of a term used in translated.
+// Occurrences inside the expected translation (e.g. "Konto" in "Benutzerkonto") don't count.
+func (c *Checker) checkForbidden(key, term string, termType domain.TermType, expected, translated string) []domain.TermViolation {
+	forbidden := c.translation.GetForbidden(term)
+	if len(forbidden) == 0 {
+		return nil
+	}
+
+	remaining := strings.ToLower(translated)
+	if expected != "" {
+		remaining = strings.ReplaceAll(remaining, strings.ToLower(expected), " ")
+	}
+
+	var violations []domain.TermViolation
+	for _, f := range forbidden {
+		if f == "" || !strings.Contains(remaining, strings.ToLower(f)) {
+			continue
+		}
+		violations = append(violations, domain.TermViolation{
+			Key:      key,
+			Term:     term,
+			Type:     termType,
+			Expected: expected,
+			Message:  fmt.Sprintf("%q must not be translated as %q", term, f),
+		})
+	}
+	return violations
+}
+
+// CheckAll checks translations against their source texts, ordered by key
+func (c *Checker) CheckAll(sources, translations map[string]string) []domain.TermViolation {
+	var violations []domain.TermViolation
+	for _, key := range slices.Sorted(maps.Keys(translations)) {
+		source, ok := sources[key]
+		if !ok {
+			continue
+		}
+		violations = append(violations, c.Check(key, source, translations[key])...)
+	}
+	return violations
+}
+
+// containsWord reports whether text contains term as a whole word. Consistent terms
+// (foldCase) also match in any case and with an English plural ending.
+func containsWord(text, term string, foldCase bool) bool {
+	if term == "" {
+		return false
+	}
+	if foldCase {
+		text = strings.ToLower(text)
+		term = strings.ToLower(term)
+	}
+
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], term)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(term)
+
+		if foldCase {
+			for _, suffix := range []string{"es", "s"} {
+				if strings.HasPrefix(text[end:], suffix) && isBoundary(text, end+len(suffix), term, false) {
+					end += len(suffix)
+					break
+				}
+			}
+		}
+		if isBoundary(text, start, term, true) && isBoundary(text, end, term, false) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isBoundary reports whether position pos of text is a word boundary for term.
+// Terms in scripts written without spaces (CJK, Thai) have no word boundaries to check.
+func isBoundary(text string, pos int, term string, before bool) bool {
+	var edge, neighbor rune
+	if before {
+		edge, _ = utf8.DecodeRuneInString(term)
+		if pos == 0 {
+			return true
+		}
+		neighbor, _ = utf8.DecodeLastRuneInString(text[:pos])
+	} else {
+		edge, _ = utf8.DecodeLastRuneInString(term)
+		if pos >= len(text) {
+			return true
+		}
+		neighbor, _ = utf8.DecodeRuneInString(text[pos:])
+	}
+
+	if !unicode.In(edge, unicode.Latin, unicode.Cyrillic, unicode.Greek, unicode.Digit) {
+		return true
+	}
+	return !unicode.IsLetter(neighbor) && !unicode.IsDigit(neighbor)
+}
+
+// matchesTranslation reports whether translated contains the expected term translation,
+// ignoring case unless the term is case sensitive. For inflected languages each word
+// only needs to match by its stem.
+func matchesTranslation(translated, expected string, endings []string, caseSensitive bool) bool {
+	if strings.Contains(translated, expected) {
+		return true
+	}
+	if !caseSensitive {
+		translated = strings.ToLower(translated)
+		expected = strings.ToLower(expected)
+		if strings.Contains(translated, expected) {
+			return true
+		}
+	}
+	if len(endings) == 0 {
+		return false
+	}
+
+	for _, word := range strings.Fields(expected) {
+		if !strings.Contains(translated, stem(word, endings)) {
+			return false
+		}
+	}
+	return true
+}
+
+// stem drops the first of the endings a word has, keeping at least three letters
+func stem(word string, endings []string) string {
+	for _, ending := range endings {
+		if stem, ok := strings.CutSuffix(word, ending); ok && utf8.RuneCountInString(stem) >= 3 {
+			return stem
+		}
+	}
+	return word
+}
@@ -0,0 +1,100 @@
+package terminology
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// detectionRecord remembers which source strings were already analysed for terms,
+// so detection can be re-run for new strings only
+type detectionRecord struct {
+	SourceLanguage string   `json:"sourceLanguage"`
+	Texts          []string `json:"texts"` // short hashes of analysed source strings
+
+	seen map[string]bool
+}
+
+func detectionRecordPath(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "detection.json")
+}
+
+// loadDetectionRecord reads the record; a missing file or a different source language
+// yields an empty record
+func loadDetectionRecord(terminologyDir, sourceLang string) (*detectionRecord, error) {
+	record := &detectionRecord{SourceLanguage: sourceLang, seen: make(map[string]bool)}
+
+	data, err := os.ReadFile(detectionRecordPath(terminologyDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return record, nil
+		}
+		return nil, domain.NewIOError("failed to read detection record", err).
+			WithContext("path", detectionRecordPath(terminologyDir))
+	}
+
+	var stored detectionRecord
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, domain.NewFormatError("failed to parse detection record", err).
+			WithContext("path", detectionRecordPath(terminologyDir))
+	}
+	if stored.SourceLanguage != sourceLang {
+		return record, nil
+	}
+
+	for _, h := range stored.Texts {
+		record.seen[h] = true
+	}
+	return record, nil
+}
+
+// unseen returns the texts that were not analysed yet
+func (r *detectionRecord) unseen(texts []string) []string {
+	var result []string
+	for _, text := range texts {
+		if !r.seen[textHash(text)] {
+			result = append(result, text)
+		}
+	}
+	return result
+}
+
+// mark records texts as analysed
+func (r *detectionRecord) mark(texts []string) {
+	for _, text := range texts {
+		r.seen[textHash(text)] = true
+	}
+}
+
+func (r *detectionRecord) save(terminologyDir string) error {
+	r.Texts = make([]string, 0, len(r.seen))
+	for h := range r.seen {
+		r.Texts = append(r.Texts, h)
+	}
+	slices.Sort(r.Texts)
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return domain.NewFormatError("failed to marshal detection record", err)
+	}
+	if err := os.MkdirAll(terminologyDir, 0755); err != nil {
+		return domain.NewIOError("failed to create terminology directory", err).
+			WithContext("path", terminologyDir)
+	}
+	if err := os.WriteFile(detectionRecordPath(terminologyDir), data, 0644); err != nil {
+		return domain.NewIOError("failed to write detection record", err).
+			WithContext("path", detectionRecordPath(terminologyDir))
+	}
+	return nil
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"time"
-	"unicode"
 
 	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 )
 
@@ -17,37 +20,56 @@ const (
 	// Below this threshold: use full LLM analysis (faster, more accurate)
 	// Above this threshold: use hybrid approach (statistical + LLM validation)
 	FULL_ANALYSIS_THRESHOLD = 20000
+
+	// MAX_VALIDATION_CANDIDATES is the number of best-scoring candidates sent to the LLM
+	// for validation in the hybrid approach
+	MAX_VALIDATION_CANDIDATES = 2000
+
+	// VALIDATION_BATCH_SIZE is the number of candidates validated per LLM call
+	VALIDATION_BATCH_SIZE = 500
 )
 
 // Detector handles terminology detection using LLM
 type Detector struct {
 	provider provider.AIProvider
+	prompts  *prompt.Templates
+	logger   *slog.Logger
 }
 
 // NewDetector creates a new detector
 func NewDetector(provider provider.AIProvider) *Detector {
 	return &Detector{
 		provider: provider,
+		prompts:  prompt.Default(),
+		logger:   slog.New(slog.DiscardHandler),
 	}
 }
 
+// SetPrompts sets the prompt templates
+func (d *Detector) SetPrompts(prompts *prompt.Templates) {
+	d.prompts = prompts
+}
+
+// SetLogger sets the logger of detection progress
+func (d *Detector) SetLogger(logger *slog.Logger) {
+	d.logger = logger
+}
+
 // DetectTerms detects terminology from texts using LLM
 func (d *Detector) DetectTerms(ctx context.Context, texts []string, sourceLang string) ([]domain.Term, error) {
-	fmt.Printf("   🔍 DetectTerms called with %d texts, language: %s\n", len(texts), sourceLang)
-
 	// Estimate token count
 	estimatedTokens := d.estimateTokens(texts)
-	fmt.Printf("   📊 Estimated tokens: %d (threshold: %d)\n", estimatedTokens, FULL_ANALYSIS_THRESHOLD)
+	d.logger.Debug("Detecting terms", "texts", len(texts), "language", sourceLang,
+		"estimated_tokens", estimatedTokens, "threshold", FULL_ANALYSIS_THRESHOLD)
 
 	// Choose strategy based on file size
 	if estimatedTokens <= FULL_ANALYSIS_THRESHOLD {
 		// Strategy A: Small file - Full LLM analysis
-		fmt.Printf("   ✅ Using full LLM analysis strategy\n")
 		return d.analyzeWithLLM(ctx, texts, sourceLang)
 	}
 
 	// Strategy B: Large file - Hybrid approach (statistical + LLM validation)
-	fmt.Printf("   ⚠️  File too large, using hybrid detection strategy\n")
+	d.logger.Info("Source too large for full analysis, using hybrid detection", "estimated_tokens", estimatedTokens)
 	return d.hybridDetection(ctx, texts, sourceLang)
 }
 
@@ -67,11 +89,13 @@ func (d *Detector) analyzeWithLLM(ctx context.Context, texts []string, lang stri
 	doc := d.buildFullDocument(texts)
 
 	// Build detection prompt
-	prompt := d.buildDetectionPrompt(doc, lang, len(texts))
+	prompt, err := d.buildDetectionPrompt(doc, lang, len(texts))
+	if err != nil {
+		return nil, err
+	}
 
-	estimatedTokens := d.estimateTokens(texts)
-	fmt.Printf("   📝 Analyzing %d texts with LLM (estimated ~%d tokens)...\n", len(texts), estimatedTokens)
-	fmt.Printf("   ⏳ This may take 30-60 seconds for large files, please wait...\n")
+	d.logger.Info("Analyzing texts for terms, this may take 30-60 seconds for large files",
+		"texts", len(texts), "estimated_tokens", d.estimateTokens(texts))
 
 	// Create independent 5-minute timeout for this LLM call
 	callCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -83,13 +107,12 @@ func (d *Detector) analyzeWithLLM(ctx context.Context, texts []string, lang stri
 	})
 
 	if err != nil {
-		fmt.Printf("   ❌ LLM call failed: %v\n", err)
 		return nil, domain.NewTerminologyError("LLM analysis failed", err).
 			WithContext("language", lang).
 			WithContext("text_count", len(texts))
 	}
 
-	fmt.Printf("   ✅ LLM response received (%d tokens)\n", resp.Usage.TotalTokens)
+	d.logger.Debug("Analysis received", "tokens", resp.Usage.TotalTokens)
 
 	// Parse result
 	return d.parseTermsFromJSON(resp.Content)
@@ -108,61 +131,12 @@ func (d *Detector) buildFullDocument(texts []string) string {
 }
 
 // buildDetectionPrompt builds the prompt for term detection
-func (d *Detector) buildDetectionPrompt(doc string, lang string, totalCount int) string {
-	return fmt.Sprintf(`You are an expert terminology analyst for JSON internationalization files.
-
-Your task: Analyze this COMPLETE %s JSON i18n file (containing %d texts) and identify terms that need special handling for translation consistency.
-
-<DOCUMENT>
-%s
-</DOCUMENT>
-
-Analysis Instructions:
-1. Read through the ENTIRE document carefully
-2. Notice which terms appear MULTIPLE TIMES in different contexts
-3. Consider term importance based on:
-   - Frequency of occurrence
-   - Context (technical, business, branding)
-   - Impact on translation consistency
-
-Identify TWO types of terms:
-
-A. PRESERVE (never translate):
-   - Brand names (e.g., "MyApp", "OpenAI")
-   - Technical terms (e.g., "API", "OAuth", "JSON")
-   - Product names with versions (e.g., "FLUX.1", "GPT-4")
-   - Proper nouns
-
-B. CONSISTENT (must translate uniformly):
-   - Business domain terms appearing multiple times
-   - Core concepts specific to this application
-   - Terms where inconsistent translation would confuse users
-
-Response Format (JSON only, no explanation):
-{
-  "preserveTerms": [
-    {
-      "term": "API",
-      "reason": "Technical acronym",
-      "frequency": 15,
-      "examples": ["API key", "API access", "API documentation"]
-    }
-  ],
-  "consistentTerms": [
-    {
-      "term": "credits",
-      "reason": "Core business concept",
-      "frequency": 23,
-      "examples": ["You have 10 credits", "Buy credits", "Unlimited credits"]
-    }
-  ]
-}
-
-Important:
-- Only include terms that appear in the document
-- Provide accurate frequency counts
-- Include 2-3 example usages for each term
-- Focus on quality over quantity (typically 5-15 terms total)`, lang, totalCount, doc)
+func (d *Detector) buildDetectionPrompt(doc string, lang string, totalCount int) (string, error) {
+	return d.prompts.Render(prompt.DetectTerms, prompt.DetectTermsData{
+		Language: lang,
+		Count:    totalCount,
+		Document: doc,
+	})
 }
 
 // parseTermsFromJSON parses terms from LLM JSON response
@@ -173,16 +147,20 @@ func (d *Detector) parseTermsFromJSON(content string) ([]domain.Term, error) {
 	// Parse JSON
 	var result struct {
 		PreserveTerms []struct {
-			Term      string   `json:"term"`
-			Reason    string   `json:"reason"`
-			Frequency int      `json:"frequency"`
-			Examples  []string `json:"examples"`
+			Term         string   `json:"term"`
+			Reason       string   `json:"reason"`
+			PartOfSpeech string   `json:"partOfSpeech"`
+			Definition   string   `json:"definition"`
+			Frequency    int      `json:"frequency"`
+			Examples     []string `json:"examples"`
 		} `json:"preserveTerms"`
 		ConsistentTerms []struct {
-			Term      string   `json:"term"`
-			Reason    string   `json:"reason"`
-			Frequency int      `json:"frequency"`
-			Examples  []string `json:"examples"`
+			Term         string   `json:"term"`
+			Reason       string   `json:"reason"`
+			PartOfSpeech string   `json:"partOfSpeech"`
+			Definition   string   `json:"definition"`
+			Frequency    int      `json:"frequency"`
+			Examples     []string `json:"examples"`
 		} `json:"consistentTerms"`
 	}
 
@@ -197,20 +175,24 @@ func (d *Detector) parseTermsFromJSON(content string) ([]domain.Term, error) {
 	// Add preserve terms
 	for _, t := range result.PreserveTerms {
 		terms = append(terms, domain.Term{
-			Term:    t.Term,
-			Type:    domain.TermTypePreserve,
-			Reason:  t.Reason,
-			Context: fmt.Sprintf("Frequency: %d, Examples: %s", t.Frequency, strings.Join(t.Examples, "; ")),
+			Term:         t.Term,
+			Type:         domain.TermTypePreserve,
+			Reason:       t.Reason,
+			Context:      fmt.Sprintf("Frequency: %d, Examples: %s", t.Frequency, strings.Join(t.Examples, "; ")),
+			PartOfSpeech: t.PartOfSpeech,
+			Definition:   t.Definition,
 		})
 	}
 
 	// Add consistent terms
 	for _, t := range result.ConsistentTerms {
 		terms = append(terms, domain.Term{
-			Term:    t.Term,
-			Type:    domain.TermTypeConsistent,
-			Reason:  t.Reason,
-			Context: fmt.Sprintf("Frequency: %d, Examples: %s", t.Frequency, strings.Join(t.Examples, "; ")),
+			Term:         t.Term,
+			Type:         domain.TermTypeConsistent,
+			Reason:       t.Reason,
+			Context:      fmt.Sprintf("Frequency: %d, Examples: %s", t.Frequency, strings.Join(t.Examples, "; ")),
+			PartOfSpeech: t.PartOfSpeech,
+			Definition:   t.Definition,
 		})
 	}
 
@@ -219,29 +201,27 @@ func (d *Detector) parseTermsFromJSON(content string) ([]domain.Term, error) {
 
 // CandidateWord represents a candidate term from statistical analysis
 type CandidateWord struct {
-	Word      string
-	Frequency int
-	Contexts  []string // Max 5 contexts
+	Word      string   `json:"term"`
+	Frequency int      `json:"frequency"`
+	Documents int      `json:"documents"` // source strings containing the candidate
+	Score     float64  `json:"score"`
+	Signals   []string `json:"signals,omitempty"`
+	Contexts  []string `json:"contexts"` // Max 5 contexts
 }
 
 // hybridDetection performs hybrid detection for large files
 // Step 1: Local statistical analysis (no LLM)
 // Step 2: LLM batch validation
 func (d *Detector) hybridDetection(ctx context.Context, texts []string, lang string) ([]domain.Term, error) {
-	fmt.Printf("   🔄 Step 1: Extracting candidate terms using statistical analysis...\n")
-
 	// Step 1: Extract candidate terms using local statistical analysis
 	candidates := d.extractCandidatesSimplified(texts)
-
-	fmt.Printf("   ✅ Found %d candidate terms\n", len(candidates))
+	d.logger.Info("Extracted candidate terms", "candidates", len(candidates))
 
 	if len(candidates) == 0 {
-		fmt.Printf("   ℹ️  No candidates found, returning empty list\n")
 		return []domain.Term{}, nil
 	}
 
 	// Step 2: Validate candidates with LLM
-	fmt.Printf("   🔄 Step 2: Validating candidates with LLM...\n")
 	return d.validateWithLLM(ctx, candidates, lang)
 }
 
@@ -297,187 +277,79 @@ func parseTermTranslations(content string, terms []string) (map[string]string, e
 	return translations, nil
 }
 
-// extractCandidatesSimplified extracts candidate terms using local statistical analysis
+// extractCandidatesSimplified extracts the best-scoring candidate terms using local
+// statistical analysis (see ExtractCandidates)
 func (d *Detector) extractCandidatesSimplified(texts []string) map[string]*CandidateWord {
 	candidates := make(map[string]*CandidateWord)
-
-	for _, text := range texts {
-		// Simple tokenization
-		words := d.simpleTokenize(text)
-
-		// Extract 1-3 word phrases
-		for i := range words {
-			// Single word
-			d.addCandidate(candidates, words[i], text)
-
-			// Bigram
-			if i+1 < len(words) {
-				phrase := words[i] + " " + words[i+1]
-				d.addCandidate(candidates, phrase, text)
-			}
-
-			// Trigram
-			if i+2 < len(words) {
-				phrase := words[i] + " " + words[i+1] + " " + words[i+2]
-				d.addCandidate(candidates, phrase, text)
-			}
-		}
-	}
-
-	return d.filterCandidates(candidates)
-}
-
-// addCandidate adds a word to candidates with frequency tracking
-func (d *Detector) addCandidate(candidates map[string]*CandidateWord, word string, context string) {
-	// Skip too short or too long
-	if len(word) < 2 || len(word) > 50 {
-		return
-	}
-
-	// Skip stop words
-	if d.isStopWord(word) {
-		return
-	}
-
-	word = strings.TrimSpace(word)
-
-	if cand, exists := candidates[word]; exists {
-		cand.Frequency++
-		// Keep max 5 contexts
-		if len(cand.Contexts) < 5 {
-			cand.Contexts = append(cand.Contexts, context)
-		}
-	} else {
-		candidates[word] = &CandidateWord{
-			Word:      word,
-			Frequency: 1,
-			Contexts:  []string{context},
-		}
-	}
-}
-
-// filterCandidates filters candidates based on frequency and format
-func (d *Detector) filterCandidates(candidates map[string]*CandidateWord) map[string]*CandidateWord {
-	filtered := make(map[string]*CandidateWord)
-
-	for word, info := range candidates {
-		// Keep if: frequency >= 3 OR special format
-		if info.Frequency >= 3 || d.isSpecialFormat(word) {
-			filtered[word] = info
-		}
+	for _, cand := range ExtractCandidates(texts, MAX_VALIDATION_CANDIDATES) {
+		candidates[cand.Word] = cand
 	}
-
-	return filtered
+	return candidates
 }
 
-// simpleTokenize performs simple tokenization without external libraries
+// simpleTokenize splits text into words, keeping runs of CJK and Thai text whole
 func (d *Detector) simpleTokenize(text string) []string {
-	// Replace punctuation with spaces (keep hyphens and dots)
-	text = strings.Map(func(r rune) rune {
-		if r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
-			return r
-		}
-		if unicode.IsSpace(r) || unicode.IsPunct(r) {
-			return ' '
-		}
-		return r
-	}, text)
-
-	words := strings.Fields(text)
-
-	// Normalize case (preserve all-caps words like API)
-	result := []string{}
-	for _, word := range words {
-		if word == strings.ToUpper(word) && len(word) >= 2 {
-			result = append(result, word) // Keep all-caps
-		} else {
-			result = append(result, strings.ToLower(word))
+	var words []string
+	for _, segment := range segmentText(text) {
+		for _, t := range segment {
+			words = append(words, t.text)
 		}
 	}
-
-	return result
+	return words
 }
 
 // isStopWord checks if a word is a common stop word
 func (d *Detector) isStopWord(word string) bool {
-	stopWords := map[string]bool{
-		"the": true, "a": true, "an": true, "and": true, "or": true,
-		"but": true, "in": true, "on": true, "at": true, "to": true,
-		"for": true, "of": true, "with": true, "by": true, "from": true,
-		"is": true, "are": true, "was": true, "were": true, "be": true,
-		"this": true, "that": true, "these": true, "those": true,
-		"your": true, "you": true, "it": true, "its": true,
-	}
-
 	return stopWords[strings.ToLower(word)]
 }
 
 // isSpecialFormat checks if a word has special formatting (all-caps, version numbers, etc)
 func (d *Detector) isSpecialFormat(word string) bool {
-	// All-caps (e.g., API, JSON)
-	if len(word) >= 2 && word == strings.ToUpper(word) && !strings.ContainsAny(word, " ") {
-		return true
-	}
-
-	// Contains version numbers (e.g., FLUX.1, GPT-4)
-	if strings.Contains(word, ".") || strings.ContainsAny(word, "0123456789") {
-		return true
-	}
-
-	// CamelCase (e.g., MyApp, OpenAI)
-	if len(word) > 1 && unicode.IsUpper(rune(word[0])) {
-		for i := 1; i < len(word); i++ {
-			if unicode.IsUpper(rune(word[i])) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return isSpecialFormat(word)
 }
 
 // validateWithLLM validates candidates with LLM in batches
 func (d *Detector) validateWithLLM(ctx context.Context, candidates map[string]*CandidateWord, lang string) ([]domain.Term, error) {
-	batches := d.batchCandidates(candidates, 500)
+	batches := d.batchCandidates(candidates, VALIDATION_BATCH_SIZE)
 
-	fmt.Printf("   📦 Split into %d batches (500 candidates per batch)\n", len(batches))
+	d.logger.Info("Validating candidates", "batches", len(batches), "batch_size", VALIDATION_BATCH_SIZE)
 
 	allTerms := []domain.Term{}
 
 	for i, batch := range batches {
-		fmt.Printf("   🔄 Validating batch %d/%d...\n", i+1, len(batches))
+		d.logger.Debug("Validating", "batch", i+1, "batches", len(batches))
 
 		terms, err := d.validateBatchWithLLM(ctx, batch, lang)
 		if err != nil {
 			// Retry once for 504 errors
 			if strings.Contains(err.Error(), "DEADLINE_EXCEEDED") || strings.Contains(err.Error(), "504") {
-				fmt.Printf("   ⚠️  Batch %d failed with timeout, retrying once...\n", i+1)
+				d.logger.Warn("Validation timed out, retrying once", "batch", i+1, "error", err)
 				time.Sleep(10 * time.Second) // Brief pause before retry
 				terms, err = d.validateBatchWithLLM(ctx, batch, lang)
 			}
 
 			if err != nil {
-				fmt.Printf("   ❌ Batch %d failed: %v\n", i+1, err)
 				return nil, domain.NewTerminologyError(fmt.Sprintf("batch %d validation failed", i+1), err)
 			}
 		}
 
-		fmt.Printf("   ✅ Batch %d completed, found %d terms\n", i+1, len(terms))
+		d.logger.Info("Validated", "batch", i+1, "terms", len(terms))
 		allTerms = append(allTerms, terms...)
 	}
 
-	fmt.Printf("   🎉 All batches completed, total %d terms found\n", len(allTerms))
+	d.logger.Info("Candidates validated", "terms", len(allTerms))
 	return allTerms, nil
 }
 
 // validateBatchWithLLM validates a single batch of candidates
 func (d *Detector) validateBatchWithLLM(ctx context.Context, batch []*CandidateWord, lang string) ([]domain.Term, error) {
-	prompt := d.buildValidationPrompt(batch, lang)
+	prompt, err := d.buildValidationPrompt(batch, lang)
+	if err != nil {
+		return nil, err
+	}
 
-	// Calculate prompt size
-	promptTokens := len(prompt) / 4 // rough estimate
-	fmt.Printf("      📊 Batch prompt size: %d chars (~%d tokens)\n", len(prompt), promptTokens)
-	fmt.Printf("      ⏳ Calling LLM API...\n")
+	// Rough estimate of the prompt size: 4 chars per token
+	d.logger.Debug("Calling LLM", "prompt_chars", len(prompt), "estimated_tokens", len(prompt)/4)
 
 	// Create independent 5-minute timeout for this LLM call
 	batchCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -492,68 +364,27 @@ func (d *Detector) validateBatchWithLLM(ctx context.Context, batch []*CandidateW
 	elapsed := time.Since(startTime)
 
 	if err != nil {
-		fmt.Printf("      ❌ LLM API failed after %.1fs: %v\n", elapsed.Seconds(), err)
+		d.logger.Debug("LLM call failed", "duration", elapsed, "error", err)
 		return nil, err
 	}
 
-	fmt.Printf("      ✅ LLM API succeeded in %.1fs (%d tokens)\n", elapsed.Seconds(), resp.Usage.TotalTokens)
+	d.logger.Debug("LLM call succeeded", "duration", elapsed, "tokens", resp.Usage.TotalTokens)
 
 	return d.parseValidationResult(resp.Content)
 }
 
 // buildValidationPrompt builds the LLM prompt for candidate validation
-func (d *Detector) buildValidationPrompt(candidates []*CandidateWord, lang string) string {
-	var builder strings.Builder
-
-	builder.WriteString(fmt.Sprintf(`You are a terminology validation expert for JSON i18n files.
-
-I have extracted candidate terms from a large %s JSON file using statistical analysis.
-Your task: Verify which candidates are TRUE TERMS that need special handling for translation.
-
-TRUE TERMS are:
-1. PRESERVE (never translate): brand names, technical terms, product names, proper nouns
-2. CONSISTENT (must translate uniformly): business domain terms, core concepts
-
-NOT TERMS (ignore these):
-- Common words that don't need special handling
-- Generic phrases
-- Complete sentences
-
-Below are the candidates with their frequency and example contexts:
-
-`, lang))
-
-	for i, cand := range candidates {
-		builder.WriteString(fmt.Sprintf("\n%d. Candidate: \"%s\"\n", i+1, cand.Word))
-		builder.WriteString(fmt.Sprintf("   Frequency: %d times in file\n", cand.Frequency))
-		builder.WriteString("   Example contexts:\n")
-		for j, ctx := range cand.Contexts {
-			if j >= 3 {
-				break
-			}
-			builder.WriteString(fmt.Sprintf("   - \"%s\"\n", ctx))
-		}
+func (d *Detector) buildValidationPrompt(candidates []*CandidateWord, lang string) (string, error) {
+	data := prompt.ValidateTermsData{Language: lang}
+	for _, cand := range candidates {
+		data.Candidates = append(data.Candidates, prompt.Candidate{
+			Word:      cand.Word,
+			Frequency: cand.Frequency,
+			Signals:   cand.Signals,
+			Examples:  cand.Contexts[:min(len(cand.Contexts), 3)],
+		})
 	}
-
-	builder.WriteString(`
-
-Return JSON array with your decisions (ONLY include terms where is_term is true):
-[
-  {
-    "term": "API",
-    "is_term": true,
-    "type": "preserve",
-    "reason": "Technical acronym, appears in multiple technical contexts"
-  },
-  {
-    "term": "user profile",
-    "is_term": true,
-    "type": "consistent",
-    "reason": "Core UI feature name, appears frequently across different contexts"
-  }
-]`)
-
-	return builder.String()
+	return d.prompts.Render(prompt.ValidateTerms, data)
 }
 
 // parseValidationResult parses LLM validation results
@@ -601,8 +432,8 @@ func (d *Detector) batchCandidates(candidates map[string]*CandidateWord, batchSi
 	batches := [][]*CandidateWord{}
 	currentBatch := []*CandidateWord{}
 
-	for _, cand := range candidates {
-		currentBatch = append(currentBatch, cand)
+	for _, word := range slices.Sorted(maps.Keys(candidates)) {
+		currentBatch = append(currentBatch, candidates[word])
 
 		if len(currentBatch) >= batchSize {
 			batches = append(batches, currentBatch)
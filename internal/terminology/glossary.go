@@ -0,0 +1,274 @@
+package terminology
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// GlossaryEntry is a term with its details and translations, the unit exchanged
+// with TBX and CSV glossaries
+type GlossaryEntry struct {
+	Term             string
+	Type             domain.TermType // empty if the glossary doesn't say
+	Info             domain.TermInfo
+	CaseSensitiveSet bool                // the glossary says whether Info.CaseSensitive
+	Translations     map[string]string   // language -> translation
+	Forbidden        map[string][]string // language -> translations that must not be used
+}
+
+// Glossary is a terminology together with its translations for every language
+type Glossary struct {
+	SourceLanguage string
+	Entries        []GlossaryEntry
+}
+
+// Languages returns the sorted target languages used by the glossary
+func (g *Glossary) Languages() []string {
+	langs := make(map[string]bool)
+	for _, e := range g.Entries {
+		for lang := range e.Translations {
+			langs[lang] = true
+		}
+		for lang := range e.Forbidden {
+			langs[lang] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(langs))
+}
+
+// GlossaryConflict is an imported value that differs from the existing terminology
+type GlossaryConflict struct {
+	Term     string
+	Language string // empty for source-side fields
+	Field    string // type, partOfSpeech, definition, context, scope, caseSensitive or translation
+	Existing string
+	Imported string
+}
+
+// GlossaryImportReport summarizes a glossary import
+type GlossaryImportReport struct {
+	AddedTerms        int
+	AddedTranslations int
+	Conflicts         []GlossaryConflict // kept as they were unless overwriting
+	UnknownLanguages  []string           // language codes that don't map to a supported language
+}
+
+// ExportGlossary loads the terminology and all of its translations as a glossary
+func (m *Manager) ExportGlossary(terminologyDir string) (*Glossary, error) {
+	terminology, err := m.LoadTerminology(terminologyDir)
+	if err != nil {
+		return nil, domain.NewTerminologyError("failed to load terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+
+	langs, err := m.TranslationLanguages(terminologyDir)
+	if err != nil {
+		return nil, err
+	}
+	translations := make([]*domain.TerminologyTranslation, 0, len(langs))
+	for _, lang := range langs {
+		translation, err := m.LoadTerminologyTranslation(terminologyDir, lang)
+		if err != nil {
+			return nil, domain.NewTerminologyError("failed to load terminology translation", err).
+				WithContext("target_lang", lang)
+		}
+		translations = append(translations, translation)
+	}
+
+	glossary := &Glossary{SourceLanguage: terminology.SourceLanguage}
+	add := func(term string, typ domain.TermType) {
+		entry := GlossaryEntry{
+			Term:             term,
+			Type:             typ,
+			Info:             terminology.Info(term),
+			CaseSensitiveSet: true,
+			Translations:     make(map[string]string),
+			Forbidden:        make(map[string][]string),
+		}
+		for _, translation := range translations {
+			if value, ok := translation.Translations[term]; ok && typ == domain.TermTypeConsistent {
+				entry.Translations[translation.TargetLanguage] = value
+			}
+			if forbidden := translation.GetForbidden(term); len(forbidden) > 0 {
+				entry.Forbidden[translation.TargetLanguage] = slices.Clone(forbidden)
+			}
+		}
+		glossary.Entries = append(glossary.Entries, entry)
+	}
+	for _, term := range terminology.PreserveTerms {
+		add(term, domain.TermTypePreserve)
+	}
+	for _, term := range terminology.ConsistentTerms {
+		add(term, domain.TermTypeConsistent)
+	}
+
+	return glossary, nil
+}
+
+// ImportGlossary merges a glossary into the terminology files. New terms, details and
+// translations are added; values that differ from existing ones are reported as conflicts
+// and only replaced when overwrite is set. Forbidden translations are always merged.
+func (m *Manager) ImportGlossary(terminologyDir string, glossary *Glossary, overwrite bool) (*GlossaryImportReport, error) {
+	terminology := &domain.Terminology{
+		SourceLanguage:  glossary.SourceLanguage,
+		PreserveTerms:   []string{},
+		ConsistentTerms: []string{},
+	}
+	if m.TerminologyExists(terminologyDir) {
+		var err error
+		if terminology, err = m.LoadTerminology(terminologyDir); err != nil {
+			return nil, domain.NewTerminologyError("failed to load terminology", err).
+				WithContext("dir", terminologyDir)
+		}
+		if glossary.SourceLanguage != "" && terminology.SourceLanguage != glossary.SourceLanguage {
+			return nil, domain.NewValidationError("glossary source language doesn't match the terminology", nil).
+				WithContext("terminology", terminology.SourceLanguage).
+				WithContext("glossary", glossary.SourceLanguage)
+		}
+	}
+
+	report := &GlossaryImportReport{}
+	conflict := func(term, lang, field, existing, imported string) {
+		report.Conflicts = append(report.Conflicts, GlossaryConflict{
+			Term: term, Language: lang, Field: field, Existing: existing, Imported: imported,
+		})
+	}
+
+	translations := make(map[string]*domain.TerminologyTranslation)
+	unknown := make(map[string]bool)
+	translationFor := func(lang string) (*domain.TerminologyTranslation, error) {
+		if translation, ok := translations[lang]; ok {
+			return translation, nil
+		}
+		if _, ok := domain.NormalizeLanguageCode(lang); !ok {
+			unknown[lang] = true
+		}
+		translation, err := m.loadOrCreateTranslation(terminologyDir, terminology.SourceLanguage, lang)
+		if err != nil {
+			return nil, err
+		}
+		translations[lang] = translation
+		return translation, nil
+	}
+
+	for _, entry := range glossary.Entries {
+		// A type or case sensitivity the glossary doesn't give leaves the existing one
+		typ := entry.Type
+		existing, known := terminology.TermType(entry.Term)
+		switch {
+		case !known:
+			typ = cmp.Or(typ, domain.TermTypeConsistent)
+			terminology.AddTerm(domain.Term{Term: entry.Term, Type: typ})
+			report.AddedTerms++
+		case typ == "":
+			typ = existing
+		case existing != typ:
+			conflict(entry.Term, "", "type", string(existing), string(typ))
+			if overwrite {
+				info := terminology.Info(entry.Term)
+				terminology.RemoveTerm(entry.Term)
+				terminology.AddTerm(domain.Term{Term: entry.Term, Type: typ})
+				terminology.SetInfo(entry.Term, info)
+			} else {
+				typ = existing
+			}
+		}
+		info := mergeTermInfo(entry.Term, terminology.Info(entry.Term), entry.Info, overwrite, conflict)
+		if entry.CaseSensitiveSet && info.CaseSensitive != entry.Info.CaseSensitive {
+			if known {
+				conflict(entry.Term, "", "caseSensitive", strconv.FormatBool(info.CaseSensitive), strconv.FormatBool(entry.Info.CaseSensitive))
+			}
+			if !known || overwrite {
+				info.CaseSensitive = entry.Info.CaseSensitive
+			}
+		}
+		terminology.SetInfo(entry.Term, info)
+
+		for _, lang := range slices.Sorted(maps.Keys(entry.Translations)) {
+			value := entry.Translations[lang]
+			if value == "" || typ == domain.TermTypePreserve {
+				continue
+			}
+			translation, err := translationFor(lang)
+			if err != nil {
+				return nil, err
+			}
+			current, ok := translation.Translations[entry.Term]
+			switch {
+			case !ok:
+				translation.AddTranslation(entry.Term, value)
+				report.AddedTranslations++
+			case current != value:
+				conflict(entry.Term, lang, "translation", current, value)
+				if overwrite {
+					translation.AddTranslation(entry.Term, value)
+				}
+			}
+		}
+
+		for _, lang := range slices.Sorted(maps.Keys(entry.Forbidden)) {
+			translation, err := translationFor(lang)
+			if err != nil {
+				return nil, err
+			}
+			for _, forbidden := range entry.Forbidden[lang] {
+				if forbidden != "" && forbidden != translation.Translations[entry.Term] {
+					translation.AddForbidden(entry.Term, forbidden)
+				}
+			}
+		}
+	}
+
+	if err := m.SaveTerminology(terminologyDir, terminology); err != nil {
+		return nil, domain.NewTerminologyError("failed to save terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+	for _, lang := range slices.Sorted(maps.Keys(translations)) {
+		if err := m.SaveTerminologyTranslation(terminologyDir, translations[lang]); err != nil {
+			return nil, domain.NewTerminologyError("failed to save terminology translation", err).
+				WithContext("target_lang", lang)
+		}
+	}
+
+	report.UnknownLanguages = slices.Sorted(maps.Keys(unknown))
+	return report, nil
+}
+
+// mergeTermInfo fills empty fields of existing with imported ones, reporting fields
+// where both are set and differ
+func mergeTermInfo(term string, existing, imported domain.TermInfo, overwrite bool, conflict func(term, lang, field, existing, imported string)) domain.TermInfo {
+	merged := existing
+	mergeField := func(field string, current *string, value string) {
+		switch {
+		case value == "" || value == *current:
+		case *current == "":
+			*current = value
+		default:
+			conflict(term, "", field, *current, value)
+			if overwrite {
+				*current = value
+			}
+		}
+	}
+
+	mergeField("partOfSpeech", &merged.PartOfSpeech, imported.PartOfSpeech)
+	mergeField("definition", &merged.Definition, imported.Definition)
+	mergeField("context", &merged.Context, imported.Context)
+	mergeField("reason", &merged.Reason, imported.Reason)
+
+	switch {
+	case len(imported.Scope) == 0 || slices.Equal(imported.Scope, existing.Scope):
+	case len(existing.Scope) == 0:
+		merged.Scope = slices.Clone(imported.Scope)
+	default:
+		conflict(term, "", "scope", joinList(existing.Scope), joinList(imported.Scope))
+		if overwrite {
+			merged.Scope = slices.Clone(imported.Scope)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,241 @@
+package terminology
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// CSV glossary columns. Every other column is a target language ("zh", "pt-BR") or its
+// forbidden translations ("zh:forbidden"). List cells (scope, forbidden) are separated by ";";
+// scope also by ",".
+const (
+	csvTerm          = "term"
+	csvType          = "type"
+	csvPartOfSpeech  = "partofspeech"
+	csvDefinition    = "definition"
+	csvContext       = "context"
+	csvCaseSensitive = "casesensitive"
+	csvScope         = "scope"
+	csvForbidden     = ":forbidden"
+)
+
+// csvAliases maps spreadsheet-friendly header names to columns
+var csvAliases = map[string]string{
+	"source":         csvTerm,
+	"pos":            csvPartOfSpeech,
+	"part of speech": csvPartOfSpeech,
+	"part_of_speech": csvPartOfSpeech,
+	"notes":          csvContext,
+	"note":           csvContext,
+	"case sensitive": csvCaseSensitive,
+	"case_sensitive": csvCaseSensitive,
+}
+
+// ParseCSV reads a CSV glossary with a header row. The term column is "term", or the
+// column named after the source language when there is none.
+func ParseCSV(r io.Reader, sourceLang string) (*Glossary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, domain.NewFormatError("failed to parse CSV", err)
+	}
+	if len(records) == 0 {
+		return nil, domain.NewFormatError("CSV glossary is empty", nil)
+	}
+
+	sourceLang = normalizeGlossaryLanguage(sourceLang)
+	header := records[0]
+	columns := make([]string, len(header))
+	termCol := -1
+	for i, name := range header {
+		col := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if alias, ok := csvAliases[col]; ok {
+			col = alias
+		}
+		switch col {
+		case csvTerm:
+			termCol = i
+		case csvType, csvPartOfSpeech, csvDefinition, csvContext, csvCaseSensitive, csvScope:
+		case "":
+			continue
+		default:
+			lang, forbidden := strings.CutSuffix(col, csvForbidden)
+			normalized, ok := domain.NormalizeLanguageCode(strings.TrimSpace(lang))
+			if !ok {
+				return nil, domain.NewFormatError(fmt.Sprintf("unknown CSV column %q (expected term, type, partOfSpeech, definition, context, caseSensitive, scope or a language code)", name), nil)
+			}
+			col = normalized
+			if forbidden {
+				col += csvForbidden
+			}
+			if termCol < 0 && !forbidden && normalized == sourceLang {
+				termCol = i
+				col = csvTerm
+			}
+		}
+		columns[i] = col
+	}
+	if termCol < 0 {
+		return nil, domain.NewFormatError(fmt.Sprintf("CSV glossary needs a %q or %q column", csvTerm, sourceLang), nil)
+	}
+
+	glossary := &Glossary{SourceLanguage: sourceLang}
+	for line, record := range records[1:] {
+		if termCol >= len(record) || strings.TrimSpace(record[termCol]) == "" {
+			continue
+		}
+		entry := GlossaryEntry{
+			Term:         strings.TrimSpace(record[termCol]),
+			Translations: make(map[string]string),
+			Forbidden:    make(map[string][]string),
+		}
+
+		for i, value := range record {
+			if i >= len(columns) || i == termCol {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch col := columns[i]; col {
+			case "":
+			case csvType:
+				typ, ok := parseTermType(value)
+				if !ok {
+					return nil, domain.NewFormatError(fmt.Sprintf("line %d: unknown term type %q (expected preserve or consistent)", line+2, value), nil)
+				}
+				entry.Type = typ
+			case csvPartOfSpeech:
+				entry.Info.PartOfSpeech = value
+			case csvDefinition:
+				entry.Info.Definition = value
+			case csvContext:
+				entry.Info.Context = value
+			case csvCaseSensitive:
+				entry.Info.CaseSensitive = isYes(value)
+				entry.CaseSensitiveSet = true
+			case csvScope:
+				entry.Info.Scope = splitListCell(value, ";,")
+			default:
+				if lang, ok := strings.CutSuffix(col, csvForbidden); ok {
+					entry.Forbidden[lang] = append(entry.Forbidden[lang], splitListCell(value, ";")...)
+				} else {
+					entry.Translations[col] = value
+				}
+			}
+		}
+
+		glossary.Entries = append(glossary.Entries, entry)
+	}
+
+	return glossary, nil
+}
+
+// WriteCSV writes a glossary as CSV with one column per target language, plus a
+// forbidden column for languages that have forbidden translations
+func WriteCSV(w io.Writer, glossary *Glossary) error {
+	langs := glossary.Languages()
+	var forbiddenLangs []string
+	for _, lang := range langs {
+		for _, e := range glossary.Entries {
+			if len(e.Forbidden[lang]) > 0 {
+				forbiddenLangs = append(forbiddenLangs, lang)
+				break
+			}
+		}
+	}
+
+	header := []string{"term", "type", "partOfSpeech", "definition", "context", "caseSensitive", "scope"}
+	header = append(header, langs...)
+	for _, lang := range forbiddenLangs {
+		header = append(header, lang+csvForbidden)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return domain.NewIOError("failed to write CSV", err)
+	}
+	for _, e := range glossary.Entries {
+		caseSensitive := ""
+		if e.Info.CaseSensitive || e.CaseSensitiveSet {
+			caseSensitive = yesNo(e.Info.CaseSensitive)
+		}
+		record := []string{e.Term, string(e.Type), e.Info.PartOfSpeech, e.Info.Definition, e.Info.Context, caseSensitive, joinList(e.Info.Scope)}
+		for _, lang := range langs {
+			record = append(record, e.Translations[lang])
+		}
+		for _, lang := range forbiddenLangs {
+			record = append(record, joinList(e.Forbidden[lang]))
+		}
+		if err := writer.Write(record); err != nil {
+			return domain.NewIOError("failed to write CSV", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return domain.NewIOError("failed to write CSV", err)
+	}
+	return nil
+}
+
+// parseTermType parses a term type cell
+func parseTermType(value string) (domain.TermType, bool) {
+	switch strings.ToLower(value) {
+	case "preserve", "dnt", "do not translate":
+		return domain.TermTypePreserve, true
+	case "consistent":
+		return domain.TermTypeConsistent, true
+	}
+	return "", false
+}
+
+// normalizeGlossaryLanguage normalizes a glossary language code, keeping unknown codes as-is
+func normalizeGlossaryLanguage(lang string) string {
+	if lang == "" {
+		return ""
+	}
+	normalized, _ := domain.NormalizeLanguageCode(lang)
+	return normalized
+}
+
+// isYes parses a yes/no cell
+func isYes(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "true", "1", "x":
+		return true
+	}
+	return false
+}
+
+// yesNo writes a yes/no cell
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// splitListCell splits a list cell at any of the separators. Translations may contain
+// commas, so only lists of key patterns are split at ",".
+func splitListCell(value, separators string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// joinList joins a list for a single cell or note
+func joinList(items []string) string {
+	return strings.Join(items, "; ")
+}
@@ -0,0 +1,175 @@
+package terminology
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// HTTPRepository implements Repository on a glossary service. The location is the
+// service's base URL, which serves:
+//
+//	GET/PUT {base}/terminology           the terminology (404 if none)
+//	GET     {base}/translations          the languages with translations, e.g. ["ja","zh"]
+//	GET/PUT {base}/translations/{lang}   the terminology translation of a language (404 if none)
+//
+// Bodies use the same JSON as the terminology files.
+type HTTPRepository struct {
+	token  string
+	client *http.Client
+}
+
+// NewHTTPRepository creates a repository for a glossary service. A non-empty token
+// is sent as a bearer token.
+func NewHTTPRepository(token string) *HTTPRepository {
+	return &HTTPRepository{
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Load loads terminology from the service
+func (r *HTTPRepository) Load(baseURL string) (*domain.Terminology, error) {
+	var terminology domain.Terminology
+	if err := r.get(baseURL, "terminology", &terminology); err != nil {
+		return nil, err
+	}
+	return &terminology, nil
+}
+
+// Save saves terminology to the service
+func (r *HTTPRepository) Save(baseURL string, terminology *domain.Terminology) error {
+	return r.put(baseURL, "terminology", terminology)
+}
+
+// Exists checks if the service has a terminology. Only a 404 means it has none: when
+// the service can't be asked, Exists reports true so loading the terminology reports
+// the error, instead of a new terminology replacing the one on the service.
+func (r *HTTPRepository) Exists(baseURL string) bool {
+	ok, err := r.exists(baseURL, "terminology")
+	return ok || err != nil
+}
+
+// LoadTranslation loads a terminology translation from the service
+func (r *HTTPRepository) LoadTranslation(baseURL, targetLang string) (*domain.TerminologyTranslation, error) {
+	var translation domain.TerminologyTranslation
+	if err := r.get(baseURL, "translations/"+url.PathEscape(targetLang), &translation); err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+// SaveTranslation saves a terminology translation to the service
+func (r *HTTPRepository) SaveTranslation(baseURL string, translation *domain.TerminologyTranslation) error {
+	return r.put(baseURL, "translations/"+url.PathEscape(translation.TargetLanguage), translation)
+}
+
+// TranslationExists checks if the service has a translation for a language; like
+// Exists, it reports true when the service can't be asked
+func (r *HTTPRepository) TranslationExists(baseURL, targetLang string) bool {
+	ok, err := r.exists(baseURL, "translations/"+url.PathEscape(targetLang))
+	return ok || err != nil
+}
+
+// Languages returns the languages with translations on the service, sorted
+func (r *HTTPRepository) Languages(baseURL string) ([]string, error) {
+	langs := []string{}
+	if err := r.get(baseURL, "translations", &langs); err != nil {
+		return nil, err
+	}
+	return langs, nil
+}
+
+// do sends a request and returns the response for 2xx and 404 statuses
+func (r *HTTPRepository) do(method, baseURL, path string, body any) (*http.Response, error) {
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/" + path
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, domain.NewFormatError("failed to marshal terminology", err).
+				WithContext("url", endpoint)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return nil, domain.NewConfigError("invalid glossary service URL", err).
+			WithContext("url", endpoint)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, domain.NewIOError("glossary service request failed", err).
+			WithContext("url", endpoint)
+	}
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		_ = resp.Body.Close()
+		return nil, domain.NewIOError(fmt.Sprintf("glossary service returned %s", resp.Status), nil).
+			WithContext("url", endpoint).
+			WithContext("response", strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+// get decodes a JSON resource, reporting a missing resource as a terminology error
+func (r *HTTPRepository) get(baseURL, path string, v any) error {
+	resp, err := r.do(http.MethodGet, baseURL, path, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.NewTerminologyError("terminology not found on glossary service", nil).
+			WithContext("url", resp.Request.URL.String())
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return domain.NewFormatError("failed to parse glossary service response", err).
+			WithContext("url", resp.Request.URL.String())
+	}
+	return nil
+}
+
+// put stores a JSON resource
+func (r *HTTPRepository) put(baseURL, path string, v any) error {
+	resp, err := r.do(http.MethodPut, baseURL, path, v)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.NewIOError("glossary service rejected the update", nil).
+			WithContext("url", resp.Request.URL.String())
+	}
+	return nil
+}
+
+// exists checks whether a resource is present: a 404 means it isn't, transport errors
+// and other statuses are errors
+func (r *HTTPRepository) exists(baseURL, path string) (bool, error) {
+	resp, err := r.do(http.MethodGet, baseURL, path, nil)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode != http.StatusNotFound, nil
+}
@@ -3,31 +3,65 @@ package terminology
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 )
 
 // Manager handles terminology detection and management
 type Manager struct {
-	provider              provider.AIProvider
-	detector              *Detector
-	termRepository        *TermRepository
-	translationRepository *TranslationRepository
+	provider   provider.AIProvider
+	detector   *Detector
+	repository Repository
+	location   string // fixed glossary location; empty = the terminology directory of each call
+	prompts    *prompt.Templates
+	logger     *slog.Logger
 }
 
-// NewManager creates a new terminology manager
+// NewManager creates a new terminology manager storing terminology in the terminology directory
 func NewManager(provider provider.AIProvider) *Manager {
-	detector := NewDetector(provider)
+	return NewManagerWithRepository(provider, NewDirectoryRepository(), "")
+}
+
+// NewManagerWithRepository creates a terminology manager on a repository. A non-empty
+// location (a glossary file or service URL) is used instead of the terminology directory,
+// so several projects can share one glossary; detection records stay in the directory.
+func NewManagerWithRepository(provider provider.AIProvider, repository Repository, location string) *Manager {
 	return &Manager{
-		provider:              provider,
-		detector:              detector,
-		termRepository:        NewTermRepository(),
-		translationRepository: NewTranslationRepository(),
+		provider:   provider,
+		detector:   NewDetector(provider),
+		repository: repository,
+		location:   location,
+		prompts:    prompt.Default(),
+		logger:     slog.New(slog.DiscardHandler),
 	}
 }
 
+// SetPrompts sets the prompt templates used for term detection and translation
+func (m *Manager) SetPrompts(prompts *prompt.Templates) {
+	m.prompts = prompts
+	m.detector.SetPrompts(prompts)
+}
+
+// SetLogger sets the logger of term detection and translation progress
+func (m *Manager) SetLogger(logger *slog.Logger) {
+	m.logger = logger
+	m.detector.SetLogger(logger)
+}
+
+// Location returns where the terminology of a terminology directory is stored
+func (m *Manager) Location(terminologyDir string) string {
+	if m.location != "" {
+		return m.location
+	}
+	return terminologyDir
+}
+
 // DetectTerms detects terms from a list of texts using LLM
 func (m *Manager) DetectTerms(ctx context.Context, texts []string, sourceLang string) ([]domain.Term, error) {
 	return m.detector.DetectTerms(ctx, texts, sourceLang)
@@ -35,32 +69,267 @@ func (m *Manager) DetectTerms(ctx context.Context, texts []string, sourceLang st
 
 // LoadTerminology loads terminology from directory
 func (m *Manager) LoadTerminology(terminologyDir string) (*domain.Terminology, error) {
-	return m.termRepository.Load(terminologyDir)
+	return m.repository.Load(m.Location(terminologyDir))
 }
 
 // SaveTerminology saves terminology to directory
 func (m *Manager) SaveTerminology(terminologyDir string, terminology *domain.Terminology) error {
-	return m.termRepository.Save(terminologyDir, terminology)
+	return m.repository.Save(m.Location(terminologyDir), terminology)
 }
 
 // TerminologyExists checks if terminology file exists
 func (m *Manager) TerminologyExists(terminologyDir string) bool {
-	return m.termRepository.Exists(terminologyDir)
+	return m.repository.Exists(m.Location(terminologyDir))
 }
 
 // LoadTerminologyTranslation loads terminology translation from directory
 func (m *Manager) LoadTerminologyTranslation(terminologyDir string, targetLang string) (*domain.TerminologyTranslation, error) {
-	return m.translationRepository.Load(terminologyDir, targetLang)
+	return m.repository.LoadTranslation(m.Location(terminologyDir), targetLang)
 }
 
 // SaveTerminologyTranslation saves terminology translation to directory
 func (m *Manager) SaveTerminologyTranslation(terminologyDir string, translation *domain.TerminologyTranslation) error {
-	return m.translationRepository.Save(terminologyDir, translation)
+	return m.repository.SaveTranslation(m.Location(terminologyDir), translation)
 }
 
 // TranslationExists checks if translation file exists
 func (m *Manager) TranslationExists(terminologyDir string, targetLang string) bool {
-	return m.translationRepository.Exists(terminologyDir, targetLang)
+	return m.repository.TranslationExists(m.Location(terminologyDir), targetLang)
+}
+
+// TranslationLanguages returns the target languages that have a terminology translation
+func (m *Manager) TranslationLanguages(terminologyDir string) ([]string, error) {
+	return m.repository.Languages(m.Location(terminologyDir))
+}
+
+// DetectCandidates extracts term candidates without calling the model (see ExtractCandidates)
+// and saves them to candidates.json in the terminology directory for review. Terms already
+// in the terminology are left out; limit <= 0 keeps every candidate.
+func (m *Manager) DetectCandidates(terminologyDir string, texts []string, sourceLang string, limit int) ([]*CandidateWord, error) {
+	known := make(map[string]bool)
+	if m.TerminologyExists(terminologyDir) {
+		terminology, err := m.LoadTerminology(terminologyDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, term := range slices.Concat(terminology.PreserveTerms, terminology.ConsistentTerms) {
+			known[strings.ToLower(term)] = true
+		}
+	}
+
+	candidates := []*CandidateWord{}
+	for _, cand := range ExtractCandidates(texts, 0) {
+		if known[strings.ToLower(cand.Word)] {
+			continue
+		}
+		candidates = append(candidates, cand)
+		if limit > 0 && len(candidates) == limit {
+			break
+		}
+	}
+
+	review := &candidateReview{SourceLanguage: sourceLang, Strings: len(texts), Candidates: candidates}
+	if err := saveCandidates(terminologyDir, review); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// DetectNewTerms detects terms only in texts that were not analysed before and returns
+// the terms that are not in the terminology yet, along with the number of texts analysed.
+// Analysed texts are recorded in the terminology directory.
+func (m *Manager) DetectNewTerms(ctx context.Context, terminologyDir string, texts []string, sourceLang string) ([]domain.Term, int, error) {
+	record, err := loadDetectionRecord(terminologyDir, sourceLang)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	unseen := record.unseen(texts)
+	if len(unseen) == 0 {
+		return nil, 0, nil
+	}
+
+	detected, err := m.detector.DetectTerms(ctx, unseen, sourceLang)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var known *domain.Terminology
+	if m.TerminologyExists(terminologyDir) {
+		if known, err = m.LoadTerminology(terminologyDir); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var terms []domain.Term
+	for _, t := range detected {
+		if known != nil {
+			if _, ok := known.TermType(t.Term); ok {
+				continue
+			}
+		}
+		terms = append(terms, t)
+	}
+
+	record.mark(unseen)
+	if err := record.save(terminologyDir); err != nil {
+		return nil, 0, err
+	}
+
+	return terms, len(unseen), nil
+}
+
+// RecordDetection marks texts as analysed so later DetectNewTerms calls skip them
+func (m *Manager) RecordDetection(terminologyDir string, texts []string, sourceLang string) error {
+	record, err := loadDetectionRecord(terminologyDir, sourceLang)
+	if err != nil {
+		return err
+	}
+	record.mark(texts)
+	return record.save(terminologyDir)
+}
+
+// RemoveTerm removes a term from the terminology and from every terminology translation.
+// It reports whether the term existed.
+func (m *Manager) RemoveTerm(terminologyDir, term string) (bool, error) {
+	terminology, err := m.LoadTerminology(terminologyDir)
+	if err != nil {
+		return false, domain.NewTerminologyError("failed to load terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+	if !terminology.RemoveTerm(term) {
+		return false, nil
+	}
+	if err := m.SaveTerminology(terminologyDir, terminology); err != nil {
+		return false, domain.NewTerminologyError("failed to save terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+
+	langs, err := m.TranslationLanguages(terminologyDir)
+	if err != nil {
+		return true, err
+	}
+	for _, lang := range langs {
+		translation, err := m.LoadTerminologyTranslation(terminologyDir, lang)
+		if err != nil {
+			return true, domain.NewTerminologyError("failed to load terminology translation", err).
+				WithContext("target_lang", lang)
+		}
+		_, translated := translation.Translations[term]
+		_, forbidden := translation.Forbidden[term]
+		if !translated && !forbidden {
+			continue
+		}
+		delete(translation.Translations, term)
+		delete(translation.Forbidden, term)
+		if err := m.SaveTerminologyTranslation(terminologyDir, translation); err != nil {
+			return true, domain.NewTerminologyError("failed to save terminology translation", err).
+				WithContext("target_lang", lang)
+		}
+	}
+
+	return true, nil
+}
+
+// SetTermTranslation pins the translation of a consistent term for a target language.
+// Unknown terms are added as consistent terms; preserve terms cannot be translated.
+func (m *Manager) SetTermTranslation(terminologyDir, term, targetLang, translation string) error {
+	terminology, err := m.LoadTerminology(terminologyDir)
+	if err != nil {
+		return domain.NewTerminologyError("failed to load terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+
+	typ, ok := terminology.TermType(term)
+	if typ == domain.TermTypePreserve {
+		return domain.NewValidationError("preserve terms are never translated", nil).
+			WithContext("term", term)
+	}
+	if !ok {
+		terminology.AddConsistentTerm(term)
+		if err := m.SaveTerminology(terminologyDir, terminology); err != nil {
+			return domain.NewTerminologyError("failed to save terminology", err).
+				WithContext("dir", terminologyDir)
+		}
+	}
+
+	termTranslation, err := m.loadOrCreateTranslation(terminologyDir, terminology.SourceLanguage, targetLang)
+	if err != nil {
+		return err
+	}
+	termTranslation.AddTranslation(term, translation)
+
+	if err := m.SaveTerminologyTranslation(terminologyDir, termTranslation); err != nil {
+		return domain.NewTerminologyError("failed to save terminology translation", err).
+			WithContext("target_lang", targetLang)
+	}
+	return nil
+}
+
+// ForbidTranslations records translations of a term that must never be used for a target language
+func (m *Manager) ForbidTranslations(terminologyDir, term, targetLang string, forbidden ...string) error {
+	terminology, err := m.LoadTerminology(terminologyDir)
+	if err != nil {
+		return domain.NewTerminologyError("failed to load terminology", err).
+			WithContext("dir", terminologyDir)
+	}
+	if _, ok := terminology.TermType(term); !ok {
+		return domain.NewValidationError("term is not in the terminology", nil).
+			WithContext("term", term)
+	}
+
+	termTranslation, err := m.loadOrCreateTranslation(terminologyDir, terminology.SourceLanguage, targetLang)
+	if err != nil {
+		return err
+	}
+	for _, f := range forbidden {
+		if f = strings.TrimSpace(f); f != "" {
+			termTranslation.AddForbidden(term, f)
+		}
+	}
+
+	if err := m.SaveTerminologyTranslation(terminologyDir, termTranslation); err != nil {
+		return domain.NewTerminologyError("failed to save terminology translation", err).
+			WithContext("target_lang", targetLang)
+	}
+	return nil
+}
+
+// loadOrCreateTranslation loads the terminology translation for a language, or creates an empty one
+func (m *Manager) loadOrCreateTranslation(terminologyDir, sourceLang, targetLang string) (*domain.TerminologyTranslation, error) {
+	if !m.TranslationExists(terminologyDir, targetLang) {
+		return domain.NewTerminologyTranslation(sourceLang, targetLang), nil
+	}
+
+	termTranslation, err := m.LoadTerminologyTranslation(terminologyDir, targetLang)
+	if err != nil {
+		return nil, domain.NewTerminologyError("failed to load terminology translation", err).
+			WithContext("target_lang", targetLang)
+	}
+	if termTranslation.Translations == nil {
+		termTranslation.Translations = make(map[string]string)
+	}
+	return termTranslation, nil
+}
+
+// DetectionPrompt renders the prompt DetectTerms sends for small files (full analysis)
+func (m *Manager) DetectionPrompt(texts []string, sourceLang string) (string, error) {
+	return m.detector.buildDetectionPrompt(m.detector.buildFullDocument(texts), sourceLang, len(texts))
+}
+
+// ValidationPrompt renders the first candidate validation prompt DetectTerms sends for
+// large files. It returns "" if the texts have no candidates.
+func (m *Manager) ValidationPrompt(texts []string, sourceLang string) (string, error) {
+	batches := m.detector.batchCandidates(m.detector.extractCandidatesSimplified(texts), VALIDATION_BATCH_SIZE)
+	if len(batches) == 0 {
+		return "", nil
+	}
+	return m.detector.buildValidationPrompt(batches[0], sourceLang)
+}
+
+// TermTranslationPrompt renders the prompt TranslateTerms sends
+func (m *Manager) TermTranslationPrompt(terms []string, sourceLang, targetLang string) (string, error) {
+	return m.buildTermTranslationPrompt(terms, sourceLang, targetLang)
 }
 
 // TranslateTerms translates terms to target language
@@ -70,9 +339,12 @@ func (m *Manager) TranslateTerms(ctx context.Context, terms []string, sourceLang
 	}
 
 	// Build prompt for term translation
-	prompt := m.buildTermTranslationPrompt(terms, sourceLang, targetLang)
+	prompt, err := m.buildTermTranslationPrompt(terms, sourceLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Printf("   📝 Calling LLM to translate %d terms...\n", len(terms))
+	m.logger.Info("Translating terms", "terms", len(terms), "target_lang", targetLang)
 
 	// Create independent 5-minute timeout for this LLM call
 	callCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -103,6 +375,12 @@ func (m *Manager) TranslateTerms(ctx context.Context, terms []string, sourceLang
 
 // BuildPromptDictionary builds a terminology dictionary for use in translation prompts
 func (m *Manager) BuildPromptDictionary(terminology *domain.Terminology, translation *domain.TerminologyTranslation) string {
+	return PromptDictionary(terminology, translation, nil)
+}
+
+// PromptDictionary builds the terminology dictionary for the prompt of a batch.
+// Only terms whose scope covers at least one of the keys are listed (nil keys = all terms).
+func PromptDictionary(terminology *domain.Terminology, translation *domain.TerminologyTranslation, keys []string) string {
 	if terminology == nil {
 		return ""
 	}
@@ -110,23 +388,40 @@ func (m *Manager) BuildPromptDictionary(terminology *domain.Terminology, transla
 	var lines []string
 
 	// Preserve terms (highest priority)
-	if len(terminology.PreserveTerms) > 0 {
-		lines = append(lines, "⚠️  CRITICAL - NEVER TRANSLATE THESE TERMS:")
-		for _, term := range terminology.PreserveTerms {
-			lines = append(lines, fmt.Sprintf("   \"%s\" → NEVER TRANSLATE, KEEP EXACTLY AS IS", term))
+	var preserve []string
+	for _, term := range terminology.PreserveTerms {
+		info := terminology.Info(term)
+		if !inScopeOfAny(info, keys) {
+			continue
 		}
+		preserve = append(preserve, fmt.Sprintf("   \"%s\"%s → NEVER TRANSLATE, KEEP EXACTLY AS IS", term, describeTerm(info)))
+	}
+	if len(preserve) > 0 {
+		lines = append(lines, "⚠️  CRITICAL - NEVER TRANSLATE THESE TERMS:")
+		lines = append(lines, preserve...)
 		lines = append(lines, "")
 	}
 
 	// Consistent terms
 	if translation != nil && len(terminology.ConsistentTerms) > 0 && len(translation.Translations) > 0 {
-		lines = append(lines, "📝 REQUIRED TRANSLATIONS:")
+		var required []string
 		for _, sourceTerm := range terminology.ConsistentTerms {
+			info := terminology.Info(sourceTerm)
+			if !inScopeOfAny(info, keys) {
+				continue
+			}
 			if targetTerm, ok := translation.Translations[sourceTerm]; ok {
-				lines = append(lines, fmt.Sprintf("   \"%s\" → \"%s\"", sourceTerm, targetTerm))
+				required = append(required, fmt.Sprintf("   \"%s\"%s → \"%s\"", sourceTerm, describeTerm(info), targetTerm))
+				if forbidden := translation.GetForbidden(sourceTerm); len(forbidden) > 0 {
+					required = append(required, fmt.Sprintf("      ✗ never use: %s", quoteAll(forbidden)))
+				}
 			}
 		}
-		lines = append(lines, "")
+		if len(required) > 0 {
+			lines = append(lines, "📝 REQUIRED TRANSLATIONS:")
+			lines = append(lines, required...)
+			lines = append(lines, "")
+		}
 	}
 
 	if len(lines) > 0 {
@@ -136,30 +431,30 @@ func (m *Manager) BuildPromptDictionary(terminology *domain.Terminology, transla
 	return joinLines(lines)
 }
 
-func (m *Manager) buildTermTranslationPrompt(terms []string, sourceLang, targetLang string) string {
-	termList := ""
-	for i, term := range terms {
-		termList += fmt.Sprintf("%d. \"%s\"\n", i+1, term)
+// describeTerm formats a term's part of speech and definition for the prompt
+func describeTerm(info domain.TermInfo) string {
+	description := info.Describe()
+	if description == "" {
+		return ""
 	}
+	return " (" + description + ")"
+}
 
-	return fmt.Sprintf(`You are a professional terminology translator.
-
-Translate the following %s terms to %s. These are domain-specific terms that need accurate translation.
-
-Terms to translate:
-%s
-
-Return ONLY a JSON object mapping each term to its translation:
-{
-  "term1": "translation1",
-  "term2": "translation2"
+// quoteAll quotes and joins texts for prompts and messages
+func quoteAll(texts []string) string {
+	quoted := make([]string, len(texts))
+	for i, text := range texts {
+		quoted[i] = fmt.Sprintf("%q", text)
+	}
+	return strings.Join(quoted, ", ")
 }
 
-Important:
-- Keep brand names and technical acronyms unchanged if they are typically not translated
-- Ensure consistency in terminology
-- Use the most appropriate translation for the context
-`, sourceLang, targetLang, termList)
+func (m *Manager) buildTermTranslationPrompt(terms []string, sourceLang, targetLang string) (string, error) {
+	return m.prompts.Render(prompt.TranslateTerms, prompt.TranslateTermsData{
+		SourceLang: sourceLang,
+		TargetLang: targetLang,
+		Terms:      terms,
+	})
 }
 
 func joinLines(lines []string) string {
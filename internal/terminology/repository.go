@@ -1,25 +1,116 @@
 package terminology
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/hikanner/jta/internal/domain"
+	"gopkg.in/yaml.v3"
 )
 
-// Repository defines the interface for terminology storage
+// Repository defines the interface for terminology storage. The location identifies
+// the glossary and depends on the store: a terminology directory, a glossary file or
+// the URL of a glossary service.
 type Repository interface {
-	Load(path string) (*domain.Terminology, error)
-	Save(path string, terminology *domain.Terminology) error
-	Exists(path string) bool
+	Load(location string) (*domain.Terminology, error)
+	Save(location string, terminology *domain.Terminology) error
+	Exists(location string) bool
+
+	LoadTranslation(location, targetLang string) (*domain.TerminologyTranslation, error)
+	SaveTranslation(location string, translation *domain.TerminologyTranslation) error
+	TranslationExists(location, targetLang string) bool
+	Languages(location string) ([]string, error)
+}
+
+// OpenRepository returns the repository for a terminology store: "" for the terminology
+// directory, a .json/.yaml/.yml path for a single glossary file shared across projects,
+// or an http(s) URL for a glossary service (authenticated with token, if set).
+func OpenRepository(store, token string) (Repository, error) {
+	switch {
+	case store == "":
+		return NewDirectoryRepository(), nil
+	case strings.HasPrefix(store, "http://") || strings.HasPrefix(store, "https://"):
+		return NewHTTPRepository(token), nil
+	}
+
+	switch strings.ToLower(filepath.Ext(store)) {
+	case ".json":
+		return NewJSONGlossaryRepository(), nil
+	case ".yaml", ".yml":
+		return NewYAMLGlossaryRepository(), nil
+	}
+	return nil, domain.NewConfigError("unsupported terminology store (use a .json/.yaml file or an http(s) URL)", nil).
+		WithContext("store", store)
+}
+
+// DirectoryRepository implements Repository with terminology.json and
+// terminology.<lang>.json files in a terminology directory
+type DirectoryRepository struct {
+	terms        *TermRepository
+	translations *TranslationRepository
+}
+
+// NewDirectoryRepository creates a new directory repository
+func NewDirectoryRepository() *DirectoryRepository {
+	return &DirectoryRepository{
+		terms:        NewTermRepository(),
+		translations: NewTranslationRepository(),
+	}
+}
+
+// Load loads terminology from the directory
+func (r *DirectoryRepository) Load(terminologyDir string) (*domain.Terminology, error) {
+	return r.terms.Load(terminologyDir)
+}
+
+// Save saves terminology to the directory
+func (r *DirectoryRepository) Save(terminologyDir string, terminology *domain.Terminology) error {
+	return r.terms.Save(terminologyDir, terminology)
 }
 
-// JSONRepository implements Repository using JSON files
-type JSONRepository struct{}
+// Exists checks if the directory has a terminology file
+func (r *DirectoryRepository) Exists(terminologyDir string) bool {
+	return r.terms.Exists(terminologyDir)
+}
+
+// LoadTranslation loads a terminology translation from the directory
+func (r *DirectoryRepository) LoadTranslation(terminologyDir, targetLang string) (*domain.TerminologyTranslation, error) {
+	return r.translations.Load(terminologyDir, targetLang)
+}
+
+// SaveTranslation saves a terminology translation to the directory
+func (r *DirectoryRepository) SaveTranslation(terminologyDir string, translation *domain.TerminologyTranslation) error {
+	return r.translations.Save(terminologyDir, translation)
+}
+
+// TranslationExists checks if the directory has a translation file for a language
+func (r *DirectoryRepository) TranslationExists(terminologyDir, targetLang string) bool {
+	return r.translations.Exists(terminologyDir, targetLang)
+}
+
+// Languages returns the languages with a translation file, sorted
+func (r *DirectoryRepository) Languages(terminologyDir string) ([]string, error) {
+	return r.translations.Languages(terminologyDir)
+}
+
+// JSONRepository implements Repository with the terminology in a JSON file and its
+// translations in terminology.<lang>.json files next to it
+type JSONRepository struct {
+	translations *TranslationRepository
+}
+
+var _ Repository = (*JSONRepository)(nil)
 
 // NewJSONRepository creates a new JSON repository
 func NewJSONRepository() *JSONRepository {
-	return &JSONRepository{}
+	return &JSONRepository{translations: NewTranslationRepository()}
 }
 
 // Load loads terminology from a JSON file
@@ -64,3 +155,264 @@ func (r *JSONRepository) Exists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// LoadTranslation loads a terminology translation from the file's directory
+func (r *JSONRepository) LoadTranslation(path, targetLang string) (*domain.TerminologyTranslation, error) {
+	return r.translations.Load(filepath.Dir(path), targetLang)
+}
+
+// SaveTranslation saves a terminology translation to the file's directory
+func (r *JSONRepository) SaveTranslation(path string, translation *domain.TerminologyTranslation) error {
+	return r.translations.Save(filepath.Dir(path), translation)
+}
+
+// TranslationExists checks if the file's directory has a translation file for a language
+func (r *JSONRepository) TranslationExists(path, targetLang string) bool {
+	return r.translations.Exists(filepath.Dir(path), targetLang)
+}
+
+// Languages returns the languages with a translation file next to the terminology file, sorted
+func (r *JSONRepository) Languages(path string) ([]string, error) {
+	return r.translations.Languages(filepath.Dir(path))
+}
+
+// glossaryDocument is the single-file glossary of the JSON and YAML glossary repositories:
+// the terminology plus the translations and forbidden translations of every language
+type glossaryDocument struct {
+	SourceLanguage  string                         `json:"sourceLanguage" yaml:"sourceLanguage"`
+	PreserveTerms   []string                       `json:"preserveTerms" yaml:"preserveTerms"`
+	ConsistentTerms []string                       `json:"consistentTerms" yaml:"consistentTerms"`
+	Details         map[string]domain.TermInfo     `json:"details,omitempty" yaml:"details,omitempty"`
+	Translations    map[string]map[string]string   `json:"translations,omitempty" yaml:"translations,omitempty"` // language -> term -> translation
+	Forbidden       map[string]map[string][]string `json:"forbidden,omitempty" yaml:"forbidden,omitempty"`       // language -> term -> forbidden translations
+}
+
+// fileRepository implements Repository on a single glossary file
+type fileRepository struct {
+	format    string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// JSONGlossaryRepository implements Repository using a single JSON glossary file
+type JSONGlossaryRepository struct {
+	fileRepository
+}
+
+// NewJSONGlossaryRepository creates a new JSON glossary repository
+func NewJSONGlossaryRepository() *JSONGlossaryRepository {
+	return &JSONGlossaryRepository{fileRepository{
+		format:    "JSON",
+		marshal:   func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") },
+		unmarshal: json.Unmarshal,
+	}}
+}
+
+// YAMLGlossaryRepository implements Repository using a single YAML glossary file
+type YAMLGlossaryRepository struct {
+	fileRepository
+}
+
+// NewYAMLGlossaryRepository creates a new YAML glossary repository
+func NewYAMLGlossaryRepository() *YAMLGlossaryRepository {
+	return &YAMLGlossaryRepository{fileRepository{
+		format:    "YAML",
+		marshal:   marshalYAML,
+		unmarshal: yaml.Unmarshal,
+	}}
+}
+
+// marshalYAML encodes with the 2-space indentation people use for hand-edited YAML
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// read loads the glossary file
+func (r *fileRepository) read(path string) (*glossaryDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, domain.NewIOError("failed to read terminology file", err).
+			WithContext("path", path)
+	}
+
+	var doc glossaryDocument
+	if err := r.unmarshal(data, &doc); err != nil {
+		return nil, domain.NewFormatError("failed to parse terminology "+r.format, err).
+			WithContext("path", path)
+	}
+	return &doc, nil
+}
+
+// fileLocks serializes the writes of a terminology file, by absolute path, across
+// repositories: translations running at the same time save their term translations
+// into the same file
+var fileLocks sync.Map // path -> *sync.Mutex
+
+// lockFile locks a terminology file for an update and returns the unlock
+func lockFile(path string) func() {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	mu, _ := fileLocks.LoadOrStore(path, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// update applies a change to the glossary file, creating it if needed
+func (r *fileRepository) update(path string, change func(doc *glossaryDocument)) error {
+	unlock := lockFile(path)
+	defer unlock()
+
+	doc := &glossaryDocument{}
+	if r.Exists(path) {
+		var err error
+		if doc, err = r.read(path); err != nil {
+			return err
+		}
+	}
+	change(doc)
+
+	data, err := r.marshal(doc)
+	if err != nil {
+		return domain.NewFormatError("failed to marshal terminology", err).
+			WithContext("path", path)
+	}
+	dir := filepath.Dir(path)
+	if dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return domain.NewIOError("failed to create terminology directory", err).
+				WithContext("path", dir)
+		}
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return domain.NewIOError("failed to write terminology file", err).
+			WithContext("path", path)
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file and renames it, so readers never see a
+// truncated file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Chmod(0644), tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Load loads terminology from the glossary file
+func (r *fileRepository) Load(path string) (*domain.Terminology, error) {
+	doc, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Terminology{
+		SourceLanguage:  doc.SourceLanguage,
+		PreserveTerms:   doc.PreserveTerms,
+		ConsistentTerms: doc.ConsistentTerms,
+		Details:         doc.Details,
+	}, nil
+}
+
+// Save saves terminology to the glossary file, keeping its translations
+func (r *fileRepository) Save(path string, terminology *domain.Terminology) error {
+	return r.update(path, func(doc *glossaryDocument) {
+		doc.SourceLanguage = terminology.SourceLanguage
+		doc.PreserveTerms = terminology.PreserveTerms
+		doc.ConsistentTerms = terminology.ConsistentTerms
+		doc.Details = terminology.Details
+	})
+}
+
+// Exists checks if the glossary file exists
+func (r *fileRepository) Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// LoadTranslation loads the translations of one language from the glossary file
+func (r *fileRepository) LoadTranslation(path, targetLang string) (*domain.TerminologyTranslation, error) {
+	doc, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := doc.Translations[targetLang]; !ok {
+		return nil, domain.NewTerminologyError("no terminology translation for language", nil).
+			WithContext("path", path).
+			WithContext("target_lang", targetLang)
+	}
+
+	translation := domain.NewTerminologyTranslation(doc.SourceLanguage, targetLang)
+	maps.Copy(translation.Translations, doc.Translations[targetLang])
+	if len(doc.Forbidden[targetLang]) > 0 {
+		translation.Forbidden = maps.Clone(doc.Forbidden[targetLang])
+	}
+	return translation, nil
+}
+
+// SaveTranslation saves the translations of one language to the glossary file
+func (r *fileRepository) SaveTranslation(path string, translation *domain.TerminologyTranslation) error {
+	return r.update(path, func(doc *glossaryDocument) {
+		lang := translation.TargetLanguage
+		if doc.Translations == nil {
+			doc.Translations = make(map[string]map[string]string)
+		}
+		doc.Translations[lang] = translation.Translations
+		if doc.Translations[lang] == nil {
+			doc.Translations[lang] = make(map[string]string)
+		}
+
+		if len(translation.Forbidden) > 0 {
+			if doc.Forbidden == nil {
+				doc.Forbidden = make(map[string]map[string][]string)
+			}
+			doc.Forbidden[lang] = translation.Forbidden
+		} else {
+			delete(doc.Forbidden, lang)
+		}
+	})
+}
+
+// TranslationExists checks if the glossary file has translations for a language
+func (r *fileRepository) TranslationExists(path, targetLang string) bool {
+	if !r.Exists(path) {
+		return false
+	}
+	doc, err := r.read(path)
+	if err != nil {
+		return false
+	}
+	_, ok := doc.Translations[targetLang]
+	return ok
+}
+
+// Languages returns the languages with translations in the glossary file, sorted
+func (r *fileRepository) Languages(path string) ([]string, error) {
+	if !r.Exists(path) {
+		return []string{}, nil
+	}
+	doc, err := r.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(doc.Translations)), nil
+}
@@ -0,0 +1,41 @@
+package terminology
+
+import (
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/keyfilter"
+)
+
+// inScope reports whether a term applies to a key. Terms without a scope apply
+// everywhere; invalid scope patterns never match.
+func inScope(info domain.TermInfo, key string) bool {
+	if len(info.Scope) == 0 {
+		return true
+	}
+
+	filter := keyfilter.NewFilter()
+	patterns, err := filter.ParsePatterns(strings.Join(info.Scope, ","))
+	if err != nil {
+		return false
+	}
+	for _, pattern := range patterns {
+		if filter.MatchKey(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// inScopeOfAny reports whether a term applies to at least one of the keys (nil = all keys)
+func inScopeOfAny(info domain.TermInfo, keys []string) bool {
+	if keys == nil || len(info.Scope) == 0 {
+		return true
+	}
+	for _, key := range keys {
+		if inScope(info, key) {
+			return true
+		}
+	}
+	return false
+}
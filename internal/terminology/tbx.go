@@ -0,0 +1,287 @@
+package terminology
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// TBX data categories jta reads and writes. The x- categories carry jta settings
+// that have no standard TBX equivalent.
+const (
+	tbxPartOfSpeech   = "partOfSpeech"
+	tbxDefinition     = "definition"
+	tbxContext        = "context"
+	tbxAdminStatus    = "administrativeStatus"
+	tbxNormativeAuth  = "normativeAuthorization" // TBX 2008 name of the term status
+	tbxDoNotTranslate = "x-doNotTranslate"
+	tbxCaseSensitive  = "x-caseSensitive"
+	tbxScope          = "x-scope"
+
+	tbxPreferred  = "preferredTerm-admn-sts"
+	tbxDeprecated = "deprecatedTerm-admn-sts"
+)
+
+// tbxDocument mirrors the parts of TBX jta reads. It accepts both TBX 2019
+// (<tbx>, <conceptEntry>, <langSec>, <termSec>) and TBX 2008 (<martif>, <termEntry>,
+// <langSet>, <tig>/<ntig>) documents.
+type tbxDocument struct {
+	Lang           string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Concepts       []tbxConcept `xml:"text>body>conceptEntry"`
+	LegacyConcepts []tbxConcept `xml:"text>body>termEntry"`
+}
+
+type tbxConcept struct {
+	Descrips    []tbxNote    `xml:"descrip"`
+	DescripGrps []tbxNote    `xml:"descripGrp>descrip"`
+	LangSecs    []tbxLangSec `xml:"langSec"`
+	LangSets    []tbxLangSec `xml:"langSet"`
+}
+
+type tbxLangSec struct {
+	Lang        string       `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Descrips    []tbxNote    `xml:"descrip"`
+	DescripGrps []tbxNote    `xml:"descripGrp>descrip"`
+	TermSecs    []tbxTermSec `xml:"termSec"`
+	Tigs        []tbxTermSec `xml:"tig"`
+	Ntigs       []tbxNtig    `xml:"ntig"`
+}
+
+type tbxTermSec struct {
+	Term        string    `xml:"term"`
+	TermNotes   []tbxNote `xml:"termNote"`
+	Descrips    []tbxNote `xml:"descrip"`
+	DescripGrps []tbxNote `xml:"descripGrp>descrip"`
+}
+
+type tbxNtig struct {
+	TermGrp     tbxTermSec `xml:"termGrp"`
+	Descrips    []tbxNote  `xml:"descrip"`
+	DescripGrps []tbxNote  `xml:"descripGrp>descrip"`
+}
+
+type tbxNote struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// noteValue returns the first non-empty value of a data category
+func noteValue(typ string, groups ...[]tbxNote) string {
+	for _, notes := range groups {
+		for _, n := range notes {
+			if n.Type == typ && strings.TrimSpace(n.Value) != "" {
+				return strings.TrimSpace(n.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// terms returns the terms of a language section in document order
+func (l tbxLangSec) terms() []tbxTermSec {
+	terms := slices.Concat(l.TermSecs, l.Tigs)
+	for _, n := range l.Ntigs {
+		t := n.TermGrp
+		t.Descrips = slices.Concat(t.Descrips, n.Descrips)
+		t.DescripGrps = slices.Concat(t.DescripGrps, n.DescripGrps)
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// deprecated reports whether a term is marked deprecated or superseded
+func (t tbxTermSec) deprecated() bool {
+	status := noteValue(tbxAdminStatus, t.TermNotes)
+	if status == "" {
+		status = noteValue(tbxNormativeAuth, t.TermNotes)
+	}
+	return strings.HasPrefix(status, "deprecated") || strings.HasPrefix(status, "superseded")
+}
+
+// ParseTBX reads a TBX glossary. The source language is sourceLang if given, otherwise
+// the document's xml:lang. In every language section the first term that isn't deprecated
+// is the term (or its translation); deprecated terms become forbidden translations.
+func ParseTBX(r io.Reader, sourceLang string) (*Glossary, error) {
+	var doc tbxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, domain.NewFormatError("failed to parse TBX", err)
+	}
+
+	if sourceLang == "" {
+		sourceLang = doc.Lang
+	}
+	if sourceLang == "" {
+		return nil, domain.NewValidationError("TBX document has no xml:lang, the source language must be given", nil)
+	}
+	sourceLang = normalizeGlossaryLanguage(sourceLang)
+
+	glossary := &Glossary{SourceLanguage: sourceLang}
+	for _, concept := range slices.Concat(doc.Concepts, doc.LegacyConcepts) {
+		langSecs := slices.Concat(concept.LangSecs, concept.LangSets)
+
+		var source *tbxLangSec
+		for j := range langSecs {
+			if normalizeGlossaryLanguage(langSecs[j].Lang) == sourceLang {
+				source = &langSecs[j]
+				break
+			}
+		}
+		if source == nil {
+			continue
+		}
+		sourceTerms := source.terms()
+		idx := slices.IndexFunc(sourceTerms, func(t tbxTermSec) bool { return !t.deprecated() && strings.TrimSpace(t.Term) != "" })
+		if idx < 0 {
+			continue
+		}
+		term := sourceTerms[idx]
+
+		entry := GlossaryEntry{
+			Term: strings.TrimSpace(term.Term),
+			Info: domain.TermInfo{
+				PartOfSpeech: noteValue(tbxPartOfSpeech, term.TermNotes),
+				Definition:   noteValue(tbxDefinition, term.Descrips, term.DescripGrps, source.Descrips, source.DescripGrps, concept.Descrips, concept.DescripGrps),
+				Context:      noteValue(tbxContext, term.Descrips, term.DescripGrps),
+			},
+			Translations: make(map[string]string),
+			Forbidden:    make(map[string][]string),
+		}
+		// Without these notes the type and case sensitivity are left to the terminology
+		if doNotTranslate := noteValue(tbxDoNotTranslate, term.TermNotes, term.Descrips, concept.Descrips); doNotTranslate != "" {
+			entry.Type = domain.TermTypeConsistent
+			if isYes(doNotTranslate) {
+				entry.Type = domain.TermTypePreserve
+			}
+		}
+		if caseSensitive := noteValue(tbxCaseSensitive, term.TermNotes, term.Descrips, concept.Descrips); caseSensitive != "" {
+			entry.Info.CaseSensitive = isYes(caseSensitive)
+			entry.CaseSensitiveSet = true
+		}
+		if scope := noteValue(tbxScope, term.Descrips, concept.Descrips); scope != "" {
+			entry.Info.Scope = splitListCell(scope, ";,")
+		}
+
+		for _, langSec := range langSecs {
+			lang := normalizeGlossaryLanguage(langSec.Lang)
+			if lang == sourceLang || lang == "" {
+				continue
+			}
+			for _, t := range langSec.terms() {
+				value := strings.TrimSpace(t.Term)
+				switch {
+				case value == "":
+				case t.deprecated():
+					entry.Forbidden[lang] = append(entry.Forbidden[lang], value)
+				case entry.Translations[lang] == "":
+					entry.Translations[lang] = value
+				}
+			}
+		}
+
+		glossary.Entries = append(glossary.Entries, entry)
+	}
+
+	return glossary, nil
+}
+
+// tbxOutDocument is the TBX-Basic (ISO 30042:2019) document written by WriteTBX
+type tbxOutDocument struct {
+	XMLName  xml.Name        `xml:"tbx"`
+	Xmlns    string          `xml:"xmlns,attr"`
+	Type     string          `xml:"type,attr"`
+	Style    string          `xml:"style,attr"`
+	Lang     string          `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Header   string          `xml:"tbxHeader>fileDesc>sourceDesc>p"`
+	Concepts []tbxOutConcept `xml:"text>body>conceptEntry"`
+}
+
+type tbxOutConcept struct {
+	ID       string          `xml:"id,attr"`
+	Descrips []tbxNote       `xml:"descrip,omitempty"`
+	LangSecs []tbxOutLangSec `xml:"langSec"`
+}
+
+type tbxOutLangSec struct {
+	Lang     string          `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	TermSecs []tbxOutTermSec `xml:"termSec"`
+}
+
+type tbxOutTermSec struct {
+	Term      string    `xml:"term"`
+	TermNotes []tbxNote `xml:"termNote,omitempty"`
+	Descrips  []tbxNote `xml:"descrip,omitempty"`
+}
+
+// WriteTBX writes a glossary as a TBX-Basic document. Translations are preferred terms,
+// forbidden translations are deprecated terms.
+func WriteTBX(w io.Writer, glossary *Glossary, toolVersion string) error {
+	doc := tbxOutDocument{
+		Xmlns:  "urn:iso:std:iso:30042:ed-2",
+		Type:   "TBX-Basic",
+		Style:  "dca",
+		Lang:   glossary.SourceLanguage,
+		Header: "Exported by jta " + toolVersion,
+	}
+
+	langs := glossary.Languages()
+	for i, entry := range glossary.Entries {
+		concept := tbxOutConcept{ID: fmt.Sprintf("c%d", i+1)}
+		if entry.Info.Definition != "" {
+			concept.Descrips = append(concept.Descrips, tbxNote{Type: tbxDefinition, Value: entry.Info.Definition})
+		}
+		if len(entry.Info.Scope) > 0 {
+			concept.Descrips = append(concept.Descrips, tbxNote{Type: tbxScope, Value: joinList(entry.Info.Scope)})
+		}
+
+		source := tbxOutTermSec{Term: entry.Term}
+		if entry.Info.PartOfSpeech != "" {
+			source.TermNotes = append(source.TermNotes, tbxNote{Type: tbxPartOfSpeech, Value: entry.Info.PartOfSpeech})
+		}
+		source.TermNotes = append(source.TermNotes, tbxNote{Type: tbxAdminStatus, Value: tbxPreferred})
+		switch entry.Type {
+		case domain.TermTypePreserve:
+			source.TermNotes = append(source.TermNotes, tbxNote{Type: tbxDoNotTranslate, Value: "yes"})
+		case domain.TermTypeConsistent:
+			source.TermNotes = append(source.TermNotes, tbxNote{Type: tbxDoNotTranslate, Value: "no"})
+		}
+		if entry.Info.CaseSensitive || entry.CaseSensitiveSet {
+			source.TermNotes = append(source.TermNotes, tbxNote{Type: tbxCaseSensitive, Value: yesNo(entry.Info.CaseSensitive)})
+		}
+		if entry.Info.Context != "" {
+			source.Descrips = append(source.Descrips, tbxNote{Type: tbxContext, Value: entry.Info.Context})
+		}
+		concept.LangSecs = append(concept.LangSecs, tbxOutLangSec{Lang: glossary.SourceLanguage, TermSecs: []tbxOutTermSec{source}})
+
+		for _, lang := range langs {
+			var termSecs []tbxOutTermSec
+			if value := entry.Translations[lang]; value != "" {
+				termSecs = append(termSecs, tbxOutTermSec{Term: value, TermNotes: []tbxNote{{Type: tbxAdminStatus, Value: tbxPreferred}}})
+			}
+			for _, forbidden := range entry.Forbidden[lang] {
+				termSecs = append(termSecs, tbxOutTermSec{Term: forbidden, TermNotes: []tbxNote{{Type: tbxAdminStatus, Value: tbxDeprecated}}})
+			}
+			if len(termSecs) > 0 {
+				concept.LangSecs = append(concept.LangSecs, tbxOutLangSec{Lang: lang, TermSecs: termSecs})
+			}
+		}
+
+		doc.Concepts = append(doc.Concepts, concept)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return domain.NewIOError("failed to write TBX", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return domain.NewFormatError("failed to encode TBX", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return domain.NewIOError("failed to write TBX", err)
+	}
+	return nil
+}
@@ -1,13 +1,23 @@
 package terminology
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 )
 
@@ -115,6 +125,31 @@ func TestJSONRepositoryExists(t *testing.T) {
 	}
 }
 
+func TestJSONRepositoryTranslations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.json")
+	repo := NewJSONRepository()
+
+	translation := domain.NewTerminologyTranslation("en", "de")
+	translation.Translations["account"] = "Konto"
+	if err := repo.SaveTranslation(path, translation); err != nil {
+		t.Fatalf("SaveTranslation() error = %v", err)
+	}
+
+	if !repo.TranslationExists(path, "de") || repo.TranslationExists(path, "fr") {
+		t.Error("TranslationExists() should be true for de only")
+	}
+	loaded, err := repo.LoadTranslation(path, "de")
+	if err != nil {
+		t.Fatalf("LoadTranslation() error = %v", err)
+	}
+	if loaded.Translations["account"] != "Konto" {
+		t.Errorf("Translations[account] = %q, want Konto", loaded.Translations["account"])
+	}
+	if langs, _ := repo.Languages(path); !slices.Equal(langs, []string{"de"}) {
+		t.Errorf("Languages() = %v, want [de]", langs)
+	}
+}
+
 func TestManagerBuildPromptDictionary(t *testing.T) {
 	manager := NewManager(nil) // Provider not needed for BuildPromptDictionary
 
@@ -394,7 +429,10 @@ func TestBuildDetectionPrompt(t *testing.T) {
 	lang := "en"
 	count := 1
 
-	prompt := detector.buildDetectionPrompt(doc, lang, count)
+	prompt, err := detector.buildDetectionPrompt(doc, lang, count)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify prompt contains required elements
 	requiredElements := []string{
@@ -624,7 +662,10 @@ func TestDetector_BuildValidationPrompt(t *testing.T) {
 		{Word: "API", Frequency: 3, Contexts: []string{"REST API"}},
 	}
 
-	prompt := detector.buildValidationPrompt(candidates, "en")
+	prompt, err := detector.buildValidationPrompt(candidates, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify prompt contains candidates
 	if !contains(prompt, "GitHub") {
@@ -914,6 +955,37 @@ func TestTranslationRepository_ExistsNonExistent_False(t *testing.T) {
 	}
 }
 
+func TestTranslationRepository_ConcurrentSaves(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Translations into the same language save the same file at the same time
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			translation := domain.NewTerminologyTranslation("en", "de")
+			for j := range 20 {
+				translation.Translations[fmt.Sprintf("term%d", j)] = strings.Repeat("x", i*100+j)
+			}
+			if err := NewTranslationRepository().Save(tmpDir, translation); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	// One complete write wins, and no temp file is left behind
+	loaded, err := NewTranslationRepository().Load(tmpDir, "de")
+	if err != nil || len(loaded.Translations) != 20 {
+		t.Fatalf("Load() = %v, %v", loaded, err)
+	}
+	if langs, _ := NewTranslationRepository().Languages(tmpDir); !slices.Equal(langs, []string{"de"}) {
+		t.Errorf("Languages() = %v", langs)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("%d files in the terminology directory, want 1", len(entries))
+	}
+}
+
 // Tests for TermRepository
 func TestTermRepository_SaveAndLoad_Flow(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -1206,3 +1278,938 @@ func TestDetector_ValidateWithLLM_WithError(t *testing.T) {
 		t.Error("validateWithLLM() expected error but got none")
 	}
 }
+
+func TestManager_DetectNewTerms_OnlyNewTexts(t *testing.T) {
+	tmpDir := t.TempDir()
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse(`{"preserveTerms": [{"term": "GitHub"}], "consistentTerms": [{"term": "repository"}]}`)
+	mockProvider.AddResponse(`{"preserveTerms": [{"term": "GitHub"}], "consistentTerms": [{"term": "webhook"}]}`)
+
+	manager := NewManager(mockProvider)
+	texts := []string{"Connect GitHub", "Create a repository"}
+
+	terms, analysed, err := manager.DetectNewTerms(context.Background(), tmpDir, texts, "en")
+	if err != nil {
+		t.Fatalf("DetectNewTerms() error = %v", err)
+	}
+	if analysed != 2 || len(terms) != 2 {
+		t.Fatalf("first DetectNewTerms() = %v, %d", terms, analysed)
+	}
+	if err := manager.SaveTerminology(tmpDir, &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"GitHub"},
+		ConsistentTerms: []string{"repository"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing new: no provider call
+	terms, analysed, err = manager.DetectNewTerms(context.Background(), tmpDir, texts, "en")
+	if err != nil || analysed != 0 || len(terms) != 0 {
+		t.Fatalf("unchanged DetectNewTerms() = %v, %d, %v", terms, analysed, err)
+	}
+	if mockProvider.GetCallCount() != 1 {
+		t.Errorf("provider called %d times, want 1", mockProvider.GetCallCount())
+	}
+
+	// One new text; already known terms are dropped
+	texts = append(texts, "Add a webhook to GitHub")
+	terms, analysed, err = manager.DetectNewTerms(context.Background(), tmpDir, texts, "en")
+	if err != nil {
+		t.Fatalf("DetectNewTerms() error = %v", err)
+	}
+	if analysed != 1 || len(terms) != 1 || terms[0].Term != "webhook" {
+		t.Errorf("incremental DetectNewTerms() = %v, %d", terms, analysed)
+	}
+
+	// A different source language starts over
+	if err := manager.RecordDetection(tmpDir, texts, "de"); err != nil {
+		t.Fatal(err)
+	}
+	record, _ := loadDetectionRecord(tmpDir, "en")
+	if len(record.unseen(texts)) != len(texts) {
+		t.Error("detection record should be per source language")
+	}
+}
+
+func TestManager_SetAndRemoveTerm(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewManager(nil)
+
+	if err := manager.SaveTerminology(tmpDir, &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"GitHub"},
+		ConsistentTerms: []string{"account"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.SetTermTranslation(tmpDir, "account", "zh", "帐号"); err != nil {
+		t.Fatalf("SetTermTranslation() error = %v", err)
+	}
+	if err := manager.SetTermTranslation(tmpDir, "account", "de", "Konto"); err != nil {
+		t.Fatalf("SetTermTranslation() error = %v", err)
+	}
+	// Unknown terms become consistent terms
+	if err := manager.SetTermTranslation(tmpDir, "workspace", "de", "Arbeitsbereich"); err != nil {
+		t.Fatalf("SetTermTranslation() error = %v", err)
+	}
+	if err := manager.SetTermTranslation(tmpDir, "GitHub", "de", "GitHub"); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("SetTermTranslation(preserve) error = %v, want validation error", err)
+	}
+
+	langs, err := manager.TranslationLanguages(tmpDir)
+	if err != nil || !slices.Equal(langs, []string{"de", "zh"}) {
+		t.Errorf("TranslationLanguages() = %v, %v", langs, err)
+	}
+	term, _ := manager.LoadTerminology(tmpDir)
+	if typ, ok := term.TermType("workspace"); !ok || typ != domain.TermTypeConsistent {
+		t.Errorf("workspace term type = %q, %v", typ, ok)
+	}
+
+	removed, err := manager.RemoveTerm(tmpDir, "account")
+	if err != nil || !removed {
+		t.Fatalf("RemoveTerm() = %v, %v", removed, err)
+	}
+	for _, lang := range langs {
+		translation, _ := manager.LoadTerminologyTranslation(tmpDir, lang)
+		if _, ok := translation.Translations["account"]; ok {
+			t.Errorf("%s translation still contains removed term", lang)
+		}
+	}
+	if removed, _ := manager.RemoveTerm(tmpDir, "account"); removed {
+		t.Error("RemoveTerm() of missing term should report false")
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	term := &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"API", "GitHub"},
+		ConsistentTerms: []string{"account", "workspace"},
+	}
+	translations := map[string]map[string]string{
+		"de": {"account": "Konto", "workspace": "Arbeitsbereich"},
+		"ru": {"account": "учётная запись"},
+		"fr": {"account": "compte"},
+		"zh": {"account": "帐号"},
+	}
+
+	tests := []struct {
+		name       string
+		lang       string
+		source     string
+		translated string
+		want       []string // violated terms
+	}{
+		{"preserve kept", "fr", "Connect to GitHub", "Se connecter à GitHub", nil},
+		{"preserve translated", "zh", "Open the API docs", "打开接口文档", []string{"API"}},
+		{"preserve needs word match", "zh", "A RAPID response", "快速响应", nil},
+		{"preserve is case sensitive", "fr", "Open GitHub", "Ouvrir Github", []string{"GitHub"}},
+		{"pinned term used", "zh", "Your account", "您的帐号", nil},
+		{"pinned term missing", "zh", "Your account", "您的账户", []string{"account"}},
+		{"source plural and case", "fr", "Accounts", "Comptes", nil},
+		{"source plural wrong translation", "fr", "Delete accounts", "Supprimer les profils", []string{"account"}},
+		{"term not in source", "zh", "Your profile", "您的资料", nil},
+		{"german inflection", "de", "Settings of the account", "Einstellungen des Kontos", nil},
+		{"german wrong term", "de", "Your account", "Ihr Benutzerprofil", []string{"account"}},
+		{"german compound", "de", "Account settings", "Kontoeinstellungen", nil},
+		{"german stem needs the whole word", "de", "Check your account", "Prüfen Sie die Kontrolle", []string{"account"}},
+		{"russian inflection", "ru", "Delete account", "Удалить учётной записи", nil},
+		{"french has no stem tolerance", "fr", "My account", "Mon compt", []string{"account"}},
+		{"untranslated term is skipped", "zh", "Your workspace", "您的空间", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translation := &domain.TerminologyTranslation{
+				SourceLanguage: "en",
+				TargetLanguage: tt.lang,
+				Translations:   translations[tt.lang],
+			}
+			checker := NewChecker(term, translation, tt.lang)
+
+			var got []string
+			for _, v := range checker.Check("key", tt.source, tt.translated) {
+				got = append(got, v.Term)
+				if v.Key != "key" || v.Message == "" {
+					t.Errorf("violation = %+v", v)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check(%q, %q) violations = %v, want %v", tt.source, tt.translated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_CheckAll(t *testing.T) {
+	checker := NewChecker(&domain.Terminology{PreserveTerms: []string{"API"}}, nil, "ja")
+
+	violations := checker.CheckAll(
+		map[string]string{"b": "API key", "a": "API", "c": "Other"},
+		map[string]string{"b": "APIキー", "a": "エーピーアイ", "c": "その他", "d": "API"},
+	)
+	if len(violations) != 1 || violations[0].Key != "a" || violations[0].Type != domain.TermTypePreserve {
+		t.Errorf("CheckAll() = %+v", violations)
+	}
+
+	if NewChecker(nil, nil, "ja").Check("a", "API", "x") != nil {
+		t.Error("checker without terminology should report nothing")
+	}
+}
+
+func TestPromptDictionary_DetailsScopeAndForbidden(t *testing.T) {
+	terminology := &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"GitHub"},
+		ConsistentTerms: []string{"Plan", "account"},
+		Details: map[string]domain.TermInfo{
+			"Plan":    {PartOfSpeech: "noun", Definition: "subscription tier", Scope: []string{"billing.**"}},
+			"account": {PartOfSpeech: "noun"},
+		},
+	}
+	translation := &domain.TerminologyTranslation{
+		SourceLanguage: "en",
+		TargetLanguage: "zh",
+		Translations:   map[string]string{"Plan": "套餐", "account": "帐号"},
+		Forbidden:      map[string][]string{"account": {"账户", "户口"}},
+	}
+
+	all := PromptDictionary(terminology, translation, nil)
+	for _, want := range []string{`"Plan" (noun: subscription tier) → "套餐"`, `"account" (noun) → "帐号"`, `✗ never use: "账户", "户口"`} {
+		if !contains(all, want) {
+			t.Errorf("PromptDictionary() missing %q:\n%s", want, all)
+		}
+	}
+
+	billing := PromptDictionary(terminology, translation, []string{"billing.plans.pro"})
+	if !contains(billing, "套餐") {
+		t.Errorf("billing keys should include the scoped term:\n%s", billing)
+	}
+	settings := PromptDictionary(terminology, translation, []string{"settings.title"})
+	if contains(settings, "套餐") {
+		t.Errorf("settings keys should not include the scoped term:\n%s", settings)
+	}
+	if !contains(settings, "帐号") || !contains(settings, "GitHub") {
+		t.Errorf("unscoped terms should always be included:\n%s", settings)
+	}
+}
+
+func TestChecker_DetailsScopeAndForbidden(t *testing.T) {
+	term := &domain.Terminology{
+		SourceLanguage:  "en",
+		ConsistentTerms: []string{"Plan", "account"},
+		Details: map[string]domain.TermInfo{
+			"Plan": {CaseSensitive: true, Scope: []string{"billing.**"}},
+		},
+	}
+	translation := &domain.TerminologyTranslation{
+		SourceLanguage: "en",
+		TargetLanguage: "zh",
+		Translations:   map[string]string{"Plan": "套餐", "account": "帐号"},
+		Forbidden:      map[string][]string{"account": {"账户"}, "Plan": {"计划"}},
+	}
+	checker := NewChecker(term, translation, "zh")
+
+	tests := []struct {
+		name       string
+		key        string
+		source     string
+		translated string
+		want       []string // violation messages
+	}{
+		{"scoped term in scope", "billing.title", "Choose a Plan", "选择计划", []string{
+			`"Plan" must be translated as "套餐"`, `"Plan" must not be translated as "计划"`}},
+		{"scoped term out of scope", "settings.title", "Choose a Plan", "选择计划", nil},
+		{"case sensitive term", "billing.note", "We plan to ship soon", "我们计划很快发布", nil},
+		{"forbidden translation", "account.title", "Your account", "您的帐号和账户", []string{`"account" must not be translated as "账户"`}},
+		{"correct translation", "account.title", "Your account", "您的帐号", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range checker.Check(tt.key, tt.source, tt.translated) {
+				got = append(got, v.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_ForbidTranslations(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewManager(nil)
+
+	if err := manager.SaveTerminology(tmpDir, &domain.Terminology{
+		SourceLanguage:  "en",
+		ConsistentTerms: []string{"account"},
+		Details:         map[string]domain.TermInfo{"account": {Definition: "a user login"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.ForbidTranslations(tmpDir, "account", "zh", "账户", " ", "户口"); err != nil {
+		t.Fatalf("ForbidTranslations() error = %v", err)
+	}
+	if err := manager.ForbidTranslations(tmpDir, "missing", "zh", "x"); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("ForbidTranslations(unknown term) error = %v, want validation error", err)
+	}
+
+	translation, err := manager.LoadTerminologyTranslation(tmpDir, "zh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := translation.GetForbidden("account"); !slices.Equal(got, []string{"账户", "户口"}) {
+		t.Errorf("GetForbidden() = %v", got)
+	}
+	term, _ := manager.LoadTerminology(tmpDir)
+	if term.Info("account").Definition != "a user login" {
+		t.Errorf("details were not persisted: %+v", term.Details)
+	}
+
+	if _, err := manager.RemoveTerm(tmpDir, "account"); err != nil {
+		t.Fatal(err)
+	}
+	translation, _ = manager.LoadTerminologyTranslation(tmpDir, "zh")
+	term, _ = manager.LoadTerminology(tmpDir)
+	if translation.GetForbidden("account") != nil || !term.Info("account").IsZero() {
+		t.Errorf("RemoveTerm() left forbidden %v / details %v", translation.Forbidden, term.Details)
+	}
+}
+
+// legacyTBX is a TBX 2008 (martif) glossary as sent by enterprise customers
+const legacyTBX = `<?xml version="1.0" encoding="UTF-8"?>
+<martif type="TBX" xml:lang="en-US">
+  <martifHeader><fileDesc><sourceDesc><p>Customer glossary</p></sourceDesc></fileDesc></martifHeader>
+  <text><body>
+    <termEntry id="t1">
+      <descripGrp><descrip type="definition">A user login</descrip></descripGrp>
+      <langSet xml:lang="en-US">
+        <tig><term>Account</term><termNote type="partOfSpeech">noun</termNote></tig>
+      </langSet>
+      <langSet xml:lang="zh-CN">
+        <tig><term>账户</term><termNote type="normativeAuthorization">deprecatedTerm</termNote></tig>
+        <ntig><termGrp><term>帐号</term><termNote type="administrativeStatus">preferredTerm-admn-sts</termNote></termGrp></ntig>
+      </langSet>
+      <langSet xml:lang="de-DE">
+        <tig><term>Konto</term></tig>
+      </langSet>
+    </termEntry>
+    <termEntry id="t2">
+      <langSet xml:lang="fr"><tig><term>Sans source</term></tig></langSet>
+    </termEntry>
+  </body></text>
+</martif>`
+
+func TestParseTBX_Legacy(t *testing.T) {
+	glossary, err := ParseTBX(strings.NewReader(legacyTBX), "")
+	if err != nil {
+		t.Fatalf("ParseTBX() error = %v", err)
+	}
+	if glossary.SourceLanguage != "en" || len(glossary.Entries) != 1 {
+		t.Fatalf("ParseTBX() = %s, %d entries", glossary.SourceLanguage, len(glossary.Entries))
+	}
+
+	entry := glossary.Entries[0]
+	// Without a do-not-translate note the type is left to the terminology
+	if entry.Term != "Account" || entry.Type != "" || entry.CaseSensitiveSet {
+		t.Errorf("entry = %q (%s)", entry.Term, entry.Type)
+	}
+	if entry.Info.Describe() != "noun: A user login" {
+		t.Errorf("Info = %+v", entry.Info)
+	}
+	if entry.Translations["zh"] != "帐号" || entry.Translations["de"] != "Konto" {
+		t.Errorf("Translations = %v", entry.Translations)
+	}
+	if !slices.Equal(entry.Forbidden["zh"], []string{"账户"}) {
+		t.Errorf("Forbidden = %v", entry.Forbidden)
+	}
+
+	if _, err := ParseTBX(strings.NewReader(`<martif><text><body/></text></martif>`), ""); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("ParseTBX() without a source language error = %v", err)
+	}
+}
+
+func testGlossary() *Glossary {
+	return &Glossary{
+		SourceLanguage: "en",
+		Entries: []GlossaryEntry{
+			{Term: "Jta Cloud", Type: domain.TermTypePreserve},
+			{
+				Term:             "Plan",
+				Type:             domain.TermTypeConsistent,
+				Info:             domain.TermInfo{PartOfSpeech: "noun", Definition: "subscription tier, e.g. \"Pro\"", Context: "Shown on pricing", CaseSensitive: true, Scope: []string{"billing.**", "*.plan"}},
+				CaseSensitiveSet: true,
+				Translations:     map[string]string{"zh": "套餐", "ja": "プラン"},
+				Forbidden:        map[string][]string{"zh": {"计划", "方案"}, "ja": {"計画, 予定"}},
+			},
+		},
+	}
+}
+
+func TestGlossary_RoundTrip(t *testing.T) {
+	formats := map[string]struct {
+		write func(*bytes.Buffer, *Glossary) error
+		parse func(*bytes.Buffer) (*Glossary, error)
+	}{
+		"tbx": {
+			write: func(b *bytes.Buffer, g *Glossary) error { return WriteTBX(b, g, "test") },
+			parse: func(b *bytes.Buffer) (*Glossary, error) { return ParseTBX(b, "") },
+		},
+		"csv": {
+			write: func(b *bytes.Buffer, g *Glossary) error { return WriteCSV(b, g) },
+			parse: func(b *bytes.Buffer) (*Glossary, error) { return ParseCSV(b, "en") },
+		},
+	}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			want := testGlossary()
+			var buf bytes.Buffer
+			if err := format.write(&buf, want); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			got, err := format.parse(&buf)
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			if got.SourceLanguage != "en" || len(got.Entries) != 2 {
+				t.Fatalf("got %s with %d entries", got.SourceLanguage, len(got.Entries))
+			}
+			if got.Entries[0].Term != "Jta Cloud" || got.Entries[0].Type != domain.TermTypePreserve {
+				t.Errorf("entry 0 = %+v", got.Entries[0])
+			}
+			plan, wantPlan := got.Entries[1], want.Entries[1]
+			if plan.Term != "Plan" || plan.Type != domain.TermTypeConsistent ||
+				plan.Info.PartOfSpeech != wantPlan.Info.PartOfSpeech || plan.Info.Definition != wantPlan.Info.Definition ||
+				plan.Info.Context != wantPlan.Info.Context || !plan.Info.CaseSensitive || !slices.Equal(plan.Info.Scope, wantPlan.Info.Scope) {
+				t.Errorf("entry 1 = %+v", plan)
+			}
+			// Lists are split at ";" only: translations may contain commas
+			if plan.Translations["zh"] != "套餐" || plan.Translations["ja"] != "プラン" || !slices.Equal(plan.Forbidden["zh"], []string{"计划", "方案"}) ||
+				!slices.Equal(plan.Forbidden["ja"], []string{"計画, 予定"}) {
+				t.Errorf("translations = %v, forbidden = %v", plan.Translations, plan.Forbidden)
+			}
+		})
+	}
+}
+
+func TestParseCSV_SpreadsheetColumns(t *testing.T) {
+	input := "\ufeffEN,Part of speech,Notes,zh-CN,pt_BR\nworkspace,noun,Team area,工作空间,espaço de trabalho\n,,,ignored,\n"
+	glossary, err := ParseCSV(strings.NewReader(input), "en")
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(glossary.Entries) != 1 {
+		t.Fatalf("ParseCSV() = %d entries, want 1", len(glossary.Entries))
+	}
+	entry := glossary.Entries[0]
+	if entry.Term != "workspace" || entry.Info.PartOfSpeech != "noun" || entry.Info.Context != "Team area" ||
+		entry.Translations["zh"] != "工作空间" || entry.Translations["pt"] != "espaço de trabalho" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("term,Owner\nx,me\n"), "en"); !domain.IsErrorType(err, domain.ErrorTypeFormat) {
+		t.Errorf("ParseCSV() unknown column error = %v", err)
+	}
+	if _, err := ParseCSV(strings.NewReader("term,type\nx,sometimes\n"), "en"); !domain.IsErrorType(err, domain.ErrorTypeFormat) {
+		t.Errorf("ParseCSV() unknown type error = %v", err)
+	}
+}
+
+func TestManager_ImportGlossary_Conflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	manager := NewManager(nil)
+
+	if err := manager.SaveTerminology(tmpDir, &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{},
+		ConsistentTerms: []string{"Plan", "Jta Cloud"},
+		Details:         map[string]domain.TermInfo{"Plan": {Definition: "a roadmap"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetTermTranslation(tmpDir, "Plan", "zh", "计划"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := manager.ImportGlossary(tmpDir, testGlossary(), false)
+	if err != nil {
+		t.Fatalf("ImportGlossary() error = %v", err)
+	}
+	var fields []string
+	for _, c := range report.Conflicts {
+		fields = append(fields, c.Term+"/"+c.Language+"/"+c.Field)
+	}
+	if !slices.Equal(fields, []string{"Jta Cloud//type", "Plan//definition", "Plan//caseSensitive", "Plan/zh/translation"}) {
+		t.Errorf("conflicts = %v", fields)
+	}
+	if report.AddedTerms != 0 || report.AddedTranslations != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	term, _ := manager.LoadTerminology(tmpDir)
+	zh, _ := manager.LoadTerminologyTranslation(tmpDir, "zh")
+	ja, _ := manager.LoadTerminologyTranslation(tmpDir, "ja")
+	if typ, _ := term.TermType("Jta Cloud"); typ != domain.TermTypeConsistent {
+		t.Errorf("conflicting type was overwritten: %s", typ)
+	}
+	if info := term.Info("Plan"); info.Definition != "a roadmap" || info.PartOfSpeech != "noun" || info.CaseSensitive {
+		t.Errorf("details should keep existing and fill empty fields: %+v", info)
+	}
+	if zh.Translations["Plan"] != "计划" || ja.Translations["Plan"] != "プラン" {
+		t.Errorf("translations zh = %v, ja = %v", zh.Translations, ja.Translations)
+	}
+	// The existing translation is never recorded as forbidden
+	if !slices.Equal(zh.GetForbidden("Plan"), []string{"方案"}) {
+		t.Errorf("forbidden = %v", zh.GetForbidden("Plan"))
+	}
+
+	if _, err := manager.ImportGlossary(tmpDir, testGlossary(), true); err != nil {
+		t.Fatalf("ImportGlossary(overwrite) error = %v", err)
+	}
+	term, _ = manager.LoadTerminology(tmpDir)
+	zh, _ = manager.LoadTerminologyTranslation(tmpDir, "zh")
+	if typ, _ := term.TermType("Jta Cloud"); typ != domain.TermTypePreserve {
+		t.Errorf("overwrite should change the type, got %s", typ)
+	}
+	if info := term.Info("Plan"); info.Definition != testGlossary().Entries[1].Info.Definition || !info.CaseSensitive || zh.Translations["Plan"] != "套餐" {
+		t.Errorf("overwrite should replace values: %+v, %v", term.Info("Plan"), zh.Translations)
+	}
+
+	if _, err := manager.ImportGlossary(tmpDir, &Glossary{SourceLanguage: "de"}, false); !domain.IsErrorType(err, domain.ErrorTypeValidation) {
+		t.Errorf("ImportGlossary() with another source language error = %v", err)
+	}
+
+	// A glossary without types or case sensitivity (e.g. a CSV of translations) keeps them
+	glossary, err := ParseCSV(strings.NewReader("term,zh\nJta Cloud,\nPlan,套餐\n"), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = manager.ImportGlossary(tmpDir, glossary, true)
+	if err != nil {
+		t.Fatalf("ImportGlossary(untyped) error = %v", err)
+	}
+	term, _ = manager.LoadTerminology(tmpDir)
+	if typ, _ := term.TermType("Jta Cloud"); typ != domain.TermTypePreserve || len(report.Conflicts) > 0 || !term.Info("Plan").CaseSensitive {
+		t.Errorf("untyped glossary changed the terminology: type %s, conflicts %v", typ, report.Conflicts)
+	}
+}
+
+func TestOpenRepository(t *testing.T) {
+	tests := []struct {
+		store string
+		want  string
+	}{
+		{"", "*terminology.DirectoryRepository"},
+		{"../shared/glossary.yaml", "*terminology.YAMLGlossaryRepository"},
+		{"glossary.YML", "*terminology.YAMLGlossaryRepository"},
+		{"glossary.json", "*terminology.JSONGlossaryRepository"},
+		{"https://glossary.example.com/acme", "*terminology.HTTPRepository"},
+	}
+	for _, tt := range tests {
+		repo, err := OpenRepository(tt.store, "")
+		if err != nil {
+			t.Fatalf("OpenRepository(%q) error = %v", tt.store, err)
+		}
+		if got := fmt.Sprintf("%T", repo); got != tt.want {
+			t.Errorf("OpenRepository(%q) = %s, want %s", tt.store, got, tt.want)
+		}
+	}
+
+	if _, err := OpenRepository("glossary.db", ""); !domain.IsErrorType(err, domain.ErrorTypeConfig) {
+		t.Errorf("OpenRepository(unsupported) error = %v, want config error", err)
+	}
+}
+
+func TestManager_SharedGlossaryFile(t *testing.T) {
+	for _, name := range []string{"glossary.yaml", "glossary.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "shared", name)
+			repo, err := OpenRepository(path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Two projects with their own terminology directories share the glossary
+			projectA := NewManagerWithRepository(nil, repo, path)
+			projectB := NewManagerWithRepository(nil, repo, path)
+
+			if projectA.TerminologyExists("a/.jta") {
+				t.Fatal("TerminologyExists() = true before saving")
+			}
+			if err := projectA.SaveTerminology("a/.jta", &domain.Terminology{
+				SourceLanguage:  "en",
+				PreserveTerms:   []string{"GitHub"},
+				ConsistentTerms: []string{"account"},
+				Details:         map[string]domain.TermInfo{"account": {PartOfSpeech: "noun", Scope: []string{"user.**"}}},
+			}); err != nil {
+				t.Fatalf("SaveTerminology() error = %v", err)
+			}
+			if err := projectA.SetTermTranslation("a/.jta", "account", "zh", "帐号"); err != nil {
+				t.Fatalf("SetTermTranslation() error = %v", err)
+			}
+			if err := projectB.ForbidTranslations("b/.jta", "account", "zh", "账户"); err != nil {
+				t.Fatalf("ForbidTranslations() error = %v", err)
+			}
+			if err := projectB.SetTermTranslation("b/.jta", "account", "ja", "アカウント"); err != nil {
+				t.Fatalf("SetTermTranslation() error = %v", err)
+			}
+
+			term, err := projectB.LoadTerminology("b/.jta")
+			if err != nil {
+				t.Fatalf("LoadTerminology() error = %v", err)
+			}
+			if !slices.Equal(term.PreserveTerms, []string{"GitHub"}) || term.Info("account").PartOfSpeech != "noun" ||
+				!slices.Equal(term.Info("account").Scope, []string{"user.**"}) {
+				t.Errorf("LoadTerminology() = %+v", term)
+			}
+			langs, err := projectA.TranslationLanguages("a/.jta")
+			if err != nil || !slices.Equal(langs, []string{"ja", "zh"}) {
+				t.Errorf("TranslationLanguages() = %v, %v", langs, err)
+			}
+			zh, err := projectA.LoadTerminologyTranslation("a/.jta", "zh")
+			if err != nil {
+				t.Fatalf("LoadTerminologyTranslation() error = %v", err)
+			}
+			if zh.SourceLanguage != "en" || zh.Translations["account"] != "帐号" || !slices.Equal(zh.GetForbidden("account"), []string{"账户"}) {
+				t.Errorf("zh translation = %+v", zh)
+			}
+			if projectA.TranslationExists("a/.jta", "de") {
+				t.Error("TranslationExists(de) = true")
+			}
+
+			// Saving the terminology keeps the translations stored in the same file
+			term.AddPreserveTerm("API")
+			if err := projectA.SaveTerminology("a/.jta", term); err != nil {
+				t.Fatal(err)
+			}
+			if !projectB.TranslationExists("b/.jta", "zh") {
+				t.Error("SaveTerminology() dropped the translations")
+			}
+			if _, err := os.Stat("a"); !os.IsNotExist(err) {
+				t.Error("a shared glossary should not write to the terminology directory")
+			}
+		})
+	}
+}
+
+// glossaryService is a stand-in for an HTTP glossary service
+type glossaryService struct {
+	token        string
+	terminology  json.RawMessage
+	translations map[string]json.RawMessage
+}
+
+func (s *glossaryService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/acme/")
+	lang, isTranslation := strings.CutPrefix(path, "translations/")
+	switch {
+	case path == "translations" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(slices.Sorted(maps.Keys(s.translations)))
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if isTranslation {
+			s.translations[lang] = body
+		} else {
+			s.terminology = body
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case isTranslation && s.translations[lang] != nil:
+		_, _ = w.Write(s.translations[lang])
+	case path == "terminology" && s.terminology != nil:
+		_, _ = w.Write(s.terminology)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGlossaryFile_ConcurrentTranslations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.yaml")
+	langs := []string{"de", "fr", "ja", "ko", "zh", "es", "it", "pt"}
+
+	// Every translation saves through its own repository, as apps of different models do
+	var wg sync.WaitGroup
+	for _, lang := range langs {
+		wg.Go(func() {
+			repo, err := OpenRepository(path, "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			translation := domain.NewTerminologyTranslation("en", lang)
+			for i := range 10 {
+				translation.Translations[fmt.Sprintf("term%d", i)] = lang
+				if err := repo.SaveTranslation(path, translation); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	repo, _ := OpenRepository(path, "")
+	got, err := repo.Languages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := slices.Sorted(slices.Values(langs)); !slices.Equal(got, want) {
+		t.Errorf("Languages() = %v, want %v", got, want)
+	}
+}
+
+func TestManager_HTTPGlossaryService(t *testing.T) {
+	service := &glossaryService{token: "secret", translations: map[string]json.RawMessage{}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	baseURL := server.URL + "/acme"
+
+	manager := NewManagerWithRepository(nil, NewHTTPRepository("secret"), baseURL)
+	if manager.Location(".jta") != baseURL {
+		t.Errorf("Location() = %q", manager.Location(".jta"))
+	}
+	if manager.TerminologyExists(".jta") {
+		t.Fatal("TerminologyExists() = true on an empty service")
+	}
+	if _, err := manager.LoadTerminology(".jta"); !domain.IsErrorType(err, domain.ErrorTypeTerminology) {
+		t.Errorf("LoadTerminology() on an empty service error = %v", err)
+	}
+
+	if err := manager.SaveTerminology(".jta", &domain.Terminology{SourceLanguage: "en", ConsistentTerms: []string{"workspace"}}); err != nil {
+		t.Fatalf("SaveTerminology() error = %v", err)
+	}
+	if err := manager.SetTermTranslation(".jta", "workspace", "zh", "工作空间"); err != nil {
+		t.Fatalf("SetTermTranslation() error = %v", err)
+	}
+
+	term, err := manager.LoadTerminology(".jta")
+	if err != nil || !slices.Equal(term.ConsistentTerms, []string{"workspace"}) {
+		t.Errorf("LoadTerminology() = %+v, %v", term, err)
+	}
+	langs, err := manager.TranslationLanguages(".jta")
+	if err != nil || !slices.Equal(langs, []string{"zh"}) {
+		t.Errorf("TranslationLanguages() = %v, %v", langs, err)
+	}
+	zh, err := manager.LoadTerminologyTranslation(".jta", "zh")
+	if err != nil || zh.Translations["workspace"] != "工作空间" {
+		t.Errorf("LoadTerminologyTranslation() = %+v, %v", zh, err)
+	}
+
+	unauthorized := NewManagerWithRepository(nil, NewHTTPRepository("wrong"), baseURL)
+	if _, err := unauthorized.LoadTerminology(".jta"); !domain.IsErrorType(err, domain.ErrorTypeIO) {
+		t.Errorf("LoadTerminology() with a wrong token error = %v, want IO error", err)
+	}
+
+	// Only a 404 means missing: a failing or unreachable service is not an empty one
+	if !unauthorized.TerminologyExists(".jta") || !unauthorized.TranslationExists(".jta", "ja") {
+		t.Error("TerminologyExists() = false when the service refuses the request")
+	}
+	server.Close()
+	if !manager.TerminologyExists(".jta") {
+		t.Error("TerminologyExists() = false when the service is unreachable")
+	}
+	if _, err := manager.LoadTerminology(".jta"); !domain.IsErrorType(err, domain.ErrorTypeIO) {
+		t.Errorf("LoadTerminology() from an unreachable service error = %v, want IO error", err)
+	}
+}
+
+func candidateWords(candidates []*CandidateWord) map[string]*CandidateWord {
+	words := make(map[string]*CandidateWord)
+	for _, c := range candidates {
+		words[c.Word] = c
+	}
+	return words
+}
+
+func TestExtractCandidates_PhrasesAndForms(t *testing.T) {
+	texts := []string{
+		"Enable Single Sign-On for your workspace",
+		"Single Sign-On lets members log in with {provider}",
+		"Configure Single Sign-On in the admin console",
+		"Your workspace has {count} members",
+		"Delete %s from the workspace?",
+		"Create an API key",
+		"The API key is invalid",
+		"Use OAuth to connect <b>GitHub</b>",
+		"Save Changes",
+	}
+
+	candidates := ExtractCandidates(texts, 0)
+	words := candidateWords(candidates)
+
+	sso := words["Single Sign-On"]
+	if sso == nil || sso.Frequency != 3 || sso.Documents != 3 || !slices.Contains(sso.Signals, signalCapitalized) {
+		t.Fatalf("Single Sign-On = %+v, want a capitalized candidate seen 3 times", sso)
+	}
+	if c := words["OAuth"]; c == nil || !slices.Contains(c.Signals, signalMixedCase) {
+		t.Errorf("OAuth = %+v, want a mixed-case candidate", c)
+	}
+	if words["API key"] == nil || words["workspace"] == nil {
+		t.Errorf("missing candidates in %v", slices.Collect(maps.Keys(words)))
+	}
+
+	// Covered by a longer candidate, placeholders, markup and stop words
+	for _, word := range []string{"Sign-On", "Single", "API", "provider", "count", "b", "the", "your workspace"} {
+		if words[word] != nil {
+			t.Errorf("unexpected candidate %q", word)
+		}
+	}
+	// Title Case labels say nothing about capitalization
+	if words["Save Changes"] != nil {
+		t.Error("single Title Case label should not be a candidate")
+	}
+
+	// Deterministic and sorted by score
+	if again := ExtractCandidates(texts, 0); !slices.EqualFunc(candidates, again, func(a, b *CandidateWord) bool {
+		return a.Word == b.Word && a.Score == b.Score
+	}) {
+		t.Error("ExtractCandidates() is not deterministic")
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Score > candidates[i-1].Score {
+			t.Errorf("candidates not sorted by score at %d", i)
+		}
+	}
+	if limited := ExtractCandidates(texts, 2); len(limited) != 2 || limited[0].Word != candidates[0].Word {
+		t.Errorf("ExtractCandidates(limit 2) = %d candidates", len(limited))
+	}
+}
+
+func TestExtractCandidates_UnspacedScripts(t *testing.T) {
+	tests := []struct {
+		name   string
+		texts  []string
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "Chinese",
+			texts:  []string{"账户设置已保存", "请打开账户设置", "工作区成员可以访问账户设置", "删除工作区", "工作区名称"},
+			want:   []string{"账户设置", "工作区"},
+			absent: []string{"账户", "户设置"},
+		},
+		{
+			name:   "Japanese",
+			texts:  []string{"アカウント設定を保存しました", "アカウントを削除", "ワークスペースに招待", "ワークスペースのメンバー"},
+			want:   []string{"アカウント", "ワークスペース"},
+			absent: []string{"しました"},
+		},
+		{
+			name:   "Thai",
+			texts:  []string{"บันทึกการตั้งค่าแล้ว", "เปิดการตั้งค่าบัญชี", "ลบบัญชี", "การตั้งค่าบัญชีของคุณ"},
+			want:   []string{"การตั้งค่า", "บัญชี"},
+			absent: []string{"การตั้งค่าบั", "ตั้งค่า"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words := candidateWords(ExtractCandidates(tt.texts, 0))
+			for _, w := range tt.want {
+				if words[w] == nil {
+					t.Errorf("missing candidate %q in %v", w, slices.Sorted(maps.Keys(words)))
+				}
+			}
+			for _, w := range tt.absent {
+				if words[w] != nil {
+					t.Errorf("unexpected candidate %q", w)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_DetectCandidates(t *testing.T) {
+	tmpDir := t.TempDir()
+	mockProvider := provider.NewMockProvider("gpt-4")
+	manager := NewManager(mockProvider)
+
+	if err := manager.SaveTerminology(tmpDir, &domain.Terminology{
+		SourceLanguage:  "en",
+		PreserveTerms:   []string{"github"},
+		ConsistentTerms: []string{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"Connect GitHub", "Open the GitHub repository", "Create a repository", "Use OAuth"}
+	candidates, err := manager.DetectCandidates(tmpDir, texts, "en", 1)
+	if err != nil {
+		t.Fatalf("DetectCandidates() error = %v", err)
+	}
+	if len(candidates) != 1 || strings.EqualFold(candidates[0].Word, "GitHub") {
+		t.Errorf("DetectCandidates() = %v, want one candidate that isn't a known term", candidates)
+	}
+	if mockProvider.GetCallCount() != 0 {
+		t.Errorf("provider called %d times, want 0", mockProvider.GetCallCount())
+	}
+
+	data, err := os.ReadFile(CandidatesPath(tmpDir))
+	if err != nil {
+		t.Fatalf("candidates file not written: %v", err)
+	}
+	var review candidateReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		t.Fatal(err)
+	}
+	if review.SourceLanguage != "en" || review.Strings != 4 || len(review.Candidates) != 1 {
+		t.Errorf("candidates file = %+v", review)
+	}
+}
+
+func TestManager_Prompts(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(prompt.Dir(tmpDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := `Find terms in {{.Count}} {{.Language}} texts.`
+	if err := os.WriteFile(prompt.Path(tmpDir, prompt.DetectTerms), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompts, err := prompt.Load(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockProvider := provider.NewMockProvider("gpt-4")
+	manager := NewManager(mockProvider)
+	manager.SetPrompts(prompts)
+
+	texts := []string{"Open GitHub", "Sync GitHub", "Create a repository"}
+	detection, err := manager.DetectionPrompt(texts, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detection != "Find terms in 3 en texts." {
+		t.Errorf("DetectionPrompt() = %q", detection)
+	}
+
+	validation, err := manager.ValidationPrompt(texts, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(validation, `Candidate: "GitHub"`) {
+		t.Errorf("ValidationPrompt() should list GitHub:\n%s", validation)
+	}
+
+	translation, err := manager.TermTranslationPrompt([]string{"repository"}, "en", "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(translation, "Translate the following en terms to de") || !strings.Contains(translation, `1. "repository"`) {
+		t.Errorf("TermTranslationPrompt():\n%s", translation)
+	}
+	if mockProvider.GetCallCount() != 0 {
+		t.Errorf("provider called %d times, want 0", mockProvider.GetCallCount())
+	}
+}
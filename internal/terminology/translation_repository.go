@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/hikanner/jta/internal/domain"
 )
@@ -48,7 +50,9 @@ func (r *TranslationRepository) Save(terminologyDir string, translation *domain.
 		return fmt.Errorf("failed to marshal translation: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	unlock := lockFile(path)
+	defer unlock()
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("failed to write translation file: %w", err)
 	}
 
@@ -61,3 +65,19 @@ func (r *TranslationRepository) Exists(terminologyDir string, targetLang string)
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// Languages returns the target languages that have a translation file, sorted
+func (r *TranslationRepository) Languages(terminologyDir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(terminologyDir, "terminology.*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list translation files: %w", err)
+	}
+
+	langs := make([]string, 0, len(matches))
+	for _, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "terminology."), ".json")
+		langs = append(langs, name)
+	}
+	slices.Sort(langs)
+	return langs, nil
+}
@@ -0,0 +1,291 @@
+// Package tm implements a local translation memory: previously translated
+// strings that can be reused exactly or offered to the model as references.
+package tm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// DefaultFuzzyThreshold is the minimum similarity for a fuzzy match
+const DefaultFuzzyThreshold = 0.75
+
+// Entry is a single translation unit
+type Entry struct {
+	SourceLang string    `json:"source_lang"`
+	TargetLang string    `json:"target_lang"`
+	Source     string    `json:"source"`
+	Target     string    `json:"target"`
+	Key        string    `json:"key,omitempty"`    // JSON key path the unit came from, if known
+	Origin     string    `json:"origin,omitempty"` // "jta", "tmx", ...
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Match is an entry found for a source text
+type Match struct {
+	Entry
+	Similarity float64 // 0-1, 1 = exact
+}
+
+// Memory is a translation memory stored as JSON Lines (one entry per line).
+// Entries are unique per language pair and source text; the latest one wins.
+type Memory struct {
+	mu      sync.RWMutex
+	saveMu  sync.Mutex // one write of the file at a time
+	path    string
+	entries map[string]*Entry // entryKey -> entry
+	byPair  map[string][]*Entry
+	dirty   bool
+}
+
+// Path returns where the translation memory lives inside a terminology directory
+func Path(terminologyDir string) string {
+	return filepath.Join(terminologyDir, "tm.jsonl")
+}
+
+// Load reads a translation memory; a missing file yields an empty memory
+func Load(path string) (*Memory, error) {
+	m := &Memory{
+		path:    path,
+		entries: make(map[string]*Entry),
+		byPair:  make(map[string][]*Entry),
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return m, nil
+		}
+		return nil, domain.NewIOError("failed to open translation memory", err).
+			WithContext("path", path)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal([]byte(text), &e); err != nil {
+			return nil, domain.NewFormatError("failed to parse translation memory", err).
+				WithContext("path", path).
+				WithContext("line", line)
+		}
+		m.put(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, domain.NewIOError("failed to read translation memory", err).
+			WithContext("path", path)
+	}
+
+	m.dirty = false
+	return m, nil
+}
+
+// Add stores an entry, replacing any earlier translation of the same source text.
+// Language codes are normalized to supported codes (e.g. "en-US" -> "en").
+// It reports whether the memory changed.
+func (m *Memory) Add(e Entry) bool {
+	if e.Source == "" || e.Target == "" {
+		return false
+	}
+	e.SourceLang = normalizeLanguage(e.SourceLang)
+	e.TargetLang = normalizeLanguage(e.TargetLang)
+	if e.UpdatedAt.IsZero() {
+		e.UpdatedAt = time.Now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.entries[entryKey(e.SourceLang, e.TargetLang, e.Source)]; ok && old.Target == e.Target {
+		return false
+	}
+	m.put(e)
+	return true
+}
+
+// put inserts or replaces an entry (caller holds the lock)
+func (m *Memory) put(e Entry) {
+	key := entryKey(e.SourceLang, e.TargetLang, e.Source)
+	if old, ok := m.entries[key]; ok {
+		*old = e
+	} else {
+		entry := &e
+		m.entries[key] = entry
+		pair := pairKey(e.SourceLang, e.TargetLang)
+		m.byPair[pair] = append(m.byPair[pair], entry)
+	}
+	m.dirty = true
+}
+
+// Exact returns the stored translation of exactly this source text
+func (m *Memory) Exact(sourceLang, targetLang, source string) (Entry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[entryKey(sourceLang, targetLang, source)]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// SourceOf returns the source text a translation of a key was last made from, so a
+// changed source can be told apart from a translation edited by hand
+func (m *Memory) SourceOf(sourceLang, targetLang, key, target string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var latest *Entry
+	for _, e := range m.byPair[pairKey(sourceLang, targetLang)] {
+		if e.Key == key && e.Target == target && (latest == nil || e.UpdatedAt.After(latest.UpdatedAt)) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return "", false
+	}
+	return latest.Source, true
+}
+
+// Fuzzy returns up to limit entries whose source is at least threshold similar
+// to source (exact matches excluded), best first
+func (m *Memory) Fuzzy(sourceLang, targetLang, source string, threshold float64, limit int) []Match {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []Match
+	for _, e := range m.byPair[pairKey(sourceLang, targetLang)] {
+		if e.Source == source {
+			continue
+		}
+		if sim := Similarity(source, e.Source, threshold); sim >= threshold {
+			matches = append(matches, Match{Entry: *e, Similarity: sim})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Source < matches[j].Source
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Entries returns all entries ordered by language pair and source text
+func (m *Memory) Entries() []Entry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.SourceLang != b.SourceLang {
+			return a.SourceLang < b.SourceLang
+		}
+		if a.TargetLang != b.TargetLang {
+			return a.TargetLang < b.TargetLang
+		}
+		return a.Source < b.Source
+	})
+	return entries
+}
+
+// Len returns the number of entries
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.entries)
+}
+
+// Save writes the memory back to its file if it changed
+func (m *Memory) Save() (err error) {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	// Entries added while writing mark the memory dirty again
+	m.mu.Lock()
+	dirty := m.dirty
+	m.dirty = false
+	m.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+	defer func() {
+		if err != nil {
+			m.mu.Lock()
+			m.dirty = true
+			m.mu.Unlock()
+		}
+	}()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return domain.NewIOError("failed to create translation memory directory", err).
+			WithContext("path", m.path)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a truncated memory
+	tmp := m.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return domain.NewIOError("failed to write translation memory", err).
+			WithContext("path", m.path)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, e := range m.Entries() {
+		if err := enc.Encode(e); err != nil {
+			_ = f.Close()
+			return domain.NewFormatError("failed to encode translation memory entry", err)
+		}
+	}
+	if err := errors.Join(w.Flush(), f.Close()); err != nil {
+		return domain.NewIOError("failed to write translation memory", err).
+			WithContext("path", m.path)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		return domain.NewIOError("failed to write translation memory", err).
+			WithContext("path", m.path)
+	}
+
+	return nil
+}
+
+func entryKey(sourceLang, targetLang, source string) string {
+	return pairKey(sourceLang, targetLang) + "\x00" + source
+}
+
+func pairKey(sourceLang, targetLang string) string {
+	return strings.ToLower(normalizeLanguage(sourceLang)) + "\x00" + strings.ToLower(normalizeLanguage(targetLang))
+}
+
+// normalizeLanguage maps locale identifiers to supported codes, keeping unknown ones
+func normalizeLanguage(code string) string {
+	normalized, _ := domain.NormalizeLanguageCode(code)
+	return normalized
+}
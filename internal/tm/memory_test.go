@@ -0,0 +1,122 @@
+package tm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemory_AddExactFuzzy(t *testing.T) {
+	m, err := Load(filepath.Join(t.TempDir(), "tm.jsonl"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Sign in", Target: "Anmelden"})
+	m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Sign in to continue", Target: "Zum Fortfahren anmelden"})
+	m.Add(Entry{SourceLang: "en", TargetLang: "fr", Source: "Sign in", Target: "Se connecter"})
+
+	if e, ok := m.Exact("en", "de", "Sign in"); !ok || e.Target != "Anmelden" {
+		t.Errorf("Exact() = %v, %v", e, ok)
+	}
+	if _, ok := m.Exact("en", "de", "sign in"); ok {
+		t.Error("Exact() should be case sensitive")
+	}
+	if _, ok := m.Exact("en", "es", "Sign in"); ok {
+		t.Error("Exact() should not cross language pairs")
+	}
+
+	// Replacing a translation keeps one entry
+	if !m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Sign in", Target: "Einloggen"}) {
+		t.Error("Add() with new target should report a change")
+	}
+	if m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Sign in", Target: "Einloggen"}) {
+		t.Error("Add() with same target should not report a change")
+	}
+	if m.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", m.Len())
+	}
+
+	matches := m.Fuzzy("en", "de", "Please sign in to continue", 0.6, 3)
+	if len(matches) != 1 || matches[0].Target != "Zum Fortfahren anmelden" {
+		t.Fatalf("Fuzzy() = %+v", matches)
+	}
+	if matches[0].Similarity >= 1 || matches[0].Similarity < 0.6 {
+		t.Errorf("Similarity = %v", matches[0].Similarity)
+	}
+
+	// Exact source is not returned as a fuzzy match
+	for _, match := range m.Fuzzy("en", "de", "Sign in", 0.1, 0) {
+		if match.Source == "Sign in" {
+			t.Error("Fuzzy() returned the exact match")
+		}
+	}
+}
+
+func TestMemory_SaveLoad(t *testing.T) {
+	path := Path(t.TempDir())
+	m, _ := Load(path)
+	m.Add(Entry{SourceLang: "en", TargetLang: "ja", Source: "Save <b>now</b>", Target: "今すぐ<b>保存</b>", Key: "actions.save", Origin: "jta"})
+	m.Add(Entry{SourceLang: "en", TargetLang: "ja", Source: "Cancel", Target: "キャンセル"})
+
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", loaded.Len())
+	}
+	e, ok := loaded.Exact("en", "ja", "Save <b>now</b>")
+	if !ok || e.Target != "今すぐ<b>保存</b>" || e.Key != "actions.save" || e.UpdatedAt.IsZero() {
+		t.Errorf("round trip entry = %+v", e)
+	}
+
+	// Corrupt lines are reported
+	_ = os.WriteFile(path, []byte("{not json}\n"), 0644)
+	if _, err := Load(path); err == nil {
+		t.Error("Load() should fail on corrupt file")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		want float64
+	}{
+		{"Sign in", "Sign in", 0, 1},
+		{"Sign in", "sign  IN", 0, 1},
+		{"kitten", "sitting", 0, 1 - 3.0/7},
+		{"abc", "abcdefghij", 0.5, 0}, // rejected by length
+		{"", "", 0, 1},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b, tt.min); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMemory_SourceOf(t *testing.T) {
+	m, _ := Load(filepath.Join(t.TempDir(), "tm.jsonl"))
+	m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Save", Target: "Speichern", Key: "actions.save"})
+	m.Add(Entry{SourceLang: "en", TargetLang: "de", Source: "Save file", Target: "Speichern", Key: "menu.save"})
+
+	if source, ok := m.SourceOf("en-US", "de", "actions.save", "Speichern"); !ok || source != "Save" {
+		t.Errorf("SourceOf() = %q, %v, want Save", source, ok)
+	}
+	if source, ok := m.SourceOf("en", "de", "menu.save", "Speichern"); !ok || source != "Save file" {
+		t.Errorf("SourceOf() = %q, %v, want Save file", source, ok)
+	}
+	// A translation edited by hand was never made from any source
+	if _, ok := m.SourceOf("en", "de", "actions.save", "Abspeichern"); ok {
+		t.Error("SourceOf() found a source for an unknown translation")
+	}
+	if _, ok := m.SourceOf("en", "fr", "actions.save", "Speichern"); ok {
+		t.Error("SourceOf() should not cross language pairs")
+	}
+}
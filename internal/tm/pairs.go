@@ -0,0 +1,34 @@
+package tm
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/hikanner/jta/internal/utils"
+)
+
+// EntriesFromFiles pairs the string values of a source and a translated JSON document
+// by key path ("a.b", "a[0]"), the same walk incremental translation uses. Keys that
+// are missing or empty in the target are skipped. Entries are ordered by key.
+func EntriesFromFiles(source, target map[string]any, sourceLang, targetLang string) []Entry {
+	jsonUtil := utils.NewJSONUtil()
+	sourceTexts := jsonUtil.FlattenStrings(source)
+	targetTexts := jsonUtil.FlattenStrings(target)
+
+	var entries []Entry
+	for _, key := range slices.Sorted(maps.Keys(sourceTexts)) {
+		text := sourceTexts[key]
+		translated := targetTexts[key]
+		if text == "" || translated == "" {
+			continue
+		}
+		entries = append(entries, Entry{
+			SourceLang: sourceLang,
+			TargetLang: targetLang,
+			Source:     text,
+			Target:     translated,
+			Key:        key,
+		})
+	}
+	return entries
+}
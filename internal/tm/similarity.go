@@ -0,0 +1,62 @@
+package tm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity returns how similar two texts are, from 0 (unrelated) to 1 (identical
+// ignoring case and repeated whitespace). It is based on the edit distance over runes.
+// Pairs that cannot reach min are rejected early by length and return 0.
+func Similarity(a, b string, min float64) float64 {
+	ra := []rune(normalize(a))
+	rb := []rune(normalize(b))
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// The edit distance is at least the length difference
+	diff := len(ra) - len(rb)
+	if diff < 0 {
+		diff = -diff
+	}
+	if 1-float64(diff)/float64(longest) < min {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// normalize lowercases and collapses whitespace
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), unicode.IsSpace), " ")
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
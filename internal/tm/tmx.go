@@ -0,0 +1,256 @@
+package tm
+
+import (
+	"encoding/xml"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// tmxDocument mirrors the parts of TMX 1.4 that jta reads and writes
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxUnit struct {
+	TUID     string       `xml:"tuid,attr,omitempty"`
+	SrcLang  string       `xml:"srclang,attr,omitempty"`
+	Props    []tmxProp    `xml:"prop,omitempty"`
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+type tmxProp struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type tmxVariant struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Seg  tmxSeg `xml:"seg"`
+}
+
+// tmxSeg keeps the segment's inner XML so inline markup (<ph>, <bpt>, ...) is
+// reduced to its text rather than dropped
+type tmxSeg struct {
+	Inner string `xml:",innerxml"`
+}
+
+// text returns the segment text with inline TMX markup removed.
+// Codes inside <ph>, <bpt>, <ept>, <it> and <ut> are native formatting, not text.
+func (s tmxSeg) text() string {
+	dec := xml.NewDecoder(strings.NewReader("<seg>" + s.Inner + "</seg>"))
+	var sb strings.Builder
+	skip := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || isNativeCode(t.Name.Local) {
+				skip++
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+			}
+		case xml.CharData:
+			if skip == 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func isNativeCode(name string) bool {
+	switch name {
+	case "ph", "bpt", "ept", "it", "ut":
+		return true
+	}
+	return false
+}
+
+// ParseTMX reads translation units from a TMX document. Each unit yields one entry
+// per target variant; the source is the variant in the unit's (or header's) source language.
+func ParseTMX(r io.Reader, origin string) ([]Entry, error) {
+	var doc tmxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, domain.NewFormatError("failed to parse TMX", err)
+	}
+
+	now := time.Now()
+	var entries []Entry
+	for _, unit := range doc.Units {
+		srcLang := unit.SrcLang
+		if srcLang == "" {
+			srcLang = doc.Header.SrcLang
+		}
+		if len(unit.Variants) < 2 {
+			continue
+		}
+
+		// "*all*" (or no source language) means the first variant is the source
+		srcIdx := 0
+		if srcLang != "" && srcLang != "*all*" {
+			srcIdx = -1
+			for i, v := range unit.Variants {
+				if strings.EqualFold(v.Lang, srcLang) {
+					srcIdx = i
+					break
+				}
+			}
+			if srcIdx < 0 {
+				continue
+			}
+		}
+		src := unit.Variants[srcIdx]
+
+		key := ""
+		for _, p := range unit.Props {
+			if p.Type == "x-key" {
+				key = p.Value
+			}
+		}
+
+		for i, v := range unit.Variants {
+			if i == srcIdx {
+				continue
+			}
+			entries = append(entries, Entry{
+				SourceLang: src.Lang,
+				TargetLang: v.Lang,
+				Source:     src.Seg.text(),
+				Target:     v.Seg.text(),
+				Key:        key,
+				Origin:     origin,
+				UpdatedAt:  now,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// ImportStats summarizes a TMX import
+type ImportStats struct {
+	Entries          int      // Source/target pairs found in the document
+	Changed          int      // Entries added or updated in the memory
+	UnknownLanguages []string // Language codes that don't map to a supported language
+}
+
+// ImportTMX adds the units of a TMX document to the memory. Vendor locale codes
+// are normalized against the supported languages; unknown codes are kept and reported.
+func (m *Memory) ImportTMX(r io.Reader, origin string) (ImportStats, error) {
+	entries, err := ParseTMX(r, origin)
+	if err != nil {
+		return ImportStats{}, err
+	}
+
+	stats := ImportStats{Entries: len(entries)}
+	unknown := make(map[string]bool)
+	for _, e := range entries {
+		for _, lang := range []string{e.SourceLang, e.TargetLang} {
+			if normalized, ok := domain.NormalizeLanguageCode(lang); !ok {
+				unknown[normalized] = true
+			}
+		}
+		if m.Add(e) {
+			stats.Changed++
+		}
+	}
+	stats.UnknownLanguages = slices.Sorted(maps.Keys(unknown))
+
+	return stats, nil
+}
+
+// WriteTMX writes entries as a TMX 1.4 document. Entries sharing source language,
+// key and source text become one translation unit with a variant per target language.
+func WriteTMX(w io.Writer, entries []Entry, toolVersion string) error {
+	type unitKey struct{ srcLang, key, source string }
+
+	var order []unitKey
+	units := make(map[unitKey]*tmxUnit)
+	srcLangs := make(map[string]bool)
+
+	for _, e := range entries {
+		k := unitKey{e.SourceLang, e.Key, e.Source}
+		unit, ok := units[k]
+		if !ok {
+			unit = &tmxUnit{
+				TUID:     e.Key,
+				SrcLang:  e.SourceLang,
+				Variants: []tmxVariant{{Lang: e.SourceLang, Seg: newSeg(e.Source)}},
+			}
+			if e.Key != "" {
+				unit.Props = []tmxProp{{Type: "x-key", Value: e.Key}}
+			}
+			units[k] = unit
+			order = append(order, k)
+			srcLangs[e.SourceLang] = true
+		}
+		unit.Variants = append(unit.Variants, tmxVariant{Lang: e.TargetLang, Seg: newSeg(e.Target)})
+	}
+
+	// A single source language goes in the header, mixed documents use "*all*"
+	headerSrcLang := "*all*"
+	if len(srcLangs) == 1 {
+		for lang := range srcLangs {
+			headerSrcLang = lang
+		}
+	}
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "jta",
+			CreationToolVersion: toolVersion,
+			SegType:             "sentence",
+			OTMF:                "jta",
+			AdminLang:           "en",
+			SrcLang:             headerSrcLang,
+			DataType:            "plaintext",
+		},
+	}
+	for _, k := range order {
+		doc.Units = append(doc.Units, *units[k])
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return domain.NewIOError("failed to write TMX", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return domain.NewFormatError("failed to encode TMX", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return domain.NewIOError("failed to write TMX", err)
+	}
+	return nil
+}
+
+// newSeg builds a segment holding escaped plain text
+func newSeg(text string) tmxSeg {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(text))
+	return tmxSeg{Inner: sb.String()}
+}
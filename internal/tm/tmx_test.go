@@ -0,0 +1,136 @@
+package tm
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const vendorTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="VendorTool" creationtoolversion="2" segtype="sentence" o-tmf="x" adminlang="en-US" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu tuid="1">
+      <prop type="x-key">auth.signin</prop>
+      <tuv xml:lang="en-US"><seg>Sign in</seg></tuv>
+      <tuv xml:lang="de-DE"><seg>Anmelden</seg></tuv>
+      <tuv xml:lang="fr-FR"><seg>Se connecter</seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="de-DE"><seg>Hallo <ph x="1">{name}</ph><hi>!</hi></seg></tuv>
+      <tuv xml:lang="en-US"><seg>Hello <ph x="1">{name}</ph><hi>!</hi></seg></tuv>
+    </tu>
+    <tu>
+      <tuv xml:lang="en-US"><seg>Orphan</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+
+func TestParseTMX(t *testing.T) {
+	entries, err := ParseTMX(strings.NewReader(vendorTMX), "tmx")
+	if err != nil {
+		t.Fatalf("ParseTMX() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseTMX() returned %d entries, want 3: %+v", len(entries), entries)
+	}
+
+	first := entries[0]
+	if first.SourceLang != "en-US" || first.TargetLang != "de-DE" || first.Source != "Sign in" ||
+		first.Target != "Anmelden" || first.Key != "auth.signin" || first.Origin != "tmx" {
+		t.Errorf("first entry = %+v", first)
+	}
+
+	// Source is found by language, not position; native codes are dropped, highlighted text kept
+	third := entries[2]
+	if third.Source != "Hello !" || third.Target != "Hallo !" {
+		t.Errorf("inline markup entry = %+v", third)
+	}
+
+	if _, err := ParseTMX(strings.NewReader("<tmx"), "tmx"); err == nil {
+		t.Error("ParseTMX() should fail on malformed XML")
+	}
+}
+
+func TestMemory_ImportTMX(t *testing.T) {
+	m, _ := Load(filepath.Join(t.TempDir(), "tm.jsonl"))
+
+	stats, err := m.ImportTMX(strings.NewReader(vendorTMX), "tmx")
+	if err != nil {
+		t.Fatalf("ImportTMX() error = %v", err)
+	}
+	if stats.Entries != 3 || stats.Changed != 3 || len(stats.UnknownLanguages) != 0 {
+		t.Errorf("ImportTMX() = %+v", stats)
+	}
+
+	// Vendor locales are stored as supported codes
+	e, ok := m.Exact("en", "fr", "Sign in")
+	if !ok || e.Target != "Se connecter" || e.SourceLang != "en" || e.TargetLang != "fr" {
+		t.Errorf("Exact() after import = %+v, %v", e, ok)
+	}
+	if _, ok := m.Exact("en-GB", "fr-CA", "Sign in"); !ok {
+		t.Error("Exact() should normalize lookup codes")
+	}
+
+	// Importing again changes nothing
+	if stats, _ := m.ImportTMX(strings.NewReader(vendorTMX), "tmx"); stats.Changed != 0 {
+		t.Errorf("second ImportTMX() changed %d entries, want 0", stats.Changed)
+	}
+
+	klingon := strings.ReplaceAll(vendorTMX, "fr-FR", "tlh")
+	if stats, _ := m.ImportTMX(strings.NewReader(klingon), "tmx"); len(stats.UnknownLanguages) != 1 || stats.UnknownLanguages[0] != "tlh" {
+		t.Errorf("UnknownLanguages = %v, want [tlh]", stats.UnknownLanguages)
+	}
+}
+
+func TestWriteTMX_RoundTrip(t *testing.T) {
+	source := map[string]any{
+		"auth": map[string]any{"signin": "Sign in", "hint": "Use <b>{email}</b> & password"},
+		"menu": []any{"Home", "About"},
+		"new":  "Not yet translated",
+	}
+	zh := map[string]any{
+		"auth": map[string]any{"signin": "登录", "hint": "使用 <b>{email}</b> 和密码"},
+		"menu": []any{"首页", ""},
+	}
+	ja := map[string]any{
+		"auth": map[string]any{"signin": "サインイン"},
+	}
+
+	entries := EntriesFromFiles(source, zh, "en", "zh")
+	if len(entries) != 3 {
+		t.Fatalf("EntriesFromFiles() returned %d entries, want 3: %+v", len(entries), entries)
+	}
+	if entries[0].Key != "auth.hint" || entries[2].Key != "menu[0]" {
+		t.Errorf("EntriesFromFiles() keys = %s, %s", entries[0].Key, entries[2].Key)
+	}
+	entries = append(entries, EntriesFromFiles(source, ja, "en", "ja")...)
+
+	var buf strings.Builder
+	if err := WriteTMX(&buf, entries, "test"); err != nil {
+		t.Fatalf("WriteTMX() error = %v", err)
+	}
+	doc := buf.String()
+	if !strings.Contains(doc, `<tmx version="1.4">`) || !strings.Contains(doc, `srclang="en"`) {
+		t.Errorf("WriteTMX() header missing:\n%s", doc)
+	}
+	if strings.Count(doc, "<tu ") != 3 {
+		t.Errorf("WriteTMX() should group languages into 3 units:\n%s", doc)
+	}
+
+	parsed, err := ParseTMX(strings.NewReader(doc), "tmx")
+	if err != nil {
+		t.Fatalf("ParseTMX() error = %v", err)
+	}
+	if len(parsed) != len(entries) {
+		t.Fatalf("round trip returned %d entries, want %d", len(parsed), len(entries))
+	}
+	for _, e := range parsed {
+		if e.Key == "auth.hint" && e.Source != "Use <b>{email}</b> & password" {
+			t.Errorf("markup not preserved: %q", e.Source)
+		}
+		if e.Key == "auth.signin" && e.TargetLang == "ja" && e.Target != "サインイン" {
+			t.Errorf("ja target = %q", e.Target)
+		}
+	}
+}
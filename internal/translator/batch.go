@@ -2,7 +2,9 @@ package translator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"maps"
 	"strings"
 	"sync"
@@ -10,14 +12,27 @@ import (
 
 	"github.com/hikanner/jta/internal/domain"
 	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
+	"github.com/hikanner/jta/internal/terminology"
 	"golang.org/x/sync/errgroup"
 )
 
 // BatchStats contains statistics from batch processing
 type BatchStats struct {
-	APICallsCount int
-	TotalTokens   int
+	APICallsCount    int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	SkippedBatches   int                         // batches not started because the budget was exhausted
+	Keys             map[string]domain.KeyDetail // how each key of the batches was translated
+}
+
+// addUsage records the token usage of one or more API calls
+func (s *BatchStats) addUsage(usage provider.Usage) {
+	s.PromptTokens += usage.PromptTokens
+	s.CompletionTokens += usage.CompletionTokens
+	s.TotalTokens += usage.TotalTokens
 }
 
 // BatchProgressCallback is called for batch progress updates
@@ -25,7 +40,7 @@ type BatchProgressCallback func(event BatchProgressEvent)
 
 // BatchProgressEvent represents a batch processing event
 type BatchProgressEvent struct {
-	Type         string // "start", "complete", "retry", "error"
+	Type         string // "start", "complete", "retry", "error", "skipped", "done"
 	BatchIndex   int
 	TotalBatches int
 	BatchSize    int
@@ -34,6 +49,8 @@ type BatchProgressEvent struct {
 	MaxAttempts  int
 	Duration     time.Duration
 	Tokens       int
+	Usage        provider.Usage // tokens of the attempt ("complete", "retry") or of the whole batch ("error", "done")
+	Backoff      time.Duration  // delay before the next attempt (retry events only)
 	Error        error
 }
 
@@ -43,6 +60,9 @@ type BatchProcessor struct {
 	formatProtector  *format.Protector
 	reflectionEngine *ReflectionEngine
 	progressCallback BatchProgressCallback
+	budget           *Budget
+	prompts          *prompt.Templates
+	logger           *slog.Logger
 }
 
 // SetProgressCallback sets the progress callback function
@@ -50,12 +70,30 @@ func (bp *BatchProcessor) SetProgressCallback(callback BatchProgressCallback) {
 	bp.progressCallback = callback
 }
 
+// SetBudget sets the spending cap; batches are not started once it is exhausted
+func (bp *BatchProcessor) SetBudget(budget *Budget) {
+	bp.budget = budget
+}
+
+// SetLogger sets the logger of batch progress: reflection, improvement, shortening
+// and completion of each batch, with its number as the "batch" attribute
+func (bp *BatchProcessor) SetLogger(logger *slog.Logger) {
+	bp.logger = logger
+}
+
+// SetPrompts sets the prompt templates
+func (bp *BatchProcessor) SetPrompts(prompts *prompt.Templates) {
+	bp.prompts = prompts
+}
+
 // NewBatchProcessor creates a new batch processor
 func NewBatchProcessor(provider provider.AIProvider, reflectionEngine *ReflectionEngine) *BatchProcessor {
 	return &BatchProcessor{
 		provider:         provider,
 		formatProtector:  format.NewProtector(),
 		reflectionEngine: reflectionEngine,
+		prompts:          prompt.Default(),
+		logger:           slog.New(slog.DiscardHandler),
 	}
 }
 
@@ -65,8 +103,9 @@ func (bp *BatchProcessor) ProcessBatches(
 	batches [][]domain.BatchItem,
 	sourceLang, targetLang string,
 	termDict string,
-	terminology *domain.Terminology,
+	term *domain.Terminology,
 	terminologyTranslation *domain.TerminologyTranslation,
+	styleGuide *domain.StyleGuide,
 	concurrency int,
 ) (map[string]string, BatchStats, error) {
 	if concurrency <= 0 {
@@ -76,7 +115,9 @@ func (bp *BatchProcessor) ProcessBatches(
 	results := make(map[string]string)
 	var resultsMu sync.Mutex
 
-	stats := BatchStats{}
+	termChecker := terminology.NewChecker(term, terminologyTranslation, targetLang)
+
+	stats := BatchStats{Keys: make(map[string]domain.KeyDetail)}
 	var statsMu sync.Mutex
 
 	// Track failed batches
@@ -97,6 +138,27 @@ func (bp *BatchProcessor) ProcessBatches(
 			// Record total batch time (including translation and reflection)
 			batchTotalStart := time.Now()
 
+			// Don't start new work once the budget is exhausted
+			if bp.budget.Exceeded() {
+				statsMu.Lock()
+				stats.SkippedBatches++
+				for _, item := range batchItems {
+					stats.Keys[item.Key] = domain.KeyDetail{Status: domain.KeyStatusSkipped, Batch: batchIdx + 1}
+				}
+				statsMu.Unlock()
+
+				if bp.progressCallback != nil {
+					bp.progressCallback(BatchProgressEvent{
+						Type:         "skipped",
+						BatchIndex:   batchIdx + 1,
+						TotalBatches: len(batches),
+						BatchSize:    len(batchItems),
+						Concurrency:  concurrency,
+					})
+				}
+				return nil
+			}
+
 			// Notify batch start
 			if bp.progressCallback != nil {
 				bp.progressCallback(BatchProgressEvent{
@@ -108,25 +170,35 @@ func (bp *BatchProcessor) ProcessBatches(
 				})
 			}
 
+			dict := batchTermDict(termDict, term, terminologyTranslation, batchItems)
+
 			// Process with retries
 			maxRetries := 3
 			var batchResults map[string]string
-			var batchTokens int
+			var batchUsage provider.Usage
 			var err error
+			attempts := 0
 
 			for attempt := range maxRetries {
 				startTime := time.Now()
+				attempts = attempt + 1
 
-				batchResults, batchTokens, err = bp.processSingleBatchOnce(
+				var usage provider.Usage
+				batchResults, usage, err = bp.processSingleBatchOnce(
 					ctx,
 					batchItems,
 					sourceLang,
 					targetLang,
-					termDict,
+					dict,
+					styleGuide,
 				)
 
 				duration := time.Since(startTime)
 
+				// Failed attempts still cost tokens
+				batchUsage = addUsage(batchUsage, usage)
+				bp.budget.Add(usage)
+
 				if err == nil {
 					// Success
 					if bp.progressCallback != nil {
@@ -137,15 +209,18 @@ func (bp *BatchProcessor) ProcessBatches(
 							BatchSize:    len(batchItems),
 							Concurrency:  concurrency,
 							Duration:     duration,
-							Tokens:       batchTokens,
+							Tokens:       usage.TotalTokens,
+							Usage:        usage,
 						})
 					}
 					break
 				}
 
-				// Failed
-				if attempt < maxRetries-1 {
-					// Will retry
+				// Failed; rate limits the provider's limiter already retried are final
+				if attempt < maxRetries-1 && !errors.Is(err, provider.ErrRateLimitRetried) {
+					// Will retry after exponential backoff, extended to the provider's Retry-After hint
+					backoff := retryBackoff(attempt, err)
+
 					if bp.progressCallback != nil {
 						bp.progressCallback(BatchProgressEvent{
 							Type:         "retry",
@@ -155,13 +230,16 @@ func (bp *BatchProcessor) ProcessBatches(
 							Concurrency:  concurrency,
 							Attempt:      attempt + 1,
 							MaxAttempts:  maxRetries,
+							Backoff:      backoff,
+							Usage:        usage,
 							Error:        err,
 						})
 					}
 
-					// Exponential backoff
-					backoff := time.Duration(1<<uint(attempt)) * time.Second
-					time.Sleep(backoff)
+					if waitErr := provider.WaitFor(ctx, backoff, nil); waitErr != nil {
+						err = waitErr
+						break
+					}
 				} else {
 					// Final failure
 					if bp.progressCallback != nil {
@@ -174,13 +252,28 @@ func (bp *BatchProcessor) ProcessBatches(
 							Attempt:      attempt + 1,
 							MaxAttempts:  maxRetries,
 							Duration:     duration,
+							Usage:        batchUsage,
 							Error:        err,
 						})
 					}
+					break
 				}
 			}
 
 			if err != nil {
+				statsMu.Lock()
+				stats.addUsage(batchUsage)
+				for _, item := range batchItems {
+					stats.Keys[item.Key] = domain.KeyDetail{
+						Status:      domain.KeyStatusFailed,
+						Batch:       batchIdx + 1,
+						Attempts:    attempts,
+						BatchTokens: batchUsage.TotalTokens,
+						Error:       err.Error(),
+					}
+				}
+				statsMu.Unlock()
+
 				// Record failure but don't return error (don't cancel other batches)
 				failedMu.Lock()
 				failedBatches[batchIdx] = err
@@ -188,16 +281,25 @@ func (bp *BatchProcessor) ProcessBatches(
 				return nil // Don't propagate error to avoid canceling other batches
 			}
 
+			// Keep the drafts and what reflection said about them for the run report
+			drafts := maps.Clone(batchResults)
+			var suggestions map[string]string
+			batchTotal := batchUsage // with reflection and shortening
+
 			// Apply reflection to this batch if reflection engine is available
-			if bp.reflectionEngine != nil && bp.reflectionEngine.ShouldReflect(batchResults, terminology) {
+			// (skipped once the budget is exhausted: the draft is kept as is)
+			reflect := bp.reflectionEngine != nil && bp.reflectionEngine.ShouldReflect(batchResults, term) &&
+				!bp.budget.Exceeded()
+			if reflect {
 				// Build reflection input for this batch
 				reflectionInput := ReflectionInput{
 					SourceTexts:            make(map[string]string),
 					TranslatedTexts:        batchResults,
 					SourceLang:             sourceLang,
 					TargetLang:             targetLang,
-					Terminology:            terminology,
+					Terminology:            term,
 					TerminologyTranslation: terminologyTranslation,
+					StyleGuide:             styleGuide,
 				}
 
 				// Extract source texts from batch items
@@ -205,24 +307,21 @@ func (bp *BatchProcessor) ProcessBatches(
 					reflectionInput.SourceTexts[item.Key] = item.Text
 				}
 
+				// Point reflection at terms the draft got wrong
+				reflectionInput.TermViolations = violationMessages(
+					termChecker.CheckAll(reflectionInput.SourceTexts, batchResults))
+
 				// Create progress callback for this batch (captures batchIdx for this specific batch)
 				progressCallback := func(event ReflectionProgressEvent) {
 					switch event.Type {
 					case "reflecting_start":
-						fmt.Printf("[Batch %d] 🔍 Reflecting...\n", batchIdx+1)
+						bp.logger.Debug("Reflecting", "batch", batchIdx+1)
 					case "reflected_complete":
-						if event.Count > 0 {
-							fmt.Printf("[Batch %d] ✓ Reflected       (%.1fs) Found %d suggestions\n",
-								batchIdx+1, event.Duration.Seconds(), event.Count)
-						} else {
-							fmt.Printf("[Batch %d] ✓ Reflected       (%.1fs) All OK\n",
-								batchIdx+1, event.Duration.Seconds())
-						}
+						bp.logger.Info("Reflected", "batch", batchIdx+1, "duration", event.Duration, "suggestions", event.Count)
 					case "improving_start":
-						fmt.Printf("[Batch %d] ✨ Improving...\n", batchIdx+1)
+						bp.logger.Debug("Improving", "batch", batchIdx+1)
 					case "improved_complete":
-						fmt.Printf("[Batch %d] ✓ Improved        (%.1fs) Updated %d translations\n",
-							batchIdx+1, event.Duration.Seconds(), event.Count)
+						bp.logger.Info("Improved", "batch", batchIdx+1, "duration", event.Duration, "updated", event.Count)
 					}
 				}
 
@@ -230,19 +329,63 @@ func (bp *BatchProcessor) ProcessBatches(
 				reflectionResult, reflectErr := bp.reflectionEngine.Reflect(ctx, reflectionInput, progressCallback)
 
 				if reflectErr != nil {
-					// Log error but don't fail the batch
-					fmt.Printf("[Batch %d] ✗ Reflection failed: %v\n", batchIdx+1, reflectErr)
+					// Log error but don't fail the batch: the drafts are kept, what was used is charged below
+					bp.logger.Warn("Reflection failed", "batch", batchIdx+1, "error", reflectErr)
 				} else if reflectionResult.ReflectionNeeded && len(reflectionResult.ImprovedTexts) > 0 {
 					// Apply improvements
 					maps.Copy(batchResults, reflectionResult.ImprovedTexts)
+				}
+
+				if reflectionResult != nil {
+					bp.budget.Add(reflectionResult.Usage)
+					suggestions = reflectionResult.Suggestions
+					batchTotal = addUsage(batchTotal, reflectionResult.Usage)
 
-					// Update API call count
+					// Update API call count and tokens
 					statsMu.Lock()
 					stats.APICallsCount += reflectionResult.APICallsUsed
+					stats.addUsage(reflectionResult.Usage)
 					statsMu.Unlock()
 				}
 			}
 
+			// Send translations over their length limit back for a shortening pass
+			overflows := checkLengths(batchItems, batchResults)
+			var shortened map[string]string
+			if len(overflows) > 0 && bp.reflectionEngine != nil && !bp.budget.Exceeded() {
+				bp.logger.Debug("Shortening", "batch", batchIdx+1, "keys", len(overflows))
+				shortenStart := time.Now()
+
+				shortenInput := ShortenInput{
+					SourceTexts: make(map[string]string, len(batchItems)),
+					Overflows:   overflows,
+					SourceLang:  sourceLang,
+					TargetLang:  targetLang,
+					StyleGuide:  styleGuide,
+				}
+				for _, item := range batchItems {
+					shortenInput.SourceTexts[item.Key] = item.Text
+				}
+
+				result, usage, shortenErr := bp.reflectionEngine.Shorten(ctx, shortenInput)
+				bp.budget.Add(usage)
+				batchTotal = addUsage(batchTotal, usage)
+
+				statsMu.Lock()
+				stats.APICallsCount++
+				stats.addUsage(usage)
+				statsMu.Unlock()
+
+				if shortenErr != nil {
+					bp.logger.Warn("Shortening failed", "batch", batchIdx+1, "error", shortenErr)
+				} else {
+					shortened = result
+					maps.Copy(batchResults, shortened)
+					bp.logger.Info("Shortened", "batch", batchIdx+1, "duration", time.Since(shortenStart),
+						"fit", len(overflows)-len(checkLengths(batchItems, batchResults)), "keys", len(overflows))
+				}
+			}
+
 			// Update results
 			resultsMu.Lock()
 			maps.Copy(results, batchResults)
@@ -251,15 +394,46 @@ func (bp *BatchProcessor) ProcessBatches(
 			// Update stats
 			statsMu.Lock()
 			stats.APICallsCount++
-			stats.TotalTokens += batchTokens
+			stats.addUsage(batchUsage)
+			for _, item := range batchItems {
+				detail := domain.KeyDetail{
+					Status:      domain.KeyStatusTranslated,
+					Batch:       batchIdx + 1,
+					Draft:       drafts[item.Key],
+					Suggestion:  suggestions[item.Key],
+					Attempts:    attempts,
+					BatchTokens: batchTotal.TotalTokens,
+				}
+				if _, ok := batchResults[item.Key]; !ok {
+					detail.Status = domain.KeyStatusFailed
+					detail.Error = "no translation returned"
+				}
+				stats.Keys[item.Key] = detail
+			}
+			// Only the overflows the shortening pass returned a translation for
+			for _, overflow := range overflows {
+				if _, ok := shortened[overflow.Key]; !ok {
+					continue
+				}
+				detail := stats.Keys[overflow.Key]
+				detail.Shortened = true
+				stats.Keys[overflow.Key] = detail
+			}
 			statsMu.Unlock()
 
-			// Print final completion
-			batchTotalElapsed := time.Since(batchTotalStart)
-			if bp.reflectionEngine != nil && bp.reflectionEngine.ShouldReflect(batchResults, terminology) {
-				fmt.Printf("[Batch %d] ✅ COMPLETE       (total: %.1fs)\n", batchIdx+1, batchTotalElapsed.Seconds())
-			} else {
-				fmt.Printf("[Batch %d] ✅ COMPLETE       (%.1fs, no reflection)\n", batchIdx+1, batchTotalElapsed.Seconds())
+			batchDuration := time.Since(batchTotalStart)
+			bp.logger.Info("Batch complete", "batch", batchIdx+1, "duration", batchDuration, "reflection", reflect)
+			if bp.progressCallback != nil {
+				bp.progressCallback(BatchProgressEvent{
+					Type:         "done",
+					BatchIndex:   batchIdx + 1,
+					TotalBatches: len(batches),
+					BatchSize:    len(batchItems),
+					Concurrency:  concurrency,
+					Duration:     batchDuration,
+					Tokens:       batchTotal.TotalTokens,
+					Usage:        batchTotal,
+				})
 			}
 
 			return nil
@@ -295,36 +469,90 @@ func (bp *BatchProcessor) ProcessBatches(
 		// The caller will get partial translations
 	}
 
+	// Budget exhausted before all batches ran: return partial results
+	if stats.SkippedBatches > 0 {
+		return results, stats, domain.NewBudgetError("cost budget exceeded", nil).
+			WithContext("spent", fmt.Sprintf("$%.4f", bp.budget.Spent())).
+			WithContext("max_cost", fmt.Sprintf("$%.4f", bp.budget.Max())).
+			WithContext("skipped_batches", stats.SkippedBatches)
+	}
+
 	return results, stats, nil
 }
 
+// batchTermDict returns the terminology dictionary of a batch: the run's, unless terms are
+// scoped to keys, in which case it is built for the keys of the batch
+func batchTermDict(termDict string, term *domain.Terminology, translation *domain.TerminologyTranslation, items []domain.BatchItem) string {
+	if !term.HasScopedTerms() {
+		return termDict
+	}
+	return scopedTermDict(term, translation, items)
+}
+
+// scopedTermDict builds the terminology dictionary for the keys of a single batch
+func scopedTermDict(term *domain.Terminology, translation *domain.TerminologyTranslation, items []domain.BatchItem) string {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return terminology.PromptDictionary(term, translation, keys)
+}
+
+// violationMessages groups violation messages by key
+func violationMessages(violations []domain.TermViolation) map[string][]string {
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make(map[string][]string)
+	for _, v := range violations {
+		messages[v.Key] = append(messages[v.Key], v.Message)
+	}
+	return messages
+}
+
+// retryBackoff returns the delay before the next attempt: 1s, 2s, 4s...
+// or longer when the provider asked us to back off via Retry-After
+func retryBackoff(attempt int, err error) time.Duration {
+	backoff := time.Duration(1<<uint(attempt)) * time.Second
+	if retryAfter, ok := provider.RetryAfter(err); ok && retryAfter > backoff {
+		backoff = retryAfter
+	}
+	return backoff
+}
+
 // processSingleBatchOnce processes a single batch of items (one attempt, no retries)
 func (bp *BatchProcessor) processSingleBatchOnce(
 	ctx context.Context,
 	items []domain.BatchItem,
 	sourceLang, targetLang string,
 	termDict string,
-) (map[string]string, int, error) {
+	styleGuide *domain.StyleGuide,
+) (map[string]string, provider.Usage, error) {
 	// Build batch translation prompt
-	prompt := bp.buildBatchPrompt(items, sourceLang, targetLang, termDict)
+	prompt, err := bp.buildBatchPrompt(items, sourceLang, targetLang, termDict, styleGuide)
+	if err != nil {
+		return nil, provider.Usage{}, err
+	}
 
 	// Create independent 5-minute timeout for this LLM call
 	callCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	// Call AI provider (single attempt)
-	resp, err := bp.provider.Complete(callCtx, &provider.CompletionRequest{
+	req := &provider.CompletionRequest{
 		Prompt: prompt,
-	})
+	}
+	resp, err := bp.provider.Complete(callCtx, req)
 
 	if err != nil {
-		return nil, 0, err
+		return nil, provider.Usage{}, err
 	}
 
-	// Parse response
+	// Parse response; an unusable response must not be served from the cache again
 	results, err := bp.parseBatchResponse(resp.Content, items)
 	if err != nil {
-		return nil, resp.Usage.TotalTokens, domain.NewFormatError("failed to parse response", err).
+		provider.Invalidate(bp.provider, req)
+		return nil, resp.Usage, domain.NewFormatError("failed to parse response", err).
 			WithContext("item_count", len(items))
 	}
 
@@ -345,7 +573,7 @@ func (bp *BatchProcessor) processSingleBatchOnce(
 		}
 	}
 
-	return results, resp.Usage.TotalTokens, nil
+	return results, resp.Usage, nil
 }
 
 // buildBatchPrompt builds the prompt for batch translation
@@ -353,44 +581,33 @@ func (bp *BatchProcessor) buildBatchPrompt(
 	items []domain.BatchItem,
 	sourceLang, targetLang string,
 	termDict string,
-) string {
-	var builder strings.Builder
-
-	// Get target language name
-	targetLangName := targetLang // simplified, should use language map
-
-	builder.WriteString(fmt.Sprintf(`You are a professional localization translator specialized in UI/UX content.
-
-Task: Translate the following %s texts to %s for a JSON i18n file.
-
-`, sourceLang, targetLangName))
-
-	// Add terminology dictionary if provided
-	if termDict != "" {
-		builder.WriteString("【Terminology Dictionary】\n")
-		builder.WriteString(termDict)
-		builder.WriteString("\n\n")
+	styleGuide *domain.StyleGuide,
+) (string, error) {
+	data := prompt.TranslateData{
+		SourceLang:  sourceLang,
+		TargetLang:  targetLang,
+		Terminology: strings.TrimSpace(termDict),
+		StyleGuide:  buildStyleSection(styleGuide),
+		Items:       make([]prompt.Item, len(items)),
 	}
-
-	// Add format instructions
-	builder.WriteString(`【Core Requirements】
-1. 🔒 Keep all placeholders unchanged (e.g., {variable}, {{count}})
-2. 🏷️ Keep all HTML tags and special markers unchanged
-3. 📝 Follow terminology translations EXACTLY
-4. ⚡ Return format: [ID] translation (no additional explanation)
-5. 🎯 Maintain context consistency across related texts
-
-`)
-
-	// Add items
-	builder.WriteString("【Texts to Translate】\n")
 	for i, item := range items {
-		builder.WriteString(fmt.Sprintf("[%d] %s\n", i+1, item.Text))
+		data.Items[i] = prompt.Item{Number: i + 1, Key: item.Key, Text: item.Text}
+		if !item.MaxLength.IsZero() {
+			data.Items[i].Limit = item.MaxLength.String()
+			data.HasLimits = true
+		}
+		for _, ref := range item.References {
+			data.Items[i].References = append(data.Items[i].References, prompt.Reference{
+				Source:     ref.Source,
+				Target:     ref.Target,
+				Similarity: ref.Similarity,
+			})
+		}
+		data.HasReferences = data.HasReferences || len(item.References) > 0
+		data.Items[i].Note = item.Note
+		data.HasNotes = data.HasNotes || item.Note != ""
 	}
-
-	builder.WriteString("\n【Translation Results】\n")
-
-	return builder.String()
+	return bp.prompts.Render(prompt.Translate, data)
 }
 
 // parseBatchResponse parses the batch translation response
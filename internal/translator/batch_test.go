@@ -2,6 +2,8 @@ package translator
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -89,6 +91,7 @@ func TestBatchProcessor_ProcessBatches_SingleBatch(t *testing.T) {
 		"",
 		nil,
 		nil,
+		nil,
 		1,
 	)
 
@@ -136,6 +139,12 @@ func TestBatchProcessor_ProcessBatches_SingleBatch(t *testing.T) {
 	if !hasComplete {
 		t.Error("ProcessBatches() did not emit 'complete' event")
 	}
+
+	// The batch ends with its usage across translation and reflection
+	last := events[len(events)-1]
+	if last.Type != "done" || last.Usage.TotalTokens != stats.TotalTokens || last.Tokens != stats.TotalTokens {
+		t.Errorf("last event = %+v, want done with %d tokens", last, stats.TotalTokens)
+	}
 }
 
 func TestBatchProcessor_ProcessBatches_MultipleBatches(t *testing.T) {
@@ -182,6 +191,7 @@ func TestBatchProcessor_ProcessBatches_MultipleBatches(t *testing.T) {
 		"",
 		nil,
 		nil,
+		nil,
 		1, // concurrency = 1 (sequential to avoid mock provider issues)
 	)
 
@@ -248,6 +258,7 @@ func TestBatchProcessor_ProcessBatches_WithTerminology(t *testing.T) {
 		"API must not be translated\nHello = 你好",
 		terminology,
 		termTranslation,
+		nil,
 		1,
 	)
 
@@ -291,6 +302,7 @@ func TestBatchProcessor_ProcessBatches_DefaultConcurrency(t *testing.T) {
 		"",
 		nil,
 		nil,
+		nil,
 		0, // should default to 3
 	)
 
@@ -315,6 +327,7 @@ func TestBatchProcessor_ProcessBatches_EmptyBatches(t *testing.T) {
 		"",
 		nil,
 		nil,
+		nil,
 		1,
 	)
 
@@ -377,3 +390,166 @@ func TestBatchStats(t *testing.T) {
 		t.Errorf("BatchStats.TotalTokens = %d, want 1000", stats.TotalTokens)
 	}
 }
+
+func TestRetryBackoff(t *testing.T) {
+	if got := retryBackoff(0, nil); got != time.Second {
+		t.Errorf("retryBackoff(0) = %v, want 1s", got)
+	}
+	if got := retryBackoff(2, nil); got != 4*time.Second {
+		t.Errorf("retryBackoff(2) = %v, want 4s", got)
+	}
+}
+
+// rateLimitedProvider fails like a provider whose rate limiter gave up on a 429
+type rateLimitedProvider struct {
+	*provider.MockProvider
+	calls int
+}
+
+func (p *rateLimitedProvider) Complete(ctx context.Context, req *provider.CompletionRequest) (*provider.CompletionResponse, error) {
+	p.calls++
+	return nil, fmt.Errorf("%w: %w", provider.ErrRateLimitRetried, errors.New("429 Too Many Requests"))
+}
+
+func TestBatchProcessor_NoRetryOfRetriedRateLimits(t *testing.T) {
+	prov := &rateLimitedProvider{MockProvider: provider.NewMockProvider("gpt-4")}
+	bp := NewBatchProcessor(prov, NewReflectionEngine(prov))
+
+	_, stats, _ := bp.ProcessBatches(context.Background(), [][]domain.BatchItem{{{Key: "a", Text: "Hello"}}}, "en", "zh", "", nil, nil, nil, 1)
+	if prov.calls != 1 {
+		t.Errorf("provider called %d times, want 1: the rate limiter already retried", prov.calls)
+	}
+	if stats.Keys["a"].Status != domain.KeyStatusFailed {
+		t.Errorf("key status = %q, want failed", stats.Keys["a"].Status)
+	}
+}
+
+func TestBatchProcessor_FailedImprovementCharged(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 你好")
+	mockProvider.AddResponse("[hello] Too formal")
+	// No response left: the improvement call fails
+
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+	results, stats, err := bp.ProcessBatches(context.Background(), [][]domain.BatchItem{{{Key: "hello", Text: "Hello"}}}, "en", "zh", "", nil, nil, nil, 1)
+	if err != nil {
+		t.Fatalf("ProcessBatches() error = %v", err)
+	}
+	if results["hello"] != "你好" {
+		t.Errorf("results[hello] = %q, want the draft", results["hello"])
+	}
+	if stats.APICallsCount != 2 || stats.TotalTokens != 300 {
+		t.Errorf("stats = %d calls, %d tokens, want 2 calls, 300 tokens: the reflection call is charged", stats.APICallsCount, stats.TotalTokens)
+	}
+}
+
+func TestBatchProcessor_BuildBatchPrompt_References(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+
+	items := []domain.BatchItem{
+		{Key: "a", Text: "Sign in"},
+		{Key: "b", Text: "Please sign in to continue", References: []domain.TranslationReference{
+			{Source: "Sign in to continue", Target: "Zum Fortfahren anmelden", Similarity: 0.87},
+		}},
+	}
+
+	prompt, err := bp.buildBatchPrompt(items, "en", "de", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "【Translation Memory】") {
+		t.Error("prompt missing translation memory section")
+	}
+	if !strings.Contains(prompt, `[2] "Sign in to continue" → "Zum Fortfahren anmelden" (87% match)`) {
+		t.Errorf("prompt missing reference line:\n%s", prompt)
+	}
+
+	prompt, err = bp.buildBatchPrompt(items[:1], "en", "de", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(prompt, "【Translation Memory】") {
+		t.Error("prompt should not include translation memory without references")
+	}
+}
+
+func TestScopedTermDict(t *testing.T) {
+	terminology := &domain.Terminology{
+		SourceLanguage:  "en",
+		ConsistentTerms: []string{"Plan", "account"},
+		Details: map[string]domain.TermInfo{
+			"Plan": {Scope: []string{"billing.**"}},
+		},
+	}
+	translation := &domain.TerminologyTranslation{
+		SourceLanguage: "en",
+		TargetLanguage: "zh",
+		Translations:   map[string]string{"Plan": "套餐", "account": "帐号"},
+	}
+
+	billing := scopedTermDict(terminology, translation, []domain.BatchItem{{Key: "settings.title"}, {Key: "billing.plan.name"}})
+	if !strings.Contains(billing, "套餐") || !strings.Contains(billing, "帐号") {
+		t.Errorf("batch with a billing key should include both terms:\n%s", billing)
+	}
+
+	settings := scopedTermDict(terminology, translation, []domain.BatchItem{{Key: "settings.title"}})
+	if strings.Contains(settings, "套餐") || !strings.Contains(settings, "帐号") {
+		t.Errorf("batch without billing keys should only include unscoped terms:\n%s", settings)
+	}
+}
+
+func TestBatchProcessor_BuildBatchPrompt_LengthLimits(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+
+	items := []domain.BatchItem{
+		{Key: "title", Text: "Account settings"},
+		{Key: "save", Text: "Save", MaxLength: domain.LengthLimit{Max: 10, Unit: domain.LengthUnitWidth}},
+	}
+
+	prompt, err := bp.buildBatchPrompt(items, "en", "ja", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "【Length Limits】") || !strings.Contains(prompt, "\n[2] at most 10 display columns\n") {
+		t.Errorf("prompt missing length limits:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Stay within the length limits") {
+		t.Errorf("prompt missing length requirement:\n%s", prompt)
+	}
+
+	prompt, err = bp.buildBatchPrompt(items[:1], "en", "ja", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(prompt, "Length Limits") {
+		t.Error("prompt should not include length limits without limits")
+	}
+}
+
+func TestBatchProcessor_BuildBatchPrompt_Notes(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+
+	items := []domain.BatchItem{
+		{Key: "title", Text: "Account settings"},
+		{Key: "welcome", Text: "Welcome back", Note: "Too formal, use du"},
+	}
+
+	prompt, err := bp.buildBatchPrompt(items, "en", "de", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "【Reviewer Notes】") || !strings.Contains(prompt, "\n[2] Too formal, use du\n") {
+		t.Errorf("prompt missing reviewer notes:\n%s", prompt)
+	}
+
+	prompt, err = bp.buildBatchPrompt(items[:1], "en", "de", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(prompt, "Reviewer Notes") {
+		t.Error("prompt should not include reviewer notes without notes")
+	}
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"sync"
+
+	"github.com/hikanner/jta/internal/provider"
+)
+
+// Budget tracks the running cost of API calls against a spending cap.
+// A single Budget can be shared by several engines to cap a whole run.
+type Budget struct {
+	maxCost float64
+	pricing provider.ModelPricing
+	tally   *tally
+}
+
+// tally is the cost spent so far, shared by a budget and its views for other models
+type tally struct {
+	mu    sync.Mutex
+	spent float64
+}
+
+// NewBudget creates a budget; maxCost <= 0 means unlimited
+func NewBudget(maxCost float64, pricing provider.ModelPricing) *Budget {
+	return &Budget{
+		maxCost: maxCost,
+		pricing: pricing,
+		tally:   &tally{},
+	}
+}
+
+// WithPricing returns a view of the budget that prices calls with another model's
+// pricing. The cap and the cost spent so far are shared with the original.
+func (b *Budget) WithPricing(pricing provider.ModelPricing) *Budget {
+	if b == nil {
+		return nil
+	}
+	return &Budget{
+		maxCost: b.maxCost,
+		pricing: pricing,
+		tally:   b.tally,
+	}
+}
+
+// Add records the cost of an API call
+func (b *Budget) Add(usage provider.Usage) {
+	if b == nil {
+		return
+	}
+	b.tally.mu.Lock()
+	defer b.tally.mu.Unlock()
+	b.tally.spent += b.pricing.Cost(usage)
+}
+
+// Spent returns the cost recorded so far
+func (b *Budget) Spent() float64 {
+	if b == nil {
+		return 0
+	}
+	b.tally.mu.Lock()
+	defer b.tally.mu.Unlock()
+	return b.tally.spent
+}
+
+// Max returns the spending cap (0 = unlimited)
+func (b *Budget) Max() float64 {
+	if b == nil {
+		return 0
+	}
+	return b.maxCost
+}
+
+// Exceeded reports whether the recorded cost has crossed the cap
+func (b *Budget) Exceeded() bool {
+	if b == nil || b.maxCost <= 0 {
+		return false
+	}
+	b.tally.mu.Lock()
+	defer b.tally.mu.Unlock()
+	return b.tally.spent >= b.maxCost
+}
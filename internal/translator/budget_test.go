@@ -0,0 +1,98 @@
+package translator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/provider"
+)
+
+func TestBudget(t *testing.T) {
+	// nil budget is unlimited
+	var none *Budget
+	none.Add(provider.Usage{PromptTokens: 1000})
+	if none.Exceeded() || none.Spent() != 0 {
+		t.Error("nil budget should never be exceeded")
+	}
+
+	b := NewBudget(1.0, provider.ModelPricing{InputPerMillion: 1, OutputPerMillion: 1})
+	b.Add(provider.Usage{PromptTokens: 400_000, CompletionTokens: 100_000})
+	if b.Exceeded() {
+		t.Errorf("Exceeded() = true after spending %v of 1.0", b.Spent())
+	}
+	b.Add(provider.Usage{PromptTokens: 500_000})
+	if !b.Exceeded() {
+		t.Errorf("Exceeded() = false after spending %v of 1.0", b.Spent())
+	}
+
+	// Zero cap means unlimited
+	unlimited := NewBudget(0, provider.ModelPricing{InputPerMillion: 100})
+	unlimited.Add(provider.Usage{PromptTokens: 1_000_000})
+	if unlimited.Exceeded() {
+		t.Error("budget with no cap should never be exceeded")
+	}
+}
+
+func TestBudget_WithPricing(t *testing.T) {
+	b := NewBudget(1.0, provider.ModelPricing{InputPerMillion: 1})
+	expensive := b.WithPricing(provider.ModelPricing{InputPerMillion: 10})
+
+	b.Add(provider.Usage{PromptTokens: 500_000})
+	expensive.Add(provider.Usage{PromptTokens: 50_000})
+	if b.Spent() != 1.0 || expensive.Spent() != 1.0 {
+		t.Errorf("Spent() = %v and %v, want 1.0 shared", b.Spent(), expensive.Spent())
+	}
+	if !b.Exceeded() || !expensive.Exceeded() || expensive.Max() != 1.0 {
+		t.Error("views of a budget should share the cap")
+	}
+
+	var none *Budget
+	if none.WithPricing(provider.ModelPricing{}) != nil {
+		t.Error("a view of no budget should be no budget")
+	}
+}
+
+func TestBatchProcessor_ProcessBatches_BudgetExceeded(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	// First batch: translate + reflect + improve
+	mockProvider.AddResponse("[1] 你好")
+	mockProvider.AddResponse("[1] Translation is good")
+	mockProvider.AddResponse("[1] 你好")
+
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+	// Each mock call costs 150 tokens; one batch (450 tokens) exhausts the cap
+	bp.SetBudget(NewBudget(0.0003, provider.ModelPricing{InputPerMillion: 1, OutputPerMillion: 1}))
+
+	var skipped int
+	bp.SetProgressCallback(func(event BatchProgressEvent) {
+		if event.Type == "skipped" {
+			skipped++
+		}
+	})
+
+	batches := [][]domain.BatchItem{
+		{{Key: "hello", Text: "Hello"}},
+		{{Key: "world", Text: "World"}},
+	}
+
+	results, stats, err := bp.ProcessBatches(context.Background(), batches, "en", "zh", "", nil, nil, nil, 1)
+	if !domain.IsErrorType(err, domain.ErrorTypeBudget) {
+		t.Fatalf("ProcessBatches() error = %v, want budget error", err)
+	}
+	if results["hello"] != "你好" {
+		t.Errorf("partial results = %v, want hello translated", results)
+	}
+	if _, ok := results["world"]; ok {
+		t.Error("second batch should not have been translated")
+	}
+	if stats.SkippedBatches != 1 || skipped != 1 {
+		t.Errorf("SkippedBatches = %d, skipped events = %d, want 1", stats.SkippedBatches, skipped)
+	}
+	if stats.PromptTokens != 300 || stats.CompletionTokens != 150 {
+		t.Errorf("tokens = %d/%d, want 300/150", stats.PromptTokens, stats.CompletionTokens)
+	}
+	if mockProvider.GetCallCount() != 3 {
+		t.Errorf("API calls = %d, want 3", mockProvider.GetCallCount())
+	}
+}
@@ -1,16 +1,31 @@
 package translator
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/hikanner/jta/internal/domain"
 	"github.com/hikanner/jta/internal/format"
 	"github.com/hikanner/jta/internal/keyfilter"
+	"github.com/hikanner/jta/internal/length"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 	"github.com/hikanner/jta/internal/rtl"
 	"github.com/hikanner/jta/internal/terminology"
+	"github.com/hikanner/jta/internal/tm"
+)
+
+const (
+	// lockedReferenceThreshold is how similar a locked translation's source text must be
+	// to an item's to be offered as a reference
+	lockedReferenceThreshold = 0.5
+	// maxLockedReferences is the number of locked translations offered per item at most
+	maxLockedReferences = 2
 )
 
 // Engine is the main translation engine
@@ -22,6 +37,7 @@ type Engine struct {
 	keyFilter        *keyfilter.Filter
 	rtlProcessor     *rtl.Processor
 	reflectionEngine *ReflectionEngine
+	pricing          provider.ModelPricing
 }
 
 // NewEngine creates a new translation engine
@@ -51,7 +67,30 @@ func (e *Engine) GetReflectionEngine() *ReflectionEngine {
 	return e.reflectionEngine
 }
 
-// Translate performs the complete translation workflow
+// SetPricing sets the model pricing used to compute the estimated cost
+func (e *Engine) SetPricing(pricing provider.ModelPricing) {
+	e.pricing = pricing
+}
+
+// SetPrompts sets the prompt templates used for translation, reflection and improvement
+func (e *Engine) SetPrompts(prompts *prompt.Templates) {
+	e.batchProcessor.SetPrompts(prompts)
+	e.reflectionEngine.SetPrompts(prompts)
+}
+
+// SetLogger sets the logger of translation progress and warnings (see BatchProcessor.SetLogger)
+func (e *Engine) SetLogger(logger *slog.Logger) {
+	e.batchProcessor.SetLogger(logger)
+	e.reflectionEngine.SetLogger(logger)
+}
+
+// SetBudget sets the spending cap for translation runs
+func (e *Engine) SetBudget(budget *Budget) {
+	e.batchProcessor.SetBudget(budget)
+}
+
+// Translate performs the complete translation workflow.
+// When the budget runs out the partial result is returned together with a budget error.
 func (e *Engine) Translate(ctx context.Context, input domain.TranslationInput) (*domain.TranslationResult, error) {
 	startTime := time.Now()
 
@@ -62,57 +101,31 @@ func (e *Engine) Translate(ctx context.Context, input domain.TranslationInput) (
 		},
 	}
 
-	// Step 1: Apply key filtering if patterns are provided
-	sourceData := input.Source
-	if len(input.Options.Keys) > 0 || len(input.Options.ExcludeKeys) > 0 {
-		includePatterns, err := e.parseKeyPatterns(input.Options.Keys)
-		if err != nil {
-			return nil, domain.NewValidationError("failed to parse include patterns", err).
-				WithContext("patterns", input.Options.Keys)
-		}
-
-		excludePatterns, err := e.parseKeyPatterns(input.Options.ExcludeKeys)
-		if err != nil {
-			return nil, domain.NewValidationError("failed to parse exclude patterns", err).
-				WithContext("patterns", input.Options.ExcludeKeys)
-		}
-
-		filterResult, err := e.keyFilter.FilterKeys(input.Source, includePatterns, excludePatterns)
-		if err != nil {
-			return nil, domain.NewValidationError("failed to filter keys", err)
-		}
-
-		// Rebuild filtered JSON structure
-		sourceData = e.keyFilter.RebuildJSON(filterResult.Included)
-
-		// Store filter stats
-		result.Stats.FilterStats = &domain.FilterStats{
-			TotalKeys:    filterResult.Stats.TotalKeys,
-			IncludedKeys: filterResult.Stats.IncludedKeys,
-			ExcludedKeys: filterResult.Stats.ExcludedKeys,
-		}
-	}
-
-	// Step 2: Extract translatable items from source JSON
-	items, err := e.extractTranslatableItems(sourceData, "")
+	// Step 1-2: Filter keys and extract translatable items
+	prepared, err := e.prepare(input)
 	if err != nil {
-		return nil, domain.NewFormatError("failed to extract translatable items", err)
+		return nil, err
 	}
+	sourceData := prepared.sourceData
+	items := prepared.items
 
-	result.Stats.TotalItems = len(items)
+	result.Stats.FilterStats = prepared.filterStats
+	result.Stats.TotalItems = len(items) + len(prepared.reused) + len(prepared.locked)
+	result.Stats.ReusedItems = len(prepared.reused)
+	result.Stats.LockedItems = len(prepared.locked)
 
 	// Step 2: Load terminology (if not disabled)
-	var terminology *domain.Terminology
+	var term *domain.Terminology
 	var terminologyTranslation *domain.TerminologyTranslation
 	if !input.Options.NoTerminology {
-		terminology = input.Terminology
+		term = input.Terminology
 		terminologyTranslation = input.TerminologyTranslation
 	}
 
 	// Step 3: Build terminology dictionary for prompt
 	var termDict string
-	if terminology != nil {
-		termDict = e.termManager.BuildPromptDictionary(terminology, terminologyTranslation)
+	if term != nil {
+		termDict = e.termManager.BuildPromptDictionary(term, terminologyTranslation)
 	}
 
 	// Step 4: Create batches for translation
@@ -125,32 +138,72 @@ func (e *Engine) Translate(ctx context.Context, input domain.TranslationInput) (
 		input.SourceLang,
 		input.TargetLang,
 		termDict,
-		terminology,
+		term,
 		terminologyTranslation,
+		input.StyleGuide,
 		input.Options.Concurrency,
 	)
 
+	// A budget error still carries partial translations
+	var budgetErr error
 	if err != nil {
-		return nil, domain.NewTranslationError("batch processing failed", err).
-			WithContext("source_lang", input.SourceLang).
-			WithContext("target_lang", input.TargetLang).
-			WithContext("batch_count", len(batches))
+		if !domain.IsErrorType(err, domain.ErrorTypeBudget) {
+			return nil, domain.NewTranslationError("batch processing failed", err).
+				WithContext("source_lang", input.SourceLang).
+				WithContext("target_lang", input.TargetLang).
+				WithContext("batch_count", len(batches))
+		}
+		budgetErr = err
 	}
 
 	// Update stats
 	result.Stats.APICallsCount = stats.APICallsCount
+	result.Stats.PromptTokens = stats.PromptTokens
+	result.Stats.CompletionTokens = stats.CompletionTokens
 	result.Stats.TotalTokens = stats.TotalTokens
-	result.Stats.SuccessItems = len(translations)
-	result.Stats.FailedItems = result.Stats.TotalItems - result.Stats.SuccessItems
+	result.Stats.EstimatedCost = e.pricing.Cost(provider.Usage{
+		PromptTokens:     stats.PromptTokens,
+		CompletionTokens: stats.CompletionTokens,
+	})
 
 	// Note: Reflection is now done per-batch in ProcessBatches for better scalability
 	// No need for global reflection here
 
+	// Step 5.2: Report terminology violations that survived reflection
+	sourceTexts := make(map[string]string, len(items))
+	for _, item := range items {
+		sourceTexts[item.Key] = item.Text
+	}
+	result.TermViolations = terminology.NewChecker(term, terminologyTranslation, input.TargetLang).
+		CheckAll(sourceTexts, translations)
+
+	// Step 5.3: Report translations still over their length limit after shortening
+	result.LengthViolations = checkLengths(items, translations)
+
 	// Step 5.5: Apply RTL processing if target language is RTL
 	if e.rtlProcessor.NeedProcessing(input.TargetLang) {
 		translations = e.rtlProcessor.ProcessBatch(translations, input.TargetLang)
 	}
 
+	// Reused and locked translations were produced (and RTL-processed) earlier
+	maps.Copy(translations, prepared.reused)
+	maps.Copy(translations, prepared.locked)
+
+	result.Keys = stats.Keys
+	if result.Keys == nil {
+		result.Keys = make(map[string]domain.KeyDetail)
+	}
+	for key := range prepared.reused {
+		result.Keys[key] = domain.KeyDetail{Status: domain.KeyStatusReused, Origin: input.Prefilled[key].Origin}
+	}
+	for key := range prepared.locked {
+		result.Keys[key] = domain.KeyDetail{Status: domain.KeyStatusLocked}
+	}
+
+	result.Translations = translations
+	result.Stats.SuccessItems = len(translations)
+	result.Stats.FailedItems = result.Stats.TotalItems - result.Stats.SuccessItems
+
 	// Step 6: Rebuild JSON structure with translations
 	rebuilt := e.rebuildJSONWithPath(sourceData, translations, "")
 	if targetMap, ok := rebuilt.(map[string]any); ok {
@@ -162,16 +215,247 @@ func (e *Engine) Translate(ctx context.Context, input domain.TranslationInput) (
 	// Calculate duration
 	result.Stats.Duration = time.Since(startTime)
 
+	return result, budgetErr
+}
+
+// Estimate computes the expected size and cost of a translation without calling the API.
+// Token counts are approximations (about 4 characters per token) and assume every batch
+// goes through translate, reflect and improve.
+func (e *Engine) Estimate(input domain.TranslationInput) (*domain.CostEstimate, error) {
+	batches, err := e.Prompts(input)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &domain.CostEstimate{Batches: len(batches)}
+	for _, batch := range batches {
+		estimate.Items += len(batch.Items)
+
+		outputTokens := 0
+		for _, item := range batch.Items {
+			outputTokens += estimateTokens(fmt.Sprintf("[%s] %s\n", item.Key, item.Text))
+		}
+		for _, prompt := range []string{batch.Translate, batch.Reflect, batch.Improve} {
+			estimate.PromptTokens += estimateTokens(prompt)
+			estimate.CompletionTokens += outputTokens
+		}
+		estimate.APICalls += 3
+	}
+
+	estimate.EstimatedCost = e.pricing.Cost(provider.Usage{
+		PromptTokens:     estimate.PromptTokens,
+		CompletionTokens: estimate.CompletionTokens,
+	})
+
+	return estimate, nil
+}
+
+// BatchPrompts are the prompts sent for one batch
+type BatchPrompts struct {
+	Items     []domain.BatchItem
+	Translate string
+	Reflect   string
+	Improve   string
+	Shorten   string // only for batches with length limits
+}
+
+// Prompts renders the translate, reflect and improve prompts of every batch, and the shorten
+// prompt of batches with length limits, without calling the API. The source texts stand in
+// for the translations and the suggestions, which are roughly the same size.
+func (e *Engine) Prompts(input domain.TranslationInput) ([]BatchPrompts, error) {
+	prepared, err := e.prepare(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var termDict string
+	var term *domain.Terminology
+	var terminologyTranslation *domain.TerminologyTranslation
+	if !input.Options.NoTerminology && input.Terminology != nil {
+		term = input.Terminology
+		terminologyTranslation = input.TerminologyTranslation
+		termDict = e.termManager.BuildPromptDictionary(term, terminologyTranslation)
+	}
+
+	batches := e.createBatches(prepared.items, input.Options.BatchSize)
+	result := make([]BatchPrompts, 0, len(batches))
+	for _, batch := range batches {
+		texts := make(map[string]string, len(batch))
+		for _, item := range batch {
+			texts[item.Key] = item.Text
+		}
+		reflectionInput := ReflectionInput{
+			SourceTexts:            texts,
+			TranslatedTexts:        texts,
+			SourceLang:             input.SourceLang,
+			TargetLang:             input.TargetLang,
+			Terminology:            term,
+			TerminologyTranslation: terminologyTranslation,
+			StyleGuide:             input.StyleGuide,
+		}
+
+		prompts := BatchPrompts{Items: batch}
+		dict := batchTermDict(termDict, term, terminologyTranslation, batch)
+		if prompts.Translate, err = e.batchProcessor.buildBatchPrompt(batch, input.SourceLang, input.TargetLang, dict, input.StyleGuide); err != nil {
+			return nil, err
+		}
+		if prompts.Reflect, err = e.reflectionEngine.buildReflectionPrompt(reflectionInput); err != nil {
+			return nil, err
+		}
+		if prompts.Improve, err = e.reflectionEngine.buildImprovementPrompt(reflectionInput, texts); err != nil {
+			return nil, err
+		}
+
+		// Every limited text stands in for a translation that is too long
+		shortenInput := ShortenInput{
+			SourceTexts: texts,
+			SourceLang:  input.SourceLang,
+			TargetLang:  input.TargetLang,
+			StyleGuide:  input.StyleGuide,
+		}
+		for _, item := range batch {
+			if !item.MaxLength.IsZero() {
+				shortenInput.Overflows = append(shortenInput.Overflows, domain.LengthViolation{
+					Key:    item.Key,
+					Text:   item.Text,
+					Length: length.Measure(item.Text, item.MaxLength.Unit),
+					Limit:  item.MaxLength,
+				})
+			}
+		}
+		if len(shortenInput.Overflows) > 0 {
+			if prompts.Shorten, err = e.reflectionEngine.buildShortenPrompt(shortenInput); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, prompts)
+	}
 	return result, nil
 }
 
+// preparedInput is the filtered source split into items to translate, reused
+// translations and locked translations
+type preparedInput struct {
+	sourceData  map[string]any
+	items       []domain.BatchItem
+	reused      map[string]string
+	locked      map[string]string
+	filterStats *domain.FilterStats
+}
+
+// prepare applies key filtering and extracts the items that need translating
+func (e *Engine) prepare(input domain.TranslationInput) (*preparedInput, error) {
+	prepared := &preparedInput{
+		sourceData: input.Source,
+		reused:     make(map[string]string),
+		locked:     make(map[string]string),
+	}
+
+	// Apply key filtering if patterns are provided
+	if len(input.Options.Keys) > 0 || len(input.Options.ExcludeKeys) > 0 {
+		includePatterns, err := e.parseKeyPatterns(input.Options.Keys)
+		if err != nil {
+			return nil, domain.NewValidationError("failed to parse include patterns", err).
+				WithContext("patterns", input.Options.Keys)
+		}
+
+		excludePatterns, err := e.parseKeyPatterns(input.Options.ExcludeKeys)
+		if err != nil {
+			return nil, domain.NewValidationError("failed to parse exclude patterns", err).
+				WithContext("patterns", input.Options.ExcludeKeys)
+		}
+
+		filterResult, err := e.keyFilter.FilterKeys(input.Source, includePatterns, excludePatterns)
+		if err != nil {
+			return nil, domain.NewValidationError("failed to filter keys", err)
+		}
+
+		// Rebuild filtered JSON structure
+		prepared.sourceData = e.keyFilter.RebuildJSON(filterResult.Included)
+
+		prepared.filterStats = &domain.FilterStats{
+			TotalKeys:    filterResult.Stats.TotalKeys,
+			IncludedKeys: filterResult.Stats.IncludedKeys,
+			ExcludedKeys: filterResult.Stats.ExcludedKeys,
+		}
+	}
+
+	// Extract translatable items from source JSON
+	items, err := e.extractTranslatableItems(prepared.sourceData, "")
+	if err != nil {
+		return nil, domain.NewFormatError("failed to extract translatable items", err)
+	}
+
+	lengthRules, err := e.parseLengthRules(input.LengthRules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep locked translations, and reuse prefilled translations whose source text is unchanged
+	var lockedItems []domain.BatchItem
+	for _, item := range items {
+		if text, ok := input.Locked[item.Key]; ok {
+			prepared.locked[item.Key] = text
+			lockedItems = append(lockedItems, item)
+			continue
+		}
+		if prefilled, ok := input.Prefilled[item.Key]; ok && prefilled.Text != "" &&
+			(prefilled.SourceText == "" || prefilled.SourceText == item.Text) {
+			prepared.reused[item.Key] = prefilled.Text
+			continue
+		}
+		item.References = input.References[item.Key]
+		item.MaxLength = e.lengthLimit(item.Key, input.LengthLimits, lengthRules)
+		item.Note = input.Notes[item.Key]
+		prepared.items = append(prepared.items, item)
+	}
+	addLockedReferences(prepared.items, lockedItems, prepared.locked)
+
+	return prepared, nil
+}
+
+// addLockedReferences offers the locked translations of similar source texts to the
+// items as references, so that their translations stay consistent with the locked ones
+func addLockedReferences(items, lockedItems []domain.BatchItem, locked map[string]string) {
+	if len(lockedItems) == 0 {
+		return
+	}
+	for i := range items {
+		var refs []domain.TranslationReference
+		for _, l := range lockedItems {
+			if locked[l.Key] == "" {
+				continue
+			}
+			if sim := tm.Similarity(items[i].Text, l.Text, lockedReferenceThreshold); sim >= lockedReferenceThreshold {
+				refs = append(refs, domain.TranslationReference{Source: l.Text, Target: locked[l.Key], Similarity: sim})
+			}
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		slices.SortStableFunc(refs, func(a, b domain.TranslationReference) int {
+			return cmp.Compare(b.Similarity, a.Similarity)
+		})
+		refs = refs[:min(len(refs), maxLockedReferences)]
+		// References may be shared with the input, append to a copy
+		items[i].References = append(slices.Clip(items[i].References), refs...)
+	}
+}
+
+// estimateTokens approximates the token count of a text (about 4 characters per token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
 // extractTranslatableItems recursively extracts all translatable text from JSON
 func (e *Engine) extractTranslatableItems(data any, prefix string) ([]domain.BatchItem, error) {
 	var items []domain.BatchItem
 
 	switch v := data.(type) {
 	case map[string]any:
-		for key, value := range v {
+		// Sorted keys keep batches and prompts stable across runs
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			value := v[key]
 			keyPath := key
 			if prefix != "" {
 				keyPath = prefix + "." + key
@@ -1,9 +1,16 @@
 package translator
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 	"github.com/hikanner/jta/internal/terminology"
 )
@@ -519,3 +526,380 @@ func TestEngine_InferContext(t *testing.T) {
 		})
 	}
 }
+
+func TestEngine_Estimate(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+	engine.SetPricing(provider.ModelPricing{InputPerMillion: 1, OutputPerMillion: 4})
+
+	input := domain.TranslationInput{
+		Source: map[string]any{
+			"a": "First text",
+			"b": "Second text",
+			"c": map[string]any{"d": "Third text"},
+		},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Prefilled: map[string]domain.PrefilledTranslation{
+			"a": {SourceText: "First text", Text: "第一"},
+			"b": {SourceText: "Old text", Text: "旧"}, // stale, translated again
+		},
+		Options: domain.TranslationOptions{BatchSize: 1},
+	}
+
+	estimate, err := engine.Estimate(input)
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+
+	if estimate.Items != 2 || estimate.Batches != 2 {
+		t.Errorf("Items/Batches = %d/%d, want 2/2", estimate.Items, estimate.Batches)
+	}
+	if estimate.APICalls != 6 {
+		t.Errorf("APICalls = %d, want 6 (3 per batch)", estimate.APICalls)
+	}
+	if estimate.PromptTokens == 0 || estimate.CompletionTokens == 0 {
+		t.Error("Estimate() should count prompt and completion tokens")
+	}
+	want := float64(estimate.PromptTokens)/1e6 + float64(estimate.CompletionTokens)*4/1e6
+	if estimate.EstimatedCost != want {
+		t.Errorf("EstimatedCost = %v, want %v", estimate.EstimatedCost, want)
+	}
+	if mockProvider.GetCallCount() != 0 {
+		t.Error("Estimate() must not call the provider")
+	}
+}
+
+func TestEngine_Prompts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(prompt.Dir(dir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := "Into {{.TargetLang}}:{{range .Items}} [{{.Number}}] {{.Text}}{{end}}"
+	if err := os.WriteFile(prompt.Path(dir, prompt.Translate), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompts, err := prompt.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockProvider := provider.NewMockProvider("gpt-4")
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+	engine.SetPrompts(prompts)
+
+	batches, err := engine.Prompts(domain.TranslationInput{
+		Source:     map[string]any{"a": "First", "b": "Second", "c": "Third"},
+		SourceLang: "en",
+		TargetLang: "fr",
+		Options:    domain.TranslationOptions{BatchSize: 2},
+	})
+	if err != nil {
+		t.Fatalf("Prompts() error = %v", err)
+	}
+
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+	if batches[0].Translate != "Into fr: [1] First [2] Second" || batches[1].Translate != "Into fr: [1] Third" {
+		t.Errorf("translate prompts = %q, %q", batches[0].Translate, batches[1].Translate)
+	}
+	// Templates without an override keep the defaults
+	if !strings.Contains(batches[0].Reflect, "<TRANSLATIONS>\n[a] First\n[b] Second\n</TRANSLATIONS>") {
+		t.Errorf("reflect prompt:\n%s", batches[0].Reflect)
+	}
+	if !strings.Contains(batches[1].Improve, "<EXPERT_SUGGESTIONS>\n[c] Third\n</EXPERT_SUGGESTIONS>") {
+		t.Errorf("improve prompt:\n%s", batches[1].Improve)
+	}
+	if mockProvider.GetCallCount() != 0 {
+		t.Error("Prompts() must not call the provider")
+	}
+}
+
+func TestEngine_Prompts_ScopedTerms(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+
+	batches, err := engine.Prompts(domain.TranslationInput{
+		Source:     map[string]any{"billing": map[string]any{"plan": "Your Plan"}, "settings": map[string]any{"title": "Account"}},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Terminology: &domain.Terminology{
+			SourceLanguage:  "en",
+			ConsistentTerms: []string{"Plan", "account"},
+			Details:         map[string]domain.TermInfo{"Plan": {Scope: []string{"billing.**"}}},
+		},
+		TerminologyTranslation: &domain.TerminologyTranslation{
+			SourceLanguage: "en",
+			TargetLanguage: "zh",
+			Translations:   map[string]string{"Plan": "套餐", "account": "帐号"},
+		},
+		Options: domain.TranslationOptions{BatchSize: 1},
+	})
+	if err != nil {
+		t.Fatalf("Prompts() error = %v", err)
+	}
+
+	// The dictionary of each batch is the one ProcessBatches sends
+	for _, batch := range batches {
+		scoped := strings.Contains(batch.Translate, "套餐")
+		if billing := batch.Items[0].Key == "billing.plan"; scoped != billing {
+			t.Errorf("batch %s: scoped term in prompt = %v, want %v:\n%s", batch.Items[0].Key, scoped, billing, batch.Translate)
+		}
+	}
+}
+
+func TestEngine_Translate_Prefilled(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 世界")
+	mockProvider.AddResponse("[1] Translation is good")
+	mockProvider.AddResponse("[1] 世界")
+
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+	engine.SetPricing(provider.ModelPricing{InputPerMillion: 1, OutputPerMillion: 1})
+
+	result, err := engine.Translate(context.Background(), domain.TranslationInput{
+		Source:     map[string]any{"hello": "Hello", "world": "World"},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Prefilled: map[string]domain.PrefilledTranslation{
+			"hello": {SourceText: "Hello", Text: "你好", Origin: "checkpoint"},
+		},
+		Options: domain.TranslationOptions{NoTerminology: true},
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	if result.Target["hello"] != "你好" || result.Target["world"] != "世界" {
+		t.Errorf("Target = %v", result.Target)
+	}
+	if result.Stats.ReusedItems != 1 || result.Stats.SuccessItems != 2 || result.Stats.TotalItems != 2 {
+		t.Errorf("Stats = %+v", result.Stats)
+	}
+	if result.Stats.EstimatedCost <= 0 {
+		t.Error("EstimatedCost should be set from pricing")
+	}
+	if mockProvider.GetCallCount() != 3 {
+		t.Errorf("API calls = %d, want 3 (only 'world' translated)", mockProvider.GetCallCount())
+	}
+}
+
+func TestEngine_Translate_Locked(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 保存设置")
+	mockProvider.AddResponse("[1] Translation is good")
+	mockProvider.AddResponse("[1] 保存设置")
+
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+	input := domain.TranslationInput{
+		Source:     map[string]any{"save": "Save changes", "saveAll": "Save all changes"},
+		SourceLang: "en",
+		TargetLang: "zh",
+		// A locked key is kept even when a prefilled translation exists
+		Prefilled: map[string]domain.PrefilledTranslation{
+			"save": {Text: "保存", Origin: "checkpoint"},
+		},
+		Locked:  map[string]string{"save": "保存更改"},
+		Options: domain.TranslationOptions{NoTerminology: true},
+	}
+
+	batches, err := engine.Prompts(input)
+	if err != nil {
+		t.Fatalf("Prompts() error = %v", err)
+	}
+	if len(batches) != 1 || strings.Contains(batches[0].Translate, "[1] Save changes") {
+		t.Fatalf("locked key must not be sent: %+v", batches)
+	}
+	if !strings.Contains(batches[0].Translate, "保存更改") {
+		t.Errorf("locked translation missing from the references:\n%s", batches[0].Translate)
+	}
+
+	result, err := engine.Translate(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if result.Target["save"] != "保存更改" || result.Target["saveAll"] != "保存设置" {
+		t.Errorf("Target = %v", result.Target)
+	}
+	if result.Stats.LockedItems != 1 || result.Stats.ReusedItems != 0 || result.Stats.TotalItems != 2 || result.Stats.SuccessItems != 2 {
+		t.Errorf("Stats = %+v", result.Stats)
+	}
+	if result.Keys["save"].Status != domain.KeyStatusLocked {
+		t.Errorf("Keys[save] = %+v", result.Keys["save"])
+	}
+	if mockProvider.GetCallCount() != 3 {
+		t.Errorf("API calls = %d, want 3 (only 'saveAll' translated)", mockProvider.GetCallCount())
+	}
+}
+
+func TestEngine_Translate_TermViolations(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 您的账户\n[2] 打开接口文档")
+	mockProvider.AddResponse("[account] OK\n[docs] OK")
+	// Improvement fixes the pinned term but still translates the preserve term
+	mockProvider.AddResponse("[account] 您的帐号\n[docs] 打开接口文档")
+
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+
+	result, err := engine.Translate(context.Background(), domain.TranslationInput{
+		Source:     map[string]any{"account": "Your account", "docs": "Open the API docs"},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Terminology: &domain.Terminology{
+			SourceLanguage:  "en",
+			PreserveTerms:   []string{"API"},
+			ConsistentTerms: []string{"account"},
+		},
+		TerminologyTranslation: &domain.TerminologyTranslation{
+			SourceLanguage: "en",
+			TargetLanguage: "zh",
+			Translations:   map[string]string{"account": "帐号"},
+		},
+		Options: domain.TranslationOptions{BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	if result.Target["account"] != "您的帐号" {
+		t.Errorf("account = %v, want the improved translation", result.Target["account"])
+	}
+	if len(result.TermViolations) != 1 || result.TermViolations[0].Key != "docs" || result.TermViolations[0].Term != "API" {
+		t.Errorf("TermViolations = %+v", result.TermViolations)
+	}
+}
+
+func TestEngine_Translate_LengthLimits(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] Einstellungen speichern\n[2] Abbrechen\n[3] Konto löschen")
+	mockProvider.AddResponse("[cancel] OK\n[delete] OK\n[save] OK")
+	mockProvider.AddResponse("[cancel] Abbrechen\n[delete] Konto endgültig löschen\n[save] Einstellungen speichern")
+	// Shortening fits the save button; the delete button stays too long
+	mockProvider.AddResponse("[save] Speichern\n[delete] Konto dauerhaft löschen")
+
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+
+	result, err := engine.Translate(context.Background(), domain.TranslationInput{
+		Source: map[string]any{
+			"save":   "Save settings",
+			"cancel": "Cancel",
+			"delete": "Delete account",
+		},
+		SourceLang:   "en",
+		TargetLang:   "de",
+		LengthLimits: map[string]domain.LengthLimit{"save": {Max: 12, Unit: domain.LengthUnitChars}},
+		LengthRules:  []domain.LengthRule{{Keys: "*", LengthLimit: domain.LengthLimit{Max: 15, Unit: domain.LengthUnitChars}}},
+		Options:      domain.TranslationOptions{BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	if result.Target["save"] != "Speichern" {
+		t.Errorf("save = %v, want the shortened translation", result.Target["save"])
+	}
+	if result.Target["delete"] != "Konto endgültig löschen" {
+		t.Errorf("delete = %v, a longer shortening should be rejected", result.Target["delete"])
+	}
+	if len(result.LengthViolations) != 1 || result.LengthViolations[0].Key != "delete" || result.LengthViolations[0].Length != 23 {
+		t.Errorf("LengthViolations = %+v", result.LengthViolations)
+	}
+	if mockProvider.GetCallCount() != 4 {
+		t.Errorf("API calls = %d, want 4", mockProvider.GetCallCount())
+	}
+	// Only the key whose shortening was kept is reported as shortened
+	if !result.Keys["save"].Shortened || result.Keys["delete"].Shortened {
+		t.Errorf("Shortened: save = %v, delete = %v", result.Keys["save"].Shortened, result.Keys["delete"].Shortened)
+	}
+}
+
+func TestEngine_Translate_KeyDetails(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 您的账户\n[2] 打开文档")
+	mockProvider.AddResponse("[account] OK\n[docs] OK")
+	mockProvider.AddResponse("[account] 您的帐号\n[docs] 打开文档")
+
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+
+	result, err := engine.Translate(context.Background(), domain.TranslationInput{
+		Source:     map[string]any{"account": "Your account", "docs": "Open the docs", "hello": "Hello"},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Terminology: &domain.Terminology{
+			SourceLanguage:  "en",
+			ConsistentTerms: []string{"account"},
+		},
+		TerminologyTranslation: &domain.TerminologyTranslation{
+			SourceLanguage: "en",
+			TargetLanguage: "zh",
+			Translations:   map[string]string{"account": "帐号"},
+		},
+		Prefilled: map[string]domain.PrefilledTranslation{
+			"hello": {SourceText: "Hello", Text: "你好", Origin: "tm"},
+		},
+		Options: domain.TranslationOptions{BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	account := result.Keys["account"]
+	if account.Status != domain.KeyStatusTranslated || account.Batch != 1 || account.Attempts != 1 {
+		t.Errorf("account detail = %+v", account)
+	}
+	// The draft is kept next to the improved final text, with the reason it changed
+	if account.Draft != "您的账户" || result.Translations["account"] != "您的帐号" {
+		t.Errorf("account draft = %q, final = %q", account.Draft, result.Translations["account"])
+	}
+	if account.Suggestion == "" {
+		t.Error("account should carry the terminology suggestion")
+	}
+	if account.BatchTokens == 0 || account.BatchTokens != result.Keys["docs"].BatchTokens {
+		t.Errorf("batch tokens = %d and %d, want the same non-zero count", account.BatchTokens, result.Keys["docs"].BatchTokens)
+	}
+	if hello := result.Keys["hello"]; hello.Status != domain.KeyStatusReused || hello.Origin != "tm" {
+		t.Errorf("hello detail = %+v", hello)
+	}
+}
+
+func TestEngine_Translate_Logger(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	mockProvider.AddResponse("[1] 您的账户")
+	mockProvider.AddResponse("[account] Use the glossary term")
+	mockProvider.AddResponse("[account] 您的帐号")
+
+	var buf bytes.Buffer
+	engine := NewEngine(mockProvider, terminology.NewManager(mockProvider))
+	engine.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})).With("lang", "zh"))
+
+	_, err := engine.Translate(context.Background(), domain.TranslationInput{
+		Source:     map[string]any{"account": "Your account"},
+		SourceLang: "en",
+		TargetLang: "zh",
+		Options:    domain.TranslationOptions{BatchSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+
+	var messages []string
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var record struct {
+			Msg   string `json:"msg"`
+			Batch int    `json:"batch"`
+			Lang  string `json:"lang"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log record %q: %v", line, err)
+		}
+		if record.Batch != 1 || record.Lang != "zh" {
+			t.Errorf("record %q should have the batch and the logger's attributes", line)
+		}
+		messages = append(messages, record.Msg)
+	}
+	want := []string{"Reflecting", "Reflected", "Improving", "Improved", "Batch complete"}
+	if strings.Join(messages, ",") != strings.Join(want, ",") {
+		t.Errorf("messages = %v, want %v", messages, want)
+	}
+}
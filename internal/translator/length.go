@@ -0,0 +1,140 @@
+package translator
+
+import (
+	"context"
+	"time"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/keyfilter"
+	"github.com/hikanner/jta/internal/length"
+	"github.com/hikanner/jta/internal/prompt"
+	"github.com/hikanner/jta/internal/provider"
+)
+
+// lengthRule is a length rule with its key patterns parsed
+type lengthRule struct {
+	patterns []*keyfilter.KeyPattern
+	limit    domain.LengthLimit
+}
+
+// parseLengthRules parses the key patterns of length rules
+func (e *Engine) parseLengthRules(rules []domain.LengthRule) ([]lengthRule, error) {
+	parsed := make([]lengthRule, 0, len(rules))
+	for _, rule := range rules {
+		patterns, err := e.keyFilter.ParsePatterns(rule.Keys)
+		if err != nil {
+			return nil, domain.NewValidationError("invalid length rule pattern", err).
+				WithContext("keys", rule.Keys)
+		}
+		parsed = append(parsed, lengthRule{patterns: patterns, limit: rule.LengthLimit})
+	}
+	return parsed, nil
+}
+
+// lengthLimit returns the limit of a key: its own limit, else the first matching rule
+func (e *Engine) lengthLimit(key string, limits map[string]domain.LengthLimit, rules []lengthRule) domain.LengthLimit {
+	if limit, ok := limits[key]; ok {
+		return limit
+	}
+	for _, rule := range rules {
+		for _, pattern := range rule.patterns {
+			if e.keyFilter.MatchKey(key, pattern) {
+				return rule.limit
+			}
+		}
+	}
+	return domain.LengthLimit{}
+}
+
+// checkLengths returns the translations that are longer than their item's limit, in item order
+func checkLengths(items []domain.BatchItem, translations map[string]string) []domain.LengthViolation {
+	var violations []domain.LengthViolation
+	for _, item := range items {
+		text, ok := translations[item.Key]
+		if !ok {
+			continue
+		}
+		if exceeds, n := length.Exceeds(text, item.MaxLength); exceeds {
+			violations = append(violations, domain.LengthViolation{
+				Key:    item.Key,
+				Text:   text,
+				Length: n,
+				Limit:  item.MaxLength,
+			})
+		}
+	}
+	return violations
+}
+
+// ShortenInput contains the translations that are too long for their layout
+type ShortenInput struct {
+	SourceTexts map[string]string // key -> source text
+	Overflows   []domain.LengthViolation
+	SourceLang  string
+	TargetLang  string
+	StyleGuide  *domain.StyleGuide
+}
+
+// Shorten asks the model to shorten translations to their length limit. Only shortened
+// translations that are shorter than before and keep all placeholders are returned.
+func (r *ReflectionEngine) Shorten(ctx context.Context, input ShortenInput) (map[string]string, provider.Usage, error) {
+	prompt, err := r.buildShortenPrompt(input)
+	if err != nil {
+		return nil, provider.Usage{}, err
+	}
+
+	req := &provider.CompletionRequest{
+		Prompt:    prompt,
+		Model:     r.provider.GetModelName(),
+		SystemMsg: "You are an expert UI translator who fits translations into limited space.",
+	}
+
+	// Create independent 5-minute timeout for this LLM call
+	callCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	resp, err := r.provider.Complete(callCtx, req)
+	if err != nil {
+		return nil, provider.Usage{}, domain.NewTranslationError("shortening API call failed", err).
+			WithContext("source_lang", input.SourceLang).
+			WithContext("target_lang", input.TargetLang)
+	}
+
+	current := make(map[string]string, len(input.Overflows))
+	for _, overflow := range input.Overflows {
+		current[overflow.Key] = overflow.Text
+	}
+
+	parsed := r.parseImprovedTranslations(resp.Content, current)
+	shortened := make(map[string]string)
+	for _, overflow := range input.Overflows {
+		text, ok := parsed[overflow.Key]
+		if !ok || length.Measure(text, overflow.Limit.Unit) >= overflow.Length {
+			continue
+		}
+		if r.formatProtector.Validate(input.SourceTexts[overflow.Key], text) != nil {
+			continue
+		}
+		shortened[overflow.Key] = text
+	}
+	return shortened, resp.Usage, nil
+}
+
+// buildShortenPrompt builds the prompt that shortens translations to their length limit
+func (r *ReflectionEngine) buildShortenPrompt(input ShortenInput) (string, error) {
+	data := prompt.ShortenData{
+		SourceLang: input.SourceLang,
+		TargetLang: input.TargetLang,
+		StyleGuide: buildStyleSection(input.StyleGuide),
+	}
+	for _, overflow := range input.Overflows {
+		data.Items = append(data.Items, prompt.ShortenItem{
+			Key:         overflow.Key,
+			Source:      input.SourceTexts[overflow.Key],
+			Translation: overflow.Text,
+			Length:      overflow.Length,
+			Limit:       overflow.Limit.String(),
+		})
+	}
+	return r.prompts.Render(prompt.Shorten, data)
+}
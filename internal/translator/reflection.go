@@ -2,12 +2,15 @@ package translator
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/hikanner/jta/internal/domain"
 	"github.com/hikanner/jta/internal/format"
+	"github.com/hikanner/jta/internal/prompt"
 	"github.com/hikanner/jta/internal/provider"
 )
 
@@ -29,6 +32,8 @@ type ReflectionProgressEvent struct {
 type ReflectionEngine struct {
 	provider        provider.AIProvider
 	formatProtector *format.Protector
+	prompts         *prompt.Templates
+	logger          *slog.Logger
 }
 
 // NewReflectionEngine creates a new reflection engine
@@ -36,9 +41,21 @@ func NewReflectionEngine(prov provider.AIProvider) *ReflectionEngine {
 	return &ReflectionEngine{
 		provider:        prov,
 		formatProtector: format.NewProtector(),
+		prompts:         prompt.Default(),
+		logger:          slog.New(slog.DiscardHandler),
 	}
 }
 
+// SetPrompts sets the prompt templates
+func (r *ReflectionEngine) SetPrompts(prompts *prompt.Templates) {
+	r.prompts = prompts
+}
+
+// SetLogger sets the logger of warnings about improved translations
+func (r *ReflectionEngine) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
 // ReflectionInput contains input for reflection
 type ReflectionInput struct {
 	SourceTexts            map[string]string // key -> source text
@@ -47,6 +64,8 @@ type ReflectionInput struct {
 	TargetLang             string
 	Terminology            *domain.Terminology
 	TerminologyTranslation *domain.TerminologyTranslation
+	TermViolations         map[string][]string // key -> terminology violations found by the checker
+	StyleGuide             *domain.StyleGuide  // style guide of the target language, if any
 }
 
 // ReflectionResult contains reflection results
@@ -54,13 +73,15 @@ type ReflectionResult struct {
 	Suggestions      map[string]string // key -> expert suggestions from LLM
 	ImprovedTexts    map[string]string // key -> improved translations
 	ReflectionNeeded bool
-	APICallsUsed     int           // Should be 2 (reflect + improve)
-	ReflectDuration  time.Duration // Time spent on reflection step
-	ImproveDuration  time.Duration // Time spent on improvement step
+	APICallsUsed     int            // Should be 2 (reflect + improve)
+	Usage            provider.Usage // Tokens used by reflect + improve
+	ReflectDuration  time.Duration  // Time spent on reflection step
+	ImproveDuration  time.Duration  // Time spent on improvement step
 }
 
 // Reflect performs Agentic reflection on translations
-// This is the main entry point following Andrew Ng's two-step approach
+// This is the main entry point following Andrew Ng's two-step approach.
+// When a step fails, the result so far (usage and API calls) is returned with the error.
 func (r *ReflectionEngine) Reflect(ctx context.Context, input ReflectionInput, progressCallback ReflectionProgressCallback) (*ReflectionResult, error) {
 	result := &ReflectionResult{
 		Suggestions:   make(map[string]string),
@@ -86,17 +107,20 @@ func (r *ReflectionEngine) Reflect(ctx context.Context, input ReflectionInput, p
 	}
 
 	reflectStart := time.Now()
-	suggestions, err := r.reflectStep(ctx, input)
+	suggestions, reflectUsage, err := r.reflectStep(ctx, input)
 	result.ReflectDuration = time.Since(reflectStart)
 
 	if err != nil {
-		return nil, domain.NewTranslationError("reflection step failed", err).
+		return result, domain.NewTranslationError("reflection step failed", err).
 			WithContext("source_lang", input.SourceLang).
 			WithContext("target_lang", input.TargetLang).
 			WithContext("translation_count", len(input.TranslatedTexts))
 	}
+	// Terminology violations must be fixed whatever the reviewer thought
+	addViolationSuggestions(suggestions, input.TermViolations)
 	result.Suggestions = suggestions
 	result.APICallsUsed++ // +1 API call for reflection
+	result.Usage = addUsage(result.Usage, reflectUsage)
 
 	// Notify reflection complete
 	if progressCallback != nil {
@@ -117,17 +141,19 @@ func (r *ReflectionEngine) Reflect(ctx context.Context, input ReflectionInput, p
 	}
 
 	improveStart := time.Now()
-	improved, err := r.improveStep(ctx, input, suggestions)
+	improved, improveUsage, err := r.improveStep(ctx, input, suggestions)
 	result.ImproveDuration = time.Since(improveStart)
 
 	if err != nil {
-		return nil, domain.NewTranslationError("improvement step failed", err).
+		// The result still carries the reflection's usage, to be charged by the caller
+		return result, domain.NewTranslationError("improvement step failed", err).
 			WithContext("source_lang", input.SourceLang).
 			WithContext("target_lang", input.TargetLang).
 			WithContext("suggestion_count", len(suggestions))
 	}
 	result.ImprovedTexts = improved
 	result.APICallsUsed++ // +1 API call for improvement
+	result.Usage = addUsage(result.Usage, improveUsage)
 
 	// Notify improvement complete
 	if progressCallback != nil {
@@ -144,7 +170,7 @@ func (r *ReflectionEngine) Reflect(ctx context.Context, input ReflectionInput, p
 		if sourceText != "" {
 			if err := r.formatProtector.Validate(sourceText, improvedText); err != nil {
 				// Log warning but don't fail - format might be intentionally adjusted
-				fmt.Printf("⚠️  Format validation warning for key '%s': %v\n", key, err)
+				r.logger.Warn("Improved translation changed format elements", "key", key, "error", err)
 			}
 		}
 	}
@@ -154,9 +180,12 @@ func (r *ReflectionEngine) Reflect(ctx context.Context, input ReflectionInput, p
 
 // reflectStep performs the reflection step
 // LLM evaluates translations across 4 dimensions: accuracy, fluency, style, terminology
-func (r *ReflectionEngine) reflectStep(ctx context.Context, input ReflectionInput) (map[string]string, error) {
+func (r *ReflectionEngine) reflectStep(ctx context.Context, input ReflectionInput) (map[string]string, provider.Usage, error) {
 	// Build reflection prompt following Andrew Ng's approach
-	prompt := r.buildReflectionPrompt(input)
+	prompt, err := r.buildReflectionPrompt(input)
+	if err != nil {
+		return nil, provider.Usage{}, err
+	}
 
 	// Call AI provider
 	req := &provider.CompletionRequest{
@@ -171,7 +200,7 @@ func (r *ReflectionEngine) reflectStep(ctx context.Context, input ReflectionInpu
 
 	resp, err := r.provider.Complete(callCtx, req)
 	if err != nil {
-		return nil, domain.NewTranslationError("reflection API call failed", err).
+		return nil, provider.Usage{}, domain.NewTranslationError("reflection API call failed", err).
 			WithContext("source_lang", input.SourceLang).
 			WithContext("target_lang", input.TargetLang)
 	}
@@ -179,7 +208,7 @@ func (r *ReflectionEngine) reflectStep(ctx context.Context, input ReflectionInpu
 	// Parse suggestions from LLM response
 	suggestions := r.parseReflectionSuggestions(resp.Content, input.TranslatedTexts)
 
-	return suggestions, nil
+	return suggestions, resp.Usage, nil
 }
 
 // improveStep performs the improvement step
@@ -188,9 +217,12 @@ func (r *ReflectionEngine) improveStep(
 	ctx context.Context,
 	input ReflectionInput,
 	suggestions map[string]string,
-) (map[string]string, error) {
+) (map[string]string, provider.Usage, error) {
 	// Build improvement prompt following Andrew Ng's approach
-	prompt := r.buildImprovementPrompt(input, suggestions)
+	prompt, err := r.buildImprovementPrompt(input, suggestions)
+	if err != nil {
+		return nil, provider.Usage{}, err
+	}
 
 	// Call AI provider
 	req := &provider.CompletionRequest{
@@ -205,7 +237,7 @@ func (r *ReflectionEngine) improveStep(
 
 	resp, err := r.provider.Complete(callCtx, req)
 	if err != nil {
-		return nil, domain.NewTranslationError("improvement API call failed", err).
+		return nil, provider.Usage{}, domain.NewTranslationError("improvement API call failed", err).
 			WithContext("source_lang", input.SourceLang).
 			WithContext("target_lang", input.TargetLang)
 	}
@@ -213,129 +245,61 @@ func (r *ReflectionEngine) improveStep(
 	// Parse improved translations from LLM response
 	improved := r.parseImprovedTranslations(resp.Content, input.TranslatedTexts)
 
-	return improved, nil
+	return improved, resp.Usage, nil
 }
 
 // buildReflectionPrompt builds the reflection prompt following Andrew Ng's approach
-// Evaluates: (i) accuracy, (ii) fluency, (iii) style, (iv) terminology
-func (r *ReflectionEngine) buildReflectionPrompt(input ReflectionInput) string {
-	var sb strings.Builder
-
-	// Task description
-	sb.WriteString(fmt.Sprintf(
-		"Your task is to carefully read source texts and translations from %s to %s, "+
-			"and then give constructive criticism and helpful suggestions to improve the translations.\n\n",
-		input.SourceLang, input.TargetLang,
-	))
-
-	// Add terminology requirements if provided
-	if input.Terminology != nil && len(input.Terminology.PreserveTerms) > 0 {
-		sb.WriteString("【Terminology Requirements】\n")
-		sb.WriteString("The following terms must be preserved (kept in original form):\n")
-		sb.WriteString(strings.Join(input.Terminology.PreserveTerms, ", "))
-		sb.WriteString("\n\n")
+// Uses 4-dimension evaluation: accuracy, fluency, style, terminology
+func (r *ReflectionEngine) buildReflectionPrompt(input ReflectionInput) (string, error) {
+	data := prompt.ReflectData{
+		SourceLang:   input.SourceLang,
+		TargetLang:   input.TargetLang,
+		StyleGuide:   buildStyleSection(input.StyleGuide),
+		Sources:      sortedTexts(input.SourceTexts),
+		Translations: sortedTexts(input.TranslatedTexts),
 	}
 
-	if input.Terminology != nil && input.TerminologyTranslation != nil && len(input.Terminology.ConsistentTerms) > 0 {
-		sb.WriteString("Consistent terminology translations:\n")
-		// Build terminology dictionary from translations
-		for _, term := range input.Terminology.ConsistentTerms {
-			if translation, ok := input.TerminologyTranslation.Translations[term]; ok {
-				sb.WriteString(fmt.Sprintf("- %s: %s\n", term, translation))
+	if input.Terminology != nil {
+		data.PreserveTerms = input.Terminology.PreserveTerms
+		if input.TerminologyTranslation != nil {
+			for _, term := range input.Terminology.ConsistentTerms {
+				if translation, ok := input.TerminologyTranslation.Translations[term]; ok {
+					data.ConsistentTerms = append(data.ConsistentTerms, prompt.TermTranslation{
+						Term:        term,
+						Translation: translation,
+						Forbidden:   input.TerminologyTranslation.GetForbidden(term),
+					})
+				}
 			}
 		}
-		sb.WriteString("\n")
-	}
-
-	// Source texts section using XML-style tags
-	sb.WriteString("<SOURCE_TEXTS>\n")
-	for key, sourceText := range input.SourceTexts {
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", key, sourceText))
 	}
-	sb.WriteString("</SOURCE_TEXTS>\n\n")
 
-	// Translations section
-	sb.WriteString("<TRANSLATIONS>\n")
-	for key, translatedText := range input.TranslatedTexts {
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", key, translatedText))
+	for _, key := range slices.Sorted(maps.Keys(input.TermViolations)) {
+		data.Violations = append(data.Violations, prompt.Violation{Key: key, Messages: input.TermViolations[key]})
 	}
-	sb.WriteString("</TRANSLATIONS>\n\n")
-
-	// Evaluation criteria (Andrew Ng's 4 dimensions)
-	sb.WriteString("When writing suggestions, pay attention to whether there are ways to improve the translation's:\n")
-	sb.WriteString(fmt.Sprintf(
-		"(i) accuracy (by correcting errors of addition, mistranslation, omission, or untranslated text),\n"+
-			"(ii) fluency (by applying %s grammar, spelling and punctuation rules, and ensuring there are no unnecessary repetitions),\n"+
-			"(iii) style (by ensuring the translations reflect the style of the source text and take into account any cultural context),\n"+
-			"(iv) terminology (by ensuring terminology use is consistent and reflects the source text domain; and by only ensuring you use equivalent idioms in %s).\n\n",
-		input.TargetLang, input.TargetLang,
-	))
-
-	// Output instructions
-	sb.WriteString("Write a list of specific, helpful and constructive suggestions for improving the translation.\n")
-	sb.WriteString("Each suggestion should address one specific part of the translation.\n\n")
 
-	sb.WriteString("Output format:\n")
-	sb.WriteString("[key] Suggestion text (or \"OK\" if no improvement needed)\n\n")
-
-	sb.WriteString("Output only the suggestions and nothing else.")
-
-	return sb.String()
+	return r.prompts.Render(prompt.Reflect, data)
 }
 
 // buildImprovementPrompt builds the improvement prompt following Andrew Ng's approach
-func (r *ReflectionEngine) buildImprovementPrompt(input ReflectionInput, suggestions map[string]string) string {
-	var sb strings.Builder
-
-	// Task description
-	sb.WriteString(fmt.Sprintf(
-		"Your task is to carefully read, then edit, translations from %s to %s using expert suggestions and improve them.\n\n",
-		input.SourceLang, input.TargetLang,
-	))
-
-	// Source texts section
-	sb.WriteString("<SOURCE_TEXTS>\n")
-	for key, sourceText := range input.SourceTexts {
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", key, sourceText))
-	}
-	sb.WriteString("</SOURCE_TEXTS>\n\n")
-
-	// Initial translations section
-	sb.WriteString("<INITIAL_TRANSLATIONS>\n")
-	for key, translatedText := range input.TranslatedTexts {
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", key, translatedText))
-	}
-	sb.WriteString("</INITIAL_TRANSLATIONS>\n\n")
+func (r *ReflectionEngine) buildImprovementPrompt(input ReflectionInput, suggestions map[string]string) (string, error) {
+	return r.prompts.Render(prompt.Improve, prompt.ImproveData{
+		SourceLang:   input.SourceLang,
+		TargetLang:   input.TargetLang,
+		StyleGuide:   buildStyleSection(input.StyleGuide),
+		Sources:      sortedTexts(input.SourceTexts),
+		Translations: sortedTexts(input.TranslatedTexts),
+		Suggestions:  sortedTexts(suggestions),
+	})
+}
 
-	// Expert suggestions section
-	sb.WriteString("<EXPERT_SUGGESTIONS>\n")
-	for key, suggestion := range suggestions {
-		sb.WriteString(fmt.Sprintf("[%s] %s\n", key, suggestion))
+// sortedTexts returns keyed texts sorted by key
+func sortedTexts(texts map[string]string) []prompt.Text {
+	sorted := make([]prompt.Text, 0, len(texts))
+	for _, key := range slices.Sorted(maps.Keys(texts)) {
+		sorted = append(sorted, prompt.Text{Key: key, Text: texts[key]})
 	}
-	sb.WriteString("</EXPERT_SUGGESTIONS>\n\n")
-
-	// Instructions
-	sb.WriteString("Please take into account the expert suggestions when editing the translations. ")
-	sb.WriteString("Edit the translations by ensuring:\n\n")
-	sb.WriteString(fmt.Sprintf(
-		"(i) accuracy (by correcting errors of addition, mistranslation, omission, or untranslated text),\n"+
-			"(ii) fluency (by applying %s grammar, spelling and punctuation rules and ensuring there are no unnecessary repetitions),\n"+
-			"(iii) style (by ensuring the translations reflect the style of the source text),\n"+
-			"(iv) terminology (inappropriate for context, inconsistent use), or\n"+
-			"(v) other errors.\n\n",
-		input.TargetLang,
-	))
-
-	// Format preservation reminder
-	sb.WriteString("IMPORTANT: Preserve all format elements (placeholders like {variable}, HTML tags, special markers).\n\n")
-
-	// Output format
-	sb.WriteString("Output format:\n")
-	sb.WriteString("[key] improved translation\n\n")
-
-	sb.WriteString("Output only the improved translations and nothing else.")
-
-	return sb.String()
+	return sorted
 }
 
 // parseReflectionSuggestions parses suggestions from the reflection response
@@ -414,6 +378,26 @@ func (r *ReflectionEngine) parseImprovedTranslations(response string, originalTr
 	return improved
 }
 
+// addViolationSuggestions makes terminology violations part of the suggestions
+func addViolationSuggestions(suggestions map[string]string, violations map[string][]string) {
+	for key, messages := range violations {
+		fix := "Terminology: " + strings.Join(messages, "; ") + "."
+		if existing := suggestions[key]; existing != "" && !strings.EqualFold(existing, "OK") {
+			fix += " " + existing
+		}
+		suggestions[key] = fix
+	}
+}
+
+// addUsage sums two token usages
+func addUsage(a, b provider.Usage) provider.Usage {
+	return provider.Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
+
 // ShouldReflect determines if reflection is needed for a batch
 // In Agentic mode, we always reflect to allow LLM to discover quality issues
 func (r *ReflectionEngine) ShouldReflect(translations map[string]string, terminology *domain.Terminology) bool {
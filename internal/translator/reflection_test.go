@@ -236,6 +236,35 @@ func TestReflect_WithProgressCallback(t *testing.T) {
 	}
 }
 
+// TestReflect_ImproveFailure tests that a failed improvement still reports the reflection's usage
+func TestReflect_ImproveFailure(t *testing.T) {
+	mockProvider := provider.NewMockProvider("test-model")
+	mockProvider.AddResponse(`[key1] Too formal`)
+
+	engine := NewReflectionEngine(mockProvider)
+
+	input := ReflectionInput{
+		SourceTexts:     map[string]string{"key1": "Test"},
+		TranslatedTexts: map[string]string{"key1": "测试"},
+		SourceLang:      "en",
+		TargetLang:      "zh",
+	}
+
+	result, err := engine.Reflect(context.Background(), input, nil)
+	if err == nil {
+		t.Fatal("Expected an error from the improvement step")
+	}
+	if result == nil {
+		t.Fatal("Expected a partial result next to the error")
+	}
+	if result.APICallsUsed != 1 || result.Usage.TotalTokens != 150 {
+		t.Errorf("Got %d API calls, %d tokens, want 1 call, 150 tokens", result.APICallsUsed, result.Usage.TotalTokens)
+	}
+	if len(result.ImprovedTexts) != 0 {
+		t.Errorf("Expected no improved texts, got: %v", result.ImprovedTexts)
+	}
+}
+
 // TestReflectStep_Success tests the reflection step in isolation
 func TestReflectStep_Success(t *testing.T) {
 	mockProvider := provider.NewMockProvider("test-model")
@@ -257,7 +286,7 @@ func TestReflectStep_Success(t *testing.T) {
 		TargetLang: "zh",
 	}
 
-	suggestions, err := engine.reflectStep(context.Background(), input)
+	suggestions, _, err := engine.reflectStep(context.Background(), input)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -286,7 +315,7 @@ func TestReflectStep_LLMError(t *testing.T) {
 		TargetLang:      "zh",
 	}
 
-	_, err := engine.reflectStep(context.Background(), input)
+	_, _, err := engine.reflectStep(context.Background(), input)
 	if err == nil {
 		t.Fatal("Expected error from LLM failure")
 	}
@@ -325,7 +354,7 @@ func TestImproveStep_Success(t *testing.T) {
 		"key2": "Use better terminology",
 	}
 
-	improved, err := engine.improveStep(context.Background(), input, suggestions)
+	improved, _, err := engine.improveStep(context.Background(), input, suggestions)
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -354,7 +383,10 @@ func TestBuildReflectionPrompt(t *testing.T) {
 		TargetLang: "zh",
 	}
 
-	prompt := engine.buildReflectionPrompt(input)
+	prompt, err := engine.buildReflectionPrompt(input)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify prompt contains required sections
 	requiredSections := []string{
@@ -409,7 +441,10 @@ func TestBuildReflectionPrompt_WithTerminology(t *testing.T) {
 		TerminologyTranslation: termTranslation,
 	}
 
-	prompt := engine.buildReflectionPrompt(input)
+	prompt, err := engine.buildReflectionPrompt(input)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify terminology appears in prompt
 	if !strings.Contains(prompt, "API") {
@@ -443,7 +478,10 @@ func TestBuildImprovementPrompt(t *testing.T) {
 		"key1": "Make it more formal",
 	}
 
-	prompt := engine.buildImprovementPrompt(input, suggestions)
+	prompt, err := engine.buildImprovementPrompt(input, suggestions)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Verify prompt contains required sections
 	requiredSections := []string{
@@ -671,3 +709,42 @@ func TestReflect_Duration(t *testing.T) {
 		t.Error("Expected non-negative ImproveDuration")
 	}
 }
+
+func TestReflect_TermViolations(t *testing.T) {
+	mockProvider := provider.NewMockProvider("test-model")
+	mockProvider.AddResponse("[key1] OK\n[key2] Sounds unnatural")
+	mockProvider.AddResponse("[key1] 您的帐号\n[key2] 更自然的翻译")
+
+	engine := NewReflectionEngine(mockProvider)
+	input := ReflectionInput{
+		SourceTexts:     map[string]string{"key1": "Your account", "key2": "Another test"},
+		TranslatedTexts: map[string]string{"key1": "您的账户", "key2": "另一个测试"},
+		SourceLang:      "en",
+		TargetLang:      "zh",
+		TermViolations:  map[string][]string{"key1": {`"account" must be translated as "帐号"`}},
+	}
+
+	prompt, err := engine.buildReflectionPrompt(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "<TERMINOLOGY_VIOLATIONS>\n[key1] \"account\" must be translated as \"帐号\"") {
+		t.Errorf("reflection prompt missing violations:\n%s", prompt)
+	}
+
+	result, err := engine.Reflect(context.Background(), input, nil)
+	if err != nil {
+		t.Fatalf("Reflect() error = %v", err)
+	}
+
+	// The checker's finding replaces the reviewer's "OK"
+	if !strings.HasPrefix(result.Suggestions["key1"], "Terminology: ") {
+		t.Errorf("Suggestions[key1] = %q", result.Suggestions["key1"])
+	}
+	if result.Suggestions["key2"] != "Sounds unnatural" {
+		t.Errorf("Suggestions[key2] = %q", result.Suggestions["key2"])
+	}
+	if result.ImprovedTexts["key1"] != "您的帐号" {
+		t.Errorf("ImprovedTexts[key1] = %q", result.ImprovedTexts["key1"])
+	}
+}
@@ -0,0 +1,42 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hikanner/jta/internal/domain"
+)
+
+// buildStyleSection renders a style guide for the translate, reflect and improve prompts,
+// without a trailing newline
+func buildStyleSection(guide *domain.StyleGuide) string {
+	if guide.IsZero() {
+		return ""
+	}
+
+	var builder strings.Builder
+	if guide.Formality != "" {
+		builder.WriteString(fmt.Sprintf("- Formality: %s\n", guide.Formality))
+	}
+	if guide.Tone != "" {
+		builder.WriteString(fmt.Sprintf("- Tone: %s\n", guide.Tone))
+	}
+	if guide.Punctuation != "" {
+		builder.WriteString(fmt.Sprintf("- Punctuation: %s\n", guide.Punctuation))
+	}
+	if len(guide.Forbidden) > 0 {
+		quoted := make([]string, len(guide.Forbidden))
+		for i, phrase := range guide.Forbidden {
+			quoted[i] = fmt.Sprintf("%q", phrase)
+		}
+		builder.WriteString(fmt.Sprintf("- Never use: %s\n", strings.Join(quoted, ", ")))
+	}
+	if len(guide.Examples) > 0 {
+		builder.WriteString("- Examples of the expected style:\n")
+		for _, ex := range guide.Examples {
+			builder.WriteString(fmt.Sprintf("  %q → %q\n", ex.Source, ex.Target))
+		}
+	}
+	builder.WriteString(guide.Notes)
+	return strings.TrimRight(builder.String(), "\n")
+}
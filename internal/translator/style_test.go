@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hikanner/jta/internal/domain"
+	"github.com/hikanner/jta/internal/provider"
+)
+
+func TestBuildStyleSection(t *testing.T) {
+	if buildStyleSection(nil) != "" || buildStyleSection(&domain.StyleGuide{}) != "" {
+		t.Error("empty style guide should not produce a section")
+	}
+
+	section := buildStyleSection(&domain.StyleGuide{
+		Formality: "formal (Sie)",
+		Forbidden: []string{"Du", "Klicke"},
+		Examples:  []domain.StyleExample{{Source: "Save", Target: "Speichern"}},
+		Notes:     "Use sentence case in buttons.",
+	})
+	for _, want := range []string{
+		"- Formality: formal (Sie)\n",
+		`- Never use: "Du", "Klicke"`,
+		`"Save" → "Speichern"`,
+		"Use sentence case in buttons.",
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("section missing %q:\n%s", want, section)
+		}
+	}
+}
+
+func TestStyleGuideInPrompts(t *testing.T) {
+	mockProvider := provider.NewMockProvider("gpt-4")
+	bp := NewBatchProcessor(mockProvider, NewReflectionEngine(mockProvider))
+	guide := &domain.StyleGuide{Formality: "polite です/ます form"}
+	must := func(prompt string, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return prompt
+	}
+
+	items := []domain.BatchItem{{Key: "a", Text: "Save"}}
+	if prompt := must(bp.buildBatchPrompt(items, "en", "ja", "", guide)); !strings.Contains(prompt, "【Style Guide】\n- Formality: polite です/ます form") ||
+		!strings.Contains(prompt, "Follow the style guide") {
+		t.Errorf("translate prompt missing style guide:\n%s", prompt)
+	}
+	if prompt := must(bp.buildBatchPrompt(items, "en", "ja", "", nil)); strings.Contains(prompt, "Style Guide") {
+		t.Error("translate prompt should not mention a style guide without one")
+	}
+
+	input := ReflectionInput{
+		SourceTexts:     map[string]string{"a": "Save"},
+		TranslatedTexts: map[string]string{"a": "保存する"},
+		SourceLang:      "en",
+		TargetLang:      "ja",
+		StyleGuide:      guide,
+	}
+	engine := NewReflectionEngine(mockProvider)
+	if prompt := must(engine.buildReflectionPrompt(input)); !strings.Contains(prompt, "<STYLE_GUIDE>\n- Formality: polite です/ます form") {
+		t.Errorf("reflection prompt missing style guide:\n%s", prompt)
+	}
+	if prompt := must(engine.buildImprovementPrompt(input, map[string]string{"a": "Use です/ます"})); !strings.Contains(prompt, "<STYLE_GUIDE>") {
+		t.Errorf("improvement prompt missing style guide:\n%s", prompt)
+	}
+}
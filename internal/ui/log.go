@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LabelKey is the log attribute printed before the line of a translation running
+// alongside others, e.g. "[de] "
+const LabelKey = "label"
+
+// LogHandler is a slog.Handler for people reading a terminal: one styled line per
+// record, like "[de] [Batch 2] Reflected  duration=1.2s suggestions=3". The file and
+// language of a translation are left out, its header already names them.
+type LogHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	group string // prefix of the keys of attributes added to the handler
+}
+
+// NewLogHandler creates a handler writing records at or above level to w
+func NewLogHandler(w io.Writer, level slog.Leveler) *LogHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &LogHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+// Enabled reports whether records of a level are written
+func (h *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs returns a handler that adds attributes to every record
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(c.attrs[:len(c.attrs):len(c.attrs)], h.qualify(attrs)...)
+	return &c
+}
+
+// WithGroup returns a handler that qualifies the keys of later attributes with a group
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group = h.group + name + "."
+	return &c
+}
+
+// qualify prefixes the keys of attributes with the handler's group
+func (h *LogHandler) qualify(attrs []slog.Attr) []slog.Attr {
+	if h.group == "" {
+		return attrs
+	}
+	qualified := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		qualified[i] = slog.Attr{Key: h.group + a.Key, Value: a.Value}
+	}
+	return qualified
+}
+
+// Handle writes a record
+func (h *LogHandler) Handle(_ context.Context, r slog.Record) error {
+	var label, batch string
+	var details []string
+	add := func(a slog.Attr) {
+		a.Value = a.Value.Resolve()
+		switch a.Key {
+		case LabelKey:
+			label = a.Value.String()
+		case "file", "lang":
+		case "batch":
+			batch = fmt.Sprintf("[Batch %s] ", a.Value)
+		default:
+			if !a.Equal(slog.Attr{}) {
+				details = append(details, a.Key+"="+formatLogValue(a.Value))
+			}
+		}
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		for _, qa := range h.qualify([]slog.Attr{a}) {
+			add(qa)
+		}
+		return true
+	})
+
+	var msg string
+	switch {
+	case r.Level >= slog.LevelError:
+		msg = ErrorStyle.Render(IconError + " " + r.Message)
+	case r.Level >= slog.LevelWarn:
+		msg = WarningStyle.Render(IconWarning + " " + r.Message)
+	case r.Level >= slog.LevelInfo:
+		msg = r.Message
+	default:
+		msg = SubtleStyle.Render(r.Message)
+	}
+
+	line := label + batch + msg
+	if len(details) > 0 {
+		line += "  " + SubtleStyle.Render(strings.Join(details, " "))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line+"\n")
+	return err
+}
+
+// formatLogValue formats a value for reading: durations rounded, text with spaces quoted
+func formatLogValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindDuration:
+		d := v.Duration()
+		if d >= time.Second {
+			return d.Round(100 * time.Millisecond).String()
+		}
+		return d.Round(time.Millisecond).String()
+	case slog.KindString:
+		s := v.String()
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			return strconv.Quote(s)
+		}
+		return s
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(&buf, slog.LevelInfo)).
+		With("file", "en.json", "lang", "de", LabelKey, "[de] ")
+
+	logger.Debug("Reflecting", "batch", 2)
+	logger.Info("Reflected", "batch", 2, "duration", 1234*time.Millisecond, "suggestions", 3)
+	logger.Warn("Reflection failed", "batch", 3, "error", errors.New("timeout"))
+	logger.WithGroup("terms").Info("Detected", "count", 5, "note", "two words")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (debug is below the level): %q", len(lines), buf.String())
+	}
+	want := []string{
+		"[de] [Batch 2] Reflected  duration=1.2s suggestions=3",
+		"[de] [Batch 3] " + IconWarning + " Reflection failed  error=timeout",
+		`[de] Detected  terms.count=5 terms.note="two words"`,
+	}
+	for i, line := range lines {
+		if line != want[i] {
+			t.Errorf("line %d = %q, want %q", i, line, want[i])
+		}
+	}
+}
+
+func TestLogHandler_Level(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	logger := slog.New(NewLogHandler(&buf, level))
+
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("info written at warn level: %q", buf.String())
+	}
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("debug not written at debug level: %q", buf.String())
+	}
+}
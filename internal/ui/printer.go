@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 )
@@ -9,6 +11,9 @@ import (
 // Printer provides styled console output
 type Printer struct {
 	verbose bool
+	quiet   bool      // only warnings and errors are printed
+	prefix  string    // printed before every line, e.g. "[de] "
+	w       io.Writer // nil = stdout
 }
 
 // NewPrinter creates a new printer
@@ -18,50 +23,110 @@ func NewPrinter(verbose bool) *Printer {
 	}
 }
 
+// WithPrefix returns a printer that starts every line with a prefix, so the output of
+// translations running at the same time can be told apart
+func (p *Printer) WithPrefix(prefix string) *Printer {
+	return &Printer{verbose: p.verbose, quiet: p.quiet, prefix: prefix, w: p.w}
+}
+
+// SetOutput sets where the printer writes, e.g. above a live progress view
+func (p *Printer) SetOutput(w io.Writer) {
+	p.w = w
+}
+
+// out returns where the printer writes
+func (p *Printer) out() io.Writer {
+	if p.w != nil {
+		return p.w
+	}
+	return os.Stdout
+}
+
+// SetQuiet sets whether only warnings and errors are printed
+func (p *Printer) SetQuiet(quiet bool) {
+	p.quiet = quiet
+}
+
+// Quiet reports whether only warnings and errors are printed
+func (p *Printer) Quiet() bool {
+	return p.quiet
+}
+
+// Prefix returns the prefix printed before every line
+func (p *Printer) Prefix() string {
+	return p.prefix
+}
+
+// PrintNewline prints an empty line
+func (p *Printer) PrintNewline() {
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out())
+}
+
 // PrintHeader prints a section header
 func (p *Printer) PrintHeader(text string) {
-	fmt.Println(HeaderStyle.Render(text))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), p.prefix+HeaderStyle.Render(text))
 }
 
 // PrintSuccess prints a success message
 func (p *Printer) PrintSuccess(text string) {
-	fmt.Println(SuccessStyle.Render(IconSuccess + " " + text))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), p.prefix+SuccessStyle.Render(IconSuccess+" "+text))
 }
 
 // PrintError prints an error message
 func (p *Printer) PrintError(text string) {
-	fmt.Println(ErrorStyle.Render(IconError + " " + text))
+	fmt.Fprintln(p.out(), p.prefix+ErrorStyle.Render(IconError+" "+text))
 }
 
 // PrintWarning prints a warning message
 func (p *Printer) PrintWarning(text string) {
-	fmt.Println(WarningStyle.Render(IconWarning + " " + text))
+	fmt.Fprintln(p.out(), p.prefix+WarningStyle.Render(IconWarning+" "+text))
 }
 
 // PrintInfo prints an info message
 func (p *Printer) PrintInfo(text string) {
-	fmt.Println(InfoStyle.Render(IconInfo + " " + text))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), p.prefix+InfoStyle.Render(IconInfo+" "+text))
 }
 
 // PrintSubtle prints subtle text
 func (p *Printer) PrintSubtle(text string) {
-	fmt.Println(SubtleStyle.Render("   " + text))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), p.prefix+SubtleStyle.Render("   "+text))
 }
 
 // PrintStep prints a step with icon
 func (p *Printer) PrintStep(icon, text string) {
-	fmt.Printf("%s %s\n", icon, text)
+	if p.quiet {
+		return
+	}
+	fmt.Fprintf(p.out(), "%s%s %s\n", p.prefix, icon, text)
 }
 
 // PrintProgress prints a simple progress indicator
 func (p *Printer) PrintProgress(current, total int, text string) {
+	if p.quiet {
+		return
+	}
 	percentage := float64(current) / float64(total) * 100
 	barWidth := 40
 	filled := int(float64(barWidth) * float64(current) / float64(total))
 
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 
-	fmt.Printf("\r%s [%s] %.0f%% (%d/%d)",
+	fmt.Fprintf(p.out(), "\r%s [%s] %.0f%% (%d/%d)",
 		text,
 		HighlightStyle.Render(bar),
 		percentage,
@@ -70,12 +135,15 @@ func (p *Printer) PrintProgress(current, total int, text string) {
 	)
 
 	if current == total {
-		fmt.Println() // New line when complete
+		fmt.Fprintln(p.out()) // New line when complete
 	}
 }
 
 // PrintStats prints statistics in a formatted box
 func (p *Printer) PrintStats(stats map[string]any) {
+	if p.quiet {
+		return
+	}
 	var lines []string
 
 	lines = append(lines, HeaderStyle.Render(IconChart+" Statistics:"))
@@ -88,17 +156,23 @@ func (p *Printer) PrintStats(stats map[string]any) {
 		lines = append(lines, "   "+line)
 	}
 
-	fmt.Println(StatsStyle.Render(strings.Join(lines, "\n")))
+	fmt.Fprintln(p.out(), StatsStyle.Render(strings.Join(lines, "\n")))
 }
 
 // PrintSeparator prints a visual separator
 func (p *Printer) PrintSeparator() {
-	fmt.Println(SubtleStyle.Render(strings.Repeat("─", 60)))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), SubtleStyle.Render(strings.Repeat("─", 60)))
 }
 
 // PrintBox prints text in a bordered box
 func (p *Printer) PrintBox(text string) {
-	fmt.Println(BoxStyle.Render(text))
+	if p.quiet {
+		return
+	}
+	fmt.Fprintln(p.out(), BoxStyle.Render(text))
 }
 
 // FormatDuration formats a duration in a human-readable way
@@ -114,6 +188,11 @@ func (p *Printer) FormatDuration(d time.Duration) string {
 
 // FormatNumber formats a number with commas
 func (p *Printer) FormatNumber(n int) string {
+	return formatNumber(n)
+}
+
+// formatNumber formats a number with commas
+func formatNumber(n int) string {
 	s := fmt.Sprintf("%d", n)
 	if len(s) <= 3 {
 		return s
@@ -130,8 +209,8 @@ func (p *Printer) FormatNumber(n int) string {
 
 // PrintVerbose prints only in verbose mode
 func (p *Printer) PrintVerbose(text string) {
-	if p.verbose {
-		fmt.Println(SubtleStyle.Render("   [verbose] " + text))
+	if p.verbose && !p.quiet {
+		fmt.Fprintln(p.out(), p.prefix+SubtleStyle.Render("   [verbose] "+text))
 	}
 }
 
@@ -419,3 +419,45 @@ func TestSpinner_Finish(t *testing.T) {
 		})
 	}
 }
+
+func TestPrinter_WithPrefix(t *testing.T) {
+	p := NewPrinter(true).WithPrefix("[de] ")
+	output := captureOutput(func() {
+		p.PrintStep(IconRobot, "Translating...")
+		p.PrintSuccess("Saved")
+		p.PrintVerbose("details")
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), output)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "[de] ") {
+			t.Errorf("line %q should start with the prefix", line)
+		}
+	}
+	if !p.verbose || p.Prefix() != "[de] " {
+		t.Error("WithPrefix() should keep the verbosity")
+	}
+}
+
+func TestPrinter_Quiet(t *testing.T) {
+	p := NewPrinter(true)
+	p.SetQuiet(true)
+	output := captureOutput(func() {
+		p.PrintStep(IconRobot, "Translating...")
+		p.PrintSuccess("Saved")
+		p.PrintInfo("info")
+		p.PrintVerbose("details")
+		p.PrintStats(map[string]any{"Keys": 1})
+		p.WithPrefix("[de] ").PrintSubtle("subtle")
+		p.PrintWarning("careful")
+		p.PrintError("failed")
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "careful") || !strings.Contains(lines[1], "failed") {
+		t.Errorf("quiet output = %q, want only the warning and the error", output)
+	}
+}
@@ -0,0 +1,428 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+const (
+	// progressRedraw is how often the live view is redrawn
+	progressRedraw = 200 * time.Millisecond
+	// progressBarWidth is the width of a translation's progress bar
+	progressBarWidth = 24
+	// progressMaxTasks is the number of translations the live view shows at most;
+	// finished ones make room for running ones
+	progressMaxTasks = 10
+)
+
+// Progress shows the progress of a translation run: a bar per translation with its
+// batches in flight and retries, and totals with tokens, cost and ETA. On a terminal it
+// is a live view redrawn in place, below everything written through the Progress;
+// otherwise a summary line is written every interval. A nil Progress shows nothing.
+type Progress struct {
+	mu       sync.Mutex
+	w        io.Writer
+	live     bool
+	width    int // terminal width, 0 = unknown
+	interval time.Duration
+	start    time.Time
+	tasks    []*ProgressTask
+	height   int  // lines of the live view on screen
+	partial  bool // the last output didn't end its line, the view waits for the rest
+	paused   bool
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// ProgressTask is the progress of one translation, counted in batches
+type ProgressTask struct {
+	p        *Progress
+	name     string
+	start    time.Time
+	total    int
+	done     int
+	failed   int
+	skipped  int
+	inFlight int
+	retries  int
+	tokens   int
+	cost     float64
+}
+
+// NewProgress creates the progress of a run written to w: a live view when w is a
+// terminal, a summary line every interval otherwise
+func NewProgress(w io.Writer, interval time.Duration) *Progress {
+	p := &Progress{w: w, interval: interval, start: time.Now()}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(f.Fd()) {
+		p.live = true
+		if width, _, err := term.GetSize(f.Fd()); err == nil {
+			p.width = width
+		}
+	}
+	return p
+}
+
+// Live reports whether the progress is a live view
+func (p *Progress) Live() bool {
+	return p != nil && p.live
+}
+
+// Start starts updating the progress until Stop
+func (p *Progress) Start() {
+	if p == nil || p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.stopped = make(chan struct{})
+
+	interval := p.interval
+	if p.live {
+		interval = progressRedraw
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				if p.live {
+					p.redraw()
+				} else if p.unfinished() {
+					p.writeSummary("Progress")
+				}
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop stops updating the progress, leaving its final state: the live view on screen,
+// or a last summary line
+func (p *Progress) Stop() {
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+	p.stop = nil
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.live {
+		p.paused = false
+		p.redraw()
+		p.height = 0 // the final view stays above later output
+	} else if len(p.tasks) > 0 {
+		p.writeSummary("Done")
+	}
+}
+
+// Pause removes the live view until Resume, e.g. while asking a question
+func (p *Progress) Pause() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.paused = true
+}
+
+// Resume shows the live view again after Pause
+func (p *Progress) Resume() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = false
+	p.partial = false // the answer ended the question's line
+	p.draw()
+}
+
+// Write writes output above the live view
+func (p *Progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.live {
+		return p.w.Write(b)
+	}
+	p.clear()
+	n, err := p.w.Write(b)
+	if len(b) > 0 {
+		p.partial = b[len(b)-1] != '\n'
+	}
+	p.draw()
+	return n, err
+}
+
+// Task adds a translation to the progress
+func (p *Progress) Task(name string) *ProgressTask {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	task := &ProgressTask{p: p, name: name, start: time.Now()}
+	p.tasks = append(p.tasks, task)
+	return task
+}
+
+// update changes a task under the progress lock
+func (t *ProgressTask) update(f func()) {
+	if t == nil {
+		return
+	}
+	t.p.mu.Lock()
+	defer t.p.mu.Unlock()
+	f()
+}
+
+// SetTotal sets the number of batches of the translation
+func (t *ProgressTask) SetTotal(batches int) {
+	t.update(func() { t.total = batches })
+}
+
+// Start records a batch being sent
+func (t *ProgressTask) Start() {
+	t.update(func() { t.inFlight++ })
+}
+
+// Retry records a failed attempt of a batch that is tried again
+func (t *ProgressTask) Retry() {
+	t.update(func() { t.retries++ })
+}
+
+// Done records a finished batch with its tokens and cost
+func (t *ProgressTask) Done(tokens int, cost float64) {
+	t.update(func() {
+		t.inFlight = max(t.inFlight-1, 0)
+		t.done++
+		t.tokens += tokens
+		t.cost += cost
+	})
+}
+
+// Fail records a batch that failed, with the tokens and cost of its attempts
+func (t *ProgressTask) Fail(tokens int, cost float64) {
+	t.update(func() {
+		t.inFlight = max(t.inFlight-1, 0)
+		t.failed++
+		t.tokens += tokens
+		t.cost += cost
+	})
+}
+
+// Skip records a batch that was not sent
+func (t *ProgressTask) Skip() {
+	t.update(func() { t.skipped++ })
+}
+
+// finished returns the number of batches that won't be worked on anymore
+func (t *ProgressTask) finished() int {
+	return t.done + t.failed + t.skipped
+}
+
+// eta estimates the time left from the time finished batches took
+func eta(elapsed time.Duration, finished, total int) time.Duration {
+	if finished == 0 || finished >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) / float64(finished) * float64(total-finished))
+}
+
+// unfinished reports whether a task has batches left
+func (p *Progress) unfinished() bool {
+	for _, t := range p.tasks {
+		if t.total == 0 || t.finished() < t.total {
+			return true
+		}
+	}
+	return false
+}
+
+// totals adds up the tasks
+func (p *Progress) totals() (finished, total, retries, tokens int, cost float64) {
+	for _, t := range p.tasks {
+		finished += t.finished()
+		total += t.total
+		retries += t.retries
+		tokens += t.tokens
+		cost += t.cost
+	}
+	return finished, total, retries, tokens, cost
+}
+
+// clear removes the live view from the screen
+func (p *Progress) clear() {
+	if p.height > 0 {
+		fmt.Fprintf(p.w, "\r\x1b[%dA\x1b[J", p.height)
+		p.height = 0
+	}
+}
+
+// draw writes the live view below the output
+func (p *Progress) draw() {
+	if !p.live || p.paused || p.partial || len(p.tasks) == 0 {
+		return
+	}
+	lines := p.render()
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if p.width > 0 {
+			line = ansi.Truncate(line, p.width-1, "…")
+		}
+		buf.WriteString(line + "\n")
+	}
+	_, _ = p.w.Write(buf.Bytes())
+	p.height = len(lines)
+}
+
+// redraw replaces the live view
+func (p *Progress) redraw() {
+	if p.paused || p.partial {
+		return
+	}
+	p.clear()
+	p.draw()
+}
+
+// render returns the lines of the live view
+func (p *Progress) render() []string {
+	tasks := p.tasks
+	hidden := 0
+	if len(tasks) > progressMaxTasks {
+		// Running translations first, then the latest finished ones
+		var running, finished []*ProgressTask
+		for _, t := range tasks {
+			if t.finished() < t.total || t.total == 0 {
+				running = append(running, t)
+			} else {
+				finished = append(finished, t)
+			}
+		}
+		shown := running[:min(len(running), progressMaxTasks)]
+		if room := progressMaxTasks - len(shown); room > 0 {
+			shown = append(shown, finished[len(finished)-room:]...)
+		}
+		hidden = len(tasks) - len(shown)
+		tasks = shown
+	}
+
+	nameWidth := 0
+	for _, t := range tasks {
+		nameWidth = max(nameWidth, ansi.StringWidth(t.name))
+	}
+
+	var lines []string
+	for _, t := range tasks {
+		lines = append(lines, t.render(nameWidth))
+	}
+	if hidden > 0 {
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("… %d more finished", hidden)))
+	}
+
+	finished, total, retries, tokens, cost := p.totals()
+	elapsed := time.Since(p.start)
+	parts := []string{
+		fmt.Sprintf("%d/%d batches", finished, total),
+		formatNumber(tokens) + " tokens",
+	}
+	if cost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", cost))
+	}
+	if retries > 0 {
+		parts = append(parts, plural(retries, "retry", "retries"))
+	}
+	parts = append(parts, elapsed.Truncate(time.Second).String()+" elapsed")
+	if left := eta(elapsed, finished, total); left > 0 {
+		parts = append(parts, "ETA "+left.Round(time.Second).String())
+	}
+	lines = append(lines, LabelStyle.Render("Total")+" "+SubtleStyle.Render(strings.Join(parts, " · ")))
+	return lines
+}
+
+// render returns the line of a translation: bar, batches, and what is going on
+func (t *ProgressTask) render(nameWidth int) string {
+	finished := t.finished()
+	filled := 0
+	if t.total > 0 {
+		filled = progressBarWidth * finished / t.total
+	}
+	bar := HighlightStyle.Render(strings.Repeat("█", filled)) + SubtleStyle.Render(strings.Repeat("░", progressBarWidth-filled))
+
+	name := t.name + strings.Repeat(" ", nameWidth-ansi.StringWidth(t.name))
+	count := fmt.Sprintf("%*d/%d", len(fmt.Sprint(t.total)), finished, t.total)
+
+	var details []string
+	switch {
+	case t.total > 0 && finished >= t.total:
+		details = append(details, SuccessStyle.Render("done"))
+	case t.inFlight > 0:
+		details = append(details, fmt.Sprintf("%d in flight", t.inFlight))
+	}
+	if t.retries > 0 {
+		details = append(details, WarningStyle.Render(plural(t.retries, "retry", "retries")))
+	}
+	if t.failed > 0 {
+		details = append(details, ErrorStyle.Render(fmt.Sprintf("%d failed", t.failed)))
+	}
+	if t.skipped > 0 {
+		details = append(details, WarningStyle.Render(fmt.Sprintf("%d skipped", t.skipped)))
+	}
+	if left := eta(time.Since(t.start), finished, t.total); left > 0 {
+		details = append(details, SubtleStyle.Render("ETA "+left.Round(time.Second).String()))
+	}
+	return fmt.Sprintf("%s %s %s  %s", name, bar, count, strings.Join(details, "  "))
+}
+
+// writeSummary writes a line with the progress of every translation, for logs
+func (p *Progress) writeSummary(title string) {
+	finished, total, retries, tokens, cost := p.totals()
+	percent := 0
+	if total > 0 {
+		percent = finished * 100 / total
+	}
+
+	var tasks []string
+	for _, t := range p.tasks {
+		tasks = append(tasks, fmt.Sprintf("%s %d/%d", t.name, t.finished(), t.total))
+	}
+	parts := []string{
+		fmt.Sprintf("%s: %d/%d batches (%d%%)", title, finished, total, percent),
+		strings.Join(tasks, ", "),
+	}
+	if retries > 0 {
+		parts = append(parts, plural(retries, "retry", "retries"))
+	}
+	parts = append(parts, formatNumber(tokens)+" tokens")
+	if cost > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", cost))
+	}
+	elapsed := time.Since(p.start)
+	parts = append(parts, elapsed.Truncate(time.Second).String()+" elapsed")
+	if left := eta(elapsed, finished, total); left > 0 {
+		parts = append(parts, "ETA "+left.Round(time.Second).String())
+	}
+	fmt.Fprintln(p.w, strings.Join(parts, " · "))
+}
+
+// plural formats a count with the singular or plural of a noun
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
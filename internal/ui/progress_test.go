@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress_Summary(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, time.Hour)
+	if p.Live() {
+		t.Fatal("a buffer is not a terminal")
+	}
+
+	de := p.Task("de")
+	de.SetTotal(4)
+	de.Start()
+	de.Retry()
+	de.Done(1200, 0.01)
+	de.Start()
+	de.Fail(300, 0.002)
+	fr := p.Task("fr")
+	fr.SetTotal(2)
+	fr.Skip()
+
+	// Output is passed through as is
+	if _, err := p.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	p.Start()
+	p.Stop()
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "line\n") {
+		t.Errorf("output = %q, should start with the written line", out)
+	}
+	for _, want := range []string{"Done: 3/6 batches (50%)", "de 2/4, fr 1/2", "1 retry", "1,500 tokens", "$0.0120"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary %q should contain %q", out, want)
+		}
+	}
+}
+
+func TestProgress_Live(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, time.Hour)
+	p.live = true // as on a terminal
+
+	task := p.Task("de")
+	task.SetTotal(2)
+	task.Start()
+	task.Start()
+	task.Done(100, 0)
+
+	p.mu.Lock()
+	lines := p.render()
+	p.mu.Unlock()
+	if len(lines) != 2 {
+		t.Fatalf("view = %q, want a task line and the total", lines)
+	}
+	if !strings.HasPrefix(lines[0], "de ") || !strings.Contains(lines[0], "1/2") || !strings.Contains(lines[0], "1 in flight") {
+		t.Errorf("task line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "1/2 batches") || !strings.Contains(lines[1], "100 tokens") {
+		t.Errorf("total line = %q", lines[1])
+	}
+
+	// Output goes above the view, which is drawn again below it
+	_, _ = p.Write([]byte("first\n"))
+	_, _ = p.Write([]byte("second\n"))
+	out := buf.String()
+	if strings.Count(out, "\x1b[2A\x1b[J") != 1 || !strings.Contains(out, "second\n") || !strings.HasSuffix(out, lines[1]+"\n") {
+		t.Errorf("live output = %q", out)
+	}
+
+	// An unfinished line waits: the view is not drawn into it
+	buf.Reset()
+	_, _ = p.Write([]byte("Continue? [Y/n] "))
+	if !strings.HasSuffix(buf.String(), "Continue? [Y/n] ") {
+		t.Errorf("output = %q, should end with the question", buf.String())
+	}
+}
+
+func TestProgress_Nil(t *testing.T) {
+	var p *Progress
+	p.Start()
+	task := p.Task("de")
+	task.SetTotal(1)
+	task.Done(1, 0)
+	p.Pause()
+	p.Resume()
+	p.Stop()
+}
@@ -150,3 +150,30 @@ func (j *JSONUtil) SetValue(data map[string]any, path string, value any) {
 	// Simple implementation - just set at top level for now
 	data[path] = value
 }
+
+// FlattenStrings returns all string values keyed by their path
+// ("a.b" for nested objects, "a[0]" for array elements)
+func (j *JSONUtil) FlattenStrings(data map[string]any) map[string]string {
+	result := make(map[string]string)
+	flattenStrings(data, "", result)
+	return result
+}
+
+func flattenStrings(data any, prefix string, result map[string]string) {
+	switch v := data.(type) {
+	case map[string]any:
+		for key, value := range v {
+			keyPath := key
+			if prefix != "" {
+				keyPath = prefix + "." + key
+			}
+			flattenStrings(value, keyPath, result)
+		}
+	case []any:
+		for i, value := range v {
+			flattenStrings(value, fmt.Sprintf("%s[%d]", prefix, i), result)
+		}
+	case string:
+		result[prefix] = v
+	}
+}
@@ -686,3 +686,32 @@ func TestCompareJSON_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestFlattenStrings(t *testing.T) {
+	j := NewJSONUtil()
+	data := map[string]any{
+		"title": "Hello",
+		"app": map[string]any{
+			"name":  "MyApp",
+			"count": 3,
+			"tabs":  []any{"Home", "Settings"},
+		},
+	}
+
+	got := j.FlattenStrings(data)
+	want := map[string]string{
+		"title":       "Hello",
+		"app.name":    "MyApp",
+		"app.tabs[0]": "Home",
+		"app.tabs[1]": "Settings",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("FlattenStrings() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("FlattenStrings()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
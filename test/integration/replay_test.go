@@ -0,0 +1,297 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hikanner/jta/internal/cli"
+	"github.com/hikanner/jta/internal/provider"
+	"github.com/hikanner/jta/internal/utils"
+)
+
+// echoTranslator is a deterministic stand-in for a real model: it "translates" by
+// prefixing texts with the target marker and accepts every translation on reflection.
+// Unlike MockProvider it answers by prompt content, so call order doesn't matter.
+type echoTranslator struct {
+	prefix string
+	calls  atomic.Int64
+}
+
+var (
+	numberedLine = regexp.MustCompile(`^\[(\d+)\] (.*)$`)
+	keyedLine    = regexp.MustCompile(`^\[(.+?)\] (.*)$`)
+)
+
+func (p *echoTranslator) Complete(ctx context.Context, req *provider.CompletionRequest) (*provider.CompletionResponse, error) {
+	p.calls.Add(1)
+
+	var out []string
+	switch {
+	case strings.Contains(req.Prompt, "<EXPERT_SUGGESTIONS>"):
+		// Improvement: keep the initial translations
+		for _, m := range section(req.Prompt, "<INITIAL_TRANSLATIONS>", "</INITIAL_TRANSLATIONS>", keyedLine) {
+			out = append(out, fmt.Sprintf("[%s] %s", m[1], m[2]))
+		}
+	case strings.Contains(req.Prompt, "<TRANSLATIONS>"):
+		// Reflection: nothing to improve
+		for _, m := range section(req.Prompt, "<TRANSLATIONS>", "</TRANSLATIONS>", keyedLine) {
+			out = append(out, fmt.Sprintf("[%s] OK", m[1]))
+		}
+	default:
+		// Batch translation
+		for _, m := range section(req.Prompt, "【Texts to Translate】", "【Translation Results】", numberedLine) {
+			out = append(out, fmt.Sprintf("[%s] %s%s", m[1], p.prefix, m[2]))
+		}
+	}
+
+	return &provider.CompletionResponse{
+		Content: strings.Join(out, "\n"),
+		Usage:   provider.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func (p *echoTranslator) Name() string          { return "echo" }
+func (p *echoTranslator) GetModelName() string  { return "echo-model" }
+func (p *echoTranslator) ValidateConfig() error { return nil }
+
+// section returns the regexp matches of the lines between start and end markers
+func section(prompt, start, end string, re *regexp.Regexp) [][]string {
+	_, rest, ok := strings.Cut(prompt, start)
+	if !ok {
+		return nil
+	}
+	body, _, _ := strings.Cut(rest, end)
+
+	var matches [][]string
+	for line := range strings.SplitSeq(body, "\n") {
+		if m := re.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// TestAppTranslate_RecordAndReplay runs the full App.Translate flow twice: once recording
+// a cassette against a deterministic provider, then offline with --provider replay
+func TestAppTranslate_RecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jsonUtil := utils.NewJSONUtil()
+
+	sourcePath := filepath.Join(dir, "en.json")
+	source := map[string]any{
+		"app": map[string]any{
+			"title":   "My App",
+			"welcome": "Welcome, {name}!",
+		},
+		"menu":   []any{"Home", "Settings", "About"},
+		"logout": "Sign out",
+		"count":  3,
+	}
+	if err := jsonUtil.SaveJSON(sourcePath, source); err != nil {
+		t.Fatal(err)
+	}
+	cassette := filepath.Join(dir, "cassettes", "en-es.json")
+
+	params := func(output string) cli.TranslateParams {
+		return cli.TranslateParams{
+			SourcePath:      sourcePath,
+			TargetLang:      "es",
+			OutputPath:      output,
+			TerminologyDir:  filepath.Join(dir, ".jta"),
+			SkipTerminology: true,
+			NoTM:            true,
+			BatchSize:       2,
+			Concurrency:     3,
+			Yes:             true,
+		}
+	}
+
+	// Record
+	echo := &echoTranslator{prefix: "es:"}
+	recording, err := cli.NewAppWithProvider(cli.AppConfig{Cassette: cassette}, provider.NewRecordingProvider(echo))
+	if err != nil {
+		t.Fatalf("NewAppWithProvider() error = %v", err)
+	}
+	recorded := filepath.Join(dir, "recorded.json")
+	if _, err := recording.Translate(ctx, params(recorded)); err != nil {
+		t.Fatalf("recording Translate() error = %v", err)
+	}
+	if err := recording.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Replay offline
+	replaying, err := cli.NewApp(ctx, cli.AppConfig{Provider: "replay", Cassette: cassette})
+	if err != nil {
+		t.Fatalf("NewApp(replay) error = %v", err)
+	}
+	replayed := filepath.Join(dir, "replayed.json")
+	if _, err := replaying.Translate(ctx, params(replayed)); err != nil {
+		t.Fatalf("replay Translate() error = %v", err)
+	}
+
+	want, _ := os.ReadFile(recorded)
+	got, _ := os.ReadFile(replayed)
+	if string(got) != string(want) {
+		t.Errorf("replayed output differs from recorded output\nrecorded: %s\nreplayed: %s", want, got)
+	}
+
+	result, err := jsonUtil.LoadJSON(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat := jsonUtil.FlattenStrings(result)
+	expected := map[string]string{
+		"app.title":   "es:My App",
+		"app.welcome": "es:Welcome, {name}!",
+		"menu[1]":     "es:Settings",
+		"logout":      "es:Sign out",
+	}
+	for key, value := range expected {
+		if flat[key] != value {
+			t.Errorf("%s = %q, want %q", key, flat[key], value)
+		}
+	}
+	if result["count"] != float64(3) {
+		t.Errorf("non-string value changed: %v", result["count"])
+	}
+}
+
+// TestAppTranslate_TranslationMemory checks that a second file reuses earlier translations
+// of the same source strings and only sends new strings to the provider
+func TestAppTranslate_TranslationMemory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jsonUtil := utils.NewJSONUtil()
+
+	translate := func(name string, source map[string]any) (map[string]string, int64) {
+		t.Helper()
+		sourcePath := filepath.Join(dir, name+".en.json")
+		if err := jsonUtil.SaveJSON(sourcePath, source); err != nil {
+			t.Fatal(err)
+		}
+		output := filepath.Join(dir, name+".fr.json")
+
+		echo := &echoTranslator{prefix: "fr:"}
+		app, err := cli.NewAppWithProvider(cli.AppConfig{}, echo)
+		if err != nil {
+			t.Fatalf("NewAppWithProvider() error = %v", err)
+		}
+		_, err = app.Translate(ctx, cli.TranslateParams{
+			SourcePath:      sourcePath,
+			SourceLang:      "en",
+			TargetLang:      "fr",
+			OutputPath:      output,
+			TerminologyDir:  filepath.Join(dir, ".jta"),
+			SkipTerminology: true,
+			BatchSize:       10,
+			Concurrency:     1,
+			Yes:             true,
+		})
+		if err != nil {
+			t.Fatalf("Translate(%s) error = %v", name, err)
+		}
+
+		result, err := jsonUtil.LoadJSON(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return jsonUtil.FlattenStrings(result), echo.calls.Load()
+	}
+
+	_, firstCalls := translate("web", map[string]any{"save": "Save", "cancel": "Cancel"})
+	if firstCalls == 0 {
+		t.Fatal("first run made no provider calls")
+	}
+
+	// Same strings under different keys: served entirely from memory
+	flat, calls := translate("mobile", map[string]any{"actions": map[string]any{"save": "Save", "cancel": "Cancel"}})
+	if calls != 0 {
+		t.Errorf("fully remembered file made %d provider calls, want 0", calls)
+	}
+	if flat["actions.save"] != "fr:Save" || flat["actions.cancel"] != "fr:Cancel" {
+		t.Errorf("reused translations = %v", flat)
+	}
+
+	// A new string still goes to the provider
+	flat, calls = translate("desktop", map[string]any{"save": "Save", "delete": "Delete"})
+	if calls == 0 {
+		t.Error("new string made no provider calls")
+	}
+	if flat["save"] != "fr:Save" || flat["delete"] != "fr:Delete" {
+		t.Errorf("mixed translations = %v", flat)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".jta", "tm.jsonl")); err != nil {
+		t.Errorf("translation memory not saved: %v", err)
+	}
+}
+
+func TestAppTranslate_ParallelLanguages(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jsonUtil := utils.NewJSONUtil()
+
+	sourcePath := filepath.Join(dir, "en.json")
+	if err := jsonUtil.SaveJSON(sourcePath, map[string]any{"save": "Save", "cancel": "Cancel"}); err != nil {
+		t.Fatal(err)
+	}
+
+	echo := &echoTranslator{prefix: "x:"}
+	app, err := cli.NewAppWithProvider(cli.AppConfig{Concurrency: 2}, echo)
+	if err != nil {
+		t.Fatalf("NewAppWithProvider() error = %v", err)
+	}
+
+	// One app translates every language at the same time, sharing memory and limits
+	langs := []string{"fr", "de", "ja"}
+	errs := make(chan error, len(langs))
+	for _, lang := range langs {
+		go func() {
+			_, err := app.Translate(ctx, cli.TranslateParams{
+				SourcePath:      sourcePath,
+				SourceLang:      "en",
+				TargetLang:      lang,
+				OutputPath:      filepath.Join(dir, lang+".json"),
+				TerminologyDir:  filepath.Join(dir, ".jta"),
+				SkipTerminology: true,
+				BatchSize:       10,
+				Concurrency:     2,
+				Yes:             true,
+				Label:           "[" + lang + "] ",
+			})
+			errs <- err
+		}()
+	}
+	for range langs {
+		if err := <-errs; err != nil {
+			t.Fatalf("Translate() error = %v", err)
+		}
+	}
+
+	for _, lang := range langs {
+		result, err := jsonUtil.LoadJSON(filepath.Join(dir, lang+".json"))
+		if err != nil {
+			t.Fatalf("output for %s: %v", lang, err)
+		}
+		if flat := jsonUtil.FlattenStrings(result); flat["save"] != "x:Save" || flat["cancel"] != "x:Cancel" {
+			t.Errorf("%s translations = %v", lang, flat)
+		}
+	}
+
+	// Every language's translations made it into the shared translation memory
+	data, err := os.ReadFile(filepath.Join(dir, ".jta", "tm.jsonl"))
+	if err != nil {
+		t.Fatalf("translation memory not saved: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2*len(langs) {
+		t.Errorf("translation memory has %d entries, want %d", lines, 2*len(langs))
+	}
+}
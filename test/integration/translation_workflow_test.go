@@ -304,23 +304,16 @@ func TestTranslationErrorHandling(t *testing.T) {
 func TestConcurrentTranslation(t *testing.T) {
 	ctx := context.Background()
 
-	// Create mock provider with multiple responses
-	mockProvider := provider.NewMockProvider("test-model")
-	// Batch 1
-	mockProvider.AddResponse(`[1] Mensaje uno
-[2] Mensaje dos
-[3] Mensaje tres`)
-	// Batch 2
-	mockProvider.AddResponse(`[1] Mensaje cuatro
-[2] Mensaje cinco
-[3] Mensaje seis`)
+	// Answer by prompt content: with concurrent batches and reflection the call
+	// order isn't fixed, so queued mock responses can't be used here
+	echoProvider := &echoTranslator{prefix: "es:"}
 
 	// Create terminology manager
 
-	termManager := terminology.NewManager(mockProvider)
+	termManager := terminology.NewManager(echoProvider)
 
 	// Create translation engine
-	engine := translator.NewEngine(mockProvider, termManager)
+	engine := translator.NewEngine(echoProvider, termManager)
 
 	// Prepare source JSON with enough items for multiple batches
 	source := map[string]any{